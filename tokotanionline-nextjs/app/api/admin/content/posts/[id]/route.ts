@@ -37,11 +37,13 @@ export async function GET(
       );
     }
 
-    // Fetch post with ContentResult if exists
+    // Fetch post with its latest ContentResult if exists (REFRESH / OPTIMIZE add one per revision)
     const post = await prisma.blogPost.findUnique({
       where: { id: params.id },
       include: {
-        contentResult: {
+        contentResults: {
+          orderBy: { createdAt: 'desc' },
+          take: 1,
           select: {
             id: true,
             summary: true,
@@ -81,14 +83,15 @@ export async function GET(
       updatedAt: post.updatedAt,
     };
 
-    // Include ContentResult if exists
-    if (post.contentResult) {
+    // Include latest ContentResult if exists
+    const contentResult = post.contentResults[0];
+    if (contentResult) {
       response.contentResult = {
-        summary: post.contentResult.summary,
-        outline: post.contentResult.outline,
-        metrics: post.contentResult.metrics,
-        engineVersion: post.contentResult.engineVersion,
-        createdAt: post.contentResult.createdAt,
+        summary: contentResult.summary,
+        outline: contentResult.outline,
+        metrics: contentResult.metrics,
+        engineVersion: contentResult.engineVersion,
+        createdAt: contentResult.createdAt,
       };
     }
 
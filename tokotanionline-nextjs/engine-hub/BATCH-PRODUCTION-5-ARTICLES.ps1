@@ -65,8 +65,14 @@ $timestamp = Get-Date -Format "yyyyMMdd-HHmmss"
 $outputFile = "batch-production-result-$timestamp.json"
 
 try {
-    $response = Invoke-RestMethod -Uri "$serverUrl$endpoint" -Method POST -Body $payload -ContentType "application/json" -TimeoutSec 600
-    
+    # Batch runs in the background: submit, then poll the report until it stops
+    $batch = Invoke-RestMethod -Uri "$serverUrl$endpoint" -Method POST -Body $payload -ContentType "application/json" -TimeoutSec 30
+    Write-Host "Batch queued: $($batch.id)" -ForegroundColor Gray
+    $deadline = (Get-Date).AddSeconds(600)
+    do {
+        Start-Sleep -Seconds 5
+        $response = Invoke-RestMethod -Uri "$serverUrl$($batch.reportUrl)" -Method GET -TimeoutSec 30
+    } while (($response.status -eq "QUEUED" -or $response.status -eq "RUNNING") -and (Get-Date) -lt $deadline)
     # Save full response
     $response | ConvertTo-Json -Depth 10 | Out-File $outputFile -Encoding UTF8
     
@@ -101,19 +107,19 @@ try {
     Write-Host "Article Details:" -ForegroundColor Yellow
     for ($i = 0; $i -lt $articles.Count; $i++) {
         $article = $articles[$i]
-        $statusColor = if ($article.success) { "Green" } else { "Red" }
-        $statusIcon = if ($article.success) { "[OK]" } else { "[FAIL]" }
+        $statusColor = if ($article.status -eq "DONE") { "Green" } else { "Red" }
+        $statusIcon = if ($article.status -eq "DONE") { "[OK]" } else { "[FAIL]" }
         
         Write-Host "  $($i + 1). $statusIcon Keyword: $($article.keyword)" -ForegroundColor $statusColor
         Write-Host "     Status: $($article.status)" -ForegroundColor Gray
-        Write-Host "     Attempt: $($article.attempt)" -ForegroundColor Gray
+        Write-Host "     Attempt: $($article.attempts)" -ForegroundColor Gray
         
-        if ($article.success) {
+        if ($article.status -eq "DONE") {
             Write-Host "     Title: $($article.title)" -ForegroundColor Gray
             Write-Host "     Word Count: $($article.wordCount)" -ForegroundColor Gray
             Write-Host "     Images: $($article.imagesCount)" -ForegroundColor Gray
         } else {
-            Write-Host "     Error: $($article.failureReason)" -ForegroundColor Red
+            Write-Host "     Error: $($article.failureClass)" -ForegroundColor Red
         }
         Write-Host ""
     }
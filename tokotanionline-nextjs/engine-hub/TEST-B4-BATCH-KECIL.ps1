@@ -35,12 +35,18 @@ $body = @{
 } | ConvertTo-Json
 
 try {
-    $response = Invoke-RestMethod -Uri "http://localhost:8090/api/engine/ai/batch-production" -Method POST -Body $body -ContentType "application/json" -TimeoutSec 300
-    
+    # Batch runs in the background: submit, then poll the report until it stops
+    $batch = Invoke-RestMethod -Uri "http://localhost:8090/api/engine/ai/batch-production" -Method POST -Body $body -ContentType "application/json" -TimeoutSec 30
+    Write-Host "Batch queued: $($batch.id)" -ForegroundColor Gray
+    $deadline = (Get-Date).AddSeconds(300)
+    do {
+        Start-Sleep -Seconds 5
+        $response = Invoke-RestMethod -Uri "http://localhost:8090$($batch.reportUrl)" -Method GET -TimeoutSec 30
+    } while (($response.status -eq "QUEUED" -or $response.status -eq "RUNNING") -and (Get-Date) -lt $deadline)
     Write-Host "========================================" -ForegroundColor Green
     Write-Host "BATCH RESULT" -ForegroundColor Green
     Write-Host "========================================" -ForegroundColor Green
-    Write-Host "Status: $($response.status)" -ForegroundColor $(if ($response.status -eq "SUCCESS") { "Green" } else { "Red" })
+    Write-Host "Status: $($response.status)" -ForegroundColor $(if ($response.status -eq "COMPLETED") { "Green" } else { "Red" })
     Write-Host "Total Generated: $($response.totalGenerated)" -ForegroundColor $(if ($response.totalGenerated -gt 0) { "Green" } else { "Red" })
     Write-Host "Total Failed: $($response.totalFailed)" -ForegroundColor $(if ($response.totalFailed -eq 0) { "Green" } else { "Yellow" })
     Write-Host "Blacklist Count: $($response.blacklist.Count)" -ForegroundColor Gray
@@ -49,17 +55,17 @@ try {
     if ($response.articles) {
         Write-Host "Articles:" -ForegroundColor Cyan
         foreach ($article in $response.articles) {
-            $statusColor = if ($article.success) { "Green" } else { "Red" }
+            $statusColor = if ($article.status -eq "DONE") { "Green" } else { "Red" }
             Write-Host "  Keyword: $($article.keyword)" -ForegroundColor $statusColor
-            Write-Host "    Success: $($article.success)" -ForegroundColor $statusColor
+            Write-Host "    Success: $($article.status -eq "DONE")" -ForegroundColor $statusColor
             Write-Host "    Status: $($article.status)" -ForegroundColor $statusColor
-            if ($article.success) {
+            if ($article.status -eq "DONE") {
                 Write-Host "    Title: $($article.title)" -ForegroundColor Gray
                 Write-Host "    Word Count: $($article.wordCount)" -ForegroundColor Gray
                 Write-Host "    Images Count: $($article.imagesCount)" -ForegroundColor Gray
             } else {
                 Write-Host "    Error: $($article.error)" -ForegroundColor Yellow
-                Write-Host "    Failure Reason: $($article.failureReason)" -ForegroundColor Yellow
+                Write-Host "    Failure Reason: $($article.failureClass)" -ForegroundColor Yellow
             }
             Write-Host ""
         }
@@ -87,7 +93,7 @@ try {
     # Check: image jalan
     $hasImages = $false
     foreach ($article in $response.articles) {
-        if ($article.success -and $article.imagesCount -gt 0) {
+        if ($article.status -eq "DONE" -and $article.imagesCount -gt 0) {
             $hasImages = $true
             break
         }
@@ -101,11 +107,11 @@ try {
     # Check: validator lulus
     $validatorPassed = $true
     foreach ($article in $response.articles) {
-        if ($article.success -and $article.status -eq "PUBLISHED") {
+        if ($article.status -eq "DONE") {
             $validatorPassed = $true
             break
         }
-        if ($article.status -eq "VALIDATION_FAILED") {
+        if ($article.failureClass -eq "VALIDATION_FAILED") {
             $validatorPassed = $false
         }
     }
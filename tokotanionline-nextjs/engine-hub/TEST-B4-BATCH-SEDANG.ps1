@@ -40,7 +40,14 @@ $body = @{
 
 try {
     $startTime = Get-Date
-    $response = Invoke-RestMethod -Uri "http://localhost:8090/api/engine/ai/batch-production" -Method POST -Body $body -ContentType "application/json" -TimeoutSec 600
+    # Batch runs in the background: submit, then poll the report until it stops
+    $batch = Invoke-RestMethod -Uri "http://localhost:8090/api/engine/ai/batch-production" -Method POST -Body $body -ContentType "application/json" -TimeoutSec 30
+    Write-Host "Batch queued: $($batch.id)" -ForegroundColor Gray
+    $deadline = (Get-Date).AddSeconds(600)
+    do {
+        Start-Sleep -Seconds 5
+        $response = Invoke-RestMethod -Uri "http://localhost:8090$($batch.reportUrl)" -Method GET -TimeoutSec 30
+    } while (($response.status -eq "QUEUED" -or $response.status -eq "RUNNING") -and (Get-Date) -lt $deadline)
     $endTime = Get-Date
     $duration = ($endTime - $startTime).TotalSeconds
     
@@ -48,7 +55,7 @@ try {
     Write-Host "BATCH RESULT" -ForegroundColor Green
     Write-Host "========================================" -ForegroundColor Green
     Write-Host "Duration: $([math]::Round($duration, 2)) seconds" -ForegroundColor Gray
-    Write-Host "Status: $($response.status)" -ForegroundColor $(if ($response.status -eq "SUCCESS") { "Green" } else { "Red" })
+    Write-Host "Status: $($response.status)" -ForegroundColor $(if ($response.status -eq "COMPLETED") { "Green" } else { "Red" })
     Write-Host "Total Generated: $($response.totalGenerated)" -ForegroundColor $(if ($response.totalGenerated -gt 0) { "Green" } else { "Red" })
     Write-Host "Total Failed: $($response.totalFailed)" -ForegroundColor $(if ($response.totalFailed -eq 0) { "Green" } else { "Yellow" })
     Write-Host "Blacklist Count: $($response.blacklist.Count)" -ForegroundColor Gray
@@ -57,18 +64,18 @@ try {
     if ($response.articles) {
         Write-Host "Articles:" -ForegroundColor Cyan
         foreach ($article in $response.articles) {
-            $statusColor = if ($article.success) { "Green" } else { "Red" }
+            $statusColor = if ($article.status -eq "DONE") { "Green" } else { "Red" }
             Write-Host "  Keyword: $($article.keyword)" -ForegroundColor $statusColor
-            Write-Host "    Success: $($article.success)" -ForegroundColor $statusColor
+            Write-Host "    Success: $($article.status -eq "DONE")" -ForegroundColor $statusColor
             Write-Host "    Status: $($article.status)" -ForegroundColor $statusColor
-            Write-Host "    Attempt: $($article.attempt)" -ForegroundColor Gray
-            if ($article.success) {
+            Write-Host "    Attempt: $($article.attempts)" -ForegroundColor Gray
+            if ($article.status -eq "DONE") {
                 Write-Host "    Title: $($article.title)" -ForegroundColor Gray
                 Write-Host "    Word Count: $($article.wordCount)" -ForegroundColor Gray
                 Write-Host "    Images Count: $($article.imagesCount)" -ForegroundColor Gray
             } else {
                 Write-Host "    Error: $($article.error)" -ForegroundColor Yellow
-                Write-Host "    Failure Reason: $($article.failureReason)" -ForegroundColor Yellow
+                Write-Host "    Failure Reason: $($article.failureClass)" -ForegroundColor Yellow
             }
             Write-Host ""
         }
@@ -96,8 +103,8 @@ try {
     # Check: retry terkendali (max 3 attempts per keyword)
     $retryControlled = $true
     foreach ($article in $response.articles) {
-        if ($article.attempt -gt 3) {
-            Write-Host "[FAIL] Retry terkendali: Keyword '$($article.keyword)' has $($article.attempt) attempts (max 3)" -ForegroundColor Red
+        if ($article.attempts -gt 3) {
+            Write-Host "[FAIL] Retry terkendali: Keyword '$($article.keyword)' has $($article.attempts) attempts (max 3)" -ForegroundColor Red
             $retryControlled = $false
             $allPass = $false
         }
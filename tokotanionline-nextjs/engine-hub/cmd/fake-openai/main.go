@@ -0,0 +1,31 @@
+package main
+
+import (
+	"log"
+	"net/http"
+	"os"
+
+	"engine-hub/internal/ai/fakeopenai"
+)
+
+// Fake OpenAI API for running the engine offline
+//
+//	FAKE_OPENAI_ADDR (default 127.0.0.1:18111)
+//
+// Point the engine at it:
+//
+//	AI_API_URL=http://127.0.0.1:18111/v1/responses
+//	IMAGE_API_URL=http://127.0.0.1:18111/v1/images/generations
+//	LLM_V2_URL / LLM_ADS_URL=http://127.0.0.1:18111/v1/chat/completions
+//	OPENAI_API_KEY=<any value > 100 chars>
+func main() {
+	addr := os.Getenv("FAKE_OPENAI_ADDR")
+	if addr == "" {
+		addr = "127.0.0.1:18111"
+	}
+
+	log.Printf("[FAKE OPENAI] listening on http://%s", addr)
+	if err := http.ListenAndServe(addr, fakeopenai.NewHandler()); err != nil {
+		log.Fatalf("[FAKE OPENAI] server error: %v", err)
+	}
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"log"
+	"net/http"
+	"os"
+
+	"engine-hub/internal/ai/fakes3"
+)
+
+// Fake S3-compatible storage for running IMAGE_STORE=s3 offline
+//
+//	FAKE_S3_ADDR (default 127.0.0.1:18112)
+//	FAKE_S3_ACCESS_KEY / FAKE_S3_SECRET_KEY (default minioadmin / minioadmin)
+//
+// Point the engine at it:
+//
+//	IMAGE_STORE=s3
+//	IMAGE_S3_ENDPOINT=http://127.0.0.1:18112
+//	IMAGE_S3_BUCKET=engine-images
+//	IMAGE_S3_ACCESS_KEY=minioadmin
+//	IMAGE_S3_SECRET_KEY=minioadmin
+func main() {
+	addr := os.Getenv("FAKE_S3_ADDR")
+	if addr == "" {
+		addr = "127.0.0.1:18112"
+	}
+	accessKey := os.Getenv("FAKE_S3_ACCESS_KEY")
+	if accessKey == "" {
+		accessKey = "minioadmin"
+	}
+	secretKey := os.Getenv("FAKE_S3_SECRET_KEY")
+	if secretKey == "" {
+		secretKey = "minioadmin"
+	}
+
+	log.Printf("[FAKE S3] listening on http://%s", addr)
+	if err := http.ListenAndServe(addr, fakes3.NewHandler(accessKey, secretKey)); err != nil {
+		log.Fatalf("[FAKE S3] server error: %v", err)
+	}
+}
@@ -0,0 +1,252 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/joho/godotenv"
+
+	"engine-hub/internal/ai/objectstore"
+	"engine-hub/internal/content"
+)
+
+// Migrate existing local images (public/images/articles, public/images/products) into the
+// configured image object store (IMAGE_STORE, same env as the server)
+//
+//	go run ./cmd/migrate-images -dry-run                 # list what would move
+//	go run ./cmd/migrate-images                          # copy + write manifest (old path → new path/URL)
+//	go run ./cmd/migrate-images -rewrite-db -delete      # also update references in Postgres, then remove local files
+//
+// Files already stored under a content-hash key are re-uploaded with the same key (local → s3) or
+// skipped when the target is the same local folder
+// -rewrite-db updates BlogPost / Blog / Product: content columns get the public URL, image columns the /images/ path
+
+// Mapping is one migrated file
+type Mapping struct {
+	OldPath string `json:"oldPath"` // /images/articles/slug/hero.png
+	NewPath string `json:"newPath"` // /images/articles/3f/3f9c....png
+	URL     string `json:"url"`     // public URL of NewPath
+	Bytes   int    `json:"bytes"`
+	Deduped bool   `json:"deduped,omitempty"`
+}
+
+// Manifest is written after every run (also on dry-run)
+type Manifest struct {
+	Store       string    `json:"store"`
+	Source      string    `json:"source"`
+	DryRun      bool      `json:"dryRun"`
+	StartedAt   time.Time `json:"startedAt"`
+	FinishedAt  time.Time `json:"finishedAt"`
+	Migrated    []Mapping `json:"migrated"`
+	Skipped     int       `json:"skipped"`
+	Failed      []string  `json:"failed,omitempty"`
+	RowsUpdated int64     `json:"rowsUpdated,omitempty"`
+	Deleted     int       `json:"deleted,omitempty"`
+}
+
+// imageExts are the files moved (anything else in public/images is left alone)
+var imageExts = map[string]bool{".png": true, ".jpg": true, ".jpeg": true, ".webp": true, ".gif": true, ".avif": true}
+
+// dbRewrite is a column holding image references
+type dbRewrite struct {
+	Table  string
+	Column string
+	Exact  bool // whole value is one /images/ path; otherwise text containing references (content, JSON)
+}
+
+var dbRewrites = []dbRewrite{
+	{Table: "BlogPost", Column: "content"},
+	{Table: "BlogPost", Column: "featuredImageUrl", Exact: true},
+	{Table: "Blog", Column: "content"},
+	{Table: "Blog", Column: "imageUrl", Exact: true},
+	{Table: "Product", Column: "imageUrl", Exact: true},
+	{Table: "Product", Column: "images"},
+}
+
+func main() {
+	if os.Getenv("ENV") == "development" {
+		if err := godotenv.Load(); err == nil {
+			log.Println("[MIGRATE IMAGES] Loaded .env file for development")
+		}
+	}
+
+	src := flag.String("src", objectstore.DefaultLocalDir(), "Next.js public folder holding the existing images")
+	dirs := flag.String("dirs", "images/articles,images/products", "comma-separated folders below -src to migrate")
+	dryRun := flag.Bool("dry-run", false, "only list files, write nothing")
+	deleteLocal := flag.Bool("delete", false, "remove local files after a successful upload (move instead of copy)")
+	rewriteDB := flag.Bool("rewrite-db", false, "update image references in Postgres (DATABASE_URL)")
+	manifestPath := flag.String("manifest", "", "manifest output (default storage/image-migrations/<timestamp>.json)")
+	flag.Parse()
+
+	store := objectstore.FromEnv()
+	sameFolder := false
+	if local, ok := store.(*objectstore.LocalStore); ok {
+		if abs, err := filepath.Abs(*src); err == nil && abs == local.Dir() {
+			sameFolder = true
+		}
+	}
+
+	manifest := &Manifest{Store: store.Name(), Source: *src, DryRun: *dryRun, StartedAt: time.Now().UTC()}
+	ctx := context.Background()
+	var moved []string // local files uploaded successfully (for -delete)
+
+	for _, dir := range strings.Split(*dirs, ",") {
+		dir = strings.Trim(strings.TrimSpace(dir), "/")
+		if dir == "" {
+			continue
+		}
+		root := filepath.Join(*src, filepath.FromSlash(dir))
+		err := filepath.WalkDir(root, func(file string, d fs.DirEntry, err error) error {
+			if err != nil {
+				if os.IsNotExist(err) {
+					log.Printf("[MIGRATE IMAGES] %s does not exist, skipping", root)
+					return filepath.SkipDir
+				}
+				return err
+			}
+			if d.IsDir() || strings.HasPrefix(d.Name(), ".") || !imageExts[strings.ToLower(filepath.Ext(file))] {
+				return nil
+			}
+
+			rel, err := filepath.Rel(*src, file)
+			if err != nil {
+				return err
+			}
+			key := filepath.ToSlash(rel)
+			if sameFolder && objectstore.IsContentKey(key) {
+				manifest.Skipped++
+				return nil
+			}
+			if *dryRun {
+				log.Printf("[MIGRATE IMAGES] would migrate /%s", key)
+				manifest.Migrated = append(manifest.Migrated, Mapping{OldPath: "/" + key})
+				return nil
+			}
+
+			data, err := os.ReadFile(file)
+			if err != nil {
+				manifest.Failed = append(manifest.Failed, fmt.Sprintf("/%s: %v", key, err))
+				return nil
+			}
+			ext := strings.ToLower(filepath.Ext(file))
+			obj, err := objectstore.PutContent(ctx, store, dir, data, ext, objectstore.ContentTypeForExt(ext))
+			if err != nil {
+				log.Printf("[MIGRATE IMAGES] ERROR: /%s: %v", key, err)
+				manifest.Failed = append(manifest.Failed, fmt.Sprintf("/%s: %v", key, err))
+				return nil
+			}
+			manifest.Migrated = append(manifest.Migrated, Mapping{OldPath: "/" + key, NewPath: obj.Path, URL: obj.URL, Bytes: len(data), Deduped: obj.Deduped})
+			if !(sameFolder && obj.Key == key) {
+				moved = append(moved, file)
+			}
+			log.Printf("[MIGRATE IMAGES] /%s → %s", key, obj.URL)
+			return nil
+		})
+		if err != nil {
+			log.Fatalf("[MIGRATE IMAGES] Failed to walk %s: %v", root, err)
+		}
+	}
+
+	if *rewriteDB && !*dryRun && len(manifest.Migrated) > 0 {
+		rows, err := rewriteReferences(ctx, manifest.Migrated)
+		manifest.RowsUpdated = rows
+		if err != nil {
+			// Files stay in place: the old paths are still referenced
+			writeManifest(manifest, *manifestPath)
+			log.Fatalf("[MIGRATE IMAGES] Failed to rewrite database references: %v", err)
+		}
+	}
+
+	if *deleteLocal && !*dryRun && len(manifest.Failed) == 0 {
+		for _, file := range moved {
+			if err := os.Remove(file); err != nil {
+				log.Printf("[MIGRATE IMAGES] WARNING: failed to remove %s: %v", file, err)
+				continue
+			}
+			manifest.Deleted++
+		}
+	} else if *deleteLocal && len(manifest.Failed) > 0 {
+		log.Printf("[MIGRATE IMAGES] %d failures - local files kept", len(manifest.Failed))
+	}
+
+	writeManifest(manifest, *manifestPath)
+	log.Printf("[MIGRATE IMAGES] Done: migrated=%d skipped=%d failed=%d rowsUpdated=%d deleted=%d",
+		len(manifest.Migrated), manifest.Skipped, len(manifest.Failed), manifest.RowsUpdated, manifest.Deleted)
+	if len(manifest.Failed) > 0 {
+		os.Exit(1)
+	}
+}
+
+// rewriteReferences replaces old paths with the new ones in one transaction
+func rewriteReferences(ctx context.Context, mappings []Mapping) (int64, error) {
+	if err := content.InitDBContext(ctx); err != nil {
+		return 0, fmt.Errorf("failed to connect database: %w", err)
+	}
+	defer content.CloseDB()
+
+	tx, err := content.GetDB().BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var total int64
+	for _, m := range mappings {
+		if m.NewPath == "" || m.NewPath == m.OldPath {
+			continue
+		}
+		for _, rw := range dbRewrites {
+			var query string
+			var args []interface{}
+			if rw.Exact {
+				query = fmt.Sprintf(`UPDATE %q SET %q = $2 WHERE %q = $1`, rw.Table, rw.Column, rw.Column)
+				args = []interface{}{m.OldPath, m.NewPath}
+			} else {
+				// content gets the public URL (renders without the app knowing the store), JSON lists the path
+				replacement := m.URL
+				if rw.Column == "images" {
+					replacement = m.NewPath
+				}
+				query = fmt.Sprintf(`UPDATE %q SET %q = replace(%q, $1, $2) WHERE strpos(%q, $1) > 0`, rw.Table, rw.Column, rw.Column, rw.Column)
+				args = []interface{}{m.OldPath, replacement}
+			}
+			res, err := tx.ExecContext(ctx, query, args...)
+			if err != nil {
+				return total, fmt.Errorf("failed to update %s.%s: %w", rw.Table, rw.Column, err)
+			}
+			n, _ := res.RowsAffected()
+			total += n
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit: %w", err)
+	}
+	log.Printf("[MIGRATE IMAGES] Database references updated: %d rows", total)
+	return total, nil
+}
+
+func writeManifest(m *Manifest, path string) {
+	m.FinishedAt = time.Now().UTC()
+	if path == "" {
+		path = filepath.Join("storage", "image-migrations", m.StartedAt.Format("20060102-150405")+".json")
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		log.Printf("[MIGRATE IMAGES] WARNING: failed to create manifest directory: %v", err)
+		return
+	}
+	data, _ := json.MarshalIndent(m, "", "  ")
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		log.Printf("[MIGRATE IMAGES] WARNING: failed to write manifest: %v", err)
+		return
+	}
+	log.Printf("[MIGRATE IMAGES] Manifest: %s", path)
+}
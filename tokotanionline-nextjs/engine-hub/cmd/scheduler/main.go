@@ -24,6 +24,10 @@ const (
 	defaultCheckInterval = 5 * time.Minute
 	// Engine hub URL for content generation
 	defaultEngineHubURL = "http://localhost:8090"
+	// Max wait for one async generation (submit → DONE/FAILED)
+	defaultGenerationTimeout = 20 * time.Minute
+	// Status poll interval while a generation runs
+	generationPollInterval = 5 * time.Second
 )
 
 // ScheduleWorker is the main scheduler worker
@@ -31,6 +35,7 @@ type ScheduleWorker struct {
 	db              *sql.DB
 	checkInterval   time.Duration
 	engineHubURL    string
+	generationTimeout time.Duration
 	running         bool
 	stopCh          chan struct{}
 }
@@ -108,11 +113,19 @@ func main() {
 		engineHubURL = defaultEngineHubURL
 	}
 
+	generationTimeout := defaultGenerationTimeout
+	if timeoutStr := os.Getenv("SCHEDULER_GENERATION_TIMEOUT"); timeoutStr != "" {
+		if d, err := time.ParseDuration(timeoutStr); err == nil && d > 0 {
+			generationTimeout = d
+		}
+	}
+
 	// Create worker
 	worker := &ScheduleWorker{
 		db:            db,
 		checkInterval: checkInterval,
 		engineHubURL:  engineHubURL,
+		generationTimeout: generationTimeout,
 		stopCh:        make(chan struct{}),
 	}
 
@@ -343,7 +356,7 @@ func (w *ScheduleWorker) generateBlogContent(schedule ContentSchedule, keyword S
 	log.Printf("[SCHEDULER] Generating blog content for keyword: %s", keyword.PrimaryKeyword)
 
 	// Call Go engine hub to generate content
-	// Use the v2 endpoint which is the default
+	// Same request as /api/engine/ai/generate-v2, submitted to the async endpoint
 	requestBody := map[string]interface{}{
 		"contentType": "DERIVATIVE",
 		"outline":     w.buildOutline(keyword.PrimaryKeyword),
@@ -356,51 +369,10 @@ func (w *ScheduleWorker) generateBlogContent(schedule ContentSchedule, keyword S
 		return "", fmt.Errorf("failed to marshal request: %w", err)
 	}
 
-	// Call engine hub
-	url := fmt.Sprintf("%s/api/engine/ai/generate-v2", w.engineHubURL)
-	req, err := http.NewRequest("POST", url, strings.NewReader(string(jsonData)))
-	if err != nil {
-		return "", fmt.Errorf("failed to create request: %w", err)
-	}
-
-	req.Header.Set("Content-Type", "application/json")
-
-	client := &http.Client{Timeout: 5 * time.Minute}
-	resp, err := client.Do(req)
+	// Async generation: submit returns an ID at once, the draft is polled (no long-held connection)
+	draft, err := w.runGeneration(jsonData)
 	if err != nil {
-		return "", fmt.Errorf("engine hub request failed: %w", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		errorBody, _ := io.ReadAll(resp.Body)
-		return "", fmt.Errorf("engine hub returned status %d: %s", resp.StatusCode, string(errorBody))
-	}
-
-	// Parse response (matches the format from AIGenerate endpoint)
-	var engineResponse struct {
-		Success bool `json:"success"`
-		Data    struct {
-			Title      string `json:"title"`
-			Content    string `json:"content"`
-			ContentHTML string `json:"content_html,omitempty"`
-			SEO        struct {
-				Title            string `json:"title"`
-				MetaDescription  string `json:"meta_description"`
-				PrimaryKeyword   string `json:"primary_keyword,omitempty"`
-			} `json:"seo"`
-		} `json:"data"`
-		Error   string `json:"error,omitempty"`
-		Message string `json:"message,omitempty"`
-		Status  string `json:"status,omitempty"`
-	}
-
-	if err := json.NewDecoder(resp.Body).Decode(&engineResponse); err != nil {
-		return "", fmt.Errorf("failed to decode response: %w", err)
-	}
-
-	if !engineResponse.Success || engineResponse.Status == "FAILED" || engineResponse.Status == "FAILED_VALIDATION" {
-		return "", fmt.Errorf("engine hub returned error: %s (status: %s)", engineResponse.Message, engineResponse.Status)
+		return "", err
 	}
 
 	// Save to database as draft
@@ -410,10 +382,10 @@ func (w *ScheduleWorker) generateBlogContent(schedule ContentSchedule, keyword S
 		MetaTitle string
 		MetaDesc  string
 	}{
-		Title:     engineResponse.Data.Title,
-		Body:      engineResponse.Data.Content,
-		MetaTitle: engineResponse.Data.SEO.Title,
-		MetaDesc:  engineResponse.Data.SEO.MetaDescription,
+		Title:     draft.Content.Title,
+		Body:      draft.Content.Body,
+		MetaTitle: draft.Content.MetaTitle,
+		MetaDesc:  draft.Content.MetaDesc,
 	}
 
 	articleID, err := w.saveBlogPostAsDraft(contentData, keyword, schedule)
@@ -424,6 +396,92 @@ func (w *ScheduleWorker) generateBlogContent(schedule ContentSchedule, keyword S
 	return articleID, nil
 }
 
+// generationDraft is the part of the engine hub DraftAI the scheduler saves
+type generationDraft struct {
+	Content struct {
+		Title     string `json:"title"`
+		Body      string `json:"body"`
+		MetaTitle string `json:"metaTitle"`
+		MetaDesc  string `json:"metaDesc"`
+	} `json:"content"`
+	Status string `json:"status"`
+}
+
+// runGeneration submits to /api/engine/ai/generate/async and polls the result until DONE, FAILED or timeout
+func (w *ScheduleWorker) runGeneration(body []byte) (*generationDraft, error) {
+	client := &http.Client{Timeout: 30 * time.Second}
+
+	resp, err := client.Post(w.engineHubURL+"/api/engine/ai/generate/async", "application/json", strings.NewReader(string(body)))
+	if err != nil {
+		return nil, fmt.Errorf("engine hub request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusAccepted {
+		errorBody, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("engine hub returned status %d: %s", resp.StatusCode, string(errorBody))
+	}
+	var submitted struct {
+		ID string `json:"id"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&submitted); err != nil {
+		return nil, fmt.Errorf("failed to decode submit response: %w", err)
+	}
+	log.Printf("[SCHEDULER] Generation %s submitted, polling result...", submitted.ID)
+
+	resultURL := fmt.Sprintf("%s/api/engine/ai/generate/result?id=%s", w.engineHubURL, submitted.ID)
+	deadline := time.Now().Add(w.generationTimeout)
+	for {
+		select {
+		case <-w.stopCh:
+			return nil, fmt.Errorf("scheduler stopped while waiting for generation %s", submitted.ID)
+		case <-time.After(generationPollInterval):
+		}
+
+		draft, done, err := w.fetchGenerationResult(client, resultURL)
+		if err != nil {
+			return nil, fmt.Errorf("generation %s: %w", submitted.ID, err)
+		}
+		if done {
+			return draft, nil
+		}
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("generation %s not finished after %v", submitted.ID, w.generationTimeout)
+		}
+	}
+}
+
+// fetchGenerationResult returns done=false while the generation is queued/running
+func (w *ScheduleWorker) fetchGenerationResult(client *http.Client, resultURL string) (*generationDraft, bool, error) {
+	resp, err := client.Get(resultURL)
+	if err != nil {
+		// Transient - keep polling until the deadline
+		log.Printf("[SCHEDULER] Result poll failed (will retry): %v", err)
+		return nil, false, nil
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		var draft generationDraft
+		if err := json.NewDecoder(resp.Body).Decode(&draft); err != nil {
+			return nil, false, fmt.Errorf("failed to decode result: %w", err)
+		}
+		return &draft, true, nil
+	case http.StatusAccepted:
+		return nil, false, nil
+	case http.StatusConflict:
+		var failed struct {
+			Error string `json:"error"`
+			State string `json:"state"`
+		}
+		json.NewDecoder(resp.Body).Decode(&failed)
+		return nil, false, fmt.Errorf("engine hub returned error: %s (state: %s)", failed.Error, failed.State)
+	default:
+		errorBody, _ := io.ReadAll(resp.Body)
+		return nil, false, fmt.Errorf("engine hub returned status %d: %s", resp.StatusCode, string(errorBody))
+	}
+}
+
 // generateProductContent generates product content for a keyword
 func (w *ScheduleWorker) generateProductContent(schedule ContentSchedule, keyword ScheduleKeyword) (string, error) {
 	// Similar to blog, but for products
@@ -1,10 +1,16 @@
 package main
 
 import (
+	"context"
+	"fmt"
+	"io"
 	"log"
 	"net/http"
 	"os"
+	"os/signal"
 	"strings"
+	"syscall"
+	"time"
 
 	"github.com/joho/godotenv"
 
@@ -14,6 +20,15 @@ import (
 	"engine-hub/internal/jobs"
 	"engine-hub/internal/marketing"
 	seoworker "engine-hub/internal/seo"
+	"engine-hub/internal/ai/batch"
+	"engine-hub/internal/ai/compliance"
+	"engine-hub/internal/ai/experiment"
+	"engine-hub/internal/ai/generation"
+	"engine-hub/internal/ai/image"
+	"engine-hub/internal/ai/imageregen"
+	"engine-hub/internal/ai/prompts"
+	"engine-hub/internal/ai/quality"
+	"engine-hub/internal/ai/usage"
 	v2 "engine-hub/internal/ai/v2"
 )
 
@@ -27,6 +42,9 @@ func main() {
 		}
 	}()
 
+	// Route std log lines ("[TAG] ...") into the structured engine log store
+	log.SetOutput(io.MultiWriter(os.Stderr, engine.StdLogWriter{}))
+
 	log.Println("[BOOT] Server starting...")
 
 	// Load .env file ONLY for development (ENV=development)
@@ -80,48 +98,108 @@ func main() {
 	
 	// Log eksplisit API key status (setelah semua validasi lulus)
 	log.Printf("[BOOT] OPENAI_API_KEY: present=true, length=%d, valid=true", len(apiKey))
-	log.Println("[BOOT] Registering Tracking Engine...")
+	log.Println("[BOOT] Registering engines...")
 	// Register Tracking Engine
 	tracking := engine.NewTrackingEngine()
 	engine.Register(tracking)
 
-	log.Println("[BOOT] Starting Tracking Engine...")
-	// Start engine
-	if err := tracking.Start(); err != nil {
+	// STEP 20C: Content Engine
+	// Note: Content engine can run in DEV MODE without database for AI pipeline testing
+	contentEngine := content.NewContentEngine()
+	engine.Register(contentEngine)
+
+	// FASE D - D1: Daily Scheduler (depends on content, requires database)
+	engine.Register(content.NewDailyScheduler())
+
+	// STEP 22B-3: Marketing dispatcher (manual start via /api/engines/control)
+	engine.Register(marketing.NewDispatcherEngine(content.GetDB))
+
+	// Resource checks for Descriptor.Requires
+	engine.RegisterResource(engine.ResourceDatabase, func() error {
+		db := content.GetDB()
+		if db == nil {
+			return fmt.Errorf("database connection not initialized")
+		}
+		return db.Ping()
+	})
+
+	// Start engines in dependency order
+	log.Println("[BOOT] Starting engines...")
+	bootCtx, cancelBoot := context.WithTimeout(context.Background(), 60*time.Second)
+	failures := engine.StartAll(bootCtx)
+	cancelBoot()
+	if err, failed := failures[tracking.Name()]; failed {
 		log.Fatal("[BOOT] Failed to start tracking engine:", err)
 	}
-	log.Println("[BOOT] Tracking Engine started")
+	for name, err := range failures {
+		log.Printf("[BOOT] WARNING: Engine %s not started: %v", name, err)
+	}
+	for name, status := range engine.GetStatuses() {
+		log.Printf("[BOOT] Engine %s: %s", name, status)
+	}
 
-	// Test job execution
-	log.Println("[BOOT] Starting test job execution...")
-	go jobs.Run("tracking", "initial-metrics-scan")
+	// Job handlers: jobs.Run / RunJobByID dispatch by job Type or Engine
+	jobs.RegisterHandler(tracking.Name(), func(ctx context.Context, job jobs.Job) (string, error) {
+		return tracking.RunJob(ctx, job.Name)
+	})
+	// Content: job name = ContentJob id (empty = oldest PENDING job), runs it under the runner's timeout / cancel
+	jobs.RegisterHandler(contentEngine.Name(), func(ctx context.Context, job jobs.Job) (string, error) {
+		return contentEngine.RunJob(ctx, job.Name)
+	})
 
-	log.Println("[BOOT] Initializing Content Engine...")
-	// STEP 20C: Start Content Engine
-	// Note: Content engine can run in DEV MODE without database for AI pipeline testing
-	contentEngine := content.NewContentEngine()
-	if err := contentEngine.Start(); err != nil {
-		log.Printf("[BOOT] WARNING: Failed to start content engine: %v", err)
-		log.Println("[BOOT] Content engine will not process jobs until database is configured")
+	// Job store & engine logs: persist in Postgres when database is available
+	if db := content.GetDB(); db != nil {
+		jobs.SetStore(jobs.NewPostgresStore(db))
+		log.Println("[BOOT] Job store: Postgres")
+		engine.SetLogSink(engine.NewPostgresLogSink(db))
+		log.Println("[BOOT] Engine log sink: Postgres")
+		usage.SetStore(usage.NewPostgresStore(db))
+		log.Println("[BOOT] AI usage ledger: Postgres")
+		prompts.SetStore(prompts.NewPostgresStore(db))
+		log.Println("[BOOT] Prompt templates: Postgres")
+		compliance.SetStore(compliance.NewPostgresStore(db))
+		log.Println("[BOOT] Compliance rule packs: Postgres")
+		quality.SetSampleStore(quality.NewPostgresSampleStore(db))
+		quality.SetProposalStore(quality.NewPostgresProposalStore(db))
+		log.Println("[BOOT] Generation samples & prompt proposals: Postgres")
+		experiment.SetStore(experiment.NewPostgresStore(db))
+		log.Println("[BOOT] Prompt experiments: Postgres")
+		generation.SetStore(generation.NewPostgresStore(db))
+		log.Println("[BOOT] Async generations: Postgres")
+		batch.SetStore(batch.NewPostgresStore(db))
+		log.Println("[BOOT] Batch production: Postgres")
+		image.SetHashStore(image.NewPostgresHashStore(db))
+		log.Println("[BOOT] Image hash index: Postgres")
+		imageregen.SetStore(imageregen.NewPostgresStore(db))
+		log.Println("[BOOT] Image revisions: Postgres")
+		image.SetLibraryStore(image.NewPostgresLibraryStore(db))
+		log.Println("[BOOT] Image library: Postgres")
 	} else {
-		log.Println("[BOOT] Content engine started successfully")
-		// Note: If no database, engine runs in DEV MODE (AI pipeline still works)
+		log.Println("[BOOT] Job store: in-memory (database not available)")
+		log.Println("[BOOT] Engine log sink: in-memory ring buffer only (database not available)")
+		log.Println("[BOOT] AI usage ledger: in-memory (database not available)")
+		log.Println("[BOOT] Prompt templates: builtin + PROMPT_TEMPLATE_DIR, edits in-memory (database not available)")
+		log.Println("[BOOT] Compliance rule packs: builtin + COMPLIANCE_RULES_DIR, edits in-memory (database not available)")
+		log.Println("[BOOT] Generation samples & prompt proposals: in-memory (database not available)")
+		log.Println("[BOOT] Prompt experiments: in-memory (database not available)")
+		log.Println("[BOOT] Async generations: in-memory (database not available)")
+		log.Println("[BOOT] Batch production: in-memory (database not available)")
+		log.Println("[BOOT] Image hash index: in-memory (database not available)")
+		log.Println("[BOOT] Image revisions: in-memory (database not available, regenerate with draft only)")
+		log.Println("[BOOT] Image library: in-memory (database not available, uploads lost on restart)")
 	}
+	generation.RecoverInterrupted()
+	batch.ResumeInterrupted()
+	pruneStop := make(chan struct{})
+	jobs.StartPruning(jobs.RetentionPeriod(), 1*time.Hour, pruneStop)
+	engine.StartLogPruning(engine.LogRetentionPeriod(), 1*time.Hour, pruneStop)
+	quality.StartRefinement(quality.RefinementInterval(), pruneStop)
+	generation.StartPruning(generation.RetentionPeriod(), 1*time.Hour, pruneStop)
+	compliance.StartAutoReload(compliance.ReloadInterval(), pruneStop)
 
-	// FASE D - D1: Start Daily Scheduler (if database available)
-	// Only start if content engine started successfully (database available)
-	if contentEngine.IsRunning() && content.GetDB() != nil {
-		log.Println("[BOOT] Initializing Daily Scheduler...")
-		scheduler := content.NewDailyScheduler()
-		if err := scheduler.Start(); err != nil {
-			log.Printf("[BOOT] WARNING: Failed to start scheduler: %v", err)
-			log.Println("[BOOT] Scheduler will not create jobs")
-		} else {
-			log.Println("[BOOT] Daily scheduler started successfully")
-		}
-	} else {
-		log.Println("[BOOT] Skipping scheduler (database not available)")
-	}
+	// Test job execution
+	log.Println("[BOOT] Starting test job execution...")
+	go jobs.Run("tracking", "initial-metrics-scan")
 
 	log.Println("[BOOT] Initializing Event Emitter...")
 	// STEP 22B-3: Initialize Event Emitter (config only, no auto-fire)
@@ -140,6 +218,7 @@ func main() {
 	http.HandleFunc("/api/engines/control", api.ControlEngine)
 	http.HandleFunc("/engines/control", api.ControlEngine) // Backward compatibility
 	http.HandleFunc("/api/engines/logs", api.EngineLogs)
+	http.HandleFunc("/api/engines/logs/stream", api.EngineLogStream) // SSE live tail
 	http.HandleFunc("/engines/logs", api.EngineLogs) // Backward compatibility
 	http.HandleFunc("/logs", api.EngineLogs)         // STEP 18C-1: Central logs endpoint
 
@@ -150,7 +229,7 @@ func main() {
 	// STEP 18B-1: Manual run endpoint - POST /api/jobs/{id}/run
 	// Note: Go's http.ServeMux doesn't support path parameters directly,
 	// so we'll handle it in the handler function
-	http.HandleFunc("/api/jobs/", api.HandleJobRun)        // Handles /api/jobs/{id}/run
+	http.HandleFunc("/api/jobs/", api.HandleJobRun)        // Handles /api/jobs/{id}/run and /api/jobs/{id}/cancel
 	http.HandleFunc("/engines/jobs/run", api.RunEngineJob) // Backward compatibility (old format)
 
 	http.HandleFunc("/api/jobs/results", api.EngineJobResults)
@@ -175,8 +254,48 @@ func main() {
 	// M-05: Product Image Generation endpoint
 	log.Println("[BOOT] Registering Product Image Generation endpoint (M-05)...")
 	http.HandleFunc("/api/engine/ai/generate-product-images", api.AIGenerateProductImages)
+
+	// Single-image regeneration - one article/product image again, old one kept as a revision
+	http.HandleFunc("/api/engine/ai/images/regenerate", api.AIImageRegenerate)      // POST {slug, section|role, prompt?, draft?}
+	http.HandleFunc("/api/engine/ai/images/revisions", api.AIImageRevisions)        // GET ?slug&kind&limit
+	http.HandleFunc("/api/engine/ai/images/revisions/restore", api.AIImageRestore)  // POST {id, requestedBy}
+	http.HandleFunc("/api/engine/ai/images/library", api.AIImageLibrary)            // GET ?tag&q | POST multipart {file, tags, keywords, altText, source}
+	http.HandleFunc("/api/engine/ai/images/library/tags", api.AIImageLibraryTags)   // POST {id, tags?, keywords?, altText?, source?, disabled?}
+	http.HandleFunc("/api/engine/ai/images/library/match", api.AIImageLibraryMatch) // GET ?heading&limit (preview, no usage recorded)
+
+	// Async generation - submit returns an ID immediately, poll status/result or register a webhook
+	http.HandleFunc("/api/engine/ai/generate/async", api.AIGenerateAsync)     // POST ContentRequest + webhookUrl → 202 {id}
+	http.HandleFunc("/api/engine/ai/generate/status", api.AIGenerationStatus) // GET ?id= state, steps, progress
+	http.HandleFunc("/api/engine/ai/generate/result", api.AIGenerationResult) // GET ?id= DraftAI (202 while running)
 	log.Println("[BOOT] AI Generate endpoints registered")
 
+	// AI usage ledger report - GET /api/engine/ai/usage?groupBy=day,brand,endpoint
+	http.HandleFunc("/api/engine/ai/usage", api.AIUsage)
+
+	// Prompt template registry - versioned prompts, editable without a release
+	http.HandleFunc("/api/engine/ai/prompts", api.AIPrompts)                  // GET list, POST new version
+	http.HandleFunc("/api/engine/ai/prompts/activate", api.AIPromptActivate) // POST {name, version}
+	http.HandleFunc("/api/engine/ai/prompts/preview", api.AIPromptPreview)   // POST {name, version|body, vars}
+	http.HandleFunc("/api/engine/ai/prompts/reload", api.AIPromptReload)     // POST re-read PROMPT_TEMPLATE_DIR + DB
+
+	// Compliance rule packs - normalizer/validator wording rules per brand and locale
+	http.HandleFunc("/api/engine/ai/compliance/packs", api.AICompliancePacks)    // GET list (?brand&locale = effective rules), POST save
+	http.HandleFunc("/api/engine/ai/compliance/reload", api.AIComplianceReload)  // POST re-read COMPLIANCE_RULES_DIR + DB
+	http.HandleFunc("/api/engine/ai/compliance/dry-run", api.AIComplianceDryRun) // POST {text, brand, locale, pack?}
+
+	// Closed-loop prompt refinement - samples from controlled production, proposals need approval
+	http.HandleFunc("/api/engine/ai/samples", api.AISamples)                                 // GET ?category&contentType&promptVersion&pass
+	http.HandleFunc("/api/engine/ai/prompts/refine", api.AIPromptRefine)                     // GET last pass, POST run now
+	http.HandleFunc("/api/engine/ai/prompts/proposals", api.AIPromptProposals)               // GET ?name&status&id
+	http.HandleFunc("/api/engine/ai/prompts/proposals/approve", api.AIPromptProposalApprove) // POST {id, decidedBy}
+	http.HandleFunc("/api/engine/ai/prompts/proposals/reject", api.AIPromptProposalReject)   // POST {id, decidedBy, note}
+
+	// Prompt A/B experiments - traffic split across /generate and batch production
+	http.HandleFunc("/api/engine/ai/experiments", api.AIExperiments)               // GET list, POST start
+	http.HandleFunc("/api/engine/ai/experiments/report", api.AIExperimentReport)   // GET ?id= per-arm CIs + winner
+	http.HandleFunc("/api/engine/ai/experiments/stop", api.AIExperimentStop)       // POST {id, promote}
+	http.HandleFunc("/api/engine/ai/experiments/outcome", api.AIExperimentOutcome) // POST {exposureId|contentKey, seoScore, impressions, clicks, dwellSeconds}
+
 	// Controlled Production endpoint - POST /api/engine/ai/controlled-production
 	// BACKEND ONLY - for quality learning system (closed-loop)
 	log.Println("[BOOT] Registering Controlled Production endpoint...")
@@ -185,8 +304,12 @@ func main() {
 
 	// Batch Production endpoint - POST /api/engine/ai/batch-production
 	// CTO FINAL - LOCKED: Production batch generation with retry logic and keyword rotation
+	// Runs in the background with per-keyword checkpoints (resumed after restart)
 	log.Println("[BOOT] Registering Batch Production endpoint...")
-	http.HandleFunc("/api/engine/ai/batch-production", api.BatchProduction)
+	http.HandleFunc("/api/engine/ai/batch-production", api.BatchProduction)                // POST config + keywords → 202 {id}
+	http.HandleFunc("/api/engine/ai/batch-production/batches", api.BatchProductionBatches) // GET ?id= | ?status&limit
+	http.HandleFunc("/api/engine/ai/batch-production/control", api.BatchProductionControl) // POST {id, action: pause|resume|cancel|retry-failed}
+	http.HandleFunc("/api/engine/ai/batch-production/report", api.BatchProductionReport)   // GET ?id= counts, failures, blacklist, summary
 	log.Println("[BOOT] Batch Production endpoint registered")
 
 	// PHASE 1: AI Generator v2 endpoints
@@ -246,6 +369,37 @@ func main() {
 		port = ":" + envPort
 	}
 	
-	log.Printf("[BOOT] ENGINE HUB RUNNING ON %s", port)
-	log.Fatal(http.ListenAndServe(port, nil))
+	// Flush buffered engine logs on shutdown (after engines stopped)
+	// Dispatch audit entries need no flush: each one is written to stdout as it is logged
+	engine.RegisterFlusher("engine-logs", engine.FlushLogSink)
+
+	server := &http.Server{Addr: port}
+	// SSE log streams never end on their own - close them so Shutdown doesn't wait for the deadline
+	server.RegisterOnShutdown(engine.CloseLogStreams)
+
+	// Graceful shutdown on SIGTERM/SIGINT (same pattern as cmd/scheduler)
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, os.Interrupt, syscall.SIGTERM)
+
+	serverErr := make(chan error, 1)
+	go func() {
+		log.Printf("[BOOT] ENGINE HUB RUNNING ON %s", port)
+		serverErr <- server.ListenAndServe()
+	}()
+
+	exitCode := 0
+	select {
+	case sig := <-sigCh:
+		log.Printf("[SHUTDOWN] Received %v, shutting down gracefully...", sig)
+	case err := <-serverErr:
+		if err != nil && err != http.ErrServerClosed {
+			log.Printf("[SHUTDOWN] HTTP server failed: %v", err)
+			exitCode = 1
+		}
+	}
+
+	shutdown(server, pruneStop)
+	if exitCode != 0 {
+		os.Exit(exitCode)
+	}
 }
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"engine-hub/internal/ai/batch"
+	"engine-hub/internal/ai/generation"
+	v2 "engine-hub/internal/ai/v2"
+	"engine-hub/internal/content"
+	"engine-hub/internal/engine"
+	"engine-hub/internal/jobs"
+	"engine-hub/internal/marketing"
+)
+
+// shutdownTimeout reads SHUTDOWN_TIMEOUT_SEC from env (default 30 detik)
+// Content jobs still running after the deadline keep their lease and are reclaimed by the reaper on next boot
+func shutdownTimeout() time.Duration {
+	seconds := 30
+	if v := os.Getenv("SHUTDOWN_TIMEOUT_SEC"); v != "" {
+		if val, err := strconv.Atoi(v); err == nil && val > 0 {
+			seconds = val
+		}
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// shutdown stops the server in order:
+// 1. HTTP server (no new requests, wait for in-flight requests)
+// 2. Engines in reverse dependency order (content engine drains its workers)
+// 3. Engine-hub jobs (/api/jobs), async generations and batch production (batches resume at next boot)
+// 4. Event handlers and marketing sends
+// 5. Flush buffered engine logs, close database
+func shutdown(server *http.Server, pruneStop chan struct{}) {
+	timeout := shutdownTimeout()
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	log.Printf("[SHUTDOWN] Deadline: %v", timeout)
+
+	log.Println("[SHUTDOWN] Stopping HTTP server...")
+	if err := server.Shutdown(ctx); err != nil {
+		log.Printf("[SHUTDOWN] WARNING: HTTP server shutdown: %v", err)
+	}
+
+	close(pruneStop)
+
+	log.Println("[SHUTDOWN] Stopping engines...")
+	for name, err := range engine.StopAll(ctx) {
+		log.Printf("[SHUTDOWN] WARNING: Engine %s: %v", name, err)
+	}
+
+	log.Println("[SHUTDOWN] Draining in-flight jobs...")
+	if err := jobs.Drain(ctx); err != nil {
+		log.Printf("[SHUTDOWN] WARNING: %v", err)
+	}
+	if err := generation.Drain(ctx); err != nil {
+		log.Printf("[SHUTDOWN] WARNING: %v", err)
+	}
+	if err := batch.Drain(ctx); err != nil {
+		log.Printf("[SHUTDOWN] WARNING: %v", err)
+	}
+
+	log.Println("[SHUTDOWN] Draining event handlers...")
+	if emitter := v2.GetEventEmitter(); emitter != nil {
+		if err := emitter.Drain(ctx); err != nil {
+			log.Printf("[SHUTDOWN] WARNING: %v", err)
+		}
+	}
+	if err := marketing.Drain(ctx); err != nil {
+		log.Printf("[SHUTDOWN] WARNING: %v", err)
+	}
+
+	engine.AddLog(engine.EngineLog{
+		Level:     "INFO",
+		Message:   "engine-hub: shutdown complete",
+		Timestamp: time.Now(),
+	})
+
+	log.Println("[SHUTDOWN] Flushing logs...")
+	for name, err := range engine.FlushAll() {
+		log.Printf("[SHUTDOWN] WARNING: Flush %s: %v", name, err)
+	}
+
+	if err := content.CloseDB(); err != nil {
+		log.Printf("[SHUTDOWN] WARNING: Failed to close database: %v", err)
+	}
+
+	log.Println("[SHUTDOWN] Engine hub stopped")
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"log"
@@ -10,6 +11,7 @@ import (
 
 	"engine-hub/internal/ai/content"
 	aiError "engine-hub/internal/ai/error"
+	"engine-hub/internal/ai/fakeopenai"
 	"engine-hub/internal/ai/validate"
 	"engine-hub/internal/ai/workflow"
 )
@@ -42,6 +44,26 @@ func main() {
 	log.Println("=" + strings.Repeat("=", 80))
 	log.Println()
 
+	// Offline mode: PHASE_A_FAKE_OPENAI=true runs against the bundled fake OpenAI server (no API key needed)
+	// Replaying recorded calls instead: AI_CASSETTE_MODE=replay AI_CASSETTE_DIR=<dir> (record once with AI_CASSETTE_MODE=record)
+	if strings.ToLower(os.Getenv("PHASE_A_FAKE_OPENAI")) == "true" {
+		fake := fakeopenai.NewServer()
+		defer fake.Close()
+		restore := fake.Apply()
+		defer restore()
+
+		// Keep fake images out of public/images (local object store root)
+		if os.Getenv("IMAGE_LOCAL_DIR") == "" && os.Getenv("IMAGE_STORAGE_DIR") == "" {
+			dir, err := os.MkdirTemp("", "phase-a-images-")
+			if err == nil {
+				os.Setenv("IMAGE_LOCAL_DIR", dir)
+				defer os.RemoveAll(dir)
+			}
+		}
+		log.Printf("[TEST] Using fake OpenAI server at %s", fake.URL)
+		log.Println()
+	}
+
 	report := TestReport{
 		Phase:     "PHASE A — Backend Runtime Verification",
 		Steps:     []string{"A1", "A2", "A3"},
@@ -119,7 +141,7 @@ Sub-topik:
 	}
 
 	log.Println("[A1] Executing pipeline...")
-	draft, err := pipeline.Execute(req)
+	draft, err := pipeline.Execute(context.Background(), req)
 
 	if err != nil {
 		result.ErrorMessage = err.Error()
@@ -354,7 +376,7 @@ Sub-topik:
 	// 3. Error classification works correctly
 	
 	log.Println("[A3] Calling ExecuteWithRetry with maxRetries=2...")
-	draft, err := pipeline.ExecuteWithRetry(req, 2)
+	draft, err := pipeline.ExecuteWithRetry(context.Background(), req, 2)
 
 	if err != nil {
 		// Error occurred - verify classification and retry behavior
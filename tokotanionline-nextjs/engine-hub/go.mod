@@ -3,8 +3,11 @@ module engine-hub
 go 1.21
 
 require (
+	github.com/chai2010/webp v1.4.0
 	github.com/google/uuid v1.6.0
 	github.com/lib/pq v1.10.9
+	golang.org/x/image v0.24.0
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require github.com/joho/godotenv v1.5.1
@@ -0,0 +1,450 @@
+package batch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+
+	"engine-hub/internal/ai/usage"
+)
+
+// Batch statuses
+const (
+	StatusQueued    = "QUEUED"  // waiting for a batch worker
+	StatusRunning   = "RUNNING" // also left as-is on shutdown, resumed at next boot
+	StatusPaused    = "PAUSED"
+	StatusCompleted = "COMPLETED"
+	StatusCancelled = "CANCELLED"
+)
+
+// Keyword item statuses
+const (
+	ItemPending = "PENDING"
+	ItemRunning = "RUNNING"
+	ItemDone    = "DONE"
+	ItemFailed  = "FAILED"
+	ItemSkipped = "SKIPPED" // batch size reached or batch cancelled
+)
+
+// Failure classes (FASE B - B3: ERROR CLASSIFICATION)
+const (
+	FailureValidation = "VALIDATION_FAILED"
+	FailureInfra      = "INFRA_ERROR"     // never blacklisted
+	FailureContent    = "CONTENT_FAILED"  // blacklisted
+	FailureUnknown    = "UNKNOWN_FAILURE" // conservative: blacklisted
+)
+
+// MAX_ATTEMPT is the maximum number of attempts per keyword (CTO FINAL - LOCKED)
+const MAX_ATTEMPT = 3
+
+var (
+	ErrNotFound       = errors.New("batch not found")
+	ErrInvalidState   = errors.New("action not allowed in current batch status")
+	ErrNothingToRetry = errors.New("batch has no failed keywords")
+	ErrNoKeywords     = errors.New("keywords array is required")
+)
+
+// Cancel causes for a running batch
+var (
+	errCancelled    = errors.New("batch cancelled")
+	errShuttingDown = errors.New("server shutting down")
+)
+
+// Config is the batch production request (CTO FINAL - LOCKED defaults: PRODUCTION, 5, DERIVATIVE_LONG)
+type Config struct {
+	Mode            string `json:"mode"`            // PRODUCTION
+	BatchSize       int    `json:"batchSize"`       // articles to generate (keywords beyond are rotation pool)
+	ContentType     string `json:"contentType"`     // DERIVATIVE_LONG
+	ImageMode       string `json:"imageMode"`       // RAW_PHOTO
+	Storage         string `json:"storage"`         // LOCAL
+	RetryLogic      string `json:"retryLogic"`      // ON = blacklist content failures
+	KeywordRotation string `json:"keywordRotation"` // ON
+	Category        string `json:"category"`        // K1
+	Language        string `json:"language"`        // id-ID
+	Brand           string `json:"brand,omitempty"` // compliance rule packs (with language)
+}
+
+// applyDefaults fills the locked defaults
+func (c *Config) applyDefaults() {
+	if c.Mode == "" {
+		c.Mode = "PRODUCTION"
+	}
+	if c.BatchSize <= 0 {
+		c.BatchSize = 5
+	}
+	if c.ContentType == "" {
+		c.ContentType = "DERIVATIVE_LONG"
+	}
+	if c.ImageMode == "" {
+		c.ImageMode = "RAW_PHOTO"
+	}
+	if c.Storage == "" {
+		c.Storage = "LOCAL"
+	}
+	if c.RetryLogic == "" {
+		c.RetryLogic = "ON"
+	}
+	if c.KeywordRotation == "" {
+		c.KeywordRotation = "ON"
+	}
+	if c.Language == "" {
+		c.Language = "id-ID"
+	}
+	if c.Category == "" {
+		c.Category = "K1"
+	}
+}
+
+// Item is the checkpoint of one keyword
+type Item struct {
+	Position      int        `json:"position"`
+	Keyword       string     `json:"keyword"`
+	Status        string     `json:"status"`
+	Attempts      int        `json:"attempts"`
+	FailureClass  string     `json:"failureClass,omitempty"`
+	Error         string     `json:"error,omitempty"`
+	Blacklisted   bool       `json:"blacklisted"`
+	Title         string     `json:"title,omitempty"`
+	WordCount     int        `json:"wordCount,omitempty"`
+	ImagesCount   int        `json:"imagesCount,omitempty"`
+	DraftRef      string     `json:"draftRef,omitempty"` // generation ID: GET /api/engine/ai/generate/result?id=
+	ExperimentArm string     `json:"experimentArm,omitempty"`
+	ExposureID    string     `json:"exposureId,omitempty"`
+	StartedAt     *time.Time `json:"startedAt,omitempty"`
+	FinishedAt    *time.Time `json:"finishedAt,omitempty"`
+}
+
+// Batch is a persistent batch production run
+type Batch struct {
+	ID          string     `json:"id"`
+	Status      string     `json:"status"`
+	Config      Config     `json:"config"`
+	Items       []Item     `json:"items"`
+	PauseReason string     `json:"pauseReason,omitempty"` // set when RateGuard paused the batch
+	CreatedAt   time.Time  `json:"createdAt"`
+	StartedAt   *time.Time `json:"startedAt,omitempty"`
+	FinishedAt  *time.Time `json:"finishedAt,omitempty"`
+	UpdatedAt   time.Time  `json:"updatedAt"`
+}
+
+// generated counts keywords with a draft
+func (b Batch) generated() int {
+	n := 0
+	for _, it := range b.Items {
+		if it.Status == ItemDone {
+			n++
+		}
+	}
+	return n
+}
+
+// Workers reads BATCH_WORKERS from env (default 1 batch at a time)
+func Workers() int {
+	workers := 1
+	if v := os.Getenv("BATCH_WORKERS"); v != "" {
+		if val, err := strconv.Atoi(v); err == nil && val > 0 {
+			workers = val
+		}
+	}
+	return workers
+}
+
+// control is the in-process handle of a started batch
+type control struct {
+	cancel context.CancelCauseFunc
+	pause  atomic.Bool // stop after the current keyword, status PAUSED
+	stop   atomic.Bool // shutdown: stop after the current keyword, status stays RUNNING
+}
+
+var (
+	slotsOnce sync.Once
+	slots     chan struct{}
+
+	// controlsMu also serializes control actions with runner start/exit
+	controlsMu sync.Mutex
+	controls   = make(map[string]*control)
+	inflight   sync.WaitGroup
+)
+
+func acquireSlots() chan struct{} {
+	slotsOnce.Do(func() {
+		slots = make(chan struct{}, Workers())
+	})
+	return slots
+}
+
+// Create persists a new batch and starts it in the background
+func Create(cfg Config, keywords []string) (Batch, error) {
+	cfg.applyDefaults()
+
+	now := time.Now()
+	b := Batch{
+		ID:        uuid.New().String(),
+		Status:    StatusQueued,
+		Config:    cfg,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	for _, kw := range keywords {
+		kw = strings.TrimSpace(kw)
+		if kw == "" {
+			continue
+		}
+		b.Items = append(b.Items, Item{Position: len(b.Items), Keyword: kw, Status: ItemPending})
+	}
+	if len(b.Items) == 0 {
+		return Batch{}, ErrNoKeywords
+	}
+
+	if err := getStore().InsertBatch(b); err != nil {
+		return Batch{}, err
+	}
+
+	controlsMu.Lock()
+	start(b)
+	controlsMu.Unlock()
+
+	log.Printf("[BATCH PRODUCTION] Batch %s queued: batchSize=%d, contentType=%s, keywords=%d",
+		b.ID, cfg.BatchSize, cfg.ContentType, len(b.Items))
+	return b, nil
+}
+
+// start launches the runner; caller holds controlsMu
+func start(b Batch) {
+	ctx, cancel := context.WithCancelCause(context.Background())
+	ctx = usage.WithAttribution(ctx, usage.Attribution{JobID: b.ID, Endpoint: "/api/engine/ai/batch-production"})
+	ctl := &control{cancel: cancel}
+	controls[b.ID] = ctl
+	inflight.Add(1)
+	go run(ctx, ctl, b)
+}
+
+// Get returns a batch with its keyword items
+func Get(id string) (Batch, error) {
+	b, err := getStore().GetBatch(id)
+	if err != nil {
+		return Batch{}, err
+	}
+	return *b, nil
+}
+
+// List returns batches newest first (status "" = all)
+func List(status string, limit int) ([]Batch, error) {
+	return getStore().ListBatches(status, limit)
+}
+
+// Pause stops a running batch after its current keyword
+func Pause(id string) (Batch, error) {
+	controlsMu.Lock()
+	defer controlsMu.Unlock()
+
+	ctl, running := controls[id]
+	if !running {
+		b, err := Get(id)
+		if err != nil {
+			return Batch{}, err
+		}
+		return b, fmt.Errorf("%w: batch is %s", ErrInvalidState, b.Status)
+	}
+	ctl.pause.Store(true)
+	log.Printf("[BATCH PRODUCTION] Batch %s: pause requested (after current keyword)", id)
+	return Get(id)
+}
+
+// Resume restarts a paused batch (or withdraws a pause that has not taken effect yet)
+func Resume(id string) (Batch, error) {
+	controlsMu.Lock()
+	defer controlsMu.Unlock()
+
+	if ctl, running := controls[id]; running {
+		if !ctl.pause.Swap(false) {
+			b, _ := Get(id)
+			return b, fmt.Errorf("%w: batch is already running", ErrInvalidState)
+		}
+		return Get(id)
+	}
+
+	b, err := Get(id)
+	if err != nil {
+		return Batch{}, err
+	}
+	if b.Status != StatusPaused {
+		return b, fmt.Errorf("%w: batch is %s", ErrInvalidState, b.Status)
+	}
+	return requeue(b)
+}
+
+// Cancel stops a batch at once; the keyword in flight is aborted and pending keywords are skipped
+func Cancel(id string) (Batch, error) {
+	controlsMu.Lock()
+	defer controlsMu.Unlock()
+
+	if ctl, running := controls[id]; running {
+		ctl.cancel(errCancelled)
+		log.Printf("[BATCH PRODUCTION] Batch %s: cancel requested", id)
+		return Get(id)
+	}
+
+	b, err := Get(id)
+	if err != nil {
+		return Batch{}, err
+	}
+	if b.Status == StatusCompleted || b.Status == StatusCancelled {
+		return b, fmt.Errorf("%w: batch is %s", ErrInvalidState, b.Status)
+	}
+	for i := range b.Items {
+		if b.Items[i].Status == ItemPending || b.Items[i].Status == ItemRunning {
+			skipItem(&b, &b.Items[i], "batch cancelled")
+		}
+	}
+	finishBatch(&b, StatusCancelled)
+	return b, nil
+}
+
+// RetryFailed resets FAILED keywords (attempts, blacklist) and runs the batch again for those only
+func RetryFailed(id string) (Batch, error) {
+	controlsMu.Lock()
+	defer controlsMu.Unlock()
+
+	if _, running := controls[id]; running {
+		b, _ := Get(id)
+		return b, fmt.Errorf("%w: batch is running", ErrInvalidState)
+	}
+	b, err := Get(id)
+	if err != nil {
+		return Batch{}, err
+	}
+
+	retried := 0
+	for i := range b.Items {
+		it := &b.Items[i]
+		if it.Status != ItemFailed {
+			continue
+		}
+		*it = Item{Position: it.Position, Keyword: it.Keyword, Status: ItemPending}
+		saveItem(b.ID, *it)
+		retried++
+	}
+	if retried == 0 {
+		return b, ErrNothingToRetry
+	}
+	log.Printf("[BATCH PRODUCTION] Batch %s: retrying %d failed keyword(s)", id, retried)
+	b.FinishedAt = nil
+	return requeue(b)
+}
+
+// requeue marks a stopped batch QUEUED and starts it; caller holds controlsMu
+func requeue(b Batch) (Batch, error) {
+	b.Status = StatusQueued
+	b.PauseReason = ""
+	saveBatch(&b)
+	start(b)
+	return b, nil
+}
+
+// ResumeInterrupted restarts batches left QUEUED/RUNNING by a previous process
+// Keywords that were in flight go back to PENDING (their attempt count is kept)
+// Call once at boot after SetStore
+func ResumeInterrupted() {
+	var unfinished []Batch
+	for _, status := range []string{StatusRunning, StatusQueued} {
+		list, err := List(status, 0)
+		if err != nil {
+			log.Printf("[BATCH PRODUCTION] Failed to list interrupted batches: %v", err)
+			return
+		}
+		unfinished = append(unfinished, list...)
+	}
+
+	controlsMu.Lock()
+	defer controlsMu.Unlock()
+	for _, b := range unfinished {
+		if _, running := controls[b.ID]; running {
+			continue
+		}
+		for i := range b.Items {
+			if b.Items[i].Status == ItemRunning {
+				b.Items[i].Status = ItemPending
+				saveItem(b.ID, b.Items[i])
+			}
+		}
+		log.Printf("[BATCH PRODUCTION] Resuming interrupted batch %s (%d/%d generated)", b.ID, b.generated(), b.Config.BatchSize)
+		start(b)
+	}
+}
+
+// Drain asks running batches to stop after their current keyword
+// When ctx expires first, the keywords in flight are aborted; batches stay RUNNING and resume at next boot
+func Drain(ctx context.Context) error {
+	controlsMu.Lock()
+	for _, ctl := range controls {
+		ctl.stop.Store(true)
+	}
+	controlsMu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		inflight.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+	}
+
+	controlsMu.Lock()
+	count := len(controls)
+	for _, ctl := range controls {
+		ctl.cancel(errShuttingDown)
+	}
+	controlsMu.Unlock()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+	}
+	return fmt.Errorf("interrupted %d batch(es) at shutdown deadline (resumed at next boot): %w", count, ctx.Err())
+}
+
+// saveBatch writes the batch row; a failed write is logged and the run continues
+func saveBatch(b *Batch) {
+	b.UpdatedAt = time.Now()
+	if err := getStore().UpdateBatch(*b); err != nil {
+		log.Printf("[BATCH PRODUCTION] Failed to update batch %s: %v", b.ID, err)
+	}
+}
+
+// saveItem writes one keyword checkpoint
+func saveItem(batchID string, it Item) {
+	if err := getStore().UpdateItem(batchID, it); err != nil {
+		log.Printf("[BATCH PRODUCTION] Failed to update keyword '%s' of batch %s: %v", it.Keyword, batchID, err)
+	}
+}
+
+// skipItem marks a keyword SKIPPED with the reason
+func skipItem(b *Batch, it *Item, reason string) {
+	now := time.Now()
+	it.Status = ItemSkipped
+	it.Error = reason
+	it.FinishedAt = &now
+	saveItem(b.ID, *it)
+}
+
+// finishBatch stores a terminal status
+func finishBatch(b *Batch, status string) {
+	now := time.Now()
+	b.Status = status
+	b.FinishedAt = &now
+	saveBatch(b)
+}
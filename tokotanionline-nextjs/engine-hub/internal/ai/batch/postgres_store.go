@@ -0,0 +1,202 @@
+package batch
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+)
+
+// PostgresStore persists batches in "EngineHubBatch" and keyword checkpoints in "EngineHubBatchItem"
+type PostgresStore struct {
+	db *sql.DB
+}
+
+// NewPostgresStore creates a batch store backed by the given database
+func NewPostgresStore(db *sql.DB) *PostgresStore {
+	return &PostgresStore{db: db}
+}
+
+func (p *PostgresStore) InsertBatch(b Batch) error {
+	config, err := json.Marshal(b.Config)
+	if err != nil {
+		return fmt.Errorf("failed to marshal batch config: %w", err)
+	}
+
+	tx, err := p.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin batch insert: %w", err)
+	}
+	defer tx.Rollback()
+
+	// jsonb passed as text (lib/pq sends []byte as bytea)
+	_, err = tx.Exec(`
+		INSERT INTO "EngineHubBatch" (id, status, config, "pauseReason", "createdAt", "startedAt", "finishedAt", "updatedAt")
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`, b.ID, b.Status, string(config), nullString(b.PauseReason), b.CreatedAt, b.StartedAt, b.FinishedAt, b.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to insert batch: %w", err)
+	}
+	for _, it := range b.Items {
+		_, err = tx.Exec(`
+			INSERT INTO "EngineHubBatchItem" ("batchId", position, keyword, status, attempts, blacklisted)
+			VALUES ($1, $2, $3, $4, $5, $6)
+		`, b.ID, it.Position, it.Keyword, it.Status, it.Attempts, it.Blacklisted)
+		if err != nil {
+			return fmt.Errorf("failed to insert batch keyword '%s': %w", it.Keyword, err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit batch insert: %w", err)
+	}
+	return nil
+}
+
+func (p *PostgresStore) UpdateBatch(b Batch) error {
+	res, err := p.db.Exec(`
+		UPDATE "EngineHubBatch" SET status = $2, "pauseReason" = $3, "startedAt" = $4, "finishedAt" = $5, "updatedAt" = $6
+		WHERE id = $1
+	`, b.ID, b.Status, nullString(b.PauseReason), b.StartedAt, b.FinishedAt, b.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to update batch: %w", err)
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (p *PostgresStore) UpdateItem(batchID string, it Item) error {
+	res, err := p.db.Exec(`
+		UPDATE "EngineHubBatchItem" SET status = $3, attempts = $4, "failureClass" = $5, error = $6, blacklisted = $7,
+			title = $8, "wordCount" = $9, "imagesCount" = $10, "draftRef" = $11, "experimentArm" = $12, "exposureId" = $13,
+			"startedAt" = $14, "finishedAt" = $15
+		WHERE "batchId" = $1 AND position = $2
+	`, batchID, it.Position, it.Status, it.Attempts, nullString(it.FailureClass), nullString(it.Error), it.Blacklisted,
+		nullString(it.Title), it.WordCount, it.ImagesCount, nullString(it.DraftRef), nullString(it.ExperimentArm),
+		nullString(it.ExposureID), it.StartedAt, it.FinishedAt)
+	if err != nil {
+		return fmt.Errorf("failed to update batch keyword: %w", err)
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (p *PostgresStore) GetBatch(id string) (*Batch, error) {
+	b, err := scanBatch(p.db.QueryRow(`
+		SELECT id, status, config, COALESCE("pauseReason", ''), "createdAt", "startedAt", "finishedAt", "updatedAt"
+		FROM "EngineHubBatch"
+		WHERE id = $1
+	`, id))
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query batch: %w", err)
+	}
+	if b.Items, err = p.items(b.ID); err != nil {
+		return nil, err
+	}
+	return &b, nil
+}
+
+func (p *PostgresStore) ListBatches(status string, limit int) ([]Batch, error) {
+	query := `
+		SELECT id, status, config, COALESCE("pauseReason", ''), "createdAt", "startedAt", "finishedAt", "updatedAt"
+		FROM "EngineHubBatch"
+		WHERE ($1 = '' OR status = $1)
+		ORDER BY "createdAt" DESC`
+	args := []interface{}{status}
+	if limit > 0 {
+		query += ` LIMIT $2`
+		args = append(args, limit)
+	}
+	rows, err := p.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query batches: %w", err)
+	}
+	defer rows.Close()
+
+	list := []Batch{}
+	for rows.Next() {
+		b, err := scanBatch(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan batch: %w", err)
+		}
+		list = append(list, b)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate batches: %w", err)
+	}
+	rows.Close()
+
+	for i := range list {
+		if list[i].Items, err = p.items(list[i].ID); err != nil {
+			return nil, err
+		}
+	}
+	return list, nil
+}
+
+// items loads the keyword checkpoints of a batch in pool order
+func (p *PostgresStore) items(batchID string) ([]Item, error) {
+	rows, err := p.db.Query(`
+		SELECT position, keyword, status, attempts, COALESCE("failureClass", ''), COALESCE(error, ''), blacklisted,
+			COALESCE(title, ''), "wordCount", "imagesCount", COALESCE("draftRef", ''), COALESCE("experimentArm", ''),
+			COALESCE("exposureId", ''), "startedAt", "finishedAt"
+		FROM "EngineHubBatchItem"
+		WHERE "batchId" = $1
+		ORDER BY position
+	`, batchID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query keywords of batch %s: %w", batchID, err)
+	}
+	defer rows.Close()
+
+	items := []Item{}
+	for rows.Next() {
+		var it Item
+		var startedAt, finishedAt sql.NullTime
+		if err := rows.Scan(&it.Position, &it.Keyword, &it.Status, &it.Attempts, &it.FailureClass, &it.Error, &it.Blacklisted,
+			&it.Title, &it.WordCount, &it.ImagesCount, &it.DraftRef, &it.ExperimentArm,
+			&it.ExposureID, &startedAt, &finishedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan keyword of batch %s: %w", batchID, err)
+		}
+		if startedAt.Valid {
+			it.StartedAt = &startedAt.Time
+		}
+		if finishedAt.Valid {
+			it.FinishedAt = &finishedAt.Time
+		}
+		items = append(items, it)
+	}
+	return items, rows.Err()
+}
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanBatch(row rowScanner) (Batch, error) {
+	var b Batch
+	var config []byte
+	var startedAt, finishedAt sql.NullTime
+	if err := row.Scan(&b.ID, &b.Status, &config, &b.PauseReason, &b.CreatedAt, &startedAt, &finishedAt, &b.UpdatedAt); err != nil {
+		return Batch{}, err
+	}
+	if err := json.Unmarshal(config, &b.Config); err != nil {
+		return Batch{}, fmt.Errorf("failed to decode config of batch %s: %w", b.ID, err)
+	}
+	if startedAt.Valid {
+		b.StartedAt = &startedAt.Time
+	}
+	if finishedAt.Valid {
+		b.FinishedAt = &finishedAt.Time
+	}
+	return b, nil
+}
+
+func nullString(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
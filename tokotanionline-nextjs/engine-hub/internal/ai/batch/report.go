@@ -0,0 +1,127 @@
+package batch
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Report is the batch production summary (counts, failures by class, blacklist, experiment arms)
+type Report struct {
+	BatchID         string         `json:"batchId"`
+	Status          string         `json:"status"`
+	PauseReason     string         `json:"pauseReason,omitempty"`
+	TotalRequested  int            `json:"totalRequested"` // batchSize
+	TotalKeywords   int            `json:"totalKeywords"`
+	TotalGenerated  int            `json:"totalGenerated"`
+	TotalFailed     int            `json:"totalFailed"`
+	TotalPending    int            `json:"totalPending"`
+	TotalSkipped    int            `json:"totalSkipped"`
+	TotalAttempts   int            `json:"totalAttempts"`
+	FailuresByClass map[string]int `json:"failuresByClass"`
+	Blacklist       []string       `json:"blacklist"`
+	ExperimentArms  map[string]int `json:"experimentArms,omitempty"` // drafts per prompt A/B arm
+	Articles        []Item         `json:"articles"`
+	DurationSec     float64        `json:"durationSec,omitempty"`
+	CreatedAt       time.Time      `json:"createdAt"`
+	StartedAt       *time.Time     `json:"startedAt,omitempty"`
+	FinishedAt      *time.Time     `json:"finishedAt,omitempty"`
+	Summary         string         `json:"summary"` // human-readable
+}
+
+// BuildReport summarizes a batch from its keyword checkpoints
+func BuildReport(b Batch) Report {
+	r := Report{
+		BatchID:         b.ID,
+		Status:          b.Status,
+		PauseReason:     b.PauseReason,
+		TotalRequested:  b.Config.BatchSize,
+		TotalKeywords:   len(b.Items),
+		FailuresByClass: make(map[string]int),
+		Blacklist:       blacklist(b),
+		ExperimentArms:  make(map[string]int),
+		Articles:        b.Items,
+		CreatedAt:       b.CreatedAt,
+		StartedAt:       b.StartedAt,
+		FinishedAt:      b.FinishedAt,
+	}
+	for _, it := range b.Items {
+		r.TotalAttempts += it.Attempts
+		switch it.Status {
+		case ItemDone:
+			r.TotalGenerated++
+			if it.ExperimentArm != "" {
+				r.ExperimentArms[it.ExperimentArm]++
+			}
+		case ItemFailed:
+			r.TotalFailed++
+			r.FailuresByClass[it.FailureClass]++
+		case ItemPending, ItemRunning:
+			r.TotalPending++
+		case ItemSkipped:
+			r.TotalSkipped++
+		}
+	}
+	if b.StartedAt != nil {
+		end := time.Now()
+		if b.FinishedAt != nil {
+			end = *b.FinishedAt
+		}
+		r.DurationSec = end.Sub(*b.StartedAt).Seconds()
+	}
+	r.Summary = summary(r)
+	return r
+}
+
+// blacklist lists keywords blacklisted after all attempts (FASE B - B3: never infra errors)
+func blacklist(b Batch) []string {
+	list := []string{}
+	for _, it := range b.Items {
+		if it.Blacklisted {
+			list = append(list, it.Keyword)
+		}
+	}
+	return list
+}
+
+// summary renders the report as text
+func summary(r Report) string {
+	var s strings.Builder
+	s.WriteString("BATCH PRODUCTION SUMMARY\n")
+	s.WriteString("========================\n\n")
+	s.WriteString(fmt.Sprintf("Batch: %s (%s)\n", r.BatchID, r.Status))
+	if r.PauseReason != "" {
+		s.WriteString(fmt.Sprintf("Paused: %s\n", r.PauseReason))
+	}
+	s.WriteString(fmt.Sprintf("Total Keywords: %d\n", r.TotalKeywords))
+	s.WriteString(fmt.Sprintf("Articles Generated: %d/%d\n", r.TotalGenerated, r.TotalRequested))
+	s.WriteString(fmt.Sprintf("Articles Failed: %d\n", r.TotalFailed))
+	s.WriteString(fmt.Sprintf("Keywords Pending: %d, Skipped: %d\n", r.TotalPending, r.TotalSkipped))
+	s.WriteString(fmt.Sprintf("Total Attempts: %d\n", r.TotalAttempts))
+	s.WriteString(fmt.Sprintf("Blacklisted Keywords: %d\n", len(r.Blacklist)))
+	s.WriteString("\n")
+
+	if len(r.Blacklist) > 0 {
+		s.WriteString("Blacklisted Keywords:\n")
+		for _, kw := range r.Blacklist {
+			s.WriteString(fmt.Sprintf("  - %s\n", kw))
+		}
+		s.WriteString("\n")
+	}
+
+	s.WriteString("Article Results:\n")
+	for _, it := range r.Articles {
+		s.WriteString(fmt.Sprintf("%d. Keyword: %s, Status: %s, Attempts: %d", it.Position+1, it.Keyword, it.Status, it.Attempts))
+		switch it.Status {
+		case ItemDone:
+			s.WriteString(fmt.Sprintf(", Word Count: %d, Images: %d", it.WordCount, it.ImagesCount))
+		case ItemFailed:
+			s.WriteString(fmt.Sprintf(", Error: %s (%s)", it.FailureClass, it.Error))
+		case ItemSkipped:
+			s.WriteString(fmt.Sprintf(", Reason: %s", it.Error))
+		}
+		s.WriteString("\n")
+	}
+
+	return s.String()
+}
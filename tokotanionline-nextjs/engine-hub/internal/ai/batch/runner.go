@@ -0,0 +1,404 @@
+package batch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"engine-hub/internal/ai/content"
+	"engine-hub/internal/ai/experiment"
+	"engine-hub/internal/ai/generation"
+	"engine-hub/internal/ai/workflow"
+	contentengine "engine-hub/internal/content"
+)
+
+// run processes the pending keywords of a batch until done, paused, cancelled or stopped
+// CTO FINAL LOGIC - LOCKED
+// for keyword in keyword_pool (until batchSize drafts):
+//
+//	attempt up to MAX_ATTEMPT (same outline), stop early on validation failure
+//	if all attempts failed: mark FAILED, blacklist content failures (never infra)
+func run(ctx context.Context, ctl *control, b Batch) {
+	defer inflight.Done()
+
+	slots := acquireSlots()
+	select {
+	case slots <- struct{}{}:
+		defer func() { <-slots }()
+	case <-ctx.Done():
+		exit(ctl, &b, context.Cause(ctx))
+		return
+	}
+
+	now := time.Now()
+	b.Status = StatusRunning
+	b.PauseReason = ""
+	if b.StartedAt == nil {
+		b.StartedAt = &now
+	}
+	saveBatch(&b)
+	log.Printf("[BATCH PRODUCTION] Batch %s running: %d/%d generated so far", b.ID, b.generated(), b.Config.BatchSize)
+
+	// Create pipeline once (reused for all articles)
+	pipeline := workflow.NewPipeline()
+
+	for i := range b.Items {
+		it := &b.Items[i]
+		if it.Status != ItemPending {
+			continue
+		}
+
+		// Stop if we've generated enough articles
+		if b.generated() >= b.Config.BatchSize {
+			log.Printf("[BATCH PRODUCTION] Batch %s reached batch size (%d)", b.ID, b.Config.BatchSize)
+			break
+		}
+		if ctl.pause.Load() || ctl.stop.Load() || ctx.Err() != nil {
+			exit(ctl, &b, context.Cause(ctx))
+			return
+		}
+
+		// FASE D - D2/D3: RateGuard (SAFE_MODE, daily budget) → pause, resume manually
+		if allowed, reason := contentengine.CheckGenerationAllowed(time.Now()); !allowed {
+			log.Printf("[BATCH PRODUCTION] Batch %s paused by RateGuard before keyword '%s': %s", b.ID, it.Keyword, reason)
+			b.PauseReason = reason
+			ctl.pause.Store(true)
+			exit(ctl, &b, nil)
+			return
+		}
+
+		log.Printf("[BATCH PRODUCTION] Batch %s: keyword %d/%d: %s", b.ID, it.Position+1, len(b.Items), it.Keyword)
+		processKeyword(ctx, &b, it, pipeline)
+		if ctx.Err() != nil {
+			exit(ctl, &b, context.Cause(ctx))
+			return
+		}
+	}
+
+	for i := range b.Items {
+		if b.Items[i].Status == ItemPending {
+			skipItem(&b, &b.Items[i], "batch size reached")
+		}
+	}
+	log.Printf("[BATCH PRODUCTION] Batch %s completed: generated=%d, blacklist=%d", b.ID, b.generated(), len(blacklist(b)))
+	exit(ctl, &b, nil)
+}
+
+// exit stores the status the runner stops in and releases the batch control
+// cause: errCancelled → CANCELLED; errShuttingDown/stop → stays RUNNING (resumed at boot); pause → PAUSED
+func exit(ctl *control, b *Batch, cause error) {
+	controlsMu.Lock()
+	defer controlsMu.Unlock()
+	defer delete(controls, b.ID)
+	defer ctl.cancel(nil)
+
+	switch {
+	case errors.Is(cause, errCancelled):
+		for i := range b.Items {
+			if b.Items[i].Status == ItemPending || b.Items[i].Status == ItemRunning {
+				skipItem(b, &b.Items[i], "batch cancelled")
+			}
+		}
+		finishBatch(b, StatusCancelled)
+		log.Printf("[BATCH PRODUCTION] Batch %s cancelled", b.ID)
+	case errors.Is(cause, errShuttingDown) || ctl.stop.Load():
+		log.Printf("[BATCH PRODUCTION] Batch %s interrupted by shutdown (will resume at next boot)", b.ID)
+	case ctl.pause.Load():
+		b.Status = StatusPaused
+		saveBatch(b)
+		log.Printf("[BATCH PRODUCTION] Batch %s paused", b.ID)
+	default:
+		finishBatch(b, StatusCompleted)
+	}
+}
+
+// processKeyword runs up to MAX_ATTEMPT pipeline attempts for one keyword and checkpoints the item
+func processKeyword(ctx context.Context, b *Batch, it *Item, pipeline *workflow.Pipeline) {
+	cfg := b.Config
+
+	// FASE A - A3: RETRY CONTROLLER (ANTI KACAU)
+	// Outline is generated ONCE per keyword; every attempt uses the same prompt
+	outline := OutlineFromKeyword(it.Keyword, cfg.ContentType, cfg.Category)
+
+	// Prompt A/B: one arm per keyword (all attempts render the same template version)
+	contentReq := content.ContentRequest{
+		ContentType: content.ContentType(cfg.ContentType),
+		Category:    cfg.Category,
+		Language:    cfg.Language,
+		Brand:       cfg.Brand,
+		Outline:     outline,
+	}
+	assignment := experiment.Assign(&contentReq, it.Keyword)
+	if assignment != nil {
+		it.ExperimentArm = assignment.Arm
+	}
+
+	now := time.Now()
+	it.Status = ItemRunning
+	it.StartedAt = &now
+	saveItem(b.ID, *it)
+
+	var lastErr error
+	for it.Attempts < MAX_ATTEMPT {
+		// FASE D - D2: infra errors back off like ContentJob retries
+		if lastErr != nil && isInfraError(strings.ToLower(lastErr.Error())) {
+			delay := contentengine.RetryBackoff(it.Attempts)
+			log.Printf("[BATCH PRODUCTION] Keyword '%s': infra error, retrying in %v", it.Keyword, delay)
+			select {
+			case <-ctx.Done():
+				requeueItem(b, it)
+				return
+			case <-time.After(delay):
+			}
+		}
+
+		it.Attempts++
+		saveItem(b.ID, *it)
+		log.Printf("[BATCH PRODUCTION] Keyword '%s', attempt %d/%d (using same outline)", it.Keyword, it.Attempts, MAX_ATTEMPT)
+
+		draft, err := execute(ctx, pipeline, contentReq)
+		if ctx.Err() != nil {
+			// Aborted attempt (cancel/shutdown) does not count
+			it.Attempts--
+			requeueItem(b, it)
+			return
+		}
+		if err == nil {
+			log.Printf("[BATCH PRODUCTION] Keyword '%s', attempt %d SUCCESS", it.Keyword, it.Attempts)
+			completeItem(b, it, contentReq, assignment, draft)
+			return
+		}
+
+		lastErr = err
+		log.Printf("[BATCH PRODUCTION] Keyword '%s', attempt %d failed: %v", it.Keyword, it.Attempts, err)
+
+		// Validation errors are not retried
+		if isValidationError(err) {
+			log.Printf("[BATCH PRODUCTION] Validation error for keyword '%s' - not retrying", it.Keyword)
+			break
+		}
+	}
+
+	if lastErr == nil {
+		lastErr = fmt.Errorf("no attempts left (%d/%d)", it.Attempts, MAX_ATTEMPT)
+	}
+	failItem(b, it, assignment, lastErr)
+}
+
+// execute runs the pipeline, converting panics into errors (one bad keyword must not kill the batch)
+func execute(ctx context.Context, pipeline *workflow.Pipeline, req content.ContentRequest) (draft *workflow.DraftAI, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("pipeline panicked: %v", r)
+		}
+	}()
+	return pipeline.Execute(ctx, req)
+}
+
+// requeueItem puts an interrupted keyword back to PENDING
+func requeueItem(b *Batch, it *Item) {
+	it.Status = ItemPending
+	it.StartedAt = nil
+	saveItem(b.ID, *it)
+}
+
+// completeItem stores the draft (as a generation, referenced by DraftRef) and checkpoints the keyword DONE
+func completeItem(b *Batch, it *Item, req content.ContentRequest, assignment *experiment.Assignment, draft *workflow.DraftAI) {
+	exposureID := experiment.Record(assignment, experiment.ChannelBatch, req, &draft.Content, draft.Content.Title)
+	draftRef, err := generation.SaveDraft(req, draft, assignment, exposureID)
+	if err != nil {
+		log.Printf("[BATCH PRODUCTION] Keyword '%s': failed to store draft: %v", it.Keyword, err)
+	}
+
+	now := time.Now()
+	it.Status = ItemDone
+	it.FailureClass = ""
+	it.Error = ""
+	it.Title = draft.Content.Title
+	it.WordCount = countWordsInDraft(draft)
+	it.ImagesCount = len(draft.Images)
+	it.DraftRef = draftRef
+	it.ExposureID = exposureID
+	it.FinishedAt = &now
+	saveItem(b.ID, *it)
+}
+
+// failItem classifies the last error, applies the blacklist rule and checkpoints the keyword FAILED
+func failItem(b *Batch, it *Item, assignment *experiment.Assignment, lastErr error) {
+	experiment.RecordFailure(assignment, experiment.ChannelBatch, lastErr)
+
+	// FASE B - B3: ERROR CLASSIFICATION (ANTI SALAH HUKUM)
+	// API key invalid / timeout / network → INFRA_ERROR (tidak boleh blacklist)
+	// Validator / outline gagal → CONTENT_FAILED (boleh blacklist)
+	errStr := strings.ToLower(lastErr.Error())
+	class := FailureUnknown
+	switch {
+	case isValidationError(lastErr):
+		class = FailureValidation
+	case isInfraError(errStr):
+		class = FailureInfra
+	case isContentError(errStr):
+		class = FailureContent
+	}
+	shouldBlacklist := class != FailureInfra
+
+	now := time.Now()
+	it.Status = ItemFailed
+	it.FailureClass = class
+	it.Error = lastErr.Error()
+	it.Blacklisted = shouldBlacklist && b.Config.RetryLogic == "ON"
+	it.FinishedAt = &now
+	saveItem(b.ID, *it)
+
+	if it.Blacklisted {
+		log.Printf("[BATCH PRODUCTION] Keyword '%s' failed after %d attempts (%s) - blacklisted", it.Keyword, it.Attempts, class)
+	} else {
+		log.Printf("[BATCH PRODUCTION] Keyword '%s' failed after %d attempts (%s) - NOT blacklisted", it.Keyword, it.Attempts, class)
+	}
+}
+
+// OutlineFromKeyword generates a basic outline from keyword
+// For DERIVATIVE_LONG, includes required "Hubungan Antar Jenis" section
+func OutlineFromKeyword(keyword string, contentType string, category string) string {
+	var outline strings.Builder
+
+	// Base structure for DERIVATIVE_LONG
+	if contentType == "DERIVATIVE_LONG" {
+		outline.WriteString("## Pendahuluan\n\n")
+		outline.WriteString(fmt.Sprintf("Artikel ini membahas tentang %s secara komprehensif.\n\n", keyword))
+
+		outline.WriteString(fmt.Sprintf("## %s\n\n", keyword))
+		outline.WriteString(fmt.Sprintf("Bagian ini menjelaskan konsep, definisi, dan aspek penting dari %s.\n\n", keyword))
+
+		// Required section for DERIVATIVE_LONG
+		outline.WriteString("## Hubungan Antar Jenis (sinergi, bukan berdiri sendiri)\n\n")
+		outline.WriteString("Bagian ini menjelaskan hubungan dan keterkaitan antara berbagai aspek yang terkait dengan topik utama.\n\n")
+
+		outline.WriteString(fmt.Sprintf("## Cara Menerapkan %s\n\n", keyword))
+		outline.WriteString("Bagian ini memberikan panduan praktis untuk menerapkan pengetahuan tentang topik ini.\n\n")
+
+		outline.WriteString("## Tips dan Rekomendasi\n\n")
+		outline.WriteString("Bagian ini berisi tips praktis dan rekomendasi berdasarkan pengalaman.\n\n")
+
+		outline.WriteString("## Kesimpulan\n\n")
+		outline.WriteString("Ringkasan poin-poin penting dari artikel.\n\n")
+	} else {
+		// Basic structure for other content types
+		outline.WriteString("## Pendahuluan\n\n")
+		outline.WriteString(fmt.Sprintf("## %s\n\n", keyword))
+		outline.WriteString("## Kesimpulan\n\n")
+	}
+
+	return outline.String()
+}
+
+// isValidationError checks if error is a validation error (non-retryable)
+func isValidationError(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	errStr := err.Error()
+	return strings.Contains(errStr, "VALIDATION FAILED") ||
+		strings.Contains(errStr, "WORD_COUNT_MINIMUM") ||
+		strings.Contains(errStr, "CTA_JUALAN") ||
+		strings.Contains(errStr, "KATA_TERLARANG") ||
+		strings.Contains(errStr, "NAMA_MEREK") ||
+		strings.Contains(errStr, "NADA_PROMOSI") ||
+		strings.Contains(errStr, "HEADING_TIDAK_SESUAI_OUTLINE")
+}
+
+// isInfraError checks if error is an infrastructure error (FASE B - B3)
+// INFRA_ERROR: API key, timeout, network, API status errors
+// These should NOT go to blacklist
+func isInfraError(errStr string) bool {
+	// Schema violations after the repair loop are content failures (whatever numbers the violations quote)
+	if strings.Contains(errStr, "structured output invalid") {
+		return false
+	}
+
+	// API key errors
+	if strings.Contains(errStr, "ai_api_key") ||
+		strings.Contains(errStr, "openai_api_key") ||
+		strings.Contains(errStr, "environment variable not set") ||
+		strings.Contains(errStr, "api key") ||
+		strings.Contains(errStr, "authentication") ||
+		strings.Contains(errStr, "unauthorized") ||
+		strings.Contains(errStr, "401") ||
+		strings.Contains(errStr, "403") {
+		return true
+	}
+
+	// Timeout errors
+	if strings.Contains(errStr, "timeout") ||
+		strings.Contains(errStr, "deadline exceeded") ||
+		strings.Contains(errStr, "context deadline") ||
+		strings.Contains(errStr, "request timeout") {
+		return true
+	}
+
+	// Network errors
+	if strings.Contains(errStr, "network") ||
+		strings.Contains(errStr, "connection") ||
+		strings.Contains(errStr, "dial") ||
+		strings.Contains(errStr, "no such host") ||
+		strings.Contains(errStr, "connection refused") ||
+		strings.Contains(errStr, "connection reset") {
+		return true
+	}
+
+	// API request/response errors
+	if strings.Contains(errStr, "api request failed") ||
+		strings.Contains(errStr, "failed to create request") ||
+		strings.Contains(errStr, "failed to decode response") ||
+		strings.Contains(errStr, "api returned status") ||
+		strings.Contains(errStr, "500") ||
+		strings.Contains(errStr, "502") ||
+		strings.Contains(errStr, "503") ||
+		strings.Contains(errStr, "504") {
+		return true
+	}
+
+	// Image API errors (infra)
+	if strings.Contains(errStr, "image_api_key") ||
+		strings.Contains(errStr, "image generation failed") {
+		return true
+	}
+
+	return false
+}
+
+// isContentError checks if error is a content-based error (FASE B - B3)
+// CONTENT_FAILED: validation, structure, outline, word count
+// These CAN go to blacklist
+func isContentError(errStr string) bool {
+	// Validation errors
+	if strings.Contains(errStr, "validation failed") ||
+		strings.Contains(errStr, "word_count_minimum") ||
+		strings.Contains(errStr, "cta_jualan") ||
+		strings.Contains(errStr, "kata_terlarang") ||
+		strings.Contains(errStr, "nama_merek") ||
+		strings.Contains(errStr, "nada_promosi") ||
+		strings.Contains(errStr, "heading_tidak_sesuai_outline") {
+		return true
+	}
+
+	// Structure/outline errors
+	if strings.Contains(errStr, "structural") ||
+		strings.Contains(errStr, "structured output invalid") ||
+		strings.Contains(errStr, "outline") ||
+		strings.Contains(errStr, "seo validation failed") ||
+		strings.Contains(errStr, "content_failed") {
+		return true
+	}
+
+	return false
+}
+
+// countWordsInDraft counts words in a draft article
+func countWordsInDraft(draft *workflow.DraftAI) int {
+	return len(strings.Fields(draft.Content.Title + " " + draft.Content.Body))
+}
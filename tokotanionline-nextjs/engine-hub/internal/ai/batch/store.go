@@ -0,0 +1,109 @@
+package batch
+
+import (
+	"sort"
+	"sync"
+)
+
+// Store persists batches and their keyword checkpoints
+// MemoryStore is the default; PostgresStore is used when a database is available
+type Store interface {
+	InsertBatch(b Batch) error                // batch + all items
+	UpdateBatch(b Batch) error                // batch row only (status, timestamps)
+	UpdateItem(batchID string, it Item) error // one keyword checkpoint
+	GetBatch(id string) (*Batch, error)
+	ListBatches(status string, limit int) ([]Batch, error) // newest first, status "" = all, limit 0 = no limit
+}
+
+var (
+	storeMu sync.RWMutex
+	store   Store = NewMemoryStore()
+)
+
+// SetStore replaces the batch store (call once at boot, before ResumeInterrupted)
+func SetStore(s Store) {
+	storeMu.Lock()
+	defer storeMu.Unlock()
+	store = s
+}
+
+func getStore() Store {
+	storeMu.RLock()
+	defer storeMu.RUnlock()
+	return store
+}
+
+// MemoryStore keeps batches in memory (lost on restart, dev mode)
+type MemoryStore struct {
+	mu      sync.Mutex
+	batches map[string]Batch
+}
+
+// NewMemoryStore creates an empty in-memory batch store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{batches: make(map[string]Batch)}
+}
+
+// clone copies the items slice so callers never share it with the store
+func clone(b Batch) Batch {
+	b.Items = append([]Item(nil), b.Items...)
+	return b
+}
+
+func (m *MemoryStore) InsertBatch(b Batch) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.batches[b.ID] = clone(b)
+	return nil
+}
+
+func (m *MemoryStore) UpdateBatch(b Batch) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	stored, ok := m.batches[b.ID]
+	if !ok {
+		return ErrNotFound
+	}
+	b.Items = stored.Items
+	m.batches[b.ID] = b
+	return nil
+}
+
+func (m *MemoryStore) UpdateItem(batchID string, it Item) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	b, ok := m.batches[batchID]
+	if !ok || it.Position < 0 || it.Position >= len(b.Items) {
+		return ErrNotFound
+	}
+	b.Items[it.Position] = it
+	return nil
+}
+
+func (m *MemoryStore) GetBatch(id string) (*Batch, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	b, ok := m.batches[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	b = clone(b)
+	return &b, nil
+}
+
+func (m *MemoryStore) ListBatches(status string, limit int) ([]Batch, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	list := []Batch{}
+	for _, b := range m.batches {
+		if status != "" && b.Status != status {
+			continue
+		}
+		list = append(list, clone(b))
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].CreatedAt.After(list[j].CreatedAt) })
+	if limit > 0 && len(list) > limit {
+		list = list[:limit]
+	}
+	return list, nil
+}
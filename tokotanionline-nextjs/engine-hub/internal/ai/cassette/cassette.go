@@ -0,0 +1,347 @@
+package cassette
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Record/replay for outbound AI HTTP calls (LLM + image API + image download)
+// Record once against the real API, replay offline (CI, local tests) by matching normalized requests
+//
+//	AI_CASSETTE_MODE  off (default) | record | replay | auto (replay if recorded, else record)
+//	AI_CASSETTE_DIR   cassette directory (default testdata/cassettes)
+//
+// Request headers (Authorization, API keys) are never written to cassettes
+
+// Modes
+const (
+	ModeOff    = "off"
+	ModeRecord = "record"
+	ModeReplay = "replay"
+	ModeAuto   = "auto"
+)
+
+const defaultDir = "testdata/cassettes"
+
+// ErrNoRecording is returned in replay mode when no cassette matches the request
+var ErrNoRecording = errors.New("cassette: no recording for request")
+
+// Interaction is one recorded request/response pair (one file per interaction)
+type Interaction struct {
+	Key        string    `json:"key"`
+	RecordedAt time.Time `json:"recordedAt"`
+	Request    Request   `json:"request"`
+	Response   Response  `json:"response"`
+}
+
+// Request is the recorded request (no headers)
+type Request struct {
+	Method string          `json:"method"`
+	URL    string          `json:"url"`
+	Body   json.RawMessage `json:"body,omitempty"` // normalized JSON body
+}
+
+// Response is the recorded response; JSON bodies are stored as-is, anything else base64
+type Response struct {
+	Status      int             `json:"status"`
+	ContentType string          `json:"contentType,omitempty"`
+	Body        json.RawMessage `json:"body,omitempty"`
+	BodyBase64  string          `json:"bodyBase64,omitempty"`
+}
+
+// Transport is an http.RoundTripper that records to / replays from a cassette directory
+type Transport struct {
+	Mode string
+	Dir  string
+	Next http.RoundTripper // real transport (record/auto); nil = http.DefaultTransport
+}
+
+// New creates a cassette transport
+func New(mode, dir string, next http.RoundTripper) *Transport {
+	if dir == "" {
+		dir = defaultDir
+	}
+	return &Transport{Mode: mode, Dir: dir, Next: next}
+}
+
+var (
+	defaultTransport     http.RoundTripper
+	defaultTransportOnce sync.Once
+	defaultTransportMu   sync.RWMutex
+)
+
+// Default returns the process-wide AI transport (from env on first use)
+// ModeOff returns http.DefaultTransport unchanged
+func Default() http.RoundTripper {
+	defaultTransportOnce.Do(func() {
+		rt := FromEnv()
+		defaultTransportMu.Lock()
+		if defaultTransport == nil {
+			defaultTransport = rt
+		}
+		defaultTransportMu.Unlock()
+	})
+	defaultTransportMu.RLock()
+	defer defaultTransportMu.RUnlock()
+	return defaultTransport
+}
+
+// SetDefault overrides the process-wide AI transport (tests)
+// Only generators created after the call pick it up
+func SetDefault(rt http.RoundTripper) {
+	defaultTransportOnce.Do(func() {})
+	defaultTransportMu.Lock()
+	defaultTransport = rt
+	defaultTransportMu.Unlock()
+}
+
+// FromEnv builds a transport from AI_CASSETTE_MODE / AI_CASSETTE_DIR
+func FromEnv() http.RoundTripper {
+	mode := strings.ToLower(strings.TrimSpace(os.Getenv("AI_CASSETTE_MODE")))
+	switch mode {
+	case "", ModeOff:
+		return http.DefaultTransport
+	case ModeRecord, ModeReplay, ModeAuto:
+		t := New(mode, os.Getenv("AI_CASSETTE_DIR"), http.DefaultTransport)
+		log.Printf("[CASSETTE] mode=%s dir=%s", t.Mode, t.Dir)
+		return t
+	default:
+		log.Printf("[CASSETTE] WARNING: unknown AI_CASSETTE_MODE %q, cassettes disabled", mode)
+		return http.DefaultTransport
+	}
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		data, err := io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("cassette: failed to read request body: %w", err)
+		}
+		body = data
+	}
+
+	normalized := normalizeBody(body)
+	key := Key(req.Method, req.URL.RequestURI(), normalized)
+	path := filepath.Join(t.Dir, fileName(req.URL.Path, key))
+
+	switch t.Mode {
+	case ModeReplay:
+		return t.replay(req, path, key)
+	case ModeAuto:
+		if _, err := os.Stat(path); err == nil {
+			return t.replay(req, path, key)
+		}
+	case ModeRecord:
+	default:
+		return t.next().RoundTrip(withBody(req, body))
+	}
+
+	resp, err := t.next().RoundTrip(withBody(req, body))
+	if err != nil {
+		return nil, err
+	}
+	respBody, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("cassette: failed to read response body: %w", err)
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+
+	// Only successful responses are recorded (a flaky 429/5xx must not become the fixture)
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		it := Interaction{
+			Key:        key,
+			RecordedAt: time.Now().UTC(),
+			Request:    Request{Method: req.Method, URL: req.URL.String(), Body: jsonOrNil(normalized)},
+			Response:   encodeResponse(resp.StatusCode, resp.Header.Get("Content-Type"), respBody),
+		}
+		if err := writeInteraction(path, it); err != nil {
+			log.Printf("[CASSETTE] WARNING: failed to record %s %s: %v", req.Method, req.URL.Path, err)
+		} else {
+			log.Printf("[CASSETTE] recorded %s %s -> %s", req.Method, req.URL.Path, filepath.Base(path))
+		}
+	} else {
+		log.Printf("[CASSETTE] not recording %s %s: status %d", req.Method, req.URL.Path, resp.StatusCode)
+	}
+	return resp, nil
+}
+
+func (t *Transport) next() http.RoundTripper {
+	if t.Next != nil {
+		return t.Next
+	}
+	return http.DefaultTransport
+}
+
+func (t *Transport) replay(req *http.Request, path, key string) (*http.Response, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("%w: %s %s (key %s, dir %s)", ErrNoRecording, req.Method, req.URL.Path, key, t.Dir)
+		}
+		return nil, fmt.Errorf("cassette: failed to read %s: %w", path, err)
+	}
+
+	var it Interaction
+	if err := json.Unmarshal(data, &it); err != nil {
+		return nil, fmt.Errorf("cassette: failed to decode %s: %w", path, err)
+	}
+	body, err := it.Response.decodeBody()
+	if err != nil {
+		return nil, fmt.Errorf("cassette: failed to decode body in %s: %w", path, err)
+	}
+
+	header := http.Header{}
+	if it.Response.ContentType != "" {
+		header.Set("Content-Type", it.Response.ContentType)
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", it.Response.Status, http.StatusText(it.Response.Status)),
+		StatusCode:    it.Response.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}, nil
+}
+
+// Key identifies a request: method + path/query + normalized body (host and headers are ignored,
+// so cassettes recorded against api.openai.com replay against any base URL and any API key)
+func Key(method, requestURI string, normalizedBody []byte) string {
+	h := sha256.New()
+	h.Write([]byte(strings.ToUpper(method)))
+	h.Write([]byte{'\n'})
+	h.Write([]byte(requestURI))
+	h.Write([]byte{'\n'})
+	h.Write(normalizedBody)
+	return hex.EncodeToString(h.Sum(nil))[:16]
+}
+
+// normalizeBody returns canonical JSON (sorted keys, no insignificant whitespace)
+// Non-JSON bodies are only trimmed
+func normalizeBody(body []byte) []byte {
+	trimmed := bytes.TrimSpace(body)
+	if len(trimmed) == 0 {
+		return nil
+	}
+	dec := json.NewDecoder(bytes.NewReader(trimmed))
+	dec.UseNumber()
+	var v interface{}
+	if err := dec.Decode(&v); err != nil || dec.More() {
+		return trimmed
+	}
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		return trimmed
+	}
+	return bytes.TrimRight(buf.Bytes(), "\n")
+}
+
+// fileName builds "<path-slug>-<key>.json", e.g. v1-responses-3f2a9c0d1e4b5a6f.json
+func fileName(urlPath, key string) string {
+	slug := strings.Trim(urlPath, "/")
+	slug = strings.Map(func(r rune) rune {
+		if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') {
+			return r
+		}
+		return '-'
+	}, slug)
+	if len(slug) > 60 {
+		slug = slug[:60]
+	}
+	if slug == "" {
+		slug = "root"
+	}
+	return slug + "-" + key + ".json"
+}
+
+func withBody(req *http.Request, body []byte) *http.Request {
+	if body == nil {
+		return req
+	}
+	out := req.Clone(req.Context())
+	out.Body = io.NopCloser(bytes.NewReader(body))
+	out.ContentLength = int64(len(body))
+	out.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(body)), nil
+	}
+	return out
+}
+
+func jsonOrNil(data []byte) json.RawMessage {
+	if len(data) > 0 && json.Valid(data) {
+		return json.RawMessage(data)
+	}
+	if len(data) > 0 {
+		quoted, _ := json.Marshal(string(data))
+		return json.RawMessage(quoted)
+	}
+	return nil
+}
+
+func encodeResponse(status int, contentType string, body []byte) Response {
+	r := Response{Status: status, ContentType: contentType}
+	if strings.Contains(contentType, "json") && json.Valid(body) {
+		r.Body = json.RawMessage(body)
+	} else if len(body) > 0 {
+		r.BodyBase64 = base64.StdEncoding.EncodeToString(body)
+	}
+	return r
+}
+
+func (r Response) decodeBody() ([]byte, error) {
+	if r.BodyBase64 != "" {
+		return base64.StdEncoding.DecodeString(r.BodyBase64)
+	}
+	return []byte(r.Body), nil
+}
+
+// writeInteraction writes atomically (temp file + rename) so a crashed run never leaves half a cassette
+func writeInteraction(path string, it Interaction) error {
+	data, err := json.MarshalIndent(it, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal interaction: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create cassette dir: %w", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".cassette-*")
+	if err != nil {
+		return fmt.Errorf("failed to create temp file: %w", err)
+	}
+	if _, err := tmp.Write(append(data, '\n')); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write cassette: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to close cassette: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to rename cassette: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,424 @@
+package compliance
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Rule severities
+const (
+	SeverityAutoReplace = "AUTO_REPLACE" // normalizer rewrites the match
+	SeverityWarn        = "WARN"         // validator logs the match, content passes
+	SeverityReject      = "REJECT"       // validator fails (ValidationError with the rule code)
+)
+
+// Rule categories
+const (
+	CategoryAbsoluteClaim   = "ABSOLUTE_CLAIM"
+	CategoryProhibitedWord  = "PROHIBITED_WORD"
+	CategoryPromotionalTone = "PROMOTIONAL_TONE"
+	CategoryBrandMention    = "BRAND_MENTION"
+	CategoryPlaceholder     = "PLACEHOLDER"
+)
+
+// Match modes
+const (
+	MatchWord  = "word"  // whole word/phrase, case-insensitive (default)
+	MatchRegex = "regex" // Go regexp, case-insensitive
+)
+
+// categoryCodes are the validator rule codes reported on REJECT (FASE A - A2)
+var categoryCodes = map[string]string{
+	CategoryAbsoluteClaim:   "KLAIM_ABSOLUT",
+	CategoryProhibitedWord:  "KATA_TERLARANG",
+	CategoryPromotionalTone: "NADA_PROMOSI",
+	CategoryBrandMention:    "NAMA_MEREK",
+	CategoryPlaceholder:     "STRUCTURAL_PLACEHOLDER",
+}
+
+// DefaultLocale is used when a request does not set a language
+const DefaultLocale = "id-ID"
+
+// Rule is one wording rule of a pack
+// A rule with the same ID in a more specific pack replaces it; Disabled removes it for that scope
+type Rule struct {
+	ID          string `json:"id" yaml:"id"`
+	Category    string `json:"category,omitempty" yaml:"category"`
+	Match       string `json:"match,omitempty" yaml:"match"` // word | regex
+	Pattern     string `json:"pattern,omitempty" yaml:"pattern"`
+	Replacement string `json:"replacement,omitempty" yaml:"replacement"` // AUTO_REPLACE only ("" = remove)
+	Severity    string `json:"severity,omitempty" yaml:"severity"`
+	Code        string `json:"code,omitempty" yaml:"code"` // REJECT/WARN rule code (default from category)
+	Message     string `json:"message,omitempty" yaml:"message"`
+	Disabled    bool   `json:"disabled,omitempty" yaml:"disabled"`
+}
+
+// code returns the validator rule code
+func (r Rule) code() string {
+	if r.Code != "" {
+		return r.Code
+	}
+	if code, ok := categoryCodes[r.Category]; ok {
+		return code
+	}
+	return r.Category
+}
+
+// Pack sources
+const (
+	SourceBuiltin = "builtin" // embedded packs/*.yaml
+	SourceFile    = "file"    // COMPLIANCE_RULES_DIR
+	SourceDB      = "db"      // saved through the API
+)
+
+// Pack is a named set of rules scoped by brand and locale ("" = any)
+// A file/DB pack with the same name as a builtin pack replaces it
+type Pack struct {
+	Name        string    `json:"name" yaml:"name"`
+	Brand       string    `json:"brand,omitempty" yaml:"brand"`
+	Locale      string    `json:"locale,omitempty" yaml:"locale"` // "id" also matches "id-ID"
+	Description string    `json:"description,omitempty" yaml:"description"`
+	Rules       []Rule    `json:"rules" yaml:"rules"`
+	Source      string    `json:"source,omitempty" yaml:"-"`
+	UpdatedBy   string    `json:"updatedBy,omitempty" yaml:"-"`
+	UpdatedAt   time.Time `json:"updatedAt,omitempty" yaml:"-"`
+}
+
+// specificity orders packs: generic < locale < brand < brand+locale
+func (p Pack) specificity() int {
+	n := 0
+	if p.Brand != "" {
+		n += 2
+	}
+	if p.Locale != "" {
+		n++
+	}
+	return n
+}
+
+// Scope selects the packs that apply to a generation
+type Scope struct {
+	Brand  string `json:"brand,omitempty"`
+	Locale string `json:"locale"`
+}
+
+// DefaultScope is the scope of content without brand/language (builtin rules)
+func DefaultScope() Scope {
+	return Scope{Locale: DefaultLocale}
+}
+
+// applies reports whether pack p is in scope s
+func (p Pack) applies(s Scope) bool {
+	if p.Brand != "" && !strings.EqualFold(p.Brand, s.Brand) {
+		return false
+	}
+	if p.Locale == "" {
+		return true
+	}
+	return strings.EqualFold(p.Locale, s.Locale) ||
+		strings.HasPrefix(strings.ToLower(s.Locale), strings.ToLower(p.Locale)+"-")
+}
+
+// Validation is the result of checking a pack
+type Validation struct {
+	Valid  bool     `json:"valid"`
+	Errors []string `json:"errors,omitempty"`
+}
+
+var packNamePattern = regexp.MustCompile(`^[A-Za-z0-9_.-]+$`)
+
+// ValidatePack checks names, severities, match modes and patterns
+func ValidatePack(p Pack) Validation {
+	var errs []string
+	if !packNamePattern.MatchString(p.Name) {
+		errs = append(errs, "name is required (letters, digits, '.', '_', '-')")
+	}
+	seen := make(map[string]bool)
+	for i, rule := range p.Rules {
+		label := fmt.Sprintf("rule %d", i+1)
+		if rule.ID == "" {
+			errs = append(errs, label+": id is required")
+		} else {
+			label = fmt.Sprintf("rule %s", rule.ID)
+			if seen[rule.ID] {
+				errs = append(errs, label+": duplicate id")
+			}
+			seen[rule.ID] = true
+		}
+		if rule.Disabled {
+			continue // override that only switches a rule off
+		}
+		switch rule.Severity {
+		case SeverityAutoReplace, SeverityWarn, SeverityReject:
+		default:
+			errs = append(errs, fmt.Sprintf("%s: severity must be %s, %s or %s", label, SeverityAutoReplace, SeverityWarn, SeverityReject))
+		}
+		if rule.Pattern == "" {
+			errs = append(errs, label+": pattern is required")
+			continue
+		}
+		if rule.Match != "" && rule.Match != MatchWord && rule.Match != MatchRegex {
+			errs = append(errs, fmt.Sprintf("%s: match must be %s or %s", label, MatchWord, MatchRegex))
+			continue
+		}
+		if _, err := compileRule(rule); err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %v", label, err))
+		}
+	}
+	return Validation{Valid: len(errs) == 0, Errors: errs}
+}
+
+// compileRule builds the case-insensitive matcher of a rule
+func compileRule(rule Rule) (*regexp.Regexp, error) {
+	pattern := rule.Pattern
+	if rule.Match != MatchRegex {
+		pattern = `\b` + regexp.QuoteMeta(pattern) + `\b`
+	}
+	re, err := regexp.Compile(`(?i)` + pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid pattern: %w", err)
+	}
+	return re, nil
+}
+
+// compiledRule is a rule with its matcher and origin pack
+type compiledRule struct {
+	Rule
+	pack string
+	re   *regexp.Regexp
+}
+
+// compilePack compiles every enabled rule of a valid pack
+func compilePack(p Pack) []compiledRule {
+	out := make([]compiledRule, 0, len(p.Rules))
+	for _, rule := range p.Rules {
+		cr := compiledRule{Rule: rule, pack: p.Name}
+		if !rule.Disabled {
+			re, err := compileRule(rule)
+			if err != nil {
+				continue // ValidatePack ran before; unreachable for loaded packs
+			}
+			cr.re = re
+		}
+		out = append(out, cr)
+	}
+	return out
+}
+
+// RuleSet is the merged rules of every pack in a scope
+type RuleSet struct {
+	Scope Scope
+	Packs []string // least specific first
+	rules []compiledRule
+}
+
+// resolve merges the packs in scope: more specific packs replace (or disable) rules by ID
+func resolve(scope Scope, packs []Pack, compiled map[string][]compiledRule) *RuleSet {
+	var inScope []Pack
+	for _, p := range packs {
+		if p.applies(scope) {
+			inScope = append(inScope, p)
+		}
+	}
+	sort.SliceStable(inScope, func(i, j int) bool { return inScope[i].specificity() < inScope[j].specificity() })
+
+	set := &RuleSet{Scope: scope}
+	index := make(map[string]int)
+	for _, p := range inScope {
+		set.Packs = append(set.Packs, p.Name)
+		for _, cr := range compiled[p.Name] {
+			if i, ok := index[cr.ID]; ok {
+				set.rules[i] = cr
+				continue
+			}
+			index[cr.ID] = len(set.rules)
+			set.rules = append(set.rules, cr)
+		}
+	}
+
+	enabled := set.rules[:0]
+	for _, cr := range set.rules {
+		if !cr.Disabled {
+			enabled = append(enabled, cr)
+		}
+	}
+	set.rules = enabled
+	return set
+}
+
+// ResolvedRule is a rule as applied in a scope (with the pack it came from)
+type ResolvedRule struct {
+	Rule
+	Pack string `json:"pack"`
+	Code string `json:"code,omitempty"`
+}
+
+// Rules lists the effective rules in application order
+func (s *RuleSet) Rules() []ResolvedRule {
+	out := make([]ResolvedRule, 0, len(s.rules))
+	for _, cr := range s.rules {
+		rule := cr.Rule
+		rule.Code = ""
+		r := ResolvedRule{Rule: rule, Pack: cr.pack}
+		if cr.Severity != SeverityAutoReplace {
+			r.Code = cr.code()
+		}
+		out = append(out, r)
+	}
+	return out
+}
+
+// Stages a finding can come from
+const (
+	StageNormalize = "normalize"
+	StageValidate  = "validate"
+)
+
+// Finding is one rule firing on a text
+type Finding struct {
+	Stage       string `json:"stage"`
+	RuleID      string `json:"ruleId"`
+	Pack        string `json:"pack"`
+	Category    string `json:"category,omitempty"`
+	Severity    string `json:"severity"`
+	Code        string `json:"code,omitempty"`
+	Match       string `json:"match"`
+	Count       int    `json:"count"`
+	Context     string `json:"context"`
+	Replacement string `json:"replacement,omitempty"`
+	Message     string `json:"message,omitempty"`
+}
+
+// Normalize applies the AUTO_REPLACE rules in order and returns the rewritten text with what fired
+// Word matches keep an uppercase first letter (sentence start stays capitalized)
+func (s *RuleSet) Normalize(text string) (string, []Finding) {
+	var findings []Finding
+	for _, cr := range s.rules {
+		if cr.Severity != SeverityAutoReplace {
+			continue
+		}
+		locs := cr.re.FindAllStringIndex(text, -1)
+		if len(locs) == 0 {
+			continue
+		}
+		findings = append(findings, cr.finding(StageNormalize, text, locs))
+
+		if cr.Match == MatchRegex {
+			text = cr.re.ReplaceAllString(text, cr.Replacement)
+			continue
+		}
+		replacement := cr.Replacement
+		text = cr.re.ReplaceAllStringFunc(text, func(match string) string {
+			if replacement != "" && match[0] >= 'A' && match[0] <= 'Z' {
+				return strings.ToUpper(replacement[:1]) + replacement[1:]
+			}
+			return replacement
+		})
+	}
+	return text, findings
+}
+
+// Check returns the WARN and REJECT rules that match text, in rule order
+func (s *RuleSet) Check(text string) []Finding {
+	var findings []Finding
+	for _, cr := range s.rules {
+		if cr.Severity == SeverityAutoReplace {
+			continue
+		}
+		if locs := cr.re.FindAllStringIndex(text, -1); len(locs) > 0 {
+			findings = append(findings, cr.finding(StageValidate, text, locs))
+		}
+	}
+	return findings
+}
+
+func (cr compiledRule) finding(stage, text string, locs [][]int) Finding {
+	f := Finding{
+		Stage:    stage,
+		RuleID:   cr.ID,
+		Pack:     cr.pack,
+		Category: cr.Category,
+		Severity: cr.Severity,
+		Match:    text[locs[0][0]:locs[0][1]],
+		Count:    len(locs),
+		Context:  snippet(text, locs[0][0], locs[0][1], 50),
+		Message:  cr.Message,
+	}
+	if cr.Severity == SeverityAutoReplace {
+		f.Replacement = cr.Replacement
+	} else {
+		f.Code = cr.code()
+		if f.Message == "" {
+			f.Message = fmt.Sprintf("%s '%s' detected", strings.ToLower(strings.ReplaceAll(cr.Category, "_", " ")), f.Match)
+		}
+	}
+	return f
+}
+
+// snippet returns the match with up to size bytes of context on each side
+func snippet(text string, start, end, size int) string {
+	from := start - size
+	if from < 0 {
+		from = 0
+	}
+	to := end + size
+	if to > len(text) {
+		to = len(text)
+	}
+	// do not cut a UTF-8 sequence in half
+	for from > 0 && !isRuneStart(text[from]) {
+		from--
+	}
+	for to < len(text) && !isRuneStart(text[to]) {
+		to++
+	}
+	return fmt.Sprintf("...%s...", text[from:to])
+}
+
+func isRuneStart(b byte) bool {
+	return b&0xC0 != 0x80
+}
+
+// Verdicts of a dry run
+const (
+	VerdictPass   = "PASS"
+	VerdictWarn   = "WARN"
+	VerdictReject = "REJECT"
+)
+
+// DryRun shows what the normalizer and validator would do with a text
+type DryRun struct {
+	Scope      Scope     `json:"scope"`
+	Packs      []string  `json:"packs"`
+	Verdict    string    `json:"verdict"` // PASS | WARN | REJECT
+	Normalized string    `json:"normalized"`
+	Findings   []Finding `json:"findings"`
+}
+
+// DryRun normalizes text, then checks the normalized text (same order as the pipeline)
+func (s *RuleSet) DryRun(text string) DryRun {
+	normalized, findings := s.Normalize(text)
+	checked := s.Check(normalized)
+
+	verdict := VerdictPass
+	for _, f := range checked {
+		if f.Severity == SeverityReject {
+			verdict = VerdictReject
+			break
+		}
+		verdict = VerdictWarn
+	}
+	if findings == nil {
+		findings = []Finding{}
+	}
+	return DryRun{
+		Scope:      s.Scope,
+		Packs:      s.Packs,
+		Verdict:    verdict,
+		Normalized: normalized,
+		Findings:   append(findings, checked...),
+	}
+}
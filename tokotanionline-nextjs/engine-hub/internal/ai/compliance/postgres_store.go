@@ -0,0 +1,67 @@
+package compliance
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+)
+
+// PostgresStore persists packs in "EngineHubCompliancePack" (rules as jsonb)
+type PostgresStore struct {
+	db *sql.DB
+}
+
+// NewPostgresStore creates a pack store backed by the given database
+func NewPostgresStore(db *sql.DB) *PostgresStore {
+	return &PostgresStore{db: db}
+}
+
+func (p *PostgresStore) ListPacks() ([]Pack, error) {
+	rows, err := p.db.Query(`
+		SELECT name, COALESCE(brand, ''), COALESCE(locale, ''), COALESCE(description, ''), rules,
+			COALESCE("updatedBy", ''), "updatedAt"
+		FROM "EngineHubCompliancePack"
+		ORDER BY name
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query compliance packs: %w", err)
+	}
+	defer rows.Close()
+
+	var out []Pack
+	for rows.Next() {
+		pack := Pack{Source: SourceDB}
+		var rules []byte
+		if err := rows.Scan(&pack.Name, &pack.Brand, &pack.Locale, &pack.Description, &rules, &pack.UpdatedBy, &pack.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan compliance pack: %w", err)
+		}
+		if err := json.Unmarshal(rules, &pack.Rules); err != nil {
+			return nil, fmt.Errorf("failed to decode rules of compliance pack %q: %w", pack.Name, err)
+		}
+		out = append(out, pack)
+	}
+	return out, rows.Err()
+}
+
+func (p *PostgresStore) SavePack(pack Pack) error {
+	rules, err := json.Marshal(pack.Rules)
+	if err != nil {
+		return fmt.Errorf("failed to encode compliance rules: %w", err)
+	}
+	_, err = p.db.Exec(`
+		INSERT INTO "EngineHubCompliancePack" (name, brand, locale, description, rules, "updatedBy", "updatedAt")
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (name) DO UPDATE SET
+			brand = EXCLUDED.brand, locale = EXCLUDED.locale, description = EXCLUDED.description,
+			rules = EXCLUDED.rules, "updatedBy" = EXCLUDED."updatedBy", "updatedAt" = EXCLUDED."updatedAt"
+	`, pack.Name, nullString(pack.Brand), nullString(pack.Locale), nullString(pack.Description), string(rules),
+		nullString(pack.UpdatedBy), pack.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save compliance pack: %w", err)
+	}
+	return nil
+}
+
+func nullString(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
@@ -0,0 +1,346 @@
+package compliance
+
+import (
+	"crypto/sha256"
+	"embed"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+//go:embed packs/*.yaml
+var builtinFS embed.FS
+
+// Registry loads rule packs (builtin, COMPLIANCE_RULES_DIR, store) and resolves them per scope
+// Later sources replace packs with the same name: builtin < file < db
+type Registry struct {
+	dir   string
+	store Store
+
+	mu          sync.RWMutex
+	packs       []Pack
+	compiled    map[string][]compiledRule
+	fingerprint string
+}
+
+// NewRegistry creates a registry reading packs from dir ("" = builtin only) and store
+func NewRegistry(dir string, store Store) *Registry {
+	if store == nil {
+		store = NewMemoryStore()
+	}
+	r := &Registry{dir: dir, store: store}
+	if err := r.Reload(); err != nil {
+		log.Printf("[COMPLIANCE] WARNING: %v", err)
+	}
+	return r
+}
+
+// Reload re-reads builtin, file and stored packs
+// Invalid file/stored packs are skipped with a warning; the rules are swapped atomically and only when something changed
+func (r *Registry) Reload() error {
+	byName := make(map[string]Pack)
+	var order, overrides []string
+	add := func(p Pack) {
+		if v := ValidatePack(p); !v.Valid {
+			log.Printf("[COMPLIANCE] WARNING: skipping %s pack %q: %s", p.Source, p.Name, strings.Join(v.Errors, "; "))
+			return
+		}
+		if prev, ok := byName[p.Name]; ok {
+			overrides = append(overrides, fmt.Sprintf("%s pack %q overrides %s", p.Source, p.Name, prev.Source))
+		} else {
+			order = append(order, p.Name)
+		}
+		byName[p.Name] = p
+	}
+
+	builtin, err := readPacks(builtinFS, "packs", SourceBuiltin)
+	if err != nil {
+		return fmt.Errorf("failed to read builtin compliance packs: %w", err)
+	}
+	for _, p := range builtin {
+		if v := ValidatePack(p); !v.Valid {
+			return fmt.Errorf("builtin compliance pack %q is invalid: %s", p.Name, strings.Join(v.Errors, "; "))
+		}
+		add(p)
+	}
+
+	if r.dir != "" {
+		files, err := readPacks(os.DirFS(r.dir), ".", SourceFile)
+		if err != nil {
+			log.Printf("[COMPLIANCE] WARNING: cannot read COMPLIANCE_RULES_DIR %s: %v", r.dir, err)
+		}
+		for _, p := range files {
+			add(p)
+		}
+	}
+
+	var errs []string
+	stored, err := r.store.ListPacks()
+	if err != nil {
+		errs = append(errs, err.Error())
+	}
+	for _, p := range stored {
+		p.Source = SourceDB
+		add(p)
+	}
+
+	packs := make([]Pack, 0, len(order))
+	compiled := make(map[string][]compiledRule, len(order))
+	for _, name := range order {
+		p := byName[name]
+		packs = append(packs, p)
+		compiled[name] = compilePack(p)
+	}
+	fingerprint := fingerprintOf(packs)
+
+	r.mu.Lock()
+	changed := fingerprint != r.fingerprint
+	if changed {
+		r.packs = packs
+		r.compiled = compiled
+		r.fingerprint = fingerprint
+	}
+	r.mu.Unlock()
+
+	if changed {
+		for _, o := range overrides {
+			log.Printf("[COMPLIANCE] %s", o)
+		}
+		names := make([]string, 0, len(packs))
+		for _, p := range packs {
+			names = append(names, fmt.Sprintf("%s(%s, %d rules)", p.Name, p.Source, len(p.Rules)))
+		}
+		log.Printf("[COMPLIANCE] loaded (dir=%q stored=%d): %s", r.dir, len(stored), strings.Join(names, ", "))
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("failed to load stored compliance packs: %s", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// fingerprintOf hashes the loaded packs so periodic reloads only swap (and log) on change
+func fingerprintOf(packs []Pack) string {
+	data, _ := json.Marshal(packs)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// readPacks loads *.json, *.yaml and *.yml packs of dir in name order
+// A pack without a name takes the file name (brand-x.id-ID.yaml → "brand-x.id-ID")
+func readPacks(fsys fs.FS, dir, source string) ([]Pack, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name() < entries[j].Name() })
+
+	var out []Pack
+	for _, e := range entries {
+		ext := strings.ToLower(filepath.Ext(e.Name()))
+		if e.IsDir() || (ext != ".json" && ext != ".yaml" && ext != ".yml") {
+			continue
+		}
+		file := path.Join(dir, e.Name())
+		data, err := fs.ReadFile(fsys, file)
+		if err != nil {
+			log.Printf("[COMPLIANCE] WARNING: cannot read %s: %v", file, err)
+			continue
+		}
+		p, err := ParsePack(data, ext)
+		if err != nil {
+			log.Printf("[COMPLIANCE] WARNING: skipping %s: %v", file, err)
+			continue
+		}
+		if p.Name == "" {
+			p.Name = strings.TrimSuffix(e.Name(), filepath.Ext(e.Name()))
+		}
+		p.Source = source
+		if info, err := e.Info(); err == nil && source == SourceFile {
+			p.UpdatedAt = info.ModTime()
+		}
+		out = append(out, p)
+	}
+	return out, nil
+}
+
+// ParsePack decodes a pack from JSON (ext ".json") or YAML
+func ParsePack(data []byte, ext string) (Pack, error) {
+	var p Pack
+	if ext == ".json" {
+		if err := json.Unmarshal(data, &p); err != nil {
+			return Pack{}, fmt.Errorf("invalid JSON: %w", err)
+		}
+		return p, nil
+	}
+	if err := yaml.Unmarshal(data, &p); err != nil {
+		return Pack{}, fmt.Errorf("invalid YAML: %w", err)
+	}
+	return p, nil
+}
+
+// normalizeScope fills the default locale
+func normalizeScope(scope Scope) Scope {
+	scope.Brand = strings.TrimSpace(scope.Brand)
+	scope.Locale = strings.TrimSpace(scope.Locale)
+	if scope.Locale == "" {
+		scope.Locale = DefaultLocale
+	}
+	return scope
+}
+
+// Resolve returns the merged rules for a brand/locale
+func (r *Registry) Resolve(scope Scope) *RuleSet {
+	r.mu.RLock()
+	packs, compiled := r.packs, r.compiled
+	r.mu.RUnlock()
+	return resolve(normalizeScope(scope), packs, compiled)
+}
+
+// ResolveWith resolves scope with an unsaved pack applied last (dry-run of a draft pack)
+func (r *Registry) ResolveWith(scope Scope, draft Pack) (*RuleSet, Validation) {
+	v := ValidatePack(draft)
+	if !v.Valid {
+		return nil, v
+	}
+	draft.Source = "draft"
+	scope = normalizeScope(scope)
+	// the draft takes the requested scope so it always applies, and as the last most specific pack it wins every rule ID
+	draft.Brand, draft.Locale = scope.Brand, scope.Locale
+
+	r.mu.RLock()
+	packs := make([]Pack, 0, len(r.packs)+1)
+	compiled := make(map[string][]compiledRule, len(r.compiled)+1)
+	for _, p := range r.packs {
+		if p.Name != draft.Name {
+			packs = append(packs, p)
+			compiled[p.Name] = r.compiled[p.Name]
+		}
+	}
+	r.mu.RUnlock()
+
+	packs = append(packs, draft)
+	compiled[draft.Name] = compilePack(draft)
+	set := resolve(scope, packs, compiled)
+	return set, v
+}
+
+// List returns the loaded packs (effective, after name overrides)
+func (r *Registry) List() []Pack {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	out := make([]Pack, len(r.packs))
+	copy(out, r.packs)
+	return out
+}
+
+// Get returns a loaded pack by name
+func (r *Registry) Get(name string) (Pack, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for _, p := range r.packs {
+		if p.Name == name {
+			return p, true
+		}
+	}
+	return Pack{}, false
+}
+
+// Save validates and stores a pack (insert or replace by name), then reloads
+func (r *Registry) Save(p Pack) (Pack, Validation, error) {
+	v := ValidatePack(p)
+	if !v.Valid {
+		return Pack{}, v, fmt.Errorf("pack is invalid: %s", strings.Join(v.Errors, "; "))
+	}
+	p.Source = SourceDB
+	p.UpdatedAt = time.Now()
+	if err := r.store.SavePack(p); err != nil {
+		return Pack{}, v, fmt.Errorf("failed to save pack %q: %w", p.Name, err)
+	}
+	log.Printf("[COMPLIANCE] saved pack %q (brand=%q locale=%q rules=%d) by=%q", p.Name, p.Brand, p.Locale, len(p.Rules), p.UpdatedBy)
+	if err := r.Reload(); err != nil {
+		return Pack{}, v, err
+	}
+	saved, _ := r.Get(p.Name)
+	return saved, v, nil
+}
+
+var (
+	defaultMu       sync.RWMutex
+	defaultRegistry *Registry
+)
+
+// Default returns the process-wide registry (COMPLIANCE_RULES_DIR + in-memory store until SetStore)
+func Default() *Registry {
+	defaultMu.RLock()
+	r := defaultRegistry
+	defaultMu.RUnlock()
+	if r != nil {
+		return r
+	}
+
+	defaultMu.Lock()
+	defer defaultMu.Unlock()
+	if defaultRegistry == nil {
+		defaultRegistry = NewRegistry(os.Getenv("COMPLIANCE_RULES_DIR"), NewMemoryStore())
+	}
+	return defaultRegistry
+}
+
+// SetStore rebuilds the process-wide registry on store (call once at boot)
+func SetStore(s Store) {
+	r := NewRegistry(os.Getenv("COMPLIANCE_RULES_DIR"), s)
+	defaultMu.Lock()
+	defaultRegistry = r
+	defaultMu.Unlock()
+}
+
+// Resolve returns the merged rules for scope from the process-wide registry
+func Resolve(scope Scope) *RuleSet {
+	return Default().Resolve(scope)
+}
+
+// ReloadInterval reads COMPLIANCE_RELOAD_SEC from env (default 60 detik, 0 = only on /reload)
+func ReloadInterval() time.Duration {
+	seconds := 60
+	if v := os.Getenv("COMPLIANCE_RELOAD_SEC"); v != "" {
+		if val, err := strconv.Atoi(v); err == nil && val >= 0 {
+			seconds = val
+		}
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// StartAutoReload re-reads COMPLIANCE_RULES_DIR and stored packs every interval (hot reload of edited files)
+// Stops when stopCh is closed; interval 0 disables it
+func StartAutoReload(interval time.Duration, stopCh <-chan struct{}) {
+	if interval <= 0 {
+		return
+	}
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-stopCh:
+				return
+			case <-ticker.C:
+			}
+			if err := Default().Reload(); err != nil {
+				log.Printf("[COMPLIANCE] WARNING: %v", err)
+			}
+		}
+	}()
+}
@@ -0,0 +1,48 @@
+package compliance
+
+import (
+	"sort"
+	"sync"
+)
+
+// Store persists packs saved through the API
+// MemoryStore is the default; PostgresStore is used when a database is available
+type Store interface {
+	ListPacks() ([]Pack, error)
+	SavePack(p Pack) error
+}
+
+// MemoryStore keeps packs in memory (lost on restart, dev mode)
+type MemoryStore struct {
+	mu    sync.Mutex
+	packs map[string]Pack
+}
+
+// NewMemoryStore creates an empty in-memory pack store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{packs: make(map[string]Pack)}
+}
+
+func (m *MemoryStore) ListPacks() ([]Pack, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	out := make([]Pack, 0, len(m.packs))
+	for _, p := range m.packs {
+		rules := make([]Rule, len(p.Rules))
+		copy(rules, p.Rules)
+		p.Rules = rules
+		out = append(out, p)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out, nil
+}
+
+func (m *MemoryStore) SavePack(p Pack) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	rules := make([]Rule, len(p.Rules))
+	copy(rules, p.Rules)
+	p.Rules = rules
+	m.packs[p.Name] = p
+	return nil
+}
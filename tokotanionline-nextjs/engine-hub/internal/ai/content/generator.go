@@ -1,17 +1,17 @@
 package content
 
 import (
-	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
-	"io"
 	"log"
-	"net/http"
 	"os"
 	"strings"
 	"time"
 	"unicode"
+
+	"engine-hub/internal/ai/llm"
+	"engine-hub/internal/ai/prompts"
 )
 
 // ContentRequest represents the request for content generation
@@ -20,10 +20,13 @@ type ContentRequest struct {
 	Category    string     `json:"category"`    // K1, K2, K3, K4
 	Outline     string     `json:"outline"`     // LOCKED - outline yang harus diikuti
 	Language    string     `json:"language"`    // id-ID
+	Brand       string     `json:"brand,omitempty"` // compliance rule pack scope (with Language)
 	// AI Generator v2 fields
 	AnswerDriven bool     `json:"answerDriven,omitempty"` // v2: Answer-driven writing mode
 	Intent       string   `json:"intent,omitempty"`       // v2: informational, how_to, commercial, comparison
 	Questions    []string `json:"questions,omitempty"`    // v2: Core questions for answer-driven writing
+	// Prompt template version to render (0 = active); set by prompt experiments or to pin a version
+	TemplateVersion int `json:"templateVersion,omitempty"`
 }
 
 // ContentResult represents the raw AI-generated content
@@ -33,65 +36,70 @@ type ContentResult struct {
 	MetaTitle  string `json:"metaTitle"`
 	MetaDesc   string `json:"metaDesc"`
 	Status     string `json:"status"` // RAW_AI
+	PromptVersion string `json:"promptVersion,omitempty"` // prompt template ref, e.g. content.derivative@v2
+	// Structured output (see structured.go): FAQ is also rendered into Body, CTA is not
+	FAQ        []FAQItem `json:"faq,omitempty"`
+	CTA        string    `json:"cta,omitempty"`
+	OutputMode string    `json:"outputMode,omitempty"` // json_schema | text
 }
 
 // Generator handles AI content generation
 type Generator struct {
-	client     *http.Client
-	apiKey     string
-	apiURL     string
-	model      string
+	llm    *llm.Client
+	llmErr error // why llm is nil (missing key, unknown provider)
+	apiKey string
+	model  string
 }
 
 // NewGenerator creates a new content generator
+// Provider/model/fallback: LLM_CONTENT_* (see llm.ConfigFromEnv), default Responses API + GPT-5.2
 func NewGenerator() *Generator {
 	log.Println("[GENERATOR] Creating new content generator...")
-	// Get API key from environment (OpenAI, Claude, or other)
-	apiKey := os.Getenv("OPENAI_API_KEY")
-	if apiKey == "" {
-		apiKey = os.Getenv("AI_API_KEY")
-	}
-	log.Printf("[GENERATOR] API key loaded: present=%v, length=%d", apiKey != "", len(apiKey))
 
-	// TASK 19: Use Responses API (WAJIB)
-	apiURL := os.Getenv("AI_API_URL")
-	if apiURL == "" {
-		apiURL = "https://api.openai.com/v1/responses" // TASK 19: Responses API endpoint
-	}
-
-	// A1. Lock Model ke GPT-5.2 (WAJIB - NO FALLBACK)
-	// ❌ Jangan pakai fallback otomatis ke model lama
-	// ➡️ Jika GPT-5.2 unavailable → return error, bukan downgrade diam-diam
+	// A1. Default model GPT-5.2 (NO FALLBACK kecuali LLM_CONTENT_FALLBACK di-set)
+	// ➡️ Jika model unavailable → return error, bukan downgrade diam-diam
 	model := os.Getenv("AI_MODEL")
 	if model == "" {
 		model = "gpt-5.2" // Default to GPT-5.2
 	}
-	if model != "gpt-5.2" {
-		log.Printf("[GENERATOR] WARNING: Model is set to %s, but GPT-5.2 is required. Forcing GPT-5.2.", model)
-		model = "gpt-5.2"
+
+	// TASK 19: Use Responses API (default provider)
+	cfg := llm.ConfigFromEnv("content", llm.Config{
+		Provider: llm.ProviderResponses,
+		Model:    model,
+		BaseURL:  os.Getenv("AI_API_URL"),
+		Timeout:  60 * time.Second, // 60 second timeout for AI generation
+	})
+	log.Printf("[GENERATOR] API key loaded: present=%v, length=%d", cfg.APIKey != "", len(cfg.APIKey))
+
+	if cfg.Model != "gpt-5.2" {
+		log.Printf("[GENERATOR] WARNING: Model is set to %s (default GPT-5.2)", cfg.Model)
+	}
+	log.Printf("[AI MODEL] provider=%s model=%s fallbacks=%d", cfg.Provider, cfg.Model, len(cfg.Fallbacks))
+
+	client, err := llm.New(cfg)
+	if err != nil {
+		log.Printf("[GENERATOR] WARNING: AI client not configured: %v", err)
 	}
-	log.Printf("[AI MODEL LOCKED] %s (NO FALLBACK)", model)
 
 	return &Generator{
-		client: &http.Client{
-			Timeout: 60 * time.Second, // 60 second timeout for AI generation
-		},
-		apiKey: apiKey,
-		apiURL: apiURL,
-		model:  model,
+		llm:    client,
+		llmErr: err,
+		apiKey: cfg.APIKey,
+		model:  cfg.Model,
 	}
 }
 
 // Generate produces raw AI content based on the request
 // TASK 4: Implements retry loop with word count validation (min 900 words)
 // This function only generates content, does NOT publish
-func (g *Generator) Generate(req ContentRequest) (*ContentResult, error) {
+func (g *Generator) Generate(ctx context.Context, req ContentRequest) (*ContentResult, error) {
 	log.Println("[AI] Starting content generation...")
 	log.Printf("[AI] API Key present: %v (length: %d)", g.apiKey != "", len(g.apiKey))
 	
-	if g.apiKey == "" {
-		log.Println("[AI] ERROR: API key is empty!")
-		return nil, fmt.Errorf("AI_API_KEY or OPENAI_API_KEY environment variable not set")
+	if g.llm == nil {
+		log.Printf("[AI] ERROR: %v", g.llmErr)
+		return nil, fmt.Errorf("AI client not configured: %w", g.llmErr)
 	}
 
 	// Validate request
@@ -101,35 +109,36 @@ func (g *Generator) Generate(req ContentRequest) (*ContentResult, error) {
 
 	// TASK 4: Retry loop for long-form articles (DERIVATIVE_LONG)
 	if req.ContentType == ContentDerivativeLong || req.ContentType == ContentCornerstone {
-		return g.GenerateWithRetry(req)
+		return g.GenerateWithRetry(ctx, req)
 	}
 
 	// For other content types, use standard generation (no retry)
 	// Build prompt based on content type and outline
 	log.Println("[AI] Building prompt...")
-	prompt := g.buildPrompt(req)
-	log.Printf("[AI] Prompt built, length: %d chars", len(prompt))
+	prompt, promptRef, err := g.buildPrompt(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build prompt: %w", err)
+	}
+	log.Printf("[AI] Prompt built (%s), length: %d chars", promptRef, len(prompt))
 
 	// Determine max_tokens based on content type
 	maxTokens := 4000
 
 	// Call AI model
 	log.Println("[AI] Calling OpenAI API...")
-	log.Printf("[AI MODEL LOCKED] %s", g.model)
-	log.Printf("[AI] API URL: %s", g.apiURL)
+	log.Printf("[AI MODEL] %s", g.model)
+	log.Printf("[AI] Provider: %s", g.llm.Provider())
 	log.Printf("[AI] Max tokens: %d (contentType: %s)", maxTokens, req.ContentType)
-	rawContent, usage, err := g.callAI(prompt, maxTokens)
+	result, usage, err := g.generateOnce(ctx, prompt, maxTokens, req)
 	if err != nil {
 		log.Printf("[AI] OpenAI API call failed: %v", err)
 		return nil, fmt.Errorf("AI generation failed: %w", err)
 	}
-	log.Printf("[AI] OpenAI response received, length: %d chars", len(rawContent))
+	log.Printf("[AI] OpenAI response received (%s), body length: %d chars", result.OutputMode, len(result.Body))
 	if usage != nil && usage.OutputTokens > 0 {
 		log.Printf("[AI] Token usage: output_tokens=%d", usage.OutputTokens)
 	}
-
-	// Parse AI response into structured content
-	result := g.parseResponse(rawContent, req)
+	result.PromptVersion = promptRef
 
 	// KONTRAK FINAL: Validate output contract (FAIL HARD if invalid)
 	if err := ValidateOutputContract(result); err != nil {
@@ -148,15 +157,18 @@ func (g *Generator) Generate(req ContentRequest) (*ContentResult, error) {
 
 // GenerateWithRetry implements TASK 4: Retry loop with word count validation
 // Minimum 900 words, maximum 3 retries
-func (g *Generator) GenerateWithRetry(req ContentRequest) (*ContentResult, error) {
+func (g *Generator) GenerateWithRetry(ctx context.Context, req ContentRequest) (*ContentResult, error) {
 	const (
 		MinWords = 900
 		MaxRetry = 3
 	)
 
 	// Build initial prompt
-	prompt := g.buildPrompt(req)
-	log.Printf("[AI] Initial prompt built, length: %d chars", len(prompt))
+	prompt, promptRef, err := g.buildPrompt(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build prompt: %w", err)
+	}
+	log.Printf("[AI] Initial prompt built (%s), length: %d chars", promptRef, len(prompt))
 
 	// TASK 16: MaxOutputTokens for GPT-5.2
 	maxTokens := 4096 // Bisa dinaikkan ke 6000 jika perlu
@@ -166,12 +178,12 @@ func (g *Generator) GenerateWithRetry(req ContentRequest) (*ContentResult, error
 
 	for attempt := 1; attempt <= MaxRetry; attempt++ {
 		log.Printf("[AI] Attempt %d/%d for content generation", attempt, MaxRetry)
-		log.Printf("[AI MODEL LOCKED] %s", g.model)
-		log.Printf("[AI] API URL: %s", g.apiURL)
+		log.Printf("[AI MODEL] %s", g.model)
+		log.Printf("[AI] Provider: %s", g.llm.Provider())
 		log.Printf("[AI] Max tokens: %d", maxTokens)
 
-		// Call AI model
-		rawContent, usage, err := g.callAI(prompt, maxTokens)
+		// Call AI model (JSON schema output when supported, text parsing otherwise)
+		attemptResult, usage, err := g.generateOnce(ctx, prompt, maxTokens, req)
 		if err != nil {
 			log.Printf("[AI] Attempt %d failed: %v", attempt, err)
 			lastErr = err
@@ -182,14 +194,14 @@ func (g *Generator) GenerateWithRetry(req ContentRequest) (*ContentResult, error
 			continue
 		}
 
-		log.Printf("[AI] Attempt %d: OpenAI response received, length: %d chars", attempt, len(rawContent))
+		log.Printf("[AI] Attempt %d: OpenAI response received (%s), body length: %d chars", attempt, attemptResult.OutputMode, len(attemptResult.Body))
 		if usage != nil && usage.OutputTokens > 0 {
 			log.Printf("[AI] Attempt %d: Token usage: output_tokens=%d", 
 				attempt, usage.OutputTokens)
 		}
 
-		// Parse AI response into structured content
-		result = g.parseResponse(rawContent, req)
+		result = attemptResult
+		result.PromptVersion = promptRef
 
 		// KONTRAK FINAL: Validate output contract (FAIL HARD if invalid)
 		if err := ValidateOutputContract(result); err != nil {
@@ -258,19 +270,27 @@ func (g *Generator) GenerateLongFormArticle(ctx context.Context, topic string) (
 	)
 
 	// Build hard-constraint prompt
-	prompt := g.buildHardConstraintPrompt(topic)
+	prompt, promptRef, err := g.buildHardConstraintPrompt(topic)
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to build prompt: %w", err)
+	}
+	log.Printf("[LONG-FORM] Prompt: %s", promptRef)
 	var content string
 	var wc int
 
 	for attempt := 1; attempt <= MaxRetry; attempt++ {
 		log.Printf("[LONG-FORM] Attempt %d/%d for topic: %s", attempt, MaxRetry, topic)
-		log.Printf("[AI MODEL LOCKED] %s", g.model)
+		log.Printf("[AI MODEL] %s", g.model)
 
 		// TASK 16: MaxOutputTokens for GPT-5.2
 		maxTokens := 4096 // Bisa dinaikkan ke 6000 jika perlu
 
-		// Call AI model
-		rawContent, usage, err := g.callAI(prompt, maxTokens)
+		// Call AI model and extract body content (JSON schema output when supported)
+		result, usage, err := g.generateOnce(ctx, prompt, maxTokens, ContentRequest{
+			ContentType: ContentDerivativeLong,
+			Category:    "K1",
+			Language:    "id",
+		})
 		if err != nil {
 			log.Printf("[LONG-FORM] Attempt %d failed: %v", attempt, err)
 			if attempt == MaxRetry {
@@ -280,13 +300,6 @@ func (g *Generator) GenerateLongFormArticle(ctx context.Context, topic string) (
 			continue
 		}
 
-		// Parse response to extract body content
-		result := g.parseResponse(rawContent, ContentRequest{
-			ContentType: ContentDerivativeLong,
-			Category:    "K1",
-			Language:    "id",
-		})
-
 		// Combine title and body for word count
 		fullText := result.Title + " " + result.Body
 		wc = countWordsInText(fullText)
@@ -323,53 +336,10 @@ func (g *Generator) GenerateLongFormArticle(ctx context.Context, topic string) (
 	)
 }
 
-// buildHardConstraintPrompt builds the production-grade hard-constraint prompt
+// buildHardConstraintPrompt renders the production-grade hard-constraint prompt (content.long_form template)
 // TASK 3: GANTI TOTAL - Hard constraint prompt
-func (g *Generator) buildHardConstraintPrompt(topic string) string {
-	var promptBuilder strings.Builder
-
-	promptBuilder.WriteString("You are a professional agricultural content writer.\n\n")
-	promptBuilder.WriteString("ABSOLUTE RULES:\n")
-	promptBuilder.WriteString("- Write in Indonesian\n")
-	promptBuilder.WriteString("- Minimum 900 words (ABSOLUTE MINIMUM)\n")
-	promptBuilder.WriteString("- Target 1200–1500 words\n")
-	promptBuilder.WriteString("- Use H2 and H3 headings\n")
-	promptBuilder.WriteString("- Each H2 section must have at least 3 paragraphs\n")
-	promptBuilder.WriteString("- Each paragraph must be 3–5 sentences\n")
-	promptBuilder.WriteString("- DO NOT STOP EARLY\n")
-	promptBuilder.WriteString("- DO NOT give short answers\n")
-	promptBuilder.WriteString("- If word count is below minimum, CONTINUE WRITING\n\n")
-	promptBuilder.WriteString("STRUCTURE:\n")
-	promptBuilder.WriteString("- Introduction (2–3 paragraphs)\n")
-	promptBuilder.WriteString("- 5–7 H2 sections with H3 subsections\n")
-	promptBuilder.WriteString("- Practical examples\n")
-	promptBuilder.WriteString("- Actionable tips\n")
-	promptBuilder.WriteString("- Conclusion (2 paragraphs)\n\n")
-	promptBuilder.WriteString("IMPORTANT:\n")
-	promptBuilder.WriteString("If you reach output limits, CONTINUE writing until minimum word count is reached.\n\n")
-	promptBuilder.WriteString(fmt.Sprintf("TOPIC: %s\n\n", topic))
-	promptBuilder.WriteString("OUTPUT FORMAT (JSON):\n")
-	promptBuilder.WriteString(`{
-  "title": "Judul artikel (H1)",
-  "body": "Isi artikel lengkap dengan MARKDOWN headings (## untuk H2, ### untuk H3). WAJIB menggunakan ## untuk setiap H2 section dan ### untuk setiap H3 subsection."
-}`)
-	promptBuilder.WriteString("\n\n")
-	promptBuilder.WriteString("PENTING: Field 'body' HARUS menggunakan format markdown:\n")
-	promptBuilder.WriteString("- ## untuk H2 (section utama)\n")
-	promptBuilder.WriteString("- ### untuk H3 (subsection)\n")
-	promptBuilder.WriteString("- JANGAN gunakan HTML headings (<h2>, <h3>)\n")
-	promptBuilder.WriteString("- JANGAN gunakan plain text tanpa headings\n\n")
-
-	// TASK 30: OUTPUT CONTRACT (MANDATORY) - Ini KRITIKAL
-	promptBuilder.WriteString("OUTPUT CONTRACT (MANDATORY):\n")
-	promptBuilder.WriteString("- Return FULL ARTICLE TEXT ONLY\n")
-	promptBuilder.WriteString("- Do not summarize\n")
-	promptBuilder.WriteString("- Do not shorten\n")
-	promptBuilder.WriteString("- Do not explain what you are doing\n")
-	promptBuilder.WriteString("- Do not ask questions\n")
-	promptBuilder.WriteString("- Write continuously until minimum word count is reached\n")
-
-	return promptBuilder.String()
+func (g *Generator) buildHardConstraintPrompt(topic string) (string, string, error) {
+	return prompts.Render(prompts.ContentLongForm, prompts.Vars{Topic: topic, Keyword: topic})
 }
 
 // countWordsInText counts words in text (Indonesian-aware, same as validator)
@@ -453,300 +423,35 @@ func validateRequest(req ContentRequest) error {
 	return nil
 }
 
-// buildPrompt constructs the AI prompt based on request parameters
-func (g *Generator) buildPrompt(req ContentRequest) string {
-	var promptBuilder strings.Builder
-
-	// B1. System Prompt (DIKUNCI) - Tambahkan constraint eksplisit
-	promptBuilder.WriteString("You are answering real human questions for search results.\n")
-	promptBuilder.WriteString("Answer directly, clearly, and naturally.\n")
-	promptBuilder.WriteString("Avoid filler phrases, marketing language, or academic tone.\n")
-	promptBuilder.WriteString("Each answer must stand alone.\n\n")
-	promptBuilder.WriteString("❌ Jangan sebut:\n")
-	promptBuilder.WriteString("- \"Artikel ini akan membahas…\"\n")
-	promptBuilder.WriteString("- \"Di era modern…\"\n")
-	promptBuilder.WriteString("- \"Penting untuk diketahui…\"\n\n")
-	
-	// System instruction
-	promptBuilder.WriteString("Anda adalah penulis konten ahli untuk platform pertanian. ")
-	promptBuilder.WriteString("Tulis konten yang informatif, natural, dan mengikuti outline yang diberikan.\n\n")
-
-	// Content type instructions
-	switch req.ContentType {
-	case ContentCornerstone:
-		promptBuilder.WriteString("Tipe: CORNERSTONE CONTENT\n")
-		promptBuilder.WriteString("Konten harus komprehensif, mendalam, dan authoritative.\n\n")
-	case ContentDerivative:
-		promptBuilder.WriteString("Tipe: DERIVATIVE CONTENT\n")
-		promptBuilder.WriteString("Konten harus fokus pada topik spesifik yang terkait dengan konten utama.\n")
-		promptBuilder.WriteString("Gaya: informatif, alur natural seperti manusia, non-promosi.\n\n")
-	case ContentDerivativeLong:
-		// AI Generator v2: Answer-Driven Writing (NO WORD COUNT) - TUNING v2
-		if req.AnswerDriven && len(req.Questions) > 0 {
-			promptBuilder.WriteString("AI GENERATOR v2: ANSWER-DRIVEN WRITING MODE (TUNING v2)\n\n")
-			promptBuilder.WriteString("PRINSIP INTI:\n")
-			promptBuilder.WriteString("- Artikel = kumpulan jawaban berkualitas, BUKAN karangan panjang\n")
-			promptBuilder.WriteString("- Setiap section = jawaban 1 pertanyaan\n")
-			promptBuilder.WriteString("- Lulus SEO jika: menjawab cepat, relevan, bisa berdiri sendiri\n")
-			promptBuilder.WriteString("- TIDAK ADA word count requirement\n\n")
-			promptBuilder.WriteString(fmt.Sprintf("SEARCH INTENT: %s\n\n", req.Intent))
-			promptBuilder.WriteString("CORE QUESTIONS (WAJIB DIJAWAB):\n")
-			for i, q := range req.Questions {
-				promptBuilder.WriteString(fmt.Sprintf("Q%d: %s\n", i+1, q))
-			}
-			promptBuilder.WriteString("\n")
-			promptBuilder.WriteString("B. ANSWER REFINEMENT LAYER (WAJIB):\n")
-			promptBuilder.WriteString("Struktur jawaban DIKUNCI:\n")
-			promptBuilder.WriteString("1. Kalimat 1: JAWABAN LANGSUNG (langsung ke inti, ≤ 30 kata)\n")
-			promptBuilder.WriteString("2. Kalimat 2-4: Penjelasan singkat (konteks, detail penting)\n")
-			promptBuilder.WriteString("3. Opsional: Contoh / tips praktis (jika relevan)\n\n")
-			promptBuilder.WriteString("❌ TIDAK BOLEH:\n")
-			promptBuilder.WriteString("- Opening basa-basi (\"Dalam era modern...\", \"Tidak dapat dipungkiri...\")\n")
-			promptBuilder.WriteString("- Paragraf \"AI sounding\" (terlalu formal, generic)\n")
-			promptBuilder.WriteString("- Filler phrases (\"sangat penting\", \"di era modern\", \"tidak dapat dipungkiri\")\n")
-			promptBuilder.WriteString("- Jawaban yang bisa dipakai di topik apa pun\n\n")
-			promptBuilder.WriteString("INSTRUKSI PER PERTANYAAN:\n")
-			promptBuilder.WriteString("B2. Section Prompt (PER PERTANYAAN):\n")
-			promptBuilder.WriteString("Answer the question directly in the first sentence.\n")
-			promptBuilder.WriteString("If the answer is simple, keep it short.\n")
-			promptBuilder.WriteString("Do not expand unless it adds clarity.\n\n")
-			promptBuilder.WriteString("1. Jawab SETIAP pertanyaan dengan:\n")
-			promptBuilder.WriteString("   - JAWABAN LANGSUNG di kalimat pertama (≤ 30 kata)\n")
-			promptBuilder.WriteString("   - Lalu penjelasan singkat (2-4 kalimat)\n")
-			promptBuilder.WriteString("   - Nada manusia, natural, bukan artikel AI\n")
-			promptBuilder.WriteString("   - Tidak mengulang jawaban lain\n")
-			promptBuilder.WriteString("2. Format: Gunakan H2 untuk setiap pertanyaan\n")
-			promptBuilder.WriteString("3. Panjang: Cukup untuk menjawab (tidak perlu panjang)\n")
-			promptBuilder.WriteString("4. QC: Setiap jawaban harus:\n")
-			promptBuilder.WriteString("   - Menjawab pertanyaan dengan jelas (kalimat pertama)\n")
-			promptBuilder.WriteString("   - Bisa jadi featured snippet\n")
-			promptBuilder.WriteString("   - Bisa dibaca terpisah\n")
-			promptBuilder.WriteString("   - Tidak terasa promosi atau AI writing\n")
-			promptBuilder.WriteString("   - Menyebut konteks nyata (bukan generic)\n\n")
-		} else {
-			// Legacy format (backward compatibility)
-			promptBuilder.WriteString("You are a professional agricultural content writer.\n\n")
-			promptBuilder.WriteString("ABSOLUTE RULES:\n")
-			promptBuilder.WriteString("- Write in Indonesian\n")
-			promptBuilder.WriteString("- Minimum 900 words (ABSOLUTE MINIMUM)\n")
-			promptBuilder.WriteString("- Target 1200–1500 words\n")
-			promptBuilder.WriteString("- Use H2 and H3 headings\n")
-			promptBuilder.WriteString("- Each H2 section must have at least 3 paragraphs\n")
-			promptBuilder.WriteString("- Each paragraph must be 3–5 sentences\n")
-			promptBuilder.WriteString("- DO NOT STOP EARLY\n")
-			promptBuilder.WriteString("- DO NOT give short answers\n")
-			promptBuilder.WriteString("- If word count is below minimum, CONTINUE WRITING\n\n")
-			promptBuilder.WriteString("STRUCTURE:\n")
-			promptBuilder.WriteString("- Introduction (2–3 paragraphs)\n")
-			promptBuilder.WriteString("- 5–7 H2 sections with H3 subsections\n")
-			promptBuilder.WriteString("- Practical examples\n")
-			promptBuilder.WriteString("- Actionable tips\n")
-			promptBuilder.WriteString("- Conclusion (2 paragraphs)\n\n")
-			promptBuilder.WriteString("IMPORTANT:\n")
-			promptBuilder.WriteString("If you reach output limits, CONTINUE writing until minimum word count is reached.\n\n")
-			promptBuilder.WriteString("Tipe: DERIVATIVE LONG CONTENT\n")
-			promptBuilder.WriteString("Konten dengan struktur CORE + EXTENSION LAYER untuk topical completeness.\n")
-			promptBuilder.WriteString("Gaya: informatif, alur natural, non-promosi, SERP-friendly.\n\n")
-		}
-	case ContentUseCase:
-		promptBuilder.WriteString("Tipe: USE_CASE CONTENT\n")
-		promptBuilder.WriteString("Konten harus memberikan contoh praktis dan aplikasi nyata.\n\n")
-	}
-
-	// Category context
-	promptBuilder.WriteString(fmt.Sprintf("Kategori: %s\n", req.Category))
-	promptBuilder.WriteString("Platform: Toko Tani Online (agricultural e-commerce)\n\n")
-
-	// Critical: Outline must be followed exactly
-	promptBuilder.WriteString("OUTLINE (WAJIB DIIKUTI EKSIS):\n")
-	promptBuilder.WriteString(req.Outline)
-	promptBuilder.WriteString("\n\n")
-	
-	// STEP 2: Special instruction for DERIVATIVE_LONG - ensure relationship heading
-	// TRIGGER STRUKTURAL: Extension Layer dipicu oleh contentType, BUKAN wordCount
-	if req.ContentType == ContentDerivativeLong {
-		promptBuilder.WriteString("PENTING UNTUK DERIVATIVE_LONG:\n")
-		promptBuilder.WriteString("Pastikan outline mencakup satu bagian yang menjelaskan hubungan atau keterkaitan antar jenis/topik utama, bukan berdiri sendiri.\n")
-		promptBuilder.WriteString("Jika outline memuat heading tentang 'Hubungan Antar Jenis' atau serupa, WAJIB diikuti dan ditulis dengan lengkap.\n\n")
-		// STEP 4: Instruksi eksplisit 1 baris - Extension Layer WAJIB untuk DERIVATIVE_LONG
-		promptBuilder.WriteString("⚠️ TRIGGER STRUKTURAL: Untuk konten DERIVATIVE_LONG, Extension Layer (Q&A kontekstual dan/atau panduan praktis) WAJIB ditambahkan sebagai bagian utama artikel setelah pembahasan inti selesai, bukan tambahan opsional. Ini dipicu oleh tipe konten, bukan jumlah kata.\n\n")
-	}
-
-	// Language specification
-	promptBuilder.WriteString(fmt.Sprintf("Bahasa: %s\n\n", req.Language))
-
-	// Content requirements
-	promptBuilder.WriteString("REQUIREMENTS:\n")
-	promptBuilder.WriteString("1. Ikuti outline dengan ketat - setiap heading HARUS sesuai dengan outline\n")
-	promptBuilder.WriteString("2. Tulis dalam bahasa Indonesia yang natural\n")
-	promptBuilder.WriteString("3. Gunakan 1 H1 untuk judul utama (di field 'title', bukan di body)\n")
-	promptBuilder.WriteString("4. Strukturkan body dengan MARKDOWN headings:\n")
-	promptBuilder.WriteString("   - Gunakan ## (dua hash) untuk setiap H2 section sesuai outline\n")
-	promptBuilder.WriteString("   - Gunakan ### (tiga hash) untuk setiap H3 subsection sesuai outline\n")
-	promptBuilder.WriteString("   - JANGAN gunakan HTML tags (<h2>, <h3>)\n")
-	promptBuilder.WriteString("   - JANGAN gunakan plain text tanpa headings\n")
-	promptBuilder.WriteString("5. Konten harus informatif dan tidak promosional\n")
-	promptBuilder.WriteString("6. Jangan gunakan CTA jualan\n")
-	promptBuilder.WriteString("7. Jangan menyebut nama merek\n\n")
-	
-	// Constraint bahasa eksplisit (CTO-approved - untuk menghindari validation errors)
-	promptBuilder.WriteString("CONSTRAINT BAHASA (WAJIB DIIKUTI):\n")
-	promptBuilder.WriteString("❌ LARANGAN KATA ABSOLUT (JANGAN GUNAKAN):\n")
-	promptBuilder.WriteString("   - 'pasti', 'terbukti', 'paling', 'terbaik', '100%'\n")
-	promptBuilder.WriteString("   - Klaim absolut lainnya yang tidak dapat dibuktikan\n\n")
-	promptBuilder.WriteString("❌ LARANGAN BAHASA PROMOSI (JANGAN GUNAKAN):\n")
-	promptBuilder.WriteString("   - 'sangat efektif', 'solusi terbaik', 'tidak diragukan'\n")
-	promptBuilder.WriteString("   - Bahasa yang terdengar seperti sales atau marketing\n\n")
-	promptBuilder.WriteString("❌ EMPHASIS BERLEBIHAN (SANGAT PENTING):\n")
-	promptBuilder.WriteString("   - JANGAN GUNAKAN TANDA SERU (!) SAMA SEKALI, atau maksimal 1 untuk seluruh artikel\n")
-	promptBuilder.WriteString("   - Validator akan menolak artikel dengan lebih dari 3 tanda seru\n")
-	promptBuilder.WriteString("   - Gunakan titik (.) atau koma (,) untuk mengakhiri kalimat\n")
-	promptBuilder.WriteString("   - Jika perlu emphasis, gunakan kata-kata, bukan tanda seru\n\n")
-	promptBuilder.WriteString("✅ GAYA YANG DIWAJIBKAN:\n")
-	promptBuilder.WriteString("   - Naratif: ceritakan seperti penjelasan manusia berpengalaman\n")
-	promptBuilder.WriteString("   - Informatif: fokus pada informasi faktual\n")
-	promptBuilder.WriteString("   - Observasional: berdasarkan pengamatan, bukan klaim\n")
-	promptBuilder.WriteString("   - Netral: tidak memihak, tidak promosional\n\n")
-	promptBuilder.WriteString("✅ FRAMING YANG AMAN (GUNAKAN POLA INI):\n")
-	promptBuilder.WriteString("   - 'Dalam praktiknya...'\n")
-	promptBuilder.WriteString("   - 'Pada beberapa kondisi...'\n")
-	promptBuilder.WriteString("   - 'Berdasarkan pengalaman lapangan...'\n")
-	promptBuilder.WriteString("   - 'Umumnya digunakan ketika...'\n")
-	promptBuilder.WriteString("   - 'Biasanya terjadi pada...'\n")
-	promptBuilder.WriteString("   - 'Dapat membantu dalam situasi...'\n")
-	promptBuilder.WriteString("   - Gunakan bahasa yang menunjukkan variasi dan kondisi, bukan absolut\n\n")
-
-	// Natural depth guidance - BASELINE v3.1 untuk DERIVATIVE_LONG dengan EXTENSION LAYER KONTRAKTUAL
-	// Extension Layer sebagai SECTION KONTRAKTUAL dengan heading nyata (bukan konsep)
-	if req.ContentType == ContentDerivativeLong {
-		promptBuilder.WriteString("STRUKTUR KONTEN (DERIVATIVE LONG - BASELINE v3.1 - SECTION KONTRAKTUAL):\n")
-		promptBuilder.WriteString("\n1. CORE CONTENT (Isi Utama):\n")
-		promptBuilder.WriteString("   - Definisi, penjelasan inti, konteks, implikasi\n")
-		promptBuilder.WriteString("   - Bahas setiap subtopik sampai tuntas secara logis sebelum pindah\n")
-		promptBuilder.WriteString("   - Ikuti outline dengan ketat\n")
-		promptBuilder.WriteString("   - SETELAH semua heading outline selesai, WAJIB tambahkan Extension Layer sebagai section berikutnya\n\n")
-		promptBuilder.WriteString("2. EXTENSION LAYER (SECTION KONTRAKTUAL - WAJIB DENGAN HEADING NYATA):\n")
-		promptBuilder.WriteString("   ⚠️ EXTENSION LAYER ADALAH KONTRAK STRUKTURAL, BUKAN KONSEP\n")
-		promptBuilder.WriteString("   ⚠️ SETELAH semua heading outline selesai, WAJIB tambahkan section dengan heading markdown berikut:\n\n")
-		promptBuilder.WriteString("   A. Section WAJIB: ## Pertanyaan yang Sering Diajukan\n")
-		promptBuilder.WriteString("      - GUNAKAN HEADING MARKDOWN: ## Pertanyaan yang Sering Diajukan\n")
-		promptBuilder.WriteString("      - HARUS ada minimal 4 pertanyaan dengan jawaban\n")
-		promptBuilder.WriteString("      - Format: Pertanyaan (bold) + Jawaban lengkap\n")
-		promptBuilder.WriteString("      - Pertanyaan yang benar-benar sering ditanyakan pembaca\n")
-		promptBuilder.WriteString("      - Jawaban ringkas tapi tuntas, bukan FAQ dangkal\n")
-		promptBuilder.WriteString("      - Contoh pertanyaan: 'Apa yang terjadi jika...', 'Kapan sebaiknya...', 'Apakah aman jika...', 'Apa kesalahan umum saat...'\n\n")
-		promptBuilder.WriteString("   B. Section OPSIONAL: ## Panduan Praktis / Penerapan (jika relevan)\n")
-		promptBuilder.WriteString("      - GUNAKAN HEADING MARKDOWN: ## Panduan Praktis\n")
-		promptBuilder.WriteString("      - 4-6 langkah praktis, fokus ke praktik lapangan\n")
-		promptBuilder.WriteString("      - Bukan how-to dangkal\n\n")
-		promptBuilder.WriteString("   C. Section OPSIONAL: ## Kesalahan Umum / Studi Kasus (jika relevan)\n")
-		promptBuilder.WriteString("      - GUNAKAN HEADING MARKDOWN: ## Kesalahan Umum\n")
-		promptBuilder.WriteString("      - 3-5 poin berdasarkan logika lapangan\n")
-		promptBuilder.WriteString("      - Tanpa klaim berlebihan\n\n")
-		promptBuilder.WriteString("\n3. PENUTUP ALAMI:\n")
-		promptBuilder.WriteString("   - Ringkasan reflektif, tanpa CTA jualan\n")
-		promptBuilder.WriteString("   - Tanpa kesimpulan dipaksakan\n\n")
-		promptBuilder.WriteString("KONTRAK STRUKTURAL EXTENSION LAYER (WAJIB UNTUK DERIVATIVE_LONG):\n")
-		promptBuilder.WriteString("⚠️ SETELAH semua heading outline selesai, WAJIB tambahkan section dengan heading:\n")
-		promptBuilder.WriteString("   ## Pertanyaan yang Sering Diajukan\n")
-		promptBuilder.WriteString("⚠️ Section ini HARUS muncul sebagai heading markdown (##) di body artikel\n")
-		promptBuilder.WriteString("⚠️ Bukan konsep atau saran, tapi section kontraktual yang WAJIB ada\n")
-		promptBuilder.WriteString("⚠️ Minimal 4 pertanyaan dengan jawaban lengkap\n")
-		promptBuilder.WriteString("⚠️ JANGAN berhenti sebelum section Extension Layer selesai\n")
-		promptBuilder.WriteString("⚠️ Extension Layer adalah bagian struktural artikel, bukan tambahan opsional\n")
-		promptBuilder.WriteString("⚠️ Jangan filler, jangan mengulang isi utama\n")
-		promptBuilder.WriteString("⚠️ Panjang datang dari nilai tambahan, bukan dari pengulangan\n\n")
-	} else if req.ContentType == ContentDerivative {
-		promptBuilder.WriteString("PANDUAN PENULISAN (DERIVATIVE - BASELINE v2):\n")
-		promptBuilder.WriteString("- Bahas setiap subtopik sampai tuntas secara logis sebelum pindah\n")
-		promptBuilder.WriteString("- Jangan memperpanjang demi jumlah kata - jika pembahasan selesai, akhiri secara wajar\n")
-		promptBuilder.WriteString("- Hindari ringkasan berulang dan filler yang tidak perlu\n")
-		promptBuilder.WriteString("- Gaya informatif, alur natural seperti manusia menulis, non-promosi\n")
-		promptBuilder.WriteString("- Fokus pada substansi, bukan panjang konten\n\n")
-	} else {
-		// Guidance untuk CORNERSTONE (lebih komprehensif)
-		promptBuilder.WriteString("KEDALAMAN KONTEN (NATURAL):\n")
-		promptBuilder.WriteString("- Untuk setiap bagian, bahas hingga tuntas sebelum berpindah ke bagian berikutnya\n")
-		promptBuilder.WriteString("- Sertakan latar belakang konseptual yang relevan untuk membantu pembaca memahami konteks\n")
-		promptBuilder.WriteString("- Jelaskan hubungan sebab-akibat ketika relevan\n")
-		promptBuilder.WriteString("- Elaborasi konteks yang membantu pemahaman pembaca\n")
-		promptBuilder.WriteString("- Hindari ringkasan terlalu cepat - berikan penjelasan yang memadai untuk setiap poin\n")
-		promptBuilder.WriteString("- Konten harus komprehensif dan substantif, tanpa filler atau pengulangan yang tidak perlu\n\n")
-	}
+// buildPrompt renders the prompt template for the content type (content.cornerstone, content.derivative, ...)
+// req.TemplateVersion selects a version (0 = active)
+// Returns the prompt and the template ref recorded on ContentResult.PromptVersion
+func (g *Generator) buildPrompt(req ContentRequest) (string, string, error) {
+	return prompts.Default().RenderVersion(PromptTemplateName(req.ContentType), req.TemplateVersion, prompts.Vars{
+		ContentType:  string(req.ContentType),
+		Category:     req.Category,
+		Outline:      req.Outline,
+		Language:     req.Language,
+		AnswerDriven: req.AnswerDriven,
+		Intent:       req.Intent,
+		Questions:    req.Questions,
+	})
+}
 
-	// Output format - CRITICAL: Body must contain markdown headings (## and ###)
-	promptBuilder.WriteString("OUTPUT FORMAT (JSON):\n")
-	promptBuilder.WriteString(`{
-  "title": "Judul artikel (H1)",
-  "body": "Isi artikel lengkap dengan MARKDOWN headings (## untuk H2, ### untuk H3) sesuai outline. WAJIB menggunakan ## untuk setiap H2 section dan ### untuk setiap H3 subsection.",
-  "metaTitle": "Meta title untuk SEO (max 60 karakter)",
-  "metaDesc": "Meta description untuk SEO (max 160 karakter)"
-}`)
-	promptBuilder.WriteString("\n\n")
-	promptBuilder.WriteString("PENTING: Field 'body' HARUS menggunakan format markdown:\n")
-	promptBuilder.WriteString("- ## untuk H2 (section utama)\n")
-	promptBuilder.WriteString("- ### untuk H3 (subsection)\n")
-	promptBuilder.WriteString("- JANGAN gunakan HTML headings (<h2>, <h3>)\n")
-	promptBuilder.WriteString("- JANGAN gunakan plain text tanpa headings\n\n")
-
-	// TASK 30: OUTPUT CONTRACT (MANDATORY) - Ini KRITIKAL
-	promptBuilder.WriteString("OUTPUT CONTRACT (MANDATORY):\n")
-	promptBuilder.WriteString("- Return FULL ARTICLE TEXT ONLY\n")
-	promptBuilder.WriteString("- Do not summarize\n")
-	promptBuilder.WriteString("- Do not shorten\n")
-	promptBuilder.WriteString("- Do not explain what you are doing\n")
-	promptBuilder.WriteString("- Do not ask questions\n")
-	promptBuilder.WriteString("- Write continuously until minimum word count is reached\n")
-
-	return promptBuilder.String()
+// PromptTemplateName maps a content type to its prompt template ("DERIVATIVE_LONG" → content.derivative_long)
+func PromptTemplateName(ct ContentType) string {
+	return "content." + strings.ToLower(string(ct))
 }
 
 // GetBasePrompt returns the base prompt template (for prompt refinement system)
 func (g *Generator) GetBasePrompt() string {
-	var promptBuilder strings.Builder
-	
-	// B1. System Prompt (DIKUNCI) - Tambahkan constraint eksplisit
-	promptBuilder.WriteString("You are answering real human questions for search results.\n")
-	promptBuilder.WriteString("Answer directly, clearly, and naturally.\n")
-	promptBuilder.WriteString("Avoid filler phrases, marketing language, or academic tone.\n")
-	promptBuilder.WriteString("Each answer must stand alone.\n\n")
-	promptBuilder.WriteString("❌ Jangan sebut:\n")
-	promptBuilder.WriteString("- \"Artikel ini akan membahas…\"\n")
-	promptBuilder.WriteString("- \"Di era modern…\"\n")
-	promptBuilder.WriteString("- \"Penting untuk diketahui…\"\n\n")
-	
-	// System instruction
-	promptBuilder.WriteString("Anda adalah penulis konten ahli untuk platform pertanian. ")
-	promptBuilder.WriteString("Tulis konten yang informatif, natural, dan mengikuti outline yang diberikan.\n\n")
-	
-	// Content requirements
-	promptBuilder.WriteString("REQUIREMENTS:\n")
-	promptBuilder.WriteString("1. Ikuti outline dengan ketat - setiap heading HARUS sesuai dengan outline\n")
-	promptBuilder.WriteString("2. Tulis dalam bahasa Indonesia yang natural\n")
-	promptBuilder.WriteString("3. Gunakan 1 H1 untuk judul utama (di field 'title', bukan di body)\n")
-	promptBuilder.WriteString("4. Strukturkan body dengan MARKDOWN headings:\n")
-	promptBuilder.WriteString("   - Gunakan ## (dua hash) untuk setiap H2 section sesuai outline\n")
-	promptBuilder.WriteString("   - Gunakan ### (tiga hash) untuk setiap H3 subsection sesuai outline\n")
-	promptBuilder.WriteString("   - JANGAN gunakan HTML tags (<h2>, <h3>)\n")
-	promptBuilder.WriteString("   - JANGAN gunakan plain text tanpa headings\n")
-	promptBuilder.WriteString("5. Konten harus informatif dan tidak promosional\n")
-	promptBuilder.WriteString("6. Hindari kata-kata promosi seperti 'pasti', 'terbukti', 'rahasia'\n")
-	promptBuilder.WriteString("7. Jangan gunakan CTA jualan\n")
-	promptBuilder.WriteString("8. Jangan menyebut nama merek\n\n")
-	
-	// Natural depth guidance (KONSEPTUAL, bukan paksaan)
-	promptBuilder.WriteString("KEDALAMAN KONTEN (NATURAL):\n")
-	promptBuilder.WriteString("- Untuk setiap bagian, bahas hingga tuntas sebelum berpindah ke bagian berikutnya\n")
-	promptBuilder.WriteString("- Sertakan latar belakang konseptual yang relevan untuk membantu pembaca memahami konteks\n")
-	promptBuilder.WriteString("- Jelaskan hubungan sebab-akibat ketika relevan\n")
-	promptBuilder.WriteString("- Elaborasi konteks yang membantu pemahaman pembaca\n")
-	promptBuilder.WriteString("- Hindari ringkasan terlalu cepat - berikan penjelasan yang memadai untuk setiap poin\n")
-	promptBuilder.WriteString("- Untuk setiap H2 section, berikan penjelasan lengkap dengan sub-poin yang relevan\n")
-	promptBuilder.WriteString("- Untuk setiap H3 subsection, berikan penjelasan detail dengan contoh konkret ketika relevan\n")
-	promptBuilder.WriteString("- Konten harus komprehensif dan substantif, mencakup aspek-aspek penting dari setiap topik\n")
-	promptBuilder.WriteString("- Konten harus informatif dan memadai tanpa filler atau pengulangan yang tidak perlu\n\n")
-	
-	return promptBuilder.String()
+	prompt, ref, err := prompts.Render(prompts.ContentBase, prompts.Vars{})
+	if err != nil {
+		log.Printf("[GENERATOR] WARNING: failed to render base prompt: %v", err)
+		return ""
+	}
+	log.Printf("[GENERATOR] Base prompt: %s", ref)
+	return prompt
 }
 
 // TokenUsage represents token usage from Responses API
@@ -757,123 +462,35 @@ type TokenUsage struct {
 // callAI makes the actual API call to Responses API
 // TASK 19-20: Uses Responses API format (WAJIB)
 // Returns content and token usage for logging (TASK 9)
-func (g *Generator) callAI(prompt string, maxTokens int) (string, *TokenUsage, error) {
-	// Responses API (OpenAI) request payload
-	// NOTE (2026): `response_format` is deprecated in Responses API.
-	// Use `text.format` instead to avoid "Unsupported parameter: 'response_format'".
+func (g *Generator) callAI(ctx context.Context, prompt string, maxTokens int) (string, *TokenUsage, error) {
 	// A2. Parameter Stabil (ANTI OVER-GENERATE)
-	payload := map[string]interface{}{
-		"model":             g.model,
-		"input":             prompt,
-		"max_output_tokens": maxTokens,
-		"temperature":      0.4,  // A2: Konservatif & stabil
-		"top_p":            0.85, // A2: Stabil (hindari over-creativity)
-		"presence_penalty": 0.1,  // A2: Minim repetisi
-		"frequency_penalty": 0.2,  // A2: Tidak "puitis AI"
-		"text": map[string]interface{}{
-			"format": map[string]string{
-				"type": "text",
-			},
-		},
+	req := llm.Request{
+		Prompt:           prompt,
+		MaxTokens:        maxTokens,
+		Temperature:      llm.Float(0.4),  // A2: Konservatif & stabil
+		TopP:             llm.Float(0.85), // A2: Stabil (hindari over-creativity)
+		PresencePenalty:  llm.Float(0.1),  // A2: Minim repetisi
+		FrequencyPenalty: llm.Float(0.2),  // A2: Tidak "puitis AI"
 	}
 
 	// BAGIAN 2.3: LOG PAYLOAD SEBELUM KIRIM (WAJIB)
-	log.Printf("[OPENAI PAYLOAD] model=%v max_output_tokens=%v",
-		payload["model"],
-		payload["max_output_tokens"],
-	)
+	log.Printf("[OPENAI PAYLOAD] model=%v max_output_tokens=%v", g.model, maxTokens)
 
-	jsonData, err := json.Marshal(payload)
-	if err != nil {
-		return "", nil, fmt.Errorf("failed to marshal request: %w", err)
-	}
-
-	req, err := http.NewRequest("POST", g.apiURL, bytes.NewBuffer(jsonData))
-	if err != nil {
-		return "", nil, fmt.Errorf("failed to create request: %w", err)
-	}
-
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", "Bearer "+g.apiKey)
-
-	resp, err := g.client.Do(req)
+	resp, err := g.llm.Complete(ctx, req)
 	if err != nil {
+		log.Printf("[AI] %s API error: %v", g.llm.Provider(), err)
+		// A1: Jika model unavailable → return error, bukan downgrade diam-diam
 		return "", nil, fmt.Errorf("API request failed: %w", err)
 	}
-	defer resp.Body.Close()
-
-	log.Printf("[AI] Responses API response status: %d", resp.StatusCode)
-
-	if resp.StatusCode != http.StatusOK {
-		body, readErr := io.ReadAll(resp.Body)
-		if readErr != nil {
-			log.Printf("[AI] Failed to read error response body: %v", readErr)
-			return "", nil, fmt.Errorf("API returned status %d (failed to read body)", resp.StatusCode)
-		}
-		log.Printf("[AI] Responses API error response: %s", string(body))
-		// A1: Jika GPT-5.2 unavailable → return error, bukan downgrade diam-diam
-		return "", nil, fmt.Errorf("API returned status %d: %s (GPT-5.2 required, no fallback)", resp.StatusCode, string(body))
-	}
-
-	// TASK 20: Parse Responses API response format (WAJIB)
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return "", nil, fmt.Errorf("failed to read response body: %w", err)
-	}
+	log.Printf("[AI] %s response from %s in %v (attempts: %d)", resp.Provider, resp.Model, resp.Latency, resp.Attempts)
 
 	// TASK 24: Log raw response untuk verifikasi (SEKALI SAJA)
-	log.Printf("[AI RAW RESPONSE] %s", string(body))
-
-	log.Println("[AI] Decoding Responses API response...")
-	var r struct {
-		Output []struct {
-			ID      string `json:"id"`
-			Type    string `json:"type"`
-			Content []struct {
-				Type string `json:"type"`
-				Text string `json:"text"`
-			} `json:"content"`
-		} `json:"output"`
-		Usage struct {
-			OutputTokens int `json:"output_tokens"`
-		} `json:"usage"`
-		Error struct {
-			Message string `json:"message"`
-			Type    string `json:"type"`
-		} `json:"error"`
-	}
-
-	if err := json.Unmarshal(body, &r); err != nil {
-		log.Printf("[AI] Failed to decode JSON response: %v", err)
-		return "", nil, fmt.Errorf("failed to decode response: %w", err)
-	}
-
-	// TASK 26: Extract FULL content from nested structure (WAJIB - SATU-SATUNYA CARA YANG SAH)
-	content := ""
-	for _, msg := range r.Output {
-		if msg.Type == "message" {
-			for _, c := range msg.Content {
-				if c.Type == "output_text" {
-					content += c.Text
-				}
-			}
-		}
-	}
+	log.Printf("[AI RAW RESPONSE] %s", string(resp.Raw))
 
-	if content == "" {
-		errorMsg := "no content in API response"
-		if r.Error.Message != "" {
-			errorMsg = fmt.Sprintf("API error: %s (type: %s)", r.Error.Message, r.Error.Type)
-		}
-		log.Printf("[AI] %s", errorMsg)
-		return "", nil, fmt.Errorf(errorMsg)
-	}
+	// TASK 26: FULL content (semua output_text) sudah di-extract oleh provider
+	content := resp.Text
 
 	// BAGIAN 1.3: LOG RAW CONTENT CHECK SETELAH PARSING GPT (TEPAT SETELAH EXTRACTION)
-	contentPreview := content
-	if len(contentPreview) > 500 {
-		contentPreview = contentPreview[:500]
-	}
 	log.Printf(
 		"[RAW CONTENT CHECK] chars=%d words=%d preview=%.500s",
 		len(content),
@@ -889,9 +506,9 @@ func (g *Generator) callAI(prompt string, maxTokens int) (string, *TokenUsage, e
 	// TASK 26: Log content length immediately after extraction
 	log.Printf("[ENGINE CONTENT LENGTH] chars=%d words=%d", len(content), countWordsInText(content))
 	
-	// TASK 20: Extract token usage from Responses API
+	// TASK 20: Token usage dari provider
 	usage := &TokenUsage{
-		OutputTokens: r.Usage.OutputTokens,
+		OutputTokens: resp.Usage.OutputTokens,
 	}
 	
 	if usage.OutputTokens > 0 {
@@ -901,16 +518,18 @@ func (g *Generator) callAI(prompt string, maxTokens int) (string, *TokenUsage, e
 	// TASK 21: Log verifikasi final (INI PENENTU)
 	wc := countWordsInText(content)
 	log.Printf(
-		"[AI FINAL] model=gpt-5.2 words=%d output_tokens=%d",
+		"[AI FINAL] model=%s words=%d output_tokens=%d",
+		resp.Model,
 		wc,
-		r.Usage.OutputTokens,
+		resp.Usage.OutputTokens,
 	)
 
 	// TASK 31: VERIFIKASI DENGAN LOG FINAL (WAJIB)
 	wcFinal := countWordsInText(content)
 	charCount := len(content)
 	log.Printf(
-		"[FINAL VERIFY] model=gpt-5.2 words=%d chars=%d",
+		"[FINAL VERIFY] model=%s words=%d chars=%d",
+		resp.Model,
 		wcFinal,
 		charCount,
 	)
@@ -924,9 +543,11 @@ func (g *Generator) callAI(prompt string, maxTokens int) (string, *TokenUsage, e
 }
 
 // parseResponse extracts structured content from AI response
+// Text mode: providers without JSON schema output (see structured.go)
 func (g *Generator) parseResponse(rawContent string, req ContentRequest) *ContentResult {
 	result := &ContentResult{
-		Status: "RAW_AI",
+		Status:     "RAW_AI",
+		OutputMode: OutputModeText,
 	}
 
 	// TASK 27: Log raw content length before parsing
@@ -0,0 +1,159 @@
+package content
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"engine-hub/internal/ai/llm"
+)
+
+// Output modes recorded on ContentResult.OutputMode
+const (
+	OutputModeJSONSchema = "json_schema" // schema-constrained JSON, validated on receipt
+	OutputModeText       = "text"        // free text, heuristic parseResponse
+)
+
+// FAQItem is one question/answer of a structured article
+type FAQItem struct {
+	Question string `json:"question"`
+	Answer   string `json:"answer"`
+}
+
+// ArticleSection is one H2/H3 section of a structured article
+type ArticleSection struct {
+	Heading string `json:"heading"`
+	Level   int    `json:"level"` // 2 | 3
+	Body    string `json:"body"`  // markdown without the heading line
+}
+
+// StructuredArticle is the JSON the model returns in schema mode
+type StructuredArticle struct {
+	Title     string           `json:"title"`
+	MetaTitle string           `json:"metaTitle"`
+	MetaDesc  string           `json:"metaDesc"`
+	Sections  []ArticleSection `json:"sections"`
+	FAQ       []FAQItem        `json:"faq"`
+	CTA       string           `json:"cta"`
+}
+
+// articleFormat is the JSON schema sent to providers with structured output
+var articleFormat = &llm.ResponseFormat{
+	Name: "content_article",
+	Schema: llm.Object("Article written exactly as instructed by the prompt", map[string]*llm.Schema{
+		"title":     llm.String("Article title (H1), max 100 characters"),
+		"metaTitle": llm.String("SEO title, max 60 characters"),
+		"metaDesc":  llm.String("SEO meta description, 120-160 characters"),
+		"sections": llm.Array("Article sections in reading order, following the outline", llm.Object("", map[string]*llm.Schema{
+			"heading": llm.String("Section heading without # characters"),
+			"level":   llm.Integer("2 = H2 main section, 3 = H3 subsection of the previous H2", 2, 3),
+			"body":    llm.String("Section text in markdown, without the heading line"),
+		}), 2, 20),
+		"faq": llm.Array("Frequently asked questions (empty when the prompt does not ask for a FAQ)", llm.Object("", map[string]*llm.Schema{
+			"question": llm.String("Question"),
+			"answer":   llm.String("Answer, 1-3 sentences"),
+		}), 0, 10),
+		"cta": llm.String("One closing sentence inviting the reader to keep learning; no sales language, no contact or purchase call"),
+	}),
+}
+
+// structuredInstructions tells the model how to map the prompt's article onto the schema
+const structuredInstructions = `Return the article as a JSON object matching the provided schema.
+Write the full article the prompt asks for (same length, language, tone and outline), then split it into sections:
+each section has the heading text, its level (2 for H2, 3 for H3) and its markdown body without the heading line.
+Put FAQ entries only in "faq" (not in sections).`
+
+// callStructured asks for the article as schema-constrained JSON
+// The response is validated against articleFormat with repair calls (llm.CompleteStructured)
+func (g *Generator) callStructured(ctx context.Context, prompt string, maxTokens int) (*StructuredArticle, *llm.Response, error) {
+	req := llm.Request{
+		System:           structuredInstructions,
+		Prompt:           prompt,
+		MaxTokens:        maxTokens,
+		Temperature:      llm.Float(0.4),  // A2: Konservatif & stabil
+		TopP:             llm.Float(0.85), // A2: Stabil (hindari over-creativity)
+		PresencePenalty:  llm.Float(0.1),  // A2: Minim repetisi
+		FrequencyPenalty: llm.Float(0.2),  // A2: Tidak "puitis AI"
+	}
+	log.Printf("[OPENAI PAYLOAD] model=%v max_output_tokens=%v format=%s", g.model, maxTokens, articleFormat.Name)
+
+	var article StructuredArticle
+	resp, err := g.llm.CompleteStructured(ctx, req, articleFormat, &article)
+	if err != nil {
+		return nil, resp, err
+	}
+	log.Printf("[AI] %s structured response from %s in %v (attempts: %d, sections: %d, faq: %d)",
+		resp.Provider, resp.Model, resp.Latency, resp.Attempts, len(article.Sections), len(article.FAQ))
+	return &article, resp, nil
+}
+
+// generateOnce makes one generation call and returns the parsed content
+// Schema mode when the provider supports it; providers without JSON mode use the text parser
+func (g *Generator) generateOnce(ctx context.Context, prompt string, maxTokens int, req ContentRequest) (*ContentResult, *TokenUsage, error) {
+	if g.llm == nil {
+		return nil, nil, fmt.Errorf("AI client not configured: %w", g.llmErr)
+	}
+	if g.llm.SupportsStructured() {
+		article, resp, err := g.callStructured(ctx, prompt, maxTokens)
+		var invalid *llm.StructuredError
+		switch {
+		case err == nil:
+			return article.toResult(), &TokenUsage{OutputTokens: resp.Usage.OutputTokens}, nil
+		case errors.Is(err, llm.ErrStructuredUnsupported) && resp != nil:
+			// a fallback provider without JSON mode answered: parse its text
+			result := g.parseResponse(resp.Text, req)
+			return result, &TokenUsage{OutputTokens: resp.Usage.OutputTokens}, nil
+		case errors.As(err, &invalid):
+			// Output still violates the schema after repairs: content failure, not INFRA
+			// (no text fallback: StructuredError.Text is truncated, too short for an article)
+			return nil, nil, fmt.Errorf("structured output invalid: %w", invalid)
+		case !errors.Is(err, llm.ErrStructuredUnsupported):
+			return nil, nil, fmt.Errorf("API request failed: %w", err)
+		}
+	}
+
+	rawContent, usage, err := g.callAI(ctx, prompt, maxTokens)
+	if err != nil {
+		return nil, nil, err
+	}
+	return g.parseResponse(rawContent, req), usage, nil
+}
+
+// toResult renders the sections (and FAQ) as the markdown body used by the rest of the pipeline
+func (a *StructuredArticle) toResult() *ContentResult {
+	var b strings.Builder
+	for i, s := range a.Sections {
+		marker := "## "
+		if s.Level == 3 && i > 0 {
+			marker = "### "
+		}
+		b.WriteString(marker + strings.TrimSpace(strings.TrimLeft(s.Heading, "# ")) + "\n\n")
+		b.WriteString(strings.TrimSpace(s.Body) + "\n\n")
+	}
+	if len(a.FAQ) > 0 {
+		b.WriteString("## Pertanyaan yang Sering Diajukan\n\n")
+		for _, f := range a.FAQ {
+			b.WriteString("### " + strings.TrimSpace(f.Question) + "\n\n")
+			b.WriteString(strings.TrimSpace(f.Answer) + "\n\n")
+		}
+	}
+
+	result := &ContentResult{
+		Title:      strings.TrimSpace(a.Title),
+		Body:       strings.TrimSpace(b.String()),
+		MetaTitle:  strings.TrimSpace(a.MetaTitle),
+		MetaDesc:   strings.TrimSpace(a.MetaDesc),
+		Status:     "RAW_AI",
+		FAQ:        a.FAQ,
+		CTA:        strings.TrimSpace(a.CTA),
+		OutputMode: OutputModeJSONSchema,
+	}
+	if result.MetaTitle == "" {
+		result.MetaTitle = truncateString(result.Title, 60)
+	}
+	log.Printf("[PARSE RESPONSE] Structured article: %d sections, %d FAQ, body %d chars, words=%d",
+		len(a.Sections), len(a.FAQ), len(result.Body), countWordsInText(result.Body))
+	return result
+}
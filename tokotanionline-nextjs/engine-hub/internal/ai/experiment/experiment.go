@@ -0,0 +1,376 @@
+package experiment
+
+import (
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"log"
+	"math/rand"
+	"sync"
+	"time"
+
+	"engine-hub/internal/ai/content"
+	"engine-hub/internal/ai/prompts"
+	"engine-hub/internal/ai/quality"
+
+	"github.com/google/uuid"
+)
+
+// Experiment statuses
+const (
+	StatusRunning = "RUNNING"
+	StatusStopped = "STOPPED"
+)
+
+// Channels an exposure can come from
+const (
+	ChannelGenerate = "generate" // /api/engine/ai/generate (GENERATE jobs from the app)
+	ChannelBatch    = "batch"    // /api/engine/ai/batch-production
+)
+
+var (
+	ErrNotFound = errors.New("experiment not found")
+	ErrConflict = errors.New("another experiment is already running for this template")
+	ErrStopped  = errors.New("experiment is not running")
+)
+
+// defaultMinSamples is the per-arm sample count before a winner can be declared
+const defaultMinSamples = 30
+
+// templates lists the prompt templates the content pipeline renders (the only ones that can be split)
+var templates = map[string]bool{
+	prompts.ContentCornerstone:    true,
+	prompts.ContentDerivative:     true,
+	prompts.ContentDerivativeLong: true,
+	prompts.ContentUseCase:        true,
+}
+
+// Arm is one prompt version under test
+type Arm struct {
+	Name    string `json:"name"`    // A, B, ... (defaulted from position)
+	Version int    `json:"version"` // template version rendered for this arm
+	Weight  int    `json:"weight"`  // relative traffic share (default 1)
+}
+
+// Experiment splits traffic of one template across two or more versions
+type Experiment struct {
+	ID           string     `json:"id"`
+	Name         string     `json:"name"`
+	TemplateName string     `json:"templateName"`
+	Arms         []Arm      `json:"arms"`
+	Metric       string     `json:"metric"`     // primary metric for the winner (default passRate)
+	MinSamples   int        `json:"minSamples"` // per arm before a winner can be declared
+	Status       string     `json:"status"`
+	Winner       string     `json:"winner,omitempty"` // arm name, set on stop when a winner was declared
+	CreatedBy    string     `json:"createdBy,omitempty"`
+	CreatedAt    time.Time  `json:"createdAt"`
+	StoppedAt    *time.Time `json:"stoppedAt,omitempty"`
+}
+
+// arm returns the arm with the given name
+func (e Experiment) arm(name string) (Arm, bool) {
+	for _, a := range e.Arms {
+		if a.Name == name {
+			return a, true
+		}
+	}
+	return Arm{}, false
+}
+
+// pick maps a unit key to an arm by weight (same key → same arm for the experiment's lifetime)
+// Empty key = random assignment
+func (e Experiment) pick(unitKey string) Arm {
+	total := 0
+	for _, a := range e.Arms {
+		total += a.Weight
+	}
+	var n int
+	if unitKey == "" {
+		n = rand.Intn(total)
+	} else {
+		h := fnv.New32a()
+		h.Write([]byte(e.ID + ":" + unitKey))
+		n = int(h.Sum32() % uint32(total))
+	}
+	for _, a := range e.Arms {
+		if n < a.Weight {
+			return a
+		}
+		n -= a.Weight
+	}
+	return e.Arms[len(e.Arms)-1]
+}
+
+var (
+	mu      sync.RWMutex
+	store   Store                 = NewMemoryStore()
+	running map[string]Experiment // templateName → running experiment (nil = not loaded yet)
+)
+
+// SetStore replaces the experiment store (call once at boot)
+func SetStore(s Store) {
+	mu.Lock()
+	defer mu.Unlock()
+	store = s
+	running = nil
+}
+
+func getStore() Store {
+	mu.RLock()
+	defer mu.RUnlock()
+	return store
+}
+
+// runningFor returns the running experiment for a template (loads the cache on first use)
+func runningFor(templateName string) (Experiment, bool) {
+	mu.RLock()
+	if running != nil {
+		e, ok := running[templateName]
+		mu.RUnlock()
+		return e, ok
+	}
+	mu.RUnlock()
+
+	mu.Lock()
+	defer mu.Unlock()
+	if running == nil {
+		if err := reloadLocked(); err != nil {
+			log.Printf("[EXPERIMENT] Failed to load experiments: %v", err)
+			return Experiment{}, false
+		}
+	}
+	e, ok := running[templateName]
+	return e, ok
+}
+
+// reloadLocked rebuilds the running cache from the store (mu held)
+func reloadLocked() error {
+	list, err := store.ListExperiments()
+	if err != nil {
+		return err
+	}
+	running = make(map[string]Experiment)
+	for _, e := range list {
+		if e.Status == StatusRunning {
+			running[e.TemplateName] = e
+		}
+	}
+	return nil
+}
+
+// Create validates and starts an experiment
+func Create(e Experiment) (Experiment, error) {
+	if !templates[e.TemplateName] {
+		return e, fmt.Errorf("template %q cannot be split (content templates only)", e.TemplateName)
+	}
+	if len(e.Arms) < 2 {
+		return e, fmt.Errorf("at least 2 arms are required")
+	}
+	seenName := make(map[string]bool)
+	seenVersion := make(map[int]bool)
+	for i := range e.Arms {
+		a := &e.Arms[i]
+		if a.Name == "" {
+			a.Name = string(rune('A' + i))
+		}
+		if a.Weight == 0 {
+			a.Weight = 1
+		}
+		if a.Version <= 0 {
+			return e, fmt.Errorf("arm %s: version is required", a.Name)
+		}
+		if a.Weight < 0 {
+			return e, fmt.Errorf("arm %s: weight must be positive", a.Name)
+		}
+		if seenName[a.Name] {
+			return e, fmt.Errorf("duplicate arm name %q", a.Name)
+		}
+		if seenVersion[a.Version] {
+			return e, fmt.Errorf("version %d is used by more than one arm", a.Version)
+		}
+		seenName[a.Name], seenVersion[a.Version] = true, true
+		if _, err := prompts.Default().Get(e.TemplateName, a.Version); err != nil {
+			return e, fmt.Errorf("arm %s: %w", a.Name, err)
+		}
+	}
+	if e.Metric == "" {
+		e.Metric = MetricPassRate
+	}
+	if !validMetrics[e.Metric] {
+		return e, fmt.Errorf("invalid metric %q", e.Metric)
+	}
+	if e.MinSamples <= 0 {
+		e.MinSamples = defaultMinSamples
+	}
+	if e.Name == "" {
+		e.Name = e.TemplateName + " experiment"
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if running == nil {
+		if err := reloadLocked(); err != nil {
+			return e, err
+		}
+	}
+	if other, ok := running[e.TemplateName]; ok {
+		return e, fmt.Errorf("%w: %s (%s)", ErrConflict, other.Name, other.ID)
+	}
+
+	e.ID = uuid.New().String()
+	e.Status = StatusRunning
+	e.Winner = ""
+	e.CreatedAt = time.Now()
+	e.StoppedAt = nil
+	if err := store.InsertExperiment(e); err != nil {
+		return e, err
+	}
+	running[e.TemplateName] = e
+	log.Printf("[EXPERIMENT] Started %s on %s with %d arms (%s)", e.ID, e.TemplateName, len(e.Arms), e.Metric)
+	return e, nil
+}
+
+// Get returns one experiment
+func Get(id string) (Experiment, error) {
+	list, err := getStore().ListExperiments()
+	if err != nil {
+		return Experiment{}, err
+	}
+	for _, e := range list {
+		if e.ID == id {
+			return e, nil
+		}
+	}
+	return Experiment{}, ErrNotFound
+}
+
+// List returns all experiments, newest first
+func List() ([]Experiment, error) {
+	return getStore().ListExperiments()
+}
+
+// Stop ends an experiment; with promote, a declared winner's version becomes the active template
+func Stop(id string, promote bool) (Experiment, *Report, error) {
+	e, err := Get(id)
+	if err != nil {
+		return e, nil, err
+	}
+	if e.Status != StatusRunning {
+		return e, nil, ErrStopped
+	}
+	report, err := BuildReport(e)
+	if err != nil {
+		return e, nil, err
+	}
+
+	now := time.Now()
+	e.Status = StatusStopped
+	e.StoppedAt = &now
+	e.Winner = report.Winner
+
+	mu.Lock()
+	if err := store.UpdateExperiment(e); err != nil {
+		mu.Unlock()
+		return e, nil, err
+	}
+	if running != nil {
+		delete(running, e.TemplateName)
+	}
+	mu.Unlock()
+	log.Printf("[EXPERIMENT] Stopped %s (winner: %q)", e.ID, e.Winner)
+
+	if promote && e.Winner != "" {
+		arm, _ := e.arm(e.Winner)
+		if err := prompts.Default().Activate(e.TemplateName, arm.Version); err != nil {
+			return e, report, fmt.Errorf("experiment stopped but failed to activate winner: %w", err)
+		}
+		log.Printf("[EXPERIMENT] Promoted %s (arm %s)", prompts.Ref(e.TemplateName, arm.Version), arm.Name)
+	}
+	return e, report, nil
+}
+
+// Assignment is the arm a generation request was put in
+type Assignment struct {
+	ExperimentID string `json:"experimentId"`
+	Arm          string `json:"arm"`
+	Version      int    `json:"version"`
+	UnitKey      string `json:"-"`
+}
+
+// Assign puts req into an arm of the running experiment for its template and pins req.TemplateVersion
+// Returns nil when no experiment runs or the request already pins a version
+// unitKey (keyword, outline) keeps retries of the same article in the same arm
+func Assign(req *content.ContentRequest, unitKey string) *Assignment {
+	if req.TemplateVersion != 0 {
+		return nil
+	}
+	e, ok := runningFor(content.PromptTemplateName(req.ContentType))
+	if !ok {
+		return nil
+	}
+	arm := e.pick(unitKey)
+	req.TemplateVersion = arm.Version
+	return &Assignment{ExperimentID: e.ID, Arm: arm.Name, Version: arm.Version, UnitKey: unitKey}
+}
+
+// Record stores the outcome of a successful generation for the assigned arm and returns the exposure ID
+// The generation is also saved as a quality sample so refinement sees experiment traffic
+func Record(a *Assignment, channel string, req content.ContentRequest, result *content.ContentResult, contentKey string) string {
+	if a == nil {
+		return ""
+	}
+	sample := quality.NewGenerationSample(req, result, "")
+	if err := quality.Samples().Save(sample); err != nil {
+		log.Printf("[EXPERIMENT] Failed to store sample: %v", err)
+	}
+	metrics := sample.Metrics
+	return insertExposure(Exposure{
+		ExperimentID: a.ExperimentID,
+		Arm:          a.Arm,
+		PromptRef:    sample.PromptVersion,
+		Channel:      channel,
+		UnitKey:      a.UnitKey,
+		ContentKey:   contentKey,
+		Metrics:      &metrics,
+		Pass:         sample.PassQualityProfile,
+	})
+}
+
+// RecordFailure stores a failed generation (counts as not passing for the arm)
+func RecordFailure(a *Assignment, channel string, genErr error) string {
+	if a == nil {
+		return ""
+	}
+	x := Exposure{
+		ExperimentID: a.ExperimentID,
+		Arm:          a.Arm,
+		Channel:      channel,
+		UnitKey:      a.UnitKey,
+		Failed:       true,
+	}
+	if genErr != nil {
+		x.Error = genErr.Error()
+	}
+	return insertExposure(x)
+}
+
+func insertExposure(x Exposure) string {
+	x.ID = uuid.New().String()
+	x.CreatedAt = time.Now()
+	x.UpdatedAt = x.CreatedAt
+	if err := getStore().InsertExposure(x); err != nil {
+		log.Printf("[EXPERIMENT] Failed to record exposure for %s/%s: %v", x.ExperimentID, x.Arm, err)
+		return ""
+	}
+	return x.ID
+}
+
+// RecordOutcome attaches downstream signals to an exposure (by ID, or the latest exposure with the content key)
+// Values are the latest totals from the app, not increments
+func RecordOutcome(o Outcome) error {
+	if o.ExposureID == "" && o.ContentKey == "" {
+		return fmt.Errorf("exposureId or contentKey is required")
+	}
+	o.UpdatedAt = time.Now()
+	return getStore().UpdateOutcome(o)
+}
@@ -0,0 +1,166 @@
+package experiment
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+)
+
+// PostgresStore persists experiments in "EngineHubPromptExperiment" and exposures in "EngineHubPromptExposure"
+type PostgresStore struct {
+	db *sql.DB
+}
+
+// NewPostgresStore creates an experiment store backed by the given database
+func NewPostgresStore(db *sql.DB) *PostgresStore {
+	return &PostgresStore{db: db}
+}
+
+func (p *PostgresStore) InsertExperiment(e Experiment) error {
+	arms, err := json.Marshal(e.Arms)
+	if err != nil {
+		return fmt.Errorf("failed to marshal experiment arms: %w", err)
+	}
+	_, err = p.db.Exec(`
+		INSERT INTO "EngineHubPromptExperiment" (id, name, "templateName", arms, metric, "minSamples", status, "createdBy", "createdAt")
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	`, e.ID, e.Name, e.TemplateName, string(arms), e.Metric, e.MinSamples, e.Status, nullString(e.CreatedBy), e.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to insert experiment: %w", err)
+	}
+	return nil
+}
+
+func (p *PostgresStore) UpdateExperiment(e Experiment) error {
+	res, err := p.db.Exec(`
+		UPDATE "EngineHubPromptExperiment" SET status = $2, winner = $3, "stoppedAt" = $4 WHERE id = $1
+	`, e.ID, e.Status, nullString(e.Winner), e.StoppedAt)
+	if err != nil {
+		return fmt.Errorf("failed to update experiment: %w", err)
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (p *PostgresStore) ListExperiments() ([]Experiment, error) {
+	rows, err := p.db.Query(`
+		SELECT id, name, "templateName", arms, metric, "minSamples", status, COALESCE(winner, ''),
+			COALESCE("createdBy", ''), "createdAt", "stoppedAt"
+		FROM "EngineHubPromptExperiment"
+		ORDER BY "createdAt" DESC
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query experiments: %w", err)
+	}
+	defer rows.Close()
+
+	var out []Experiment
+	for rows.Next() {
+		var e Experiment
+		var arms []byte
+		var stoppedAt sql.NullTime
+		if err := rows.Scan(&e.ID, &e.Name, &e.TemplateName, &arms, &e.Metric, &e.MinSamples, &e.Status, &e.Winner,
+			&e.CreatedBy, &e.CreatedAt, &stoppedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan experiment: %w", err)
+		}
+		if err := json.Unmarshal(arms, &e.Arms); err != nil {
+			return nil, fmt.Errorf("failed to decode arms of experiment %s: %w", e.ID, err)
+		}
+		if stoppedAt.Valid {
+			e.StoppedAt = &stoppedAt.Time
+		}
+		out = append(out, e)
+	}
+	return out, rows.Err()
+}
+
+func (p *PostgresStore) InsertExposure(x Exposure) error {
+	var metrics interface{}
+	if x.Metrics != nil {
+		data, err := json.Marshal(x.Metrics)
+		if err != nil {
+			return fmt.Errorf("failed to marshal exposure metrics: %w", err)
+		}
+		metrics = string(data)
+	}
+	_, err := p.db.Exec(`
+		INSERT INTO "EngineHubPromptExposure" (id, "experimentId", arm, "promptRef", channel, "unitKey", "contentKey",
+			failed, error, metrics, pass, "createdAt", "updatedAt")
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+	`, x.ID, x.ExperimentID, x.Arm, nullString(x.PromptRef), x.Channel, nullString(x.UnitKey), nullString(x.ContentKey),
+		x.Failed, nullString(x.Error), metrics, x.Pass, x.CreatedAt, x.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to insert exposure: %w", err)
+	}
+	return nil
+}
+
+func (p *PostgresStore) UpdateOutcome(o Outcome) error {
+	// COALESCE keeps fields the outcome does not carry
+	set := `
+		"seoScore" = COALESCE($2, "seoScore"), impressions = COALESCE($3, impressions),
+		clicks = COALESCE($4, clicks), "dwellSeconds" = COALESCE($5, "dwellSeconds"), "updatedAt" = $6`
+	var query string
+	key := o.ExposureID
+	if key != "" {
+		query = `UPDATE "EngineHubPromptExposure" SET ` + set + ` WHERE id = $1`
+	} else {
+		key = o.ContentKey
+		query = `UPDATE "EngineHubPromptExposure" SET ` + set + ` WHERE id = (
+			SELECT id FROM "EngineHubPromptExposure" WHERE "contentKey" = $1 ORDER BY "createdAt" DESC LIMIT 1)`
+	}
+	res, err := p.db.Exec(query, key, o.SEOScore, o.Impressions, o.Clicks, o.DwellSeconds, o.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to update exposure outcome: %w", err)
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return ErrExposureNotFound
+	}
+	return nil
+}
+
+func (p *PostgresStore) ListExposures(experimentID string) ([]Exposure, error) {
+	rows, err := p.db.Query(`
+		SELECT id, "experimentId", arm, COALESCE("promptRef", ''), channel, COALESCE("unitKey", ''),
+			COALESCE("contentKey", ''), failed, COALESCE(error, ''), metrics, pass, "seoScore",
+			impressions, clicks, "dwellSeconds", "createdAt", "updatedAt"
+		FROM "EngineHubPromptExposure"
+		WHERE "experimentId" = $1
+		ORDER BY "createdAt"
+	`, experimentID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query exposures: %w", err)
+	}
+	defer rows.Close()
+
+	var out []Exposure
+	for rows.Next() {
+		var x Exposure
+		var metrics []byte
+		var seoScore, dwell sql.NullFloat64
+		if err := rows.Scan(&x.ID, &x.ExperimentID, &x.Arm, &x.PromptRef, &x.Channel, &x.UnitKey, &x.ContentKey,
+			&x.Failed, &x.Error, &metrics, &x.Pass, &seoScore, &x.Impressions, &x.Clicks, &dwell,
+			&x.CreatedAt, &x.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan exposure: %w", err)
+		}
+		if len(metrics) > 0 {
+			if err := json.Unmarshal(metrics, &x.Metrics); err != nil {
+				return nil, fmt.Errorf("failed to decode metrics of exposure %s: %w", x.ID, err)
+			}
+		}
+		if seoScore.Valid {
+			x.SEOScore = &seoScore.Float64
+		}
+		if dwell.Valid {
+			x.DwellSeconds = &dwell.Float64
+		}
+		out = append(out, x)
+	}
+	return out, rows.Err()
+}
+
+func nullString(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
@@ -0,0 +1,275 @@
+package experiment
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// Metrics a winner can be declared on
+const (
+	MetricPassRate            = "passRate"            // share passing the QualityProfile (failed generations count as not passing)
+	MetricDepthScore          = "depthScore"          // mean, higher is better
+	MetricRepetitionRate      = "repetitionRate"      // mean, lower is better
+	MetricStructureCompliance = "structureCompliance" // mean, higher is better
+	MetricReadability         = "readability"         // share with HumanReadability PASS
+	MetricSEOScore            = "seoScore"            // mean of downstream SEO score
+	MetricCTR                 = "ctr"                 // clicks / impressions
+)
+
+var validMetrics = map[string]bool{
+	MetricPassRate: true, MetricDepthScore: true, MetricRepetitionRate: true, MetricStructureCompliance: true,
+	MetricReadability: true, MetricSEOScore: true, MetricCTR: true,
+}
+
+// z95 is the two-sided 95% normal quantile; significance is p < 0.05
+const (
+	z95          = 1.959964
+	significance = 0.05
+)
+
+// Estimate is a metric value with its 95% confidence interval
+// Proportions use the Wilson interval, means the normal approximation
+type Estimate struct {
+	N     int     `json:"n"`
+	Value float64 `json:"value"`
+	Low   float64 `json:"low"`
+	High  float64 `json:"high"`
+
+	proportion bool
+	variance   float64 // sample variance (means) for the comparison test
+}
+
+// ArmReport is the per-arm result
+type ArmReport struct {
+	Arm                 string   `json:"arm"`
+	Version             int      `json:"version"`
+	Exposures           int      `json:"exposures"`
+	Failed              int      `json:"failed"`
+	PassRate            Estimate `json:"passRate"`
+	Readability         Estimate `json:"readability"`
+	DepthScore          Estimate `json:"depthScore"`
+	RepetitionRate      Estimate `json:"repetitionRate"`
+	StructureCompliance Estimate `json:"structureCompliance"`
+	WordCount           Estimate `json:"wordCount"`
+	SEOScore            Estimate `json:"seoScore"`
+	CTR                 Estimate `json:"ctr"`
+	DwellSeconds        Estimate `json:"dwellSeconds"`
+	PValue              *float64 `json:"pValue,omitempty"` // primary metric vs the leading arm
+}
+
+// metric returns the estimate of a primary metric
+func (a ArmReport) metric(name string) Estimate {
+	switch name {
+	case MetricDepthScore:
+		return a.DepthScore
+	case MetricRepetitionRate:
+		return a.RepetitionRate
+	case MetricStructureCompliance:
+		return a.StructureCompliance
+	case MetricReadability:
+		return a.Readability
+	case MetricSEOScore:
+		return a.SEOScore
+	case MetricCTR:
+		return a.CTR
+	default:
+		return a.PassRate
+	}
+}
+
+// Report compares the arms of an experiment
+type Report struct {
+	Experiment Experiment  `json:"experiment"`
+	Arms       []ArmReport `json:"arms"`
+	Leader     string      `json:"leader,omitempty"` // best arm on the primary metric so far
+	Winner     string      `json:"winner,omitempty"` // set only when the leader is significantly better than every other arm
+	Decision   string      `json:"decision"`
+}
+
+// BuildReport aggregates the exposures of an experiment
+func BuildReport(e Experiment) (*Report, error) {
+	exposures, err := getStore().ListExposures(e.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	byArm := make(map[string][]Exposure)
+	for _, x := range exposures {
+		byArm[x.Arm] = append(byArm[x.Arm], x)
+	}
+
+	report := &Report{Experiment: e}
+	for _, arm := range e.Arms {
+		report.Arms = append(report.Arms, summarizeArm(arm, byArm[arm.Name]))
+	}
+	decide(report)
+	return report, nil
+}
+
+func summarizeArm(arm Arm, exposures []Exposure) ArmReport {
+	r := ArmReport{Arm: arm.Name, Version: arm.Version, Exposures: len(exposures)}
+
+	var passed, readable int
+	var depth, repetition, structure, words, seo, dwell []float64
+	var impressions, clicks int64
+	for _, x := range exposures {
+		if x.Failed {
+			r.Failed++
+		}
+		if x.Pass {
+			passed++
+		}
+		if m := x.Metrics; m != nil && !x.Failed {
+			if m.HumanReadability == "PASS" {
+				readable++
+			}
+			depth = append(depth, m.DepthScore)
+			repetition = append(repetition, m.RepetitionRate)
+			structure = append(structure, m.StructureCompliance)
+			words = append(words, float64(m.WordCount))
+		}
+		if x.SEOScore != nil {
+			seo = append(seo, *x.SEOScore)
+		}
+		if x.DwellSeconds != nil {
+			dwell = append(dwell, *x.DwellSeconds)
+		}
+		impressions += x.Impressions
+		clicks += x.Clicks
+	}
+
+	r.PassRate = proportion(passed, len(exposures))
+	r.Readability = proportion(readable, len(depth))
+	r.DepthScore = mean(depth)
+	r.RepetitionRate = mean(repetition)
+	r.StructureCompliance = mean(structure)
+	r.WordCount = mean(words)
+	r.SEOScore = mean(seo)
+	r.CTR = proportion(int(clicks), int(impressions))
+	r.DwellSeconds = mean(dwell)
+	return r
+}
+
+// decide picks the leader and declares a winner when it beats every other arm at p < 0.05
+func decide(r *Report) {
+	metric := r.Experiment.Metric
+	lowerIsBetter := metric == MetricRepetitionRate
+
+	for _, a := range r.Arms {
+		if n := a.metric(metric).N; n < r.Experiment.MinSamples {
+			r.Decision = fmt.Sprintf("collecting data: arm %s has %d/%d samples for %s", a.Arm, n, r.Experiment.MinSamples, metric)
+			r.Leader = leader(r.Arms, metric, lowerIsBetter)
+			return
+		}
+	}
+
+	r.Leader = leader(r.Arms, metric, lowerIsBetter)
+	best := r.Arms[indexOf(r.Arms, r.Leader)].metric(metric)
+	worstP := 0.0
+	for i := range r.Arms {
+		if r.Arms[i].Arm == r.Leader {
+			continue
+		}
+		p := pValue(best, r.Arms[i].metric(metric))
+		r.Arms[i].PValue = &p
+		worstP = math.Max(worstP, p)
+	}
+
+	if worstP < significance {
+		r.Winner = r.Leader
+		r.Decision = fmt.Sprintf("arm %s wins on %s (p=%.4f)", r.Winner, metric, worstP)
+		return
+	}
+	r.Decision = fmt.Sprintf("no significant difference on %s yet (leader %s, p=%.4f)", metric, r.Leader, worstP)
+}
+
+// leader returns the arm with the best point estimate (ties: first arm)
+func leader(arms []ArmReport, metric string, lowerIsBetter bool) string {
+	ranked := make([]ArmReport, len(arms))
+	copy(ranked, arms)
+	sort.SliceStable(ranked, func(i, j int) bool {
+		a, b := ranked[i].metric(metric), ranked[j].metric(metric)
+		if a.N == 0 || b.N == 0 {
+			return a.N > b.N
+		}
+		if lowerIsBetter {
+			return a.Value < b.Value
+		}
+		return a.Value > b.Value
+	})
+	if len(ranked) == 0 || ranked[0].metric(metric).N == 0 {
+		return ""
+	}
+	return ranked[0].Arm
+}
+
+func indexOf(arms []ArmReport, name string) int {
+	for i, a := range arms {
+		if a.Arm == name {
+			return i
+		}
+	}
+	return 0
+}
+
+// proportion returns successes/n with a Wilson 95% interval
+func proportion(successes, n int) Estimate {
+	if n == 0 {
+		return Estimate{proportion: true}
+	}
+	p := float64(successes) / float64(n)
+	nf := float64(n)
+	z2 := z95 * z95
+	denom := 1 + z2/nf
+	center := (p + z2/(2*nf)) / denom
+	half := z95 * math.Sqrt(p*(1-p)/nf+z2/(4*nf*nf)) / denom
+	return Estimate{N: n, Value: p, Low: math.Max(0, center-half), High: math.Min(1, center+half), proportion: true}
+}
+
+// mean returns the sample mean with a normal 95% interval
+func mean(values []float64) Estimate {
+	n := len(values)
+	if n == 0 {
+		return Estimate{}
+	}
+	var sum float64
+	for _, v := range values {
+		sum += v
+	}
+	m := sum / float64(n)
+	var ss float64
+	for _, v := range values {
+		ss += (v - m) * (v - m)
+	}
+	variance := 0.0
+	if n > 1 {
+		variance = ss / float64(n-1)
+	}
+	half := z95 * math.Sqrt(variance/float64(n))
+	return Estimate{N: n, Value: m, Low: m - half, High: m + half, variance: variance}
+}
+
+// pValue is the two-sided p-value of the difference between two estimates
+// Proportions: pooled two-proportion z-test; means: Welch z-test
+func pValue(a, b Estimate) float64 {
+	if a.N == 0 || b.N == 0 {
+		return 1
+	}
+	na, nb := float64(a.N), float64(b.N)
+	var se float64
+	if a.proportion {
+		pooled := (a.Value*na + b.Value*nb) / (na + nb)
+		se = math.Sqrt(pooled * (1 - pooled) * (1/na + 1/nb))
+	} else {
+		se = math.Sqrt(a.variance/na + b.variance/nb)
+	}
+	if se == 0 {
+		if a.Value == b.Value {
+			return 1
+		}
+		return 0
+	}
+	z := math.Abs(a.Value-b.Value) / se
+	return math.Erfc(z / math.Sqrt2)
+}
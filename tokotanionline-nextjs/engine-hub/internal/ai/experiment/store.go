@@ -0,0 +1,147 @@
+package experiment
+
+import (
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"engine-hub/internal/ai/quality"
+)
+
+// ErrExposureNotFound is returned when an outcome matches no exposure
+var ErrExposureNotFound = errors.New("experiment exposure not found")
+
+// Exposure is one generation that ran under an experiment arm
+// Quality metrics are recorded at generation time; SEO score and user signals arrive later via RecordOutcome
+type Exposure struct {
+	ID           string           `json:"id"`
+	ExperimentID string           `json:"experimentId"`
+	Arm          string           `json:"arm"`
+	PromptRef    string           `json:"promptRef,omitempty"` // ref actually rendered (builtin fallback shows up here)
+	Channel      string           `json:"channel"`
+	UnitKey      string           `json:"unitKey,omitempty"`
+	ContentKey   string           `json:"contentKey,omitempty"` // title/slug, for outcomes posted without the exposure ID
+	Failed       bool             `json:"failed"`
+	Error        string           `json:"error,omitempty"`
+	Metrics      *quality.Metrics `json:"metrics,omitempty"`
+	Pass         bool             `json:"pass"`
+	SEOScore     *float64         `json:"seoScore,omitempty"`
+	Impressions  int64            `json:"impressions"`
+	Clicks       int64            `json:"clicks"`
+	DwellSeconds *float64         `json:"dwellSeconds,omitempty"` // average time on page
+	CreatedAt    time.Time        `json:"createdAt"`
+	UpdatedAt    time.Time        `json:"updatedAt"`
+}
+
+// Outcome carries downstream signals for an exposure (latest totals; nil fields are left unchanged)
+type Outcome struct {
+	ExposureID   string    `json:"exposureId,omitempty"`
+	ContentKey   string    `json:"contentKey,omitempty"`
+	SEOScore     *float64  `json:"seoScore,omitempty"`
+	Impressions  *int64    `json:"impressions,omitempty"`
+	Clicks       *int64    `json:"clicks,omitempty"`
+	DwellSeconds *float64  `json:"dwellSeconds,omitempty"`
+	UpdatedAt    time.Time `json:"-"`
+}
+
+// apply copies the set outcome fields onto x
+func (o Outcome) apply(x *Exposure) {
+	if o.SEOScore != nil {
+		x.SEOScore = o.SEOScore
+	}
+	if o.Impressions != nil {
+		x.Impressions = *o.Impressions
+	}
+	if o.Clicks != nil {
+		x.Clicks = *o.Clicks
+	}
+	if o.DwellSeconds != nil {
+		x.DwellSeconds = o.DwellSeconds
+	}
+	x.UpdatedAt = o.UpdatedAt
+}
+
+// Store persists experiments and exposures
+// MemoryStore is the default; PostgresStore is used when a database is available
+type Store interface {
+	InsertExperiment(e Experiment) error
+	UpdateExperiment(e Experiment) error
+	ListExperiments() ([]Experiment, error) // newest first
+	InsertExposure(x Exposure) error
+	UpdateOutcome(o Outcome) error
+	ListExposures(experimentID string) ([]Exposure, error)
+}
+
+// MemoryStore keeps experiments in memory (lost on restart, dev mode)
+type MemoryStore struct {
+	mu          sync.Mutex
+	experiments []Experiment
+	exposures   []Exposure
+}
+
+// NewMemoryStore creates an empty in-memory experiment store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{}
+}
+
+func (m *MemoryStore) InsertExperiment(e Experiment) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.experiments = append(m.experiments, e)
+	return nil
+}
+
+func (m *MemoryStore) UpdateExperiment(e Experiment) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for i := range m.experiments {
+		if m.experiments[i].ID == e.ID {
+			m.experiments[i] = e
+			return nil
+		}
+	}
+	return ErrNotFound
+}
+
+func (m *MemoryStore) ListExperiments() ([]Experiment, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	out := make([]Experiment, len(m.experiments))
+	copy(out, m.experiments)
+	sort.Slice(out, func(i, j int) bool { return out[i].CreatedAt.After(out[j].CreatedAt) })
+	return out, nil
+}
+
+func (m *MemoryStore) InsertExposure(x Exposure) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.exposures = append(m.exposures, x)
+	return nil
+}
+
+func (m *MemoryStore) UpdateOutcome(o Outcome) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	// Latest exposure first, so a content key regenerated later maps to its newest run
+	for i := len(m.exposures) - 1; i >= 0; i-- {
+		x := &m.exposures[i]
+		if (o.ExposureID != "" && x.ID == o.ExposureID) || (o.ExposureID == "" && x.ContentKey == o.ContentKey) {
+			o.apply(x)
+			return nil
+		}
+	}
+	return ErrExposureNotFound
+}
+
+func (m *MemoryStore) ListExposures(experimentID string) ([]Exposure, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var out []Exposure
+	for _, x := range m.exposures {
+		if x.ExperimentID == experimentID {
+			out = append(out, x)
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,265 @@
+package fakeopenai
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Answer returns the fake model output for a prompt
+// The caller is recognised from its prompt (same prompts the engine sends to OpenAI):
+//
+//	questions (People Also Ask)  → one question per line
+//	v2 structure                 → JSON array of sections
+//	v2 title + hero              → {"title","heroCopy"}
+//	ads copy                     → {"primaryText","headline","description","ctaText"}
+//	anything else                → long-form markdown article (# title, ## sections, > 900 words)
+func Answer(prompt string) string {
+	switch {
+	case strings.Contains(prompt, "People Also Ask"):
+		return questionsAnswer(prompt)
+	case strings.Contains(prompt, "Return as JSON array with: heading"):
+		return structureAnswer(prompt)
+	case strings.Contains(prompt, `"heroCopy"`):
+		return titleHeroAnswer(prompt)
+	case strings.Contains(prompt, "primaryText"):
+		return adsAnswer(prompt)
+	default:
+		return articleAnswer(prompt)
+	}
+}
+
+// StructuredAnswer returns the fake JSON for a structured output request (schema name from the request):
+//
+//	content_article  → {"title","metaTitle","metaDesc","sections","faq","cta"}
+//	v2_structure     → {"sections":[...]}
+//	v2_title_hero    → {"title","heroCopy"}
+//	unknown name     → same as Answer
+func StructuredAnswer(schema, prompt string) string {
+	switch schema {
+	case "content_article":
+		return structuredArticleAnswer(prompt)
+	case "v2_structure":
+		return `{"sections":` + structureAnswer(prompt) + `}`
+	case "v2_title_hero":
+		return titleHeroAnswer(prompt)
+	default:
+		return Answer(prompt)
+	}
+}
+
+var (
+	outlineH2Pattern = regexp.MustCompile(`(?m)^\s*#{0,3}\s*H2\s*[—–-]+\s*(.+?)\s*$`)
+	topicPatterns    = []*regexp.Regexp{
+		regexp.MustCompile(`(?m)content about:\s*(.+?)\s*$`),
+		regexp.MustCompile(`(?m)^Topik:\s*(.+?)\s*$`),
+		regexp.MustCompile(`(?m)^Topic:\s*(.+?)\s*$`),
+		regexp.MustCompile(`(?m)^Product(?: Name)?:\s*(.+?)\s*$`),
+		regexp.MustCompile(`(?m)^Judul:\s*(.+?)\s*$`),
+	}
+)
+
+// topicOf extracts the topic from a prompt (first outline H2 as a last resort)
+func topicOf(prompt string) string {
+	for _, p := range topicPatterns {
+		if m := p.FindStringSubmatch(prompt); m != nil && m[1] != "" {
+			return m[1]
+		}
+	}
+	if headings := outlineHeadings(prompt); len(headings) > 0 {
+		return headings[0]
+	}
+	return "Pertanian Modern"
+}
+
+// outlineHeadings returns "H2 — ..." outline lines in prompt order (duplicates dropped)
+func outlineHeadings(prompt string) []string {
+	seen := map[string]bool{}
+	var headings []string
+	for _, m := range outlineH2Pattern.FindAllStringSubmatch(prompt, -1) {
+		h := strings.TrimSpace(m[1])
+		if h == "" || seen[h] {
+			continue
+		}
+		seen[h] = true
+		headings = append(headings, h)
+	}
+	return headings
+}
+
+func questionsAnswer(prompt string) string {
+	topic := strings.ToLower(topicOf(prompt))
+	return strings.Join([]string{
+		fmt.Sprintf("Bagaimana cara memulai %s bagi pemula?", topic),
+		fmt.Sprintf("Berapa lama waktu yang dibutuhkan untuk %s?", topic),
+		fmt.Sprintf("Kesalahan apa yang sering terjadi saat %s?", topic),
+		fmt.Sprintf("Alat apa saja yang diperlukan untuk %s?", topic),
+		fmt.Sprintf("Apakah %s cocok untuk lahan sempit?", topic),
+	}, "\n")
+}
+
+func structureAnswer(prompt string) string {
+	topic := topicOf(prompt)
+	type section struct {
+		Heading      string `json:"heading"`
+		HeadingLevel int    `json:"headingLevel"`
+		Body         string `json:"body"`
+		Order        int    `json:"order"`
+	}
+	var sections []section
+	for i, h := range articleHeadings(prompt, topic) {
+		sections = append(sections, section{
+			Heading:      h,
+			HeadingLevel: 2,
+			Body:         paragraph(h, i),
+			Order:        i + 1,
+		})
+	}
+	data, _ := json.Marshal(sections)
+	return string(data)
+}
+
+func titleHeroAnswer(prompt string) string {
+	topic := topicOf(prompt)
+	data, _ := json.Marshal(map[string]string{
+		"title":    "Panduan Praktis " + topic,
+		"heroCopy": fmt.Sprintf("Langkah-langkah %s yang jelas dan mudah diterapkan di lahan sendiri, lengkap dengan contoh dari petani di lapangan.", strings.ToLower(topic)),
+	})
+	return string(data)
+}
+
+func adsAnswer(prompt string) string {
+	topic := topicOf(prompt)
+	data, _ := json.Marshal(map[string]string{
+		"primaryText": fmt.Sprintf("%s membantu tanaman tumbuh lebih sehat. Cocok untuk petani rumahan maupun kebun skala besar.", topic),
+		"headline":    truncateWords(topic+" untuk Kebun Anda", 6),
+		"description": "Kualitas terjaga, dikirim ke seluruh Indonesia.",
+		"ctaText":     "Pelajari Lebih Lanjut",
+	})
+	return string(data)
+}
+
+// articleAnswer builds a markdown article: outline H2s (or defaults), 4 paragraphs each, FAQ when asked
+func articleAnswer(prompt string) string {
+	topic := topicOf(prompt)
+	headings := articleHeadings(prompt, topic)
+	if strings.Contains(prompt, "Pertanyaan yang Sering Diajukan") {
+		headings = append(headings, "Pertanyaan yang Sering Diajukan")
+	}
+
+	var b strings.Builder
+	b.WriteString("# Panduan Lengkap " + topic + "\n\n")
+	b.WriteString(paragraph(topic, len(prompt)) + "\n\n")
+	for i, h := range headings {
+		b.WriteString("## " + h + "\n\n")
+		for p := 0; p < 4; p++ {
+			b.WriteString(paragraph(h, i*4+p) + "\n\n")
+		}
+	}
+	return strings.TrimSpace(b.String())
+}
+
+// structuredArticleAnswer is articleAnswer as content_article JSON (FAQ in its own field)
+func structuredArticleAnswer(prompt string) string {
+	type section struct {
+		Heading string `json:"heading"`
+		Level   int    `json:"level"`
+		Body    string `json:"body"`
+	}
+	type faq struct {
+		Question string `json:"question"`
+		Answer   string `json:"answer"`
+	}
+	topic := topicOf(prompt)
+	article := struct {
+		Title     string    `json:"title"`
+		MetaTitle string    `json:"metaTitle"`
+		MetaDesc  string    `json:"metaDesc"`
+		Sections  []section `json:"sections"`
+		FAQ       []faq     `json:"faq"`
+		CTA       string    `json:"cta"`
+	}{
+		Title:     "Panduan Lengkap " + topic,
+		MetaTitle: truncateWords("Panduan Lengkap "+topic, 8),
+		MetaDesc:  fmt.Sprintf("Pelajari %s langkah demi langkah: persiapan lahan, perawatan rutin, dan kesalahan umum yang perlu dihindari petani.", strings.ToLower(topic)),
+		Sections:  []section{},
+		FAQ:       []faq{},
+		CTA:       fmt.Sprintf("Lanjutkan belajar tentang %s dengan panduan lain di situs ini.", strings.ToLower(topic)),
+	}
+	for i, h := range articleHeadings(prompt, topic) {
+		var paragraphs []string
+		for p := 0; p < 4; p++ {
+			paragraphs = append(paragraphs, paragraph(h, i*4+p))
+		}
+		article.Sections = append(article.Sections, section{Heading: h, Level: 2, Body: strings.Join(paragraphs, "\n\n")})
+	}
+	if strings.Contains(prompt, "Pertanyaan yang Sering Diajukan") {
+		for i, q := range strings.Split(questionsAnswer(prompt), "\n")[:3] {
+			article.FAQ = append(article.FAQ, faq{Question: q, Answer: strings.SplitAfter(paragraph(q, i), ".")[0]})
+		}
+	}
+	data, _ := json.Marshal(article)
+	return string(data)
+}
+
+// articleHeadings returns the outline H2s, padded with default sections to at least 4
+func articleHeadings(prompt, topic string) []string {
+	headings := outlineHeadings(prompt)
+	defaults := []string{
+		"Mengenal " + topic,
+		"Langkah Persiapan di Lahan",
+		"Perawatan dan Pemantauan Rutin",
+		"Kesalahan Umum yang Perlu Dihindari",
+	}
+	for _, d := range defaults {
+		if len(headings) >= 4 {
+			break
+		}
+		headings = append(headings, d)
+	}
+	return headings
+}
+
+var sentences = []string{
+	"Petani di banyak daerah memulai dengan mengamati kondisi lahan selama beberapa minggu sebelum mengambil keputusan.",
+	"Catatan sederhana tentang curah hujan, suhu, dan pertumbuhan tanaman sangat membantu saat mengevaluasi hasil panen.",
+	"Tanah yang gembur dan kaya bahan organik menyimpan air lebih lama sehingga akar tanaman berkembang dengan baik.",
+	"Pemberian pupuk sebaiknya mengikuti kebutuhan tanaman pada setiap fase pertumbuhan, bukan sekadar kebiasaan.",
+	"Pengendalian hama dilakukan lebih awal dengan memeriksa daun dan batang secara rutin setiap pagi.",
+	"Penyiraman pada pagi atau sore hari mengurangi penguapan dan menjaga kelembapan tanah tetap stabil.",
+	"Rotasi tanaman membantu memutus siklus penyakit dan menjaga keseimbangan unsur hara di dalam tanah.",
+	"Kelompok tani setempat sering berbagi pengalaman tentang varietas yang cocok dengan iklim daerahnya.",
+	"Biaya produksi dapat ditekan dengan memanfaatkan sisa panen sebagai kompos untuk musim berikutnya.",
+	"Hasil uji sederhana di lapangan memberi gambaran awal sebelum petani melakukan perubahan yang lebih besar.",
+	"Jarak tanam yang tepat memberi ruang bagi sirkulasi udara sehingga tanaman tidak mudah terserang jamur.",
+	"Pencatatan biaya dan hasil setiap musim memudahkan petani menilai apakah metode baru benar-benar bermanfaat.",
+}
+
+// paragraph returns ~80 words about subject; seed picks the sentence rotation
+func paragraph(subject string, seed int) string {
+	if seed < 0 {
+		seed = -seed
+	}
+	parts := []string{fmt.Sprintf("Dalam praktik %s, pengalaman di lapangan menjadi dasar setiap langkah.", strings.ToLower(subject))}
+	for i := 0; i < 5; i++ {
+		parts = append(parts, sentences[(seed*5+i)%len(sentences)])
+	}
+	return strings.Join(parts, " ")
+}
+
+func truncateWords(s string, n int) string {
+	words := strings.Fields(s)
+	if len(words) > n {
+		words = words[:n]
+	}
+	return strings.Join(words, " ")
+}
+
+// promptHash is a short stable id for a prompt (image file names)
+func promptHash(prompt string) string {
+	sum := sha256.Sum256([]byte(prompt))
+	return hex.EncodeToString(sum[:])[:12]
+}
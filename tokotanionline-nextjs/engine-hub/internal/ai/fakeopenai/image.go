@@ -0,0 +1,65 @@
+package fakeopenai
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"image"
+	"image/color"
+	"image/png"
+	"strconv"
+	"strings"
+)
+
+const maxImageSide = 2048
+
+// parseSize parses "WxH" (image API size), default 1024x1024
+func parseSize(size string) (int, int) {
+	w, h, ok := strings.Cut(size, "x")
+	if ok {
+		width, errW := strconv.Atoi(w)
+		height, errH := strconv.Atoi(h)
+		if errW == nil && errH == nil && width > 0 && height > 0 && width <= maxImageSide && height <= maxImageSide {
+			return width, height
+		}
+	}
+	return 1024, 1024
+}
+
+// renderPNG draws a deterministic gradient with a few blocks (colours seeded by name)
+// Not blank and not uniform, so image checks behave like on a real photo
+func renderPNG(name string, width, height int) ([]byte, error) {
+	seed := sha256.Sum256([]byte(name))
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+
+	base := color.RGBA{R: seed[0], G: seed[1], B: seed[2], A: 255}
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			img.SetRGBA(x, y, color.RGBA{
+				R: base.R + uint8(x*128/width),
+				G: base.G + uint8(y*128/height),
+				B: base.B + uint8((x+y)*64/(width+height)),
+				A: 255,
+			})
+		}
+	}
+
+	// Blocks so the image has edges (pHash/blank checks see structure)
+	for i := 0; i < 6; i++ {
+		bx := int(seed[3+i*4]) * width / 256
+		by := int(seed[4+i*4]) * height / 256
+		bw := width/8 + int(seed[5+i*4])*width/1024
+		bh := height/8 + int(seed[6+i*4])*height/1024
+		c := color.RGBA{R: seed[6+i*4], G: seed[5+i*4], B: seed[4+i*4], A: 255}
+		for y := by; y < by+bh && y < height; y++ {
+			for x := bx; x < bx+bw && x < width; x++ {
+				img.SetRGBA(x, y, c)
+			}
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
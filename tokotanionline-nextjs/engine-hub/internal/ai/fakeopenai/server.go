@@ -0,0 +1,345 @@
+package fakeopenai
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Fake OpenAI-compatible API for offline runs (test-phase-a, CI, local dev without a key)
+// Serves:
+//
+//	POST /v1/responses             Responses API (content generator, questions)
+//	POST /v1/chat/completions      Chat Completions (v2 generator, ads generator)
+//	POST /v1/images/generations    image API (returns a URL on this server)
+//	GET  /files/<name>.png         generated PNG for that URL
+//
+// Answers are deterministic for the same prompt, so runs are repeatable and cassettes stable
+// Requests with a json_schema format get JSON for that schema name (see StructuredAnswer)
+
+// Request is one call received by the fake server
+type Request struct {
+	Method string `json:"method"`
+	Path   string `json:"path"`
+	Model  string `json:"model,omitempty"`
+	Prompt string `json:"prompt,omitempty"`
+}
+
+// Handler is the fake API http.Handler
+type Handler struct {
+	mu       sync.Mutex
+	requests []Request
+	failures []int // queued error statuses for the next completion/image calls
+}
+
+// NewHandler creates the fake API handler
+func NewHandler() *Handler {
+	return &Handler{}
+}
+
+// FailNext makes the next n completion/image calls return status (retry/fallback tests)
+func (h *Handler) FailNext(n int, status int) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for i := 0; i < n; i++ {
+		h.failures = append(h.failures, status)
+	}
+}
+
+// Requests returns the calls received so far
+func (h *Handler) Requests() []Request {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	out := make([]Request, len(h.requests))
+	copy(out, h.requests)
+	return out
+}
+
+// ServeHTTP implements http.Handler
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	path := r.URL.Path
+	switch {
+	case r.Method == http.MethodGet && strings.HasPrefix(path, "/files/"):
+		h.record(Request{Method: r.Method, Path: path})
+		h.serveImageFile(w, r)
+	case r.Method == http.MethodPost && strings.HasSuffix(path, "/images/generations"):
+		h.serveImageGeneration(w, r)
+	case r.Method == http.MethodPost && (strings.HasSuffix(path, "/responses") || strings.HasSuffix(path, "/chat/completions")):
+		h.serveCompletion(w, r)
+	default:
+		writeError(w, http.StatusNotFound, fmt.Sprintf("fake openai: no route for %s %s", r.Method, path))
+	}
+}
+
+func (h *Handler) record(req Request) {
+	h.mu.Lock()
+	h.requests = append(h.requests, req)
+	h.mu.Unlock()
+}
+
+// nextFailure pops a queued failure status (0 = none)
+func (h *Handler) nextFailure() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if len(h.failures) == 0 {
+		return 0
+	}
+	status := h.failures[0]
+	h.failures = h.failures[1:]
+	return status
+}
+
+// serveCompletion answers Responses and Chat Completions requests
+// The response shape follows the request shape ("messages" = chat, "input" = responses), not the path,
+// because AI_API_URL is shared by callers that use different providers
+func (h *Handler) serveCompletion(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Model        string          `json:"model"`
+		Input        json.RawMessage `json:"input"`
+		Instructions string          `json:"instructions"`
+		Messages     []message       `json:"messages"`
+		Text         struct {
+			Format struct {
+				Type string `json:"type"`
+				Name string `json:"name"`
+			} `json:"format"`
+		} `json:"text"`
+		ResponseFormat struct {
+			Type       string `json:"type"`
+			JSONSchema struct {
+				Name string `json:"name"`
+			} `json:"json_schema"`
+		} `json:"response_format"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeError(w, http.StatusBadRequest, "fake openai: invalid JSON body: "+err.Error())
+		return
+	}
+
+	chat := len(body.Messages) > 0 || (len(body.Input) == 0 && strings.HasSuffix(r.URL.Path, "/chat/completions"))
+	prompt := body.Instructions
+	if chat {
+		prompt = joinMessages(body.Messages)
+	} else {
+		prompt = strings.TrimSpace(prompt + "\n" + inputText(body.Input))
+	}
+	h.record(Request{Method: r.Method, Path: r.URL.Path, Model: body.Model, Prompt: prompt})
+
+	if status := h.nextFailure(); status != 0 {
+		writeError(w, status, fmt.Sprintf("fake openai: injected failure (status %d)", status))
+		return
+	}
+
+	text := Answer(prompt)
+	switch {
+	case body.Text.Format.Type == "json_schema":
+		text = StructuredAnswer(body.Text.Format.Name, prompt)
+	case body.ResponseFormat.Type == "json_schema":
+		text = StructuredAnswer(body.ResponseFormat.JSONSchema.Name, prompt)
+	}
+	inputTokens := len(strings.Fields(prompt))
+	outputTokens := len(strings.Fields(text))
+
+	w.Header().Set("Content-Type", "application/json")
+	if chat {
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"id":      "chatcmpl-fake",
+			"object":  "chat.completion",
+			"created": time.Now().Unix(),
+			"model":   body.Model,
+			"choices": []map[string]interface{}{{
+				"index":         0,
+				"message":       map[string]string{"role": "assistant", "content": text},
+				"finish_reason": "stop",
+			}},
+			"usage": map[string]int{
+				"prompt_tokens":     inputTokens,
+				"completion_tokens": outputTokens,
+				"total_tokens":      inputTokens + outputTokens,
+			},
+		})
+		return
+	}
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"id":     "resp-fake",
+		"object": "response",
+		"status": "completed",
+		"model":  body.Model,
+		"output": []map[string]interface{}{{
+			"type": "message",
+			"role": "assistant",
+			"content": []map[string]string{
+				{"type": "output_text", "text": text},
+			},
+		}},
+		"usage": map[string]int{
+			"input_tokens":  inputTokens,
+			"output_tokens": outputTokens,
+			"total_tokens":  inputTokens + outputTokens,
+		},
+	})
+}
+
+// serveImageGeneration answers the image API with a URL served by this handler
+func (h *Handler) serveImageGeneration(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Model  string `json:"model"`
+		Prompt string `json:"prompt"`
+		Size   string `json:"size"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeError(w, http.StatusBadRequest, "fake openai: invalid JSON body: "+err.Error())
+		return
+	}
+	h.record(Request{Method: r.Method, Path: r.URL.Path, Model: body.Model, Prompt: body.Prompt})
+
+	if status := h.nextFailure(); status != 0 {
+		writeError(w, status, fmt.Sprintf("fake openai: injected failure (status %d)", status))
+		return
+	}
+
+	size := body.Size
+	if size == "" {
+		size = "1024x1024"
+	}
+	url := fmt.Sprintf("http://%s/files/img-%s.png?size=%s", r.Host, promptHash(body.Prompt), size)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"created": time.Now().Unix(),
+		"data": []map[string]string{
+			{"url": url, "revised_prompt": body.Prompt},
+		},
+	})
+}
+
+func (h *Handler) serveImageFile(w http.ResponseWriter, r *http.Request) {
+	width, height := parseSize(r.URL.Query().Get("size"))
+	data, err := renderPNG(strings.TrimPrefix(r.URL.Path, "/files/"), width, height)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "fake openai: failed to render image: "+err.Error())
+		return
+	}
+	w.Header().Set("Content-Type", "image/png")
+	w.Write(data)
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"error": map[string]string{"message": message, "type": "fake_error"},
+	})
+}
+
+type message struct {
+	Role    string          `json:"role"`
+	Content json.RawMessage `json:"content"`
+}
+
+func joinMessages(messages []message) string {
+	parts := make([]string, 0, len(messages))
+	for _, m := range messages {
+		parts = append(parts, contentText(m.Content))
+	}
+	return strings.Join(parts, "\n")
+}
+
+// inputText flattens Responses "input" (plain string or message list)
+func inputText(raw json.RawMessage) string {
+	if len(raw) == 0 {
+		return ""
+	}
+	var s string
+	if json.Unmarshal(raw, &s) == nil {
+		return s
+	}
+	var messages []message
+	if json.Unmarshal(raw, &messages) == nil {
+		return joinMessages(messages)
+	}
+	return string(raw)
+}
+
+// contentText flattens message content (string or [{type,text}] parts)
+func contentText(raw json.RawMessage) string {
+	var s string
+	if json.Unmarshal(raw, &s) == nil {
+		return s
+	}
+	var parts []struct {
+		Text string `json:"text"`
+	}
+	if json.Unmarshal(raw, &parts) == nil {
+		texts := make([]string, 0, len(parts))
+		for _, p := range parts {
+			texts = append(texts, p.Text)
+		}
+		return strings.Join(texts, "\n")
+	}
+	return string(raw)
+}
+
+// Server is a running fake API on a random loopback port
+type Server struct {
+	*Handler
+	URL string
+	srv *httptest.Server
+}
+
+// NewServer starts a fake API server (call Close when done)
+func NewServer() *Server {
+	h := NewHandler()
+	srv := httptest.NewServer(h)
+	log.Printf("[FAKE OPENAI] listening on %s", srv.URL)
+	return &Server{Handler: h, URL: srv.URL, srv: srv}
+}
+
+// Close stops the server
+func (s *Server) Close() {
+	s.srv.Close()
+}
+
+// Env returns the env vars that point every AI caller at this server
+func (s *Server) Env() map[string]string {
+	return map[string]string{
+		"AI_API_URL":        s.URL + "/v1/responses",
+		"LLM_CONTENT_URL":   s.URL + "/v1/responses",
+		"LLM_QUESTIONS_URL": s.URL + "/v1/responses",
+		"LLM_V2_URL":        s.URL + "/v1/chat/completions",
+		"LLM_ADS_URL":       s.URL + "/v1/chat/completions",
+		"IMAGE_API_URL":     s.URL + "/v1/images/generations",
+		"OPENAI_API_KEY":    "sk-fake-" + strings.Repeat("0", 120),
+		"IMAGE_API_KEY":     "sk-fake-image",
+	}
+}
+
+// Apply sets Env() in the process environment and returns a func restoring the previous values
+// Generators read env in their constructors, so create them after Apply
+func (s *Server) Apply() (restore func()) {
+	previous := map[string]*string{}
+	for k, v := range s.Env() {
+		if old, ok := os.LookupEnv(k); ok {
+			old := old
+			previous[k] = &old
+		} else {
+			previous[k] = nil
+		}
+		os.Setenv(k, v)
+	}
+	return func() {
+		for k, v := range previous {
+			if v == nil {
+				os.Unsetenv(k)
+			} else {
+				os.Setenv(k, *v)
+			}
+		}
+	}
+}
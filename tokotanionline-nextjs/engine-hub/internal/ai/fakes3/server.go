@@ -0,0 +1,164 @@
+package fakes3
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"engine-hub/internal/ai/objectstore"
+)
+
+// Fake S3-compatible object storage (MinIO-style stand-in) for running IMAGE_STORE=s3 offline
+// Serves path-style requests only:
+//
+//	PUT  /<bucket>/<key>   signed (SigV4 verified against the configured credentials)
+//	HEAD /<bucket>/<key>   signed
+//	GET  /<bucket>/<key>   anonymous allowed (public bucket, used as IMAGE_PUBLIC_BASE_URL)
+//
+// Buckets are created on first PUT; objects live in memory
+
+// Object is one stored object
+type Object struct {
+	Data         []byte
+	ContentType  string
+	CacheControl string
+	Modified     time.Time
+}
+
+// Handler is the fake S3 http.Handler
+type Handler struct {
+	accessKey string
+	secretKey string
+
+	mu      sync.Mutex
+	objects map[string]*Object // "bucket/key"
+	puts    int
+}
+
+// NewHandler creates the fake S3 handler accepting the given credentials
+func NewHandler(accessKey, secretKey string) *Handler {
+	return &Handler{accessKey: accessKey, secretKey: secretKey, objects: map[string]*Object{}}
+}
+
+// Object returns a stored object (nil when missing)
+func (h *Handler) Object(bucket, key string) *Object {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.objects[bucket+"/"+key]
+}
+
+// Stats returns the number of stored objects and PUT calls (dedupe checks)
+func (h *Handler) Stats() (objects, puts int) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return len(h.objects), h.puts
+}
+
+// ServeHTTP implements http.Handler
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	parts := strings.SplitN(strings.TrimPrefix(r.URL.Path, "/"), "/", 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		writeError(w, http.StatusBadRequest, "InvalidRequest", "path-style /<bucket>/<key> required")
+		return
+	}
+	id := parts[0] + "/" + parts[1]
+
+	switch r.Method {
+	case http.MethodPut:
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "IncompleteBody", err.Error())
+			return
+		}
+		if code, msg := h.verify(r, body); code != "" {
+			writeError(w, http.StatusForbidden, code, msg)
+			return
+		}
+		h.mu.Lock()
+		h.objects[id] = &Object{
+			Data:         body,
+			ContentType:  r.Header.Get("Content-Type"),
+			CacheControl: r.Header.Get("Cache-Control"),
+			Modified:     time.Now().UTC(),
+		}
+		h.puts++
+		h.mu.Unlock()
+		w.Header().Set("ETag", etag(body))
+		w.WriteHeader(http.StatusOK)
+		log.Printf("[FAKE S3] PUT %s (%d bytes)", id, len(body))
+
+	case http.MethodHead, http.MethodGet:
+		if r.Method == http.MethodHead || r.Header.Get("Authorization") != "" {
+			if code, msg := h.verify(r, nil); code != "" {
+				writeError(w, http.StatusForbidden, code, msg)
+				return
+			}
+		}
+		h.mu.Lock()
+		obj := h.objects[id]
+		h.mu.Unlock()
+		if obj == nil {
+			writeError(w, http.StatusNotFound, "NoSuchKey", "The specified key does not exist.")
+			return
+		}
+		w.Header().Set("Content-Type", obj.ContentType)
+		w.Header().Set("Content-Length", fmt.Sprint(len(obj.Data)))
+		w.Header().Set("ETag", etag(obj.Data))
+		w.Header().Set("Last-Modified", obj.Modified.Format(http.TimeFormat))
+		if obj.CacheControl != "" {
+			w.Header().Set("Cache-Control", obj.CacheControl)
+		}
+		w.WriteHeader(http.StatusOK)
+		if r.Method == http.MethodGet {
+			w.Write(obj.Data)
+		}
+
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "MethodNotAllowed", r.Method+" not supported by fake s3")
+	}
+}
+
+var authPattern = regexp.MustCompile(`^AWS4-HMAC-SHA256 Credential=([^/]+)/(\d{8})/([^/]+)/s3/aws4_request, ?SignedHeaders=([^,]+), ?Signature=([0-9a-f]{64})$`)
+
+// verify checks the SigV4 Authorization header (and the payload hash when body != nil)
+// Returns an S3 error code + message, empty code when valid
+func (h *Handler) verify(r *http.Request, body []byte) (string, string) {
+	m := authPattern.FindStringSubmatch(r.Header.Get("Authorization"))
+	if m == nil {
+		return "AccessDenied", "missing or malformed SigV4 Authorization"
+	}
+	accessKey, region, signature := m[1], m[3], m[5]
+	if accessKey != h.accessKey {
+		return "InvalidAccessKeyId", "unknown access key " + accessKey
+	}
+	payloadHash := r.Header.Get("X-Amz-Content-Sha256")
+	if body != nil && payloadHash != objectstore.PayloadHash(body) {
+		return "XAmzContentSHA256Mismatch", "payload hash does not match body"
+	}
+	amzDate := r.Header.Get("X-Amz-Date")
+	if len(amzDate) < 8 || amzDate[:8] != m[2] {
+		return "AccessDenied", "x-amz-date does not match credential scope"
+	}
+	if objectstore.SignatureV4(r, payloadHash, h.secretKey, region, amzDate) != signature {
+		return "SignatureDoesNotMatch", "The request signature we calculated does not match the signature you provided."
+	}
+	return "", ""
+}
+
+func etag(data []byte) string {
+	sum := md5.Sum(data)
+	return `"` + hex.EncodeToString(sum[:]) + `"`
+}
+
+func writeError(w http.ResponseWriter, status int, code, message string) {
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(status)
+	fmt.Fprintf(w, "<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n<Error><Code>%s</Code><Message>%s</Message></Error>", code, message)
+}
@@ -0,0 +1,329 @@
+package generation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"engine-hub/internal/ai/content"
+	"engine-hub/internal/ai/experiment"
+	"engine-hub/internal/ai/state"
+	"engine-hub/internal/ai/usage"
+	"engine-hub/internal/ai/workflow"
+)
+
+// Generation statuses
+const (
+	StatusQueued  = "QUEUED"
+	StatusRunning = "RUNNING"
+	StatusDone    = "DONE"
+	StatusFailed  = "FAILED"
+)
+
+var (
+	ErrNotFound = errors.New("generation not found")
+	ErrNotDone  = errors.New("generation has not finished")
+)
+
+// ErrShuttingDown is the cancel cause set by Drain when its deadline passes
+var ErrShuttingDown = errors.New("server shutting down")
+
+// Generation is one asynchronous run of the content pipeline
+// Status is the run lifecycle; State/StatusCode mirror the pipeline's state.StateMachine
+type Generation struct {
+	ID         string                 `json:"id"`
+	Status     string                 `json:"status"`
+	State      state.State            `json:"state"`
+	StatusCode state.StatusCode       `json:"statusCode,omitempty"`
+	Steps      []workflow.StepResult  `json:"steps"`
+	Progress   int                    `json:"progress"` // percent
+	Request    content.ContentRequest `json:"request"`
+	Result     *workflow.DraftAI      `json:"-"` // served by the result endpoint only
+	Error      string                 `json:"error,omitempty"`
+	Experiment *experiment.Assignment `json:"experiment,omitempty"`
+	ExposureID string                 `json:"exposureId,omitempty"`
+
+	WebhookURL    string `json:"webhookUrl,omitempty"`
+	WebhookStatus string `json:"webhookStatus,omitempty"` // PENDING, DELIVERED, FAILED
+	WebhookError  string `json:"webhookError,omitempty"`
+
+	CreatedAt  time.Time  `json:"createdAt"`
+	StartedAt  *time.Time `json:"startedAt,omitempty"`
+	FinishedAt *time.Time `json:"finishedAt,omitempty"`
+	UpdatedAt  time.Time  `json:"updatedAt"`
+}
+
+// Terminal reports whether the generation has finished (successfully or not)
+func (g Generation) Terminal() bool {
+	return g.Status == StatusDone || g.Status == StatusFailed
+}
+
+// Workers reads GENERATION_WORKERS from env (default 2 concurrent pipelines)
+func Workers() int {
+	workers := 2
+	if v := os.Getenv("GENERATION_WORKERS"); v != "" {
+		if val, err := strconv.Atoi(v); err == nil && val > 0 {
+			workers = val
+		}
+	}
+	return workers
+}
+
+// runTimeout reads GENERATION_TIMEOUT_SEC from env (default 900 detik per generation)
+func runTimeout() time.Duration {
+	seconds := 900
+	if v := os.Getenv("GENERATION_TIMEOUT_SEC"); v != "" {
+		if val, err := strconv.Atoi(v); err == nil && val > 0 {
+			seconds = val
+		}
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+var (
+	slotsOnce sync.Once
+	slots     chan struct{} // worker slots, sized by Workers()
+
+	runningMu sync.Mutex
+	running   = make(map[string]context.CancelCauseFunc)
+	inflight  sync.WaitGroup // run goroutines, waited by Drain
+)
+
+func acquireSlots() chan struct{} {
+	slotsOnce.Do(func() {
+		slots = make(chan struct{}, Workers())
+	})
+	return slots
+}
+
+// Submit queues a generation and returns it immediately; the pipeline runs in the background
+// ctx only carries values (usage attribution) - it may be a request context that ends right after Submit
+func Submit(ctx context.Context, req content.ContentRequest, webhookURL string) (Generation, error) {
+	now := time.Now()
+	g := Generation{
+		ID:         uuid.New().String(),
+		Status:     StatusQueued,
+		State:      state.StateInit,
+		Steps:      []workflow.StepResult{},
+		WebhookURL: webhookURL,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+	if webhookURL != "" {
+		g.WebhookStatus = WebhookPending
+	}
+
+	// Prompt A/B: same rule as the synchronous endpoint (same outline → same arm)
+	assignment := experiment.Assign(&req, req.Outline)
+	g.Request = req
+	g.Experiment = assignment
+
+	if err := getStore().Insert(g); err != nil {
+		return Generation{}, err
+	}
+
+	runCtx, cancel := context.WithCancelCause(context.WithoutCancel(ctx))
+	runCtx = usage.WithAttribution(runCtx, usage.Attribution{JobID: g.ID}) // AI calls are billed to the generation
+	runningMu.Lock()
+	running[g.ID] = cancel
+	runningMu.Unlock()
+	inflight.Add(1)
+
+	go run(runCtx, g)
+
+	log.Printf("[GENERATION] Queued %s (contentType=%s, webhook=%t)", g.ID, req.ContentType, webhookURL != "")
+	return g, nil
+}
+
+// Get returns a generation by ID
+func Get(id string) (Generation, error) {
+	g, err := getStore().Get(id)
+	if err != nil {
+		return Generation{}, err
+	}
+	return *g, nil
+}
+
+// Result returns the DraftAI of a finished generation (ErrNotDone while queued/running)
+func Result(id string) (*workflow.DraftAI, Generation, error) {
+	g, err := Get(id)
+	if err != nil {
+		return nil, Generation{}, err
+	}
+	if g.Status != StatusDone {
+		return nil, g, ErrNotDone
+	}
+	return g.Result, g, nil
+}
+
+// SaveDraft stores a DraftAI produced outside Submit (batch production) as a DONE generation
+// Returns the generation ID, so the draft can be fetched from the result endpoint
+func SaveDraft(req content.ContentRequest, draft *workflow.DraftAI, assignment *experiment.Assignment, exposureID string) (string, error) {
+	now := time.Now()
+	g := Generation{
+		ID:         uuid.New().String(),
+		Status:     StatusDone,
+		State:      state.StateStore,
+		StatusCode: state.StatusCode(draft.Content.Status),
+		Steps:      draft.Steps,
+		Progress:   100,
+		Request:    req,
+		Result:     draft,
+		Experiment: assignment,
+		ExposureID: exposureID,
+		CreatedAt:  now,
+		StartedAt:  &now,
+		FinishedAt: &now,
+		UpdatedAt:  now,
+	}
+	if g.Steps == nil {
+		g.Steps = []workflow.StepResult{}
+	}
+	if err := getStore().Insert(g); err != nil {
+		return "", err
+	}
+	return g.ID, nil
+}
+
+// run waits for a worker slot, executes the pipeline and records progress, result and webhook delivery
+func run(ctx context.Context, g Generation) {
+	defer inflight.Done()
+	defer func() {
+		runningMu.Lock()
+		if cancel, ok := running[g.ID]; ok {
+			cancel(nil)
+			delete(running, g.ID)
+		}
+		runningMu.Unlock()
+	}()
+
+	slots := acquireSlots()
+	select {
+	case slots <- struct{}{}:
+	case <-ctx.Done():
+		finish(&g, nil, context.Cause(ctx))
+		return
+	}
+
+	started := time.Now()
+	g.Status = StatusRunning
+	g.StartedAt = &started
+	save(g)
+
+	timeout := runTimeout()
+	ctx, cancel := context.WithTimeoutCause(ctx, timeout, fmt.Errorf("generation timed out after %v", timeout))
+	defer cancel()
+
+	pipeline := workflow.NewPipeline()
+	pipeline.OnProgress(func(p workflow.Progress) {
+		g.State = p.State
+		g.StatusCode = p.StatusCode
+		g.Steps = p.Steps
+		g.Progress = p.Percent
+		save(g)
+	})
+
+	draft, err := execute(ctx, pipeline, g.Request)
+	if err == nil && ctx.Err() != nil {
+		err = context.Cause(ctx)
+	}
+	<-slots // webhook delivery does not hold a worker slot
+	finish(&g, draft, err)
+}
+
+// execute runs the pipeline, converting panics into errors
+func execute(ctx context.Context, pipeline *workflow.Pipeline, req content.ContentRequest) (draft *workflow.DraftAI, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("pipeline panicked: %v", r)
+		}
+	}()
+	return pipeline.Execute(ctx, req)
+}
+
+// finish stores the terminal status, records the experiment exposure and fires the webhook
+func finish(g *Generation, draft *workflow.DraftAI, err error) {
+	finished := time.Now()
+	g.FinishedAt = &finished
+
+	if err != nil {
+		log.Printf("[GENERATION] %s failed: %v", g.ID, err)
+		g.Status = StatusFailed
+		g.Error = err.Error()
+		experiment.RecordFailure(g.Experiment, experiment.ChannelGenerate, err)
+	} else {
+		log.Printf("[GENERATION] %s done: status=%s, images=%d", g.ID, draft.Status, len(draft.Images))
+		g.Status = StatusDone
+		g.Result = draft
+		g.State = state.StateStore
+		g.StatusCode = state.StatusCode(draft.Content.Status)
+		g.Steps = draft.Steps
+		g.Progress = 100
+		if g.Experiment != nil {
+			g.ExposureID = experiment.Record(g.Experiment, experiment.ChannelGenerate, g.Request, &draft.Content, draft.Content.Title)
+		}
+	}
+	save(*g)
+
+	if g.WebhookURL != "" {
+		deliverWebhook(g)
+		save(*g)
+	}
+}
+
+// save writes the generation; a failed write only costs status freshness, the run continues
+func save(g Generation) {
+	g.UpdatedAt = time.Now()
+	if err := getStore().Update(g); err != nil {
+		log.Printf("[GENERATION] Failed to update %s: %v", g.ID, err)
+	}
+}
+
+// Drain waits for queued and running generations to finish
+// When ctx expires first, remaining runs are cancelled (ErrShuttingDown) and given a short grace period to record their status
+func Drain(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		inflight.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+	}
+
+	runningMu.Lock()
+	count := len(running)
+	for _, cancel := range running {
+		cancel(ErrShuttingDown)
+	}
+	runningMu.Unlock()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+	}
+	return fmt.Errorf("cancelled %d generation(s) still running at shutdown deadline: %w", count, ctx.Err())
+}
+
+// RecoverInterrupted marks generations left QUEUED/RUNNING by a previous process as FAILED
+// Call once at boot after SetStore, before accepting requests
+func RecoverInterrupted() {
+	n, err := getStore().FailUnfinished("interrupted by server restart", time.Now())
+	if err != nil {
+		log.Printf("[GENERATION] Failed to recover interrupted generations: %v", err)
+		return
+	}
+	if n > 0 {
+		log.Printf("[GENERATION] Marked %d interrupted generation(s) as FAILED", n)
+	}
+}
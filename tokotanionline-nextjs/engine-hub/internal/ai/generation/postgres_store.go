@@ -0,0 +1,163 @@
+package generation
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"engine-hub/internal/ai/experiment"
+	"engine-hub/internal/ai/state"
+)
+
+// PostgresStore persists generations in "EngineHubGeneration"
+type PostgresStore struct {
+	db *sql.DB
+}
+
+// NewPostgresStore creates a generation store backed by the given database
+func NewPostgresStore(db *sql.DB) *PostgresStore {
+	return &PostgresStore{db: db}
+}
+
+// jsonColumns marshals the jsonb columns; values are passed as text (lib/pq sends []byte as bytea)
+func jsonColumns(g Generation) (steps, request string, result, exp interface{}, err error) {
+	data, err := json.Marshal(g.Steps)
+	if err != nil {
+		return "", "", nil, nil, fmt.Errorf("failed to marshal generation steps: %w", err)
+	}
+	steps = string(data)
+	if data, err = json.Marshal(g.Request); err != nil {
+		return "", "", nil, nil, fmt.Errorf("failed to marshal generation request: %w", err)
+	}
+	request = string(data)
+	if g.Result != nil {
+		if data, err = json.Marshal(g.Result); err != nil {
+			return "", "", nil, nil, fmt.Errorf("failed to marshal generation result: %w", err)
+		}
+		result = string(data)
+	}
+	if g.Experiment != nil {
+		if data, err = json.Marshal(g.Experiment); err != nil {
+			return "", "", nil, nil, fmt.Errorf("failed to marshal generation experiment: %w", err)
+		}
+		exp = string(data)
+	}
+	return steps, request, result, exp, nil
+}
+
+func (p *PostgresStore) Insert(g Generation) error {
+	steps, request, result, exp, err := jsonColumns(g)
+	if err != nil {
+		return err
+	}
+	_, err = p.db.Exec(`
+		INSERT INTO "EngineHubGeneration" (id, status, state, "statusCode", progress, steps, request, result, error,
+			experiment, "exposureId", "webhookUrl", "webhookStatus", "webhookError", "createdAt", "startedAt", "finishedAt", "updatedAt")
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18)
+	`, g.ID, g.Status, string(g.State), nullString(string(g.StatusCode)), g.Progress, steps, request, result, nullString(g.Error),
+		exp, nullString(g.ExposureID), nullString(g.WebhookURL), nullString(g.WebhookStatus), nullString(g.WebhookError),
+		g.CreatedAt, g.StartedAt, g.FinishedAt, g.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to insert generation: %w", err)
+	}
+	return nil
+}
+
+func (p *PostgresStore) Update(g Generation) error {
+	steps, _, result, exp, err := jsonColumns(g)
+	if err != nil {
+		return err
+	}
+	res, err := p.db.Exec(`
+		UPDATE "EngineHubGeneration" SET status = $2, state = $3, "statusCode" = $4, progress = $5, steps = $6,
+			result = $7, error = $8, experiment = $9, "exposureId" = $10, "webhookStatus" = $11, "webhookError" = $12,
+			"startedAt" = $13, "finishedAt" = $14, "updatedAt" = $15
+		WHERE id = $1
+	`, g.ID, g.Status, string(g.State), nullString(string(g.StatusCode)), g.Progress, steps, result, nullString(g.Error),
+		exp, nullString(g.ExposureID), nullString(g.WebhookStatus), nullString(g.WebhookError),
+		g.StartedAt, g.FinishedAt, g.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to update generation: %w", err)
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (p *PostgresStore) Get(id string) (*Generation, error) {
+	var g Generation
+	var stateName, statusCode string
+	var steps, request, result, exp []byte
+	var startedAt, finishedAt sql.NullTime
+	err := p.db.QueryRow(`
+		SELECT id, status, state, COALESCE("statusCode", ''), progress, steps, request, result, COALESCE(error, ''),
+			experiment, COALESCE("exposureId", ''), COALESCE("webhookUrl", ''), COALESCE("webhookStatus", ''),
+			COALESCE("webhookError", ''), "createdAt", "startedAt", "finishedAt", "updatedAt"
+		FROM "EngineHubGeneration"
+		WHERE id = $1
+	`, id).Scan(&g.ID, &g.Status, &stateName, &statusCode, &g.Progress, &steps, &request, &result, &g.Error,
+		&exp, &g.ExposureID, &g.WebhookURL, &g.WebhookStatus, &g.WebhookError,
+		&g.CreatedAt, &startedAt, &finishedAt, &g.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query generation: %w", err)
+	}
+
+	g.State = state.State(stateName)
+	g.StatusCode = state.StatusCode(statusCode)
+	if err := json.Unmarshal(steps, &g.Steps); err != nil {
+		return nil, fmt.Errorf("failed to decode steps of generation %s: %w", id, err)
+	}
+	if err := json.Unmarshal(request, &g.Request); err != nil {
+		return nil, fmt.Errorf("failed to decode request of generation %s: %w", id, err)
+	}
+	if len(result) > 0 {
+		if err := json.Unmarshal(result, &g.Result); err != nil {
+			return nil, fmt.Errorf("failed to decode result of generation %s: %w", id, err)
+		}
+	}
+	if len(exp) > 0 {
+		g.Experiment = &experiment.Assignment{}
+		if err := json.Unmarshal(exp, g.Experiment); err != nil {
+			return nil, fmt.Errorf("failed to decode experiment of generation %s: %w", id, err)
+		}
+	}
+	if startedAt.Valid {
+		g.StartedAt = &startedAt.Time
+	}
+	if finishedAt.Valid {
+		g.FinishedAt = &finishedAt.Time
+	}
+	return &g, nil
+}
+
+func (p *PostgresStore) FailUnfinished(reason string, at time.Time) (int, error) {
+	res, err := p.db.Exec(`
+		UPDATE "EngineHubGeneration" SET status = $1, error = $2, "finishedAt" = $3, "updatedAt" = $3
+		WHERE status IN ($4, $5)
+	`, StatusFailed, reason, at, StatusQueued, StatusRunning)
+	if err != nil {
+		return 0, fmt.Errorf("failed to fail unfinished generations: %w", err)
+	}
+	n, _ := res.RowsAffected()
+	return int(n), nil
+}
+
+func (p *PostgresStore) Prune(before time.Time) (int, error) {
+	res, err := p.db.Exec(`
+		DELETE FROM "EngineHubGeneration" WHERE status IN ($1, $2) AND "createdAt" < $3
+	`, StatusDone, StatusFailed, before)
+	if err != nil {
+		return 0, fmt.Errorf("failed to prune generations: %w", err)
+	}
+	n, _ := res.RowsAffected()
+	return int(n), nil
+}
+
+func nullString(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
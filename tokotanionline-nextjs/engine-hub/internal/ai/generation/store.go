@@ -0,0 +1,142 @@
+package generation
+
+import (
+	"log"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Store persists generations (status, progress and the final DraftAI)
+// MemoryStore is the default; PostgresStore is used when a database is available
+type Store interface {
+	Insert(g Generation) error
+	Update(g Generation) error
+	Get(id string) (*Generation, error)
+	FailUnfinished(reason string, at time.Time) (int, error) // QUEUED/RUNNING → FAILED
+	Prune(before time.Time) (int, error)                     // finished generations created before
+}
+
+var (
+	storeMu sync.RWMutex
+	store   Store = NewMemoryStore()
+)
+
+// SetStore replaces the generation store (call once at boot, before generations are submitted)
+func SetStore(s Store) {
+	storeMu.Lock()
+	defer storeMu.Unlock()
+	store = s
+}
+
+func getStore() Store {
+	storeMu.RLock()
+	defer storeMu.RUnlock()
+	return store
+}
+
+// MemoryStore keeps generations in memory (lost on restart, dev mode)
+type MemoryStore struct {
+	mu          sync.Mutex
+	generations map[string]Generation
+}
+
+// NewMemoryStore creates an empty in-memory generation store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{generations: make(map[string]Generation)}
+}
+
+func (m *MemoryStore) Insert(g Generation) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.generations[g.ID] = g
+	return nil
+}
+
+func (m *MemoryStore) Update(g Generation) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.generations[g.ID]; !ok {
+		return ErrNotFound
+	}
+	m.generations[g.ID] = g
+	return nil
+}
+
+func (m *MemoryStore) Get(id string) (*Generation, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	g, ok := m.generations[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &g, nil
+}
+
+func (m *MemoryStore) FailUnfinished(reason string, at time.Time) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	n := 0
+	for id, g := range m.generations {
+		if g.Terminal() {
+			continue
+		}
+		g.Status = StatusFailed
+		g.Error = reason
+		g.FinishedAt = &at
+		g.UpdatedAt = at
+		m.generations[id] = g
+		n++
+	}
+	return n, nil
+}
+
+func (m *MemoryStore) Prune(before time.Time) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	n := 0
+	for id, g := range m.generations {
+		if g.Terminal() && g.CreatedAt.Before(before) {
+			delete(m.generations, id)
+			n++
+		}
+	}
+	return n, nil
+}
+
+// RetentionPeriod reads GENERATION_RETENTION_DAYS from env (default 7 days)
+// Results are kept only long enough for callers to fetch them
+func RetentionPeriod() time.Duration {
+	days := 7
+	if v := os.Getenv("GENERATION_RETENTION_DAYS"); v != "" {
+		if val, err := strconv.Atoi(v); err == nil && val > 0 {
+			days = val
+		}
+	}
+	return time.Duration(days) * 24 * time.Hour
+}
+
+// StartPruning deletes finished generations older than retention every interval
+// Stops when stopCh is closed
+func StartPruning(retention, interval time.Duration, stopCh <-chan struct{}) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			removed, err := getStore().Prune(time.Now().Add(-retention))
+			if err != nil {
+				log.Printf("[GENERATION] Failed to prune generations: %v", err)
+			} else if removed > 0 {
+				log.Printf("[GENERATION] Pruned %d generation(s) older than %v", removed, retention)
+			}
+
+			select {
+			case <-stopCh:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
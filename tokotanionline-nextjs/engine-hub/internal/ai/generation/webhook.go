@@ -0,0 +1,93 @@
+package generation
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+)
+
+// Webhook delivery statuses
+const (
+	WebhookPending   = "PENDING"
+	WebhookDelivered = "DELIVERED"
+	WebhookFailed    = "FAILED"
+)
+
+// webhookAttempts is the number of POSTs before delivery is marked FAILED (backoff 2s, 4s)
+const webhookAttempts = 3
+
+var webhookClient = &http.Client{Timeout: 10 * time.Second}
+
+// ValidateWebhookURL accepts absolute http(s) URLs only
+func ValidateWebhookURL(raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" || (u.Scheme != "http" && u.Scheme != "https") {
+		return fmt.Errorf("webhookUrl must be an absolute http(s) URL")
+	}
+	return nil
+}
+
+// deliverWebhook POSTs the finished generation (status view, without the draft) to its webhook URL
+// With GENERATION_WEBHOOK_SECRET set, the body is signed: X-Engine-Signature: sha256=<hex HMAC of body>
+func deliverWebhook(g *Generation) {
+	body, err := json.Marshal(map[string]interface{}{
+		"event":      "generation.finished",
+		"generation": g,
+	})
+	if err != nil {
+		g.WebhookStatus = WebhookFailed
+		g.WebhookError = fmt.Sprintf("failed to marshal webhook payload: %v", err)
+		return
+	}
+
+	var lastErr error
+	for attempt := 1; attempt <= webhookAttempts; attempt++ {
+		if attempt > 1 {
+			time.Sleep(time.Duration(1<<(attempt-1)) * time.Second)
+		}
+		if lastErr = postWebhook(g.WebhookURL, body); lastErr == nil {
+			log.Printf("[GENERATION] Webhook delivered for %s (attempt %d)", g.ID, attempt)
+			g.WebhookStatus = WebhookDelivered
+			g.WebhookError = ""
+			return
+		}
+		log.Printf("[GENERATION] Webhook attempt %d/%d for %s failed: %v", attempt, webhookAttempts, g.ID, lastErr)
+	}
+	g.WebhookStatus = WebhookFailed
+	g.WebhookError = lastErr.Error()
+}
+
+func postWebhook(target string, body []byte) error {
+	req, err := http.NewRequest(http.MethodPost, target, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Engine-Event", "generation.finished")
+	if secret := os.Getenv("GENERATION_WEBHOOK_SECRET"); secret != "" {
+		mac := hmac.New(sha256.New, []byte(secret))
+		mac.Write(body)
+		req.Header.Set("X-Engine-Signature", "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	}
+
+	resp, err := webhookClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("webhook request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned status %d", resp.StatusCode)
+	}
+	return nil
+}
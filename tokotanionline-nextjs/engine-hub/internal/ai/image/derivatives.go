@@ -0,0 +1,258 @@
+package image
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"image"
+	"image/draw"
+	_ "image/jpeg" // decoder registration
+	_ "image/png"  // decoder registration
+	"log"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	xdraw "golang.org/x/image/draw"
+	_ "golang.org/x/image/webp" // decoder registration
+)
+
+// RESPONSIVE DERIVATIVES
+// Setiap gambar yang disimpan di-decode lalu di-encode ulang per lebar (default 480/768/1200):
+// - format WebP (build cgo) atau JPEG (build tanpa cgo), quality IMAGE_VARIANT_QUALITY
+// - metadata (EXIF/ICC/teks PNG) hilang karena hanya piksel yang di-encode ulang; orientasi EXIF JPEG diterapkan dulu
+// - tidak pernah upscale: lebar > lebar asli diganti lebar asli
+// - placeholder LQIP: versi 16px sebagai data URI (di-blur oleh browser saat di-scale)
+// File asli dari image API tidak disimpan; LocalPath = varian terbesar
+// Setiap varian disimpan lewat objectstore dengan key hash isi (storage.go)
+
+// ImageVariant is one responsive width of a stored image (srcset entry)
+type ImageVariant struct {
+	Width  int    `json:"width"`
+	Height int    `json:"height"`
+	Path   string `json:"path"`   // relative to public folder, like ImageAsset.LocalPath
+	URL    string `json:"url"`    // public URL (object store)
+	Format string `json:"format"` // webp | jpeg
+	Bytes  int    `json:"bytes"`
+}
+
+// SavedImage is what Storage wrote for one downloaded image
+type SavedImage struct {
+	Path        string         // served file (largest variant, or the original when variants are off)
+	URL         string         // public URL of Path
+	Width       int            // pixel size of Path (0 when the image could not be decoded)
+	Height      int            //
+	Variants    []ImageVariant // smallest first; empty when variants are off or decoding failed
+	Placeholder string         // LQIP data URI
+}
+
+// variantConfig controls derivative generation (env, see loadVariantConfig)
+type variantConfig struct {
+	Enabled bool
+	Widths  []int // ascending
+	Quality int   // 1-100
+}
+
+const placeholderWidth = 16
+
+// loadVariantConfig reads IMAGE_VARIANTS (off = save originals as-is), IMAGE_VARIANT_WIDTHS, IMAGE_VARIANT_QUALITY
+func loadVariantConfig() variantConfig {
+	cfg := variantConfig{Enabled: true, Widths: []int{480, 768, 1200}, Quality: 75}
+
+	if v := strings.ToLower(os.Getenv("IMAGE_VARIANTS")); v == "off" || v == "false" || v == "0" {
+		cfg.Enabled = false
+	}
+	if v := os.Getenv("IMAGE_VARIANT_WIDTHS"); v != "" {
+		var widths []int
+		for _, part := range strings.Split(v, ",") {
+			if w, err := strconv.Atoi(strings.TrimSpace(part)); err == nil && w > 0 {
+				widths = append(widths, w)
+			}
+		}
+		if len(widths) > 0 {
+			sort.Ints(widths)
+			cfg.Widths = widths
+		} else {
+			log.Printf("[IMAGE STORAGE] WARNING: invalid IMAGE_VARIANT_WIDTHS=%q, using defaults", v)
+		}
+	}
+	if v := os.Getenv("IMAGE_VARIANT_QUALITY"); v != "" {
+		if q, err := strconv.Atoi(v); err == nil && q >= 1 && q <= 100 {
+			cfg.Quality = q
+		}
+	}
+	return cfg
+}
+
+// encodedVariant is one encoded width before it is written
+type encodedVariant struct {
+	Width, Height int
+	Data          []byte
+}
+
+// derivativeSet is the result of processing one image
+type derivativeSet struct {
+	Format      string // webp | jpeg
+	Ext         string // .webp | .jpg
+	Variants    []encodedVariant
+	Placeholder string
+}
+
+// buildDerivatives decodes data and encodes every configured width plus the placeholder
+func buildDerivatives(data []byte, cfg variantConfig) (*derivativeSet, error) {
+	src, format, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode image: %w", err)
+	}
+	if format == "jpeg" {
+		src = applyOrientation(src, jpegOrientation(data))
+	}
+
+	bounds := src.Bounds()
+	if bounds.Dx() == 0 || bounds.Dy() == 0 {
+		return nil, fmt.Errorf("failed to decode image: empty %s", format)
+	}
+
+	set := &derivativeSet{Format: variantFormat, Ext: variantExt}
+	for _, width := range variantWidths(bounds.Dx(), cfg.Widths) {
+		resized := resize(src, width)
+		var buf bytes.Buffer
+		if err := encodeVariant(&buf, resized, cfg.Quality); err != nil {
+			return nil, fmt.Errorf("failed to encode %dw %s: %w", width, variantFormat, err)
+		}
+		set.Variants = append(set.Variants, encodedVariant{Width: width, Height: resized.Bounds().Dy(), Data: buf.Bytes()})
+	}
+
+	var buf bytes.Buffer
+	if err := encodeVariant(&buf, resize(src, placeholderWidth), 40); err == nil {
+		set.Placeholder = "data:image/" + variantFormat + ";base64," + base64.StdEncoding.EncodeToString(buf.Bytes())
+	}
+	return set, nil
+}
+
+// variantWidths returns the configured widths narrower than the source, plus the source width
+// when it is below the largest configured width (no upscaling, no duplicate)
+func variantWidths(srcWidth int, widths []int) []int {
+	var out []int
+	for _, w := range widths {
+		if w < srcWidth {
+			out = append(out, w)
+		}
+	}
+	if srcWidth <= widths[len(widths)-1] {
+		out = append(out, srcWidth)
+	}
+	return out
+}
+
+// resize scales src to width, keeping the aspect ratio
+func resize(src image.Image, width int) image.Image {
+	b := src.Bounds()
+	height := b.Dy() * width / b.Dx()
+	if height < 1 {
+		height = 1
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	if width == b.Dx() && height == b.Dy() {
+		draw.Draw(dst, dst.Bounds(), src, b.Min, draw.Src)
+		return dst
+	}
+	xdraw.CatmullRom.Scale(dst, dst.Bounds(), src, b, xdraw.Src, nil)
+	return dst
+}
+
+// jpegOrientation returns the EXIF orientation tag (1-8) of a JPEG, 1 when absent
+func jpegOrientation(data []byte) int {
+	if len(data) < 4 || data[0] != 0xFF || data[1] != 0xD8 {
+		return 1
+	}
+	for i := 2; i+4 <= len(data); {
+		if data[i] != 0xFF {
+			return 1
+		}
+		marker := data[i+1]
+		size := int(binary.BigEndian.Uint16(data[i+2:]))
+		if marker == 0xDA || size < 2 || i+2+size > len(data) {
+			return 1 // start of scan: no more metadata segments
+		}
+		segment := data[i+4 : i+2+size]
+		if marker == 0xE1 && len(segment) > 6 && string(segment[:6]) == "Exif\x00\x00" {
+			return exifOrientation(segment[6:])
+		}
+		i += 2 + size
+	}
+	return 1
+}
+
+// exifOrientation reads tag 0x0112 from IFD0 of a TIFF block
+func exifOrientation(tiff []byte) int {
+	if len(tiff) < 8 {
+		return 1
+	}
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 1
+	}
+	ifd := int(order.Uint32(tiff[4:]))
+	if ifd+2 > len(tiff) {
+		return 1
+	}
+	count := int(order.Uint16(tiff[ifd:]))
+	for n := 0; n < count; n++ {
+		entry := ifd + 2 + n*12
+		if entry+12 > len(tiff) {
+			return 1
+		}
+		if order.Uint16(tiff[entry:]) == 0x0112 {
+			if v := int(order.Uint16(tiff[entry+8:])); v >= 1 && v <= 8 {
+				return v
+			}
+			return 1
+		}
+	}
+	return 1
+}
+
+// applyOrientation rotates/flips src so orientation 1 is upright (EXIF is dropped on re-encode)
+func applyOrientation(src image.Image, orientation int) image.Image {
+	if orientation <= 1 || orientation > 8 {
+		return src
+	}
+	b := src.Bounds()
+	w, h := b.Dx(), b.Dy()
+	dw, dh := w, h
+	if orientation >= 5 {
+		dw, dh = h, w
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			var dx, dy int
+			switch orientation {
+			case 2: // mirrored
+				dx, dy = w-1-x, y
+			case 3: // rotated 180
+				dx, dy = w-1-x, h-1-y
+			case 4: // mirrored vertically
+				dx, dy = x, h-1-y
+			case 5: // mirrored + rotated 270 CW
+				dx, dy = y, x
+			case 6: // rotated 90 CW
+				dx, dy = h-1-y, x
+			case 7: // mirrored + rotated 90 CW
+				dx, dy = h-1-y, w-1-x
+			case 8: // rotated 270 CW
+				dx, dy = y, w-1-x
+			}
+			dst.Set(dx, dy, src.At(b.Min.X+x, b.Min.Y+y))
+		}
+	}
+	return dst
+}
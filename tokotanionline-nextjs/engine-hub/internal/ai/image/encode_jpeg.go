@@ -0,0 +1,25 @@
+//go:build !cgo
+
+package image
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"image/jpeg"
+	"io"
+)
+
+// Without cgo there is no WebP encoder: variants are JPEG (same widths, same quality setting)
+const (
+	variantFormat = "jpeg"
+	variantExt    = ".jpg"
+)
+
+func encodeVariant(w io.Writer, img image.Image, quality int) error {
+	// JPEG has no alpha: flatten onto white so transparent areas don't turn black
+	flat := image.NewRGBA(img.Bounds())
+	draw.Draw(flat, flat.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	draw.Draw(flat, flat.Bounds(), img, img.Bounds().Min, draw.Over)
+	return jpeg.Encode(w, flat, &jpeg.Options{Quality: quality})
+}
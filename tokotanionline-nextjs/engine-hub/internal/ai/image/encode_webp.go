@@ -0,0 +1,20 @@
+//go:build cgo
+
+package image
+
+import (
+	"image"
+	"io"
+
+	"github.com/chai2010/webp"
+)
+
+// Variants are lossy WebP when built with cgo (libwebp, bundled by github.com/chai2010/webp)
+const (
+	variantFormat = "webp"
+	variantExt    = ".webp"
+)
+
+func encodeVariant(w io.Writer, img image.Image, quality int) error {
+	return webp.Encode(w, img, &webp.Options{Quality: float32(quality)})
+}
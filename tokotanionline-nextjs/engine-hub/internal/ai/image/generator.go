@@ -2,14 +2,19 @@ package image
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
+	"html"
 	"io"
 	"log"
 	"net/http"
 	"os"
 	"strings"
 	"time"
+
+	"engine-hub/internal/ai/cassette"
+	"engine-hub/internal/ai/usage"
 )
 
 // ImageAsset represents a generated image associated with a content section
@@ -22,6 +27,71 @@ type ImageAsset struct {
 	Heading    string `json:"heading"`   // Section heading for context
 	IsHero     bool   `json:"isHero"`     // True if this is the hero image (first image)
 	Role       string `json:"role"`       // M-04: "hero" or "section" - explicit role for structured placement
+	PublicURL  string `json:"publicUrl,omitempty"` // LocalPath on the object store's public base URL (IMAGE_PUBLIC_BASE_URL)
+	// Responsive derivatives (derivatives.go): LocalPath is the largest variant
+	Width       int            `json:"width,omitempty"`
+	Height      int            `json:"height,omitempty"`
+	Variants    []ImageVariant `json:"variants,omitempty"`    // srcset widths, smallest first
+	Placeholder string         `json:"placeholder,omitempty"` // LQIP data URI (blur-up)
+	Source      string         `json:"source,omitempty"`      // "library" when taken from the image library (empty = image API)
+	LibraryID   string         `json:"libraryId,omitempty"`   // LibraryImage.ID
+}
+
+// withSaved copies the stored file, dimensions and variants into the asset (nil = not saved)
+func (a ImageAsset) withSaved(saved *SavedImage) ImageAsset {
+	if saved == nil {
+		return a
+	}
+	a.LocalPath = saved.Path
+	a.PublicURL = saved.URL
+	a.Width, a.Height = saved.Width, saved.Height
+	a.Variants = saved.Variants
+	a.Placeholder = saved.Placeholder
+	return a
+}
+
+// Src returns the URL to reference the image with (public URL, LocalPath for assets saved before the object store)
+func (a ImageAsset) Src() string {
+	if a.PublicURL != "" {
+		return a.PublicURL
+	}
+	return a.LocalPath
+}
+
+// SrcSet returns the srcset attribute value ("/images/.../3f9c...webp 480w, ..."), empty without variants
+func (a ImageAsset) SrcSet() string {
+	parts := make([]string, 0, len(a.Variants))
+	for _, v := range a.Variants {
+		src := v.URL
+		if src == "" {
+			src = v.Path
+		}
+		parts = append(parts, fmt.Sprintf("%s %dw", src, v.Width))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// ContentSizes is the sizes attribute for images inside article content (content column max 768px)
+const ContentSizes = "(max-width: 768px) 100vw, 768px"
+
+// ContentReference renders the image for article content
+// With variants: <img> with srcset, dimensions (no layout shift), lazy loading and the LQIP as blurred background
+// Without variants (IMAGE_VARIANTS=off, undecodable image): plain markdown image
+func (a ImageAsset) ContentReference() string {
+	if len(a.Variants) == 0 {
+		return fmt.Sprintf("![%s](%s)", a.AltText, a.Src())
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, `<img src="%s" srcset="%s" sizes="%s"`, html.EscapeString(a.Src()), html.EscapeString(a.SrcSet()), ContentSizes)
+	if a.Width > 0 && a.Height > 0 {
+		fmt.Fprintf(&b, ` width="%d" height="%d"`, a.Width, a.Height)
+	}
+	fmt.Fprintf(&b, ` alt="%s" loading="lazy" decoding="async"`, html.EscapeString(a.AltText))
+	if a.Placeholder != "" {
+		fmt.Fprintf(&b, ` style="background-size:cover;background-image:url(%s)"`, a.Placeholder)
+	}
+	b.WriteString(">")
+	return b.String()
 }
 
 // Generator handles AI image generation
@@ -32,6 +102,9 @@ type Generator struct {
 	model       string
 	imageSize   string
 	storage     *Storage
+	quality     qualityConfig // post-download quality gate (IMAGE_QC_*)
+	source      string        // IMAGE_SOURCE: ai (library as fallback) | library
+	fallback    bool          // IMAGE_LIBRARY_FALLBACK: fill failed sections from the image library
 }
 
 // NewGenerator creates a new image generator
@@ -63,13 +136,17 @@ func NewGenerator() *Generator {
 
 	return &Generator{
 		client: &http.Client{
-			Timeout: 90 * time.Second, // 90 second timeout for image generation
+			Timeout:   90 * time.Second, // 90 second timeout for image generation
+			Transport: cassette.Default(), // record/replay via AI_CASSETTE_MODE
 		},
 		apiKey:    apiKey,
 		apiURL:    apiURL,
 		model:     model,
 		imageSize: size,
 		storage:   NewStorage(),
+		quality:   loadQualityConfig(),
+		source:    imageSource(),
+		fallback:  libraryFallbackEnabled(),
 	}
 }
 
@@ -263,11 +340,23 @@ func min(a, b int) int {
 
 // GenerateImages creates images for all relevant sections in the content
 // FASE C - C3: IMAGE GENERATION FLOW (DIKUNCI)
-// Flow: [CONTENT FINAL] → Extract image context → Generate via OpenAI → Download → Local save → Metadata → Relate
+// Flow: [CONTENT FINAL] → Extract image context → Generate via OpenAI → Download → Quality gate → Local save → Metadata → Relate
 // articleSlug is used to create the folder structure for local storage
-func (g *Generator) GenerateImages(body string, articleSlug string) ([]ImageAsset, error) {
-	if g.apiKey == "" {
-		return nil, fmt.Errorf("IMAGE_API_KEY or OPENAI_API_KEY environment variable not set")
+func (g *Generator) GenerateImages(ctx context.Context, body string, articleSlug string) ([]ImageAsset, error) {
+	images, _, err := g.GenerateImagesWithReport(ctx, body, articleSlug)
+	return images, err
+}
+
+// GenerateImagesWithReport is GenerateImages plus the quality gate report (accepted/rejected/retried per section)
+func (g *Generator) GenerateImagesWithReport(ctx context.Context, body string, articleSlug string) ([]ImageAsset, *QualityReport, error) {
+	report := &QualityReport{Enabled: g.quality.Enabled}
+	libraryOnly := g.source == SourceLibrary
+	if g.apiKey == "" && !libraryOnly {
+		if !g.fallback || !libraryAvailable() {
+			return nil, report, fmt.Errorf("IMAGE_API_KEY or OPENAI_API_KEY environment variable not set")
+		}
+		log.Println("[IMAGE GEN] No image API key, using the image library only")
+		libraryOnly = true
 	}
 
 	// Extract headings (H2 and H3) from body
@@ -275,10 +364,12 @@ func (g *Generator) GenerateImages(body string, articleSlug string) ([]ImageAsse
 
 	if len(sections) == 0 {
 		log.Println("[IMAGE GEN] No sections found in content, skipping image generation")
-		return []ImageAsset{}, nil
+		return []ImageAsset{}, report, nil
 	}
 
 	var images []ImageAsset
+	var seen []uint64 // pHashes accepted for this article (duplicate check)
+	usedLibrary := make(map[string]bool) // image library IDs already placed in this article
 
 	// M-04: Generate 3-5 images: 1 hero + 2-4 section images
 	// Minimum 3 images required (hero + 2 sections)
@@ -293,23 +384,7 @@ func (g *Generator) GenerateImages(body string, articleSlug string) ([]ImageAsse
 			break
 		}
 
-		// M-04: Step 1 - Extract image context (topik, objek, aktivitas)
-		// Generate prompt (extracts {OBJEK}, {KONTEKS}, {AKTIVITAS} from content)
-		prompt := g.GeneratePrompt(section.Type, section.Heading, section.Content)
-
-		// M-04: Step 2 - Generate image via OpenAI with quality filter
-		imageURL, err := g.callImageAPI(prompt)
-		if err != nil {
-			log.Printf("[IMAGE GEN] Failed to generate image for section '%s': %v", section.Heading, err)
-			// Continue with other sections even if one fails
-			continue
-		}
-
-		// M-04: Step 3 - Quality filter (reject fantasy/AI art, prioritize realistic)
-		// Note: Quality check happens at prompt level (realistic prompt enforced)
-		// Additional validation can be added here if needed
-
-		// M-04: Step 4 - Determine role (hero or section)
+		// M-04: Step 1 - Determine role (hero or section)
 		var role string
 		var isHero bool
 		if imageCount == 0 {
@@ -320,33 +395,62 @@ func (g *Generator) GenerateImages(body string, articleSlug string) ([]ImageAsse
 			isHero = false
 		}
 
-		// M-04: Step 5 - Generate natural filename
-		filename := generateNaturalFilename(section.Heading, section.Content, imageCount)
+		if libraryOnly {
+			if g.useLibrary(section, articleSlug, role, usedLibrary, &images, report) {
+				imageCount++
+			}
+			continue
+		}
 
-		// M-04: Step 6 - Download and save to local storage
-		localPath, err := g.storage.DownloadAndSave(imageURL, articleSlug, filename)
+		// M-04: Step 2 - Extract image context (topik, objek, aktivitas)
+		// Generate prompt (extracts {OBJEK}, {KONTEKS}, {AKTIVITAS} from content)
+		prompt := g.GeneratePrompt(section.Type, section.Heading, section.Content)
+
+		// M-04: Step 3 - Generate image via OpenAI, download and run the quality gate
+		// (decode, resolution, aspect, blank, text/watermark, pHash duplicate); failed → regenerate with a varied prompt
+		q, err := g.generateQualified(ctx, "[IMAGE GEN]", section.Heading, prompt, seen, report)
 		if err != nil {
-			log.Printf("[IMAGE GEN] Failed to save image locally for section '%s': %v", section.Heading, err)
-			// Continue but mark as failed - we still have the URL
-			localPath = ""
+			log.Printf("[IMAGE GEN] Failed to generate image for section '%s': %v", section.Heading, err)
+			// Fall back to the image library; otherwise continue with other sections
+			if g.fallback && g.useLibrary(section, articleSlug, role, usedLibrary, &images, report) {
+				imageCount++
+			}
+			continue
 		}
 
-		// M-04: Step 7 - Generate SEO-safe alt text (natural, contextual)
+		// M-04: Step 4 - Generate natural filename
+		filename := generateNaturalFilename(section.Heading, section.Content, imageCount)
+
+		// M-04: Step 5 - Save to storage
+		saved := &SavedImage{}
+		if q.Data != nil {
+			saved, err = g.storage.SaveArticleImage(articleSlug, filename, q.Ext, q.Data)
+			if err != nil {
+				log.Printf("[IMAGE GEN] Failed to save image locally for section '%s': %v", section.Heading, err)
+				// Continue but mark as failed - we still have the URL
+				saved = &SavedImage{}
+			}
+		}
+		if q.Quality != nil {
+			seen = append(seen, q.Quality.Hash)
+			RegisterHash(q.Quality.Hash, saved.Path, articleSlug, role)
+		}
+
+		// M-04: Step 6 - Generate SEO-safe alt text (natural, contextual)
 		altText := g.generateAltText(section.Heading, section.Content)
 
 		images = append(images, ImageAsset{
-			Section:   section.Type + ": " + section.Heading,
-			URL:       imageURL, // Keep original URL for reference
-			LocalPath: localPath,
-			AltText:   altText,
-			Prompt:    prompt,
-			Heading:   section.Heading,
-			IsHero:    isHero,
-			Role:      role, // M-04: Explicit role for structured placement
-		})
+			Section: section.Type + ": " + section.Heading,
+			URL:     q.URL, // Keep original URL for reference
+			AltText: altText,
+			Prompt:  q.Prompt,
+			Heading: section.Heading,
+			IsHero:  isHero,
+			Role:    role, // M-04: Explicit role for structured placement
+		}.withSaved(saved))
 
 		imageCount++
-		log.Printf("[IMAGE GEN] Successfully generated image [%s] for section: %s (path: %s)", role, section.Heading, localPath)
+		log.Printf("[IMAGE GEN] Successfully generated image [%s] for section: %s (path: %s, %d variants)", role, section.Heading, saved.Path, len(saved.Variants))
 	}
 
 	// M-04: Ensure minimum 3 images (hero + 2 sections)
@@ -354,8 +458,14 @@ func (g *Generator) GenerateImages(body string, articleSlug string) ([]ImageAsse
 		log.Printf("[IMAGE GEN] WARNING: Only generated %d images (minimum %d required: 1 hero + 2 sections)", imageCount, minImages)
 		// Continue anyway - non-fatal, but log warning
 	}
+	if report.Enabled {
+		log.Printf("[IMAGE GEN] Quality gate: accepted=%d rejected=%d retries=%d abandoned=%d", report.Accepted, report.Rejected, report.Retries, len(report.Abandoned))
+	}
+	if len(report.FromLibrary) > 0 {
+		log.Printf("[IMAGE GEN] %d section(s) filled from the image library", len(report.FromLibrary))
+	}
 
-	return images, nil
+	return images, report, nil
 }
 
 // generateAltText creates descriptive alt text from heading and content
@@ -556,7 +666,26 @@ func extractKeywords(heading string, content string) []string {
 }
 
 // callImageAPI makes the actual API call to the image generation service
-func (g *Generator) callImageAPI(prompt string) (string, error) {
+// Every call is recorded in the usage ledger (1 image on success)
+func (g *Generator) callImageAPI(ctx context.Context, prompt string) (imageURL string, err error) {
+	started := time.Now()
+	defer func() {
+		entry := usage.Entry{
+			Kind:      usage.KindImage,
+			Caller:    "image",
+			Provider:  "openai-images",
+			Model:     g.model,
+			Success:   err == nil,
+			LatencyMs: time.Since(started).Milliseconds(),
+		}
+		if err == nil {
+			entry.Images = 1
+		} else {
+			entry.Error = err.Error()
+		}
+		usage.Record(ctx, entry)
+	}()
+
 	// OpenAI DALL-E format
 	requestBody := map[string]interface{}{
 		"model":  g.model,
@@ -577,7 +706,7 @@ func (g *Generator) callImageAPI(prompt string) (string, error) {
 		return "", fmt.Errorf("failed to marshal request: %w", err)
 	}
 
-	req, err := http.NewRequest("POST", g.apiURL, bytes.NewBuffer(jsonData))
+	req, err := http.NewRequestWithContext(ctx, "POST", g.apiURL, bytes.NewBuffer(jsonData))
 	if err != nil {
 		return "", fmt.Errorf("failed to create request: %w", err)
 	}
@@ -616,7 +745,7 @@ func (g *Generator) callImageAPI(prompt string) (string, error) {
 // M-05: GenerateProductImages creates product images with roles (hero, detail)
 // Generates 3-6 images: minimum 3 (1 hero + 2 detail), maximum 6
 // All images must be realistic product photos, not AI art/illustrations
-func (g *Generator) GenerateProductImages(productName string, productDescription string, productSlug string) ([]ImageAsset, error) {
+func (g *Generator) GenerateProductImages(ctx context.Context, productName string, productDescription string, productSlug string) ([]ImageAsset, error) {
 	if g.apiKey == "" {
 		return nil, fmt.Errorf("IMAGE_API_KEY or OPENAI_API_KEY environment variable not set")
 	}
@@ -628,11 +757,13 @@ func (g *Generator) GenerateProductImages(productName string, productDescription
 	maxImages := 6
 
 	var images []ImageAsset
+	report := &QualityReport{Enabled: g.quality.Enabled}
+	var seen []uint64 // pHashes accepted for this product (duplicate check)
 
-	// M-05 STEP 1: Generate hero image (tampak depan produk)
+	// M-05 STEP 1: Generate hero image (tampak depan produk), downloaded bytes go through the quality gate
 	log.Printf("[PRODUCT IMAGE GEN] Generating hero image for product: %s", productName)
 	heroPrompt := g.generateProductPrompt(productName, productDescription, "hero", "foto produk nyata tampak depan")
-	heroURL, err := g.callImageAPI(heroPrompt)
+	heroQ, err := g.generateQualified(ctx, "[PRODUCT IMAGE GEN]", "hero", heroPrompt, seen, report)
 	if err != nil {
 		log.Printf("[PRODUCT IMAGE GEN] Failed to generate hero image: %v", err)
 		return nil, fmt.Errorf("hero image generation failed: %w", err)
@@ -646,26 +777,21 @@ func (g *Generator) GenerateProductImages(productName string, productDescription
 
 	// M-05 STEP 3: Save hero image
 	heroFilename := fmt.Sprintf("%s-hero.jpg", productSlug)
-	heroPath, err := g.storage.DownloadAndSaveProduct(heroURL, productSlug, heroFilename)
-	if err != nil {
-		log.Printf("[PRODUCT IMAGE GEN] Failed to save hero image: %v", err)
-		heroPath = "" // Continue without local path
-	}
+	heroSaved := g.saveQualifiedProduct(heroQ, productSlug, heroFilename, "hero", &seen)
 
 	// M-05 STEP 4: Generate SEO alt text for hero
 	heroAlt := fmt.Sprintf("%s - tampak depan", productName)
 
 	images = append(images, ImageAsset{
-		Section:   "hero",
-		URL:       heroURL,
-		LocalPath: heroPath,
-		AltText:   heroAlt,
-		Prompt:    heroPrompt,
-		Heading:   productName,
-		IsHero:    true,
-		Role:      "hero",
-	})
-	log.Printf("[PRODUCT IMAGE GEN] Hero image generated: %s", heroPath)
+		Section: "hero",
+		URL:     heroQ.URL,
+		AltText: heroAlt,
+		Prompt:  heroQ.Prompt,
+		Heading: productName,
+		IsHero:  true,
+		Role:    "hero",
+	}.withSaved(heroSaved))
+	log.Printf("[PRODUCT IMAGE GEN] Hero image generated: %s", heroSaved.Path)
 
 	// M-05 STEP 5: Generate detail images (2-5 more images)
 	detailQueries := []struct {
@@ -688,7 +814,7 @@ func (g *Generator) GenerateProductImages(productName string, productDescription
 
 		log.Printf("[PRODUCT IMAGE GEN] Generating detail image %d/%d: %s", i+1, len(detailQueries), detail.query)
 		detailPrompt := g.generateProductPrompt(productName, productDescription, "detail", detail.query)
-		detailURL, err := g.callImageAPI(detailPrompt)
+		detailQ, err := g.generateQualified(ctx, "[PRODUCT IMAGE GEN]", fmt.Sprintf("detail-%d", i+1), detailPrompt, seen, report)
 		if err != nil {
 			log.Printf("[PRODUCT IMAGE GEN] Failed to generate detail image %d: %v", i+1, err)
 			continue // Continue with next detail image
@@ -701,24 +827,19 @@ func (g *Generator) GenerateProductImages(productName string, productDescription
 
 		// M-05 STEP 3: Save detail image
 		detailFilename := fmt.Sprintf("%s-detail-%d.jpg", productSlug, i+1)
-		detailPath, err := g.storage.DownloadAndSaveProduct(detailURL, productSlug, detailFilename)
-		if err != nil {
-			log.Printf("[PRODUCT IMAGE GEN] Failed to save detail image %d: %v", i+1, err)
-			detailPath = "" // Continue without local path
-		}
+		detailSaved := g.saveQualifiedProduct(detailQ, productSlug, detailFilename, "detail", &seen)
 
 		images = append(images, ImageAsset{
-			Section:   fmt.Sprintf("detail-%d", i+1),
-			URL:       detailURL,
-			LocalPath: detailPath,
-			AltText:   detail.alt,
-			Prompt:    detailPrompt,
-			Heading:   productName,
-			IsHero:    false,
-			Role:      "detail",
-		})
+			Section: fmt.Sprintf("detail-%d", i+1),
+			URL:     detailQ.URL,
+			AltText: detail.alt,
+			Prompt:  detailQ.Prompt,
+			Heading: productName,
+			IsHero:  false,
+			Role:    "detail",
+		}.withSaved(detailSaved))
 		detailCount++
-		log.Printf("[PRODUCT IMAGE GEN] Detail image %d generated: %s", i+1, detailPath)
+		log.Printf("[PRODUCT IMAGE GEN] Detail image %d generated: %s", i+1, detailSaved.Path)
 	}
 
 	// M-05: Ensure minimum images requirement
@@ -729,35 +850,53 @@ func (g *Generator) GenerateProductImages(productName string, productDescription
 		for i := 0; i < needed && len(images) < maxImages; i++ {
 			log.Printf("[PRODUCT IMAGE GEN] Generating additional detail image to meet minimum requirement")
 			detailPrompt := g.generateProductPrompt(productName, productDescription, "detail", "foto produk tambahan")
-			detailURL, err := g.callImageAPI(detailPrompt)
+			detailQ, err := g.generateQualified(ctx, "[PRODUCT IMAGE GEN]", fmt.Sprintf("detail-extra-%d", i+1), detailPrompt, seen, report)
 			if err != nil {
 				log.Printf("[PRODUCT IMAGE GEN] Failed to generate additional detail image: %v", err)
 				continue
 			}
 
 			detailFilename := fmt.Sprintf("%s-detail-extra-%d.jpg", productSlug, i+1)
-			detailPath, err := g.storage.DownloadAndSaveProduct(detailURL, productSlug, detailFilename)
-			if err != nil {
-				detailPath = ""
-			}
+			detailSaved := g.saveQualifiedProduct(detailQ, productSlug, detailFilename, "detail", &seen)
 
 			images = append(images, ImageAsset{
-				Section:   fmt.Sprintf("detail-extra-%d", i+1),
-				URL:       detailURL,
-				LocalPath: detailPath,
-				AltText:   fmt.Sprintf("Detail tambahan %s", productName),
-				Prompt:    detailPrompt,
-				Heading:   productName,
-				IsHero:    false,
-				Role:      "detail",
-			})
+				Section: fmt.Sprintf("detail-extra-%d", i+1),
+				URL:     detailQ.URL,
+				AltText: fmt.Sprintf("Detail tambahan %s", productName),
+				Prompt:  detailQ.Prompt,
+				Heading: productName,
+				IsHero:  false,
+				Role:    "detail",
+			}.withSaved(detailSaved))
 		}
 	}
 
+	if report.Enabled {
+		log.Printf("[PRODUCT IMAGE GEN] Quality gate: accepted=%d rejected=%d retries=%d abandoned=%d", report.Accepted, report.Rejected, report.Retries, len(report.Abandoned))
+	}
 	log.Printf("[PRODUCT IMAGE GEN] Successfully generated %d images (1 hero + %d detail)", len(images), len(images)-1)
 	return images, nil
 }
 
+// saveQualifiedProduct stores a product image that passed the quality gate and adds it to the hash index
+// Returns an empty SavedImage when there is nothing to store (download or save failed)
+func (g *Generator) saveQualifiedProduct(q *qualifiedImage, productSlug, filename, role string, seen *[]uint64) *SavedImage {
+	saved := &SavedImage{}
+	if q.Data != nil {
+		var err error
+		saved, err = g.storage.SaveProductImage(productSlug, filename, q.Ext, q.Data)
+		if err != nil {
+			log.Printf("[PRODUCT IMAGE GEN] Failed to save %s image %s: %v", role, filename, err)
+			saved = &SavedImage{} // Continue without local path
+		}
+	}
+	if q.Quality != nil {
+		*seen = append(*seen, q.Quality.Hash)
+		RegisterHash(q.Quality.Hash, saved.Path, productSlug, role)
+	}
+	return saved
+}
+
 // M-05: generateProductPrompt creates product-specific image generation prompt
 // Enforces realistic product photos, not AI art/illustrations
 func (g *Generator) generateProductPrompt(productName string, description string, role string, query string) string {
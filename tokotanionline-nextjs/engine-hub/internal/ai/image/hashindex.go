@@ -0,0 +1,171 @@
+package image
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+)
+
+// HASH INDEX (library gambar)
+// pHash setiap gambar yang lolos quality gate dicatat (path, artikel/produk, role)
+// Gambar baru yang terlalu mirip dengan isi index ditolak (CheckDuplicateLibrary)
+// MemoryHashStore default; PostgresHashStore ("EngineHubImageHash") jika database tersedia
+// Index di-load sekali ke memori lalu ditambah saat gambar baru disimpan
+
+// HashEntry is one indexed image
+type HashEntry struct {
+	Hash      uint64    `json:"-"`
+	Hex       string    `json:"phash"`
+	Path      string    `json:"path"`   // /images/... (largest variant)
+	Source    string    `json:"source"` // article or product slug
+	Role      string    `json:"role"`   // hero | section | detail
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// HashStore persists the hash index
+type HashStore interface {
+	AddHash(e HashEntry) error
+	ListHashes() ([]HashEntry, error)
+}
+
+// MemoryHashStore keeps the index in memory (lost on restart, dev mode)
+type MemoryHashStore struct {
+	mu      sync.Mutex
+	entries []HashEntry
+}
+
+// NewMemoryHashStore creates an empty in-memory hash store
+func NewMemoryHashStore() *MemoryHashStore {
+	return &MemoryHashStore{}
+}
+
+func (m *MemoryHashStore) AddHash(e HashEntry) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.entries = append(m.entries, e)
+	return nil
+}
+
+func (m *MemoryHashStore) ListHashes() ([]HashEntry, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	out := make([]HashEntry, len(m.entries))
+	copy(out, m.entries)
+	return out, nil
+}
+
+// PostgresHashStore persists the index in "EngineHubImageHash"
+type PostgresHashStore struct {
+	db *sql.DB
+}
+
+// NewPostgresHashStore creates a hash store backed by the given database
+func NewPostgresHashStore(db *sql.DB) *PostgresHashStore {
+	return &PostgresHashStore{db: db}
+}
+
+func (p *PostgresHashStore) AddHash(e HashEntry) error {
+	_, err := p.db.Exec(`
+		INSERT INTO "EngineHubImageHash" (path, hash, source, role, "createdAt")
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (path) DO UPDATE SET hash = EXCLUDED.hash, source = EXCLUDED.source, role = EXCLUDED.role
+	`, e.Path, int64(e.Hash), e.Source, e.Role, e.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to insert image hash: %w", err)
+	}
+	return nil
+}
+
+func (p *PostgresHashStore) ListHashes() ([]HashEntry, error) {
+	rows, err := p.db.Query(`SELECT path, hash, source, role, "createdAt" FROM "EngineHubImageHash" ORDER BY "createdAt"`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list image hashes: %w", err)
+	}
+	defer rows.Close()
+
+	var out []HashEntry
+	for rows.Next() {
+		var e HashEntry
+		var hash int64
+		if err := rows.Scan(&e.Path, &hash, &e.Source, &e.Role, &e.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan image hash: %w", err)
+		}
+		e.Hash = uint64(hash)
+		e.Hex = fmt.Sprintf("%016x", e.Hash)
+		out = append(out, e)
+	}
+	return out, rows.Err()
+}
+
+var (
+	hashMu     sync.Mutex
+	hashStore  HashStore = NewMemoryHashStore()
+	hashCache  []HashEntry
+	hashLoaded bool
+)
+
+// SetHashStore replaces the hash index store (call once at boot)
+func SetHashStore(s HashStore) {
+	hashMu.Lock()
+	defer hashMu.Unlock()
+	hashStore = s
+	hashCache = nil
+	hashLoaded = false
+}
+
+// loadHashesLocked fills the cache on first use (hashMu held)
+func loadHashesLocked() {
+	if hashLoaded {
+		return
+	}
+	entries, err := hashStore.ListHashes()
+	if err != nil {
+		log.Printf("[IMAGE QC] WARNING: failed to load hash index: %v (library duplicate check skipped until next load)", err)
+		return
+	}
+	hashCache = entries
+	hashLoaded = true
+	log.Printf("[IMAGE QC] Hash index loaded: %d images", len(entries))
+}
+
+// nearestInLibrary returns the closest indexed image to hash
+func nearestInLibrary(hash uint64) (HashEntry, int, bool) {
+	hashMu.Lock()
+	defer hashMu.Unlock()
+	loadHashesLocked()
+
+	var best HashEntry
+	bestDist, found := 65, false
+	for _, e := range hashCache {
+		if d := HammingDistance(hash, e.Hash); d < bestDist {
+			best, bestDist, found = e, d, true
+		}
+	}
+	return best, bestDist, found
+}
+
+// RegisterHash adds an accepted image to the hash index
+func RegisterHash(hash uint64, path, source, role string) {
+	if path == "" {
+		return
+	}
+	e := HashEntry{Hash: hash, Hex: fmt.Sprintf("%016x", hash), Path: path, Source: source, Role: role, CreatedAt: time.Now().UTC()}
+
+	hashMu.Lock()
+	defer hashMu.Unlock()
+	if err := hashStore.AddHash(e); err != nil {
+		log.Printf("[IMAGE QC] WARNING: %v", err)
+		return
+	}
+	if hashLoaded {
+		for i := range hashCache {
+			if hashCache[i].Path == path {
+				hashCache[i] = e
+				return
+			}
+		}
+		hashCache = append(hashCache, e)
+	}
+}
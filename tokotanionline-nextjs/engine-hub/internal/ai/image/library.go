@@ -0,0 +1,506 @@
+package image
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/google/uuid"
+)
+
+// IMAGE LIBRARY (fallback lokal)
+// Foto kurasi (diunggah admin) dengan tag & keyword (crop, pest, fertilizer, tool, ...)
+// Dipakai di step image artikel jika image API gagal, gambar ditolak quality gate, atau generate AI dimatikan:
+// - cocokkan token heading section dengan tag/keyword (overlap terbanyak menang, sinonim kategori id/en)
+// - tie-break: paling jarang dipakai, lalu paling lama tidak dipakai (usage tracking)
+// - foto yang sudah dipakai IMAGE_LIBRARY_MAX_USES kali tidak dipilih lagi; satu foto sekali per artikel
+// Produk tidak memakai library: foto produk harus foto produk itu sendiri
+
+// Image sources (IMAGE_SOURCE)
+const (
+	SourceAI      = "ai"      // image API, library as fallback (default)
+	SourceLibrary = "library" // library only, no image API calls
+)
+
+var (
+	ErrLibraryNotFound = errors.New("library image not found")
+	ErrLibraryInvalid  = errors.New("invalid library image")
+)
+
+// LibraryImage is one curated photo
+type LibraryImage struct {
+	ID          string         `json:"id"`
+	Path        string         `json:"path"` // /images/library/... (largest variant)
+	URL         string         `json:"url"`  // public URL of Path
+	Width       int            `json:"width,omitempty"`
+	Height      int            `json:"height,omitempty"`
+	Variants    []ImageVariant `json:"variants,omitempty"`
+	Placeholder string         `json:"placeholder,omitempty"`
+	AltText     string         `json:"altText,omitempty"` // describes the photo; empty = derived from the section heading
+	Tags        []string       `json:"tags"`              // categories: crop, pest, fertilizer, tool, cabai, ...
+	Keywords    []string       `json:"keywords,omitempty"`
+	Source      string         `json:"source,omitempty"` // photographer / credit / uploader
+	Disabled    bool           `json:"disabled,omitempty"`
+	UsageCount  int            `json:"usageCount"`
+	LastUsedAt  *time.Time     `json:"lastUsedAt,omitempty"`
+	LastUsedIn  string         `json:"lastUsedIn,omitempty"` // article slug
+	CreatedAt   time.Time      `json:"createdAt"`
+	UpdatedAt   time.Time      `json:"updatedAt"`
+}
+
+// LibraryMeta is the admin-editable part of a library image (nil = unchanged on update)
+type LibraryMeta struct {
+	Tags     []string `json:"tags,omitempty"`
+	Keywords []string `json:"keywords,omitempty"`
+	AltText  *string  `json:"altText,omitempty"`
+	Source   *string  `json:"source,omitempty"`
+	Disabled *bool    `json:"disabled,omitempty"`
+}
+
+// LibraryMatch is a library image scored against a heading
+type LibraryMatch struct {
+	Image   LibraryImage `json:"image"`
+	Score   int          `json:"score"`   // distinct tag/keyword tokens found in the heading
+	Matched []string     `json:"matched"` // those tokens
+}
+
+// LibraryStore persists the library
+type LibraryStore interface {
+	InsertLibraryImage(img LibraryImage) error
+	UpdateLibraryImage(img LibraryImage) error
+	GetLibraryImage(id string) (*LibraryImage, error)
+	ListLibraryImages() ([]LibraryImage, error) // oldest first
+	MarkLibraryUsed(id, slug string, at time.Time) error
+}
+
+// MemoryLibraryStore keeps the library in memory (lost on restart, dev mode)
+type MemoryLibraryStore struct {
+	mu     sync.Mutex
+	images []LibraryImage
+}
+
+// NewMemoryLibraryStore creates an empty in-memory library
+func NewMemoryLibraryStore() *MemoryLibraryStore {
+	return &MemoryLibraryStore{}
+}
+
+func (m *MemoryLibraryStore) InsertLibraryImage(img LibraryImage) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.images = append(m.images, img)
+	return nil
+}
+
+func (m *MemoryLibraryStore) UpdateLibraryImage(img LibraryImage) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for i := range m.images {
+		if m.images[i].ID == img.ID {
+			m.images[i] = img
+			return nil
+		}
+	}
+	return ErrLibraryNotFound
+}
+
+func (m *MemoryLibraryStore) GetLibraryImage(id string) (*LibraryImage, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, img := range m.images {
+		if img.ID == id {
+			return &img, nil
+		}
+	}
+	return nil, ErrLibraryNotFound
+}
+
+func (m *MemoryLibraryStore) ListLibraryImages() ([]LibraryImage, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	out := make([]LibraryImage, len(m.images))
+	copy(out, m.images)
+	return out, nil
+}
+
+func (m *MemoryLibraryStore) MarkLibraryUsed(id, slug string, at time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for i := range m.images {
+		if m.images[i].ID == id {
+			m.images[i].UsageCount++
+			m.images[i].LastUsedAt = &at
+			m.images[i].LastUsedIn = slug
+			return nil
+		}
+	}
+	return ErrLibraryNotFound
+}
+
+var (
+	libraryMu    sync.RWMutex
+	libraryStore LibraryStore = NewMemoryLibraryStore()
+	useMu        sync.Mutex   // pick + mark used as one step (two articles must not both take the last use)
+)
+
+// SetLibraryStore replaces the library store (call once at boot)
+func SetLibraryStore(s LibraryStore) {
+	libraryMu.Lock()
+	defer libraryMu.Unlock()
+	libraryStore = s
+}
+
+func getLibraryStore() LibraryStore {
+	libraryMu.RLock()
+	defer libraryMu.RUnlock()
+	return libraryStore
+}
+
+// imageSource reads IMAGE_SOURCE (ai | library)
+func imageSource() string {
+	if strings.EqualFold(strings.TrimSpace(os.Getenv("IMAGE_SOURCE")), SourceLibrary) {
+		return SourceLibrary
+	}
+	return SourceAI
+}
+
+// libraryFallbackEnabled reads IMAGE_LIBRARY_FALLBACK (default on)
+func libraryFallbackEnabled() bool {
+	return !isOff(os.Getenv("IMAGE_LIBRARY_FALLBACK"))
+}
+
+// libraryMaxUses reads IMAGE_LIBRARY_MAX_USES (default 10, 0 = unlimited)
+func libraryMaxUses() int {
+	return envInt("IMAGE_LIBRARY_MAX_USES", 10)
+}
+
+// libraryMinMatch reads IMAGE_LIBRARY_MIN_MATCH (default 1 matching token)
+func libraryMinMatch() int {
+	return envInt("IMAGE_LIBRARY_MIN_MATCH", 1)
+}
+
+// libraryStopWords are ignored when tokenising headings, tags and keywords
+var libraryStopWords = map[string]bool{
+	"dan": true, "atau": true, "dengan": true, "untuk": true, "dari": true, "pada": true, "dalam": true,
+	"adalah": true, "yang": true, "ini": true, "itu": true, "apa": true, "bagaimana": true, "mengapa": true,
+	"kapan": true, "cara": true, "tips": true, "agar": true, "secara": true, "the": true, "and": true, "for": true,
+}
+
+// libraryTagSynonyms lets English category tags match Indonesian headings
+var libraryTagSynonyms = map[string][]string{
+	"crop":       {"tanaman", "budidaya"},
+	"pest":       {"hama"},
+	"disease":    {"penyakit"},
+	"fertilizer": {"pupuk"},
+	"tool":       {"alat", "peralatan"},
+	"harvest":    {"panen"},
+	"seed":       {"benih", "bibit"},
+	"soil":       {"tanah", "lahan"},
+	"water":      {"air", "penyiraman", "irigasi"},
+}
+
+// libraryTokens lowercases text and splits it into words (>= 3 letters, no stop words)
+func libraryTokens(text string) []string {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	seen := make(map[string]bool)
+	var out []string
+	for _, w := range words {
+		if len(w) >= 3 && !libraryStopWords[w] && !seen[w] {
+			seen[w] = true
+			out = append(out, w)
+		}
+	}
+	return out
+}
+
+// normalizeTags lowercases, trims and de-duplicates tags (multi-word tags are kept as one tag)
+func normalizeTags(tags []string) []string {
+	seen := make(map[string]bool)
+	out := []string{}
+	for _, t := range tags {
+		t = strings.Join(strings.Fields(strings.ToLower(t)), " ")
+		if t != "" && !seen[t] {
+			seen[t] = true
+			out = append(out, t)
+		}
+	}
+	return out
+}
+
+// entryTokens are the tokens a library image is found by (tags, keywords, category synonyms)
+func entryTokens(img LibraryImage) []string {
+	var text []string
+	text = append(text, img.Tags...)
+	text = append(text, img.Keywords...)
+	for _, t := range img.Tags {
+		text = append(text, libraryTagSynonyms[t]...)
+	}
+	return libraryTokens(strings.Join(text, " "))
+}
+
+// scoreLibraryImage counts entry tokens found in the heading
+// A heading word containing a token also counts ("pemupukan" matches tag "pupuk")
+func scoreLibraryImage(img LibraryImage, heading []string) (int, []string) {
+	var matched []string
+	for _, et := range entryTokens(img) {
+		for _, ht := range heading {
+			if ht == et || (len(et) >= 4 && strings.Contains(ht, et)) {
+				matched = append(matched, et)
+				break
+			}
+		}
+	}
+	return len(matched), matched
+}
+
+// MatchLibrary ranks usable library images for a heading (no usage is recorded)
+// exclude = IDs already used in the article
+func MatchLibrary(heading string, exclude map[string]bool, limit int) ([]LibraryMatch, error) {
+	images, err := getLibraryStore().ListLibraryImages()
+	if err != nil {
+		return nil, err
+	}
+	tokens := libraryTokens(heading)
+	maxUses, minMatch := libraryMaxUses(), libraryMinMatch()
+	if minMatch < 1 {
+		minMatch = 1
+	}
+
+	var matches []LibraryMatch
+	for _, img := range images {
+		if img.Disabled || exclude[img.ID] || (maxUses > 0 && img.UsageCount >= maxUses) {
+			continue
+		}
+		if score, matched := scoreLibraryImage(img, tokens); score >= minMatch {
+			matches = append(matches, LibraryMatch{Image: img, Score: score, Matched: matched})
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool {
+		a, b := matches[i], matches[j]
+		if a.Score != b.Score {
+			return a.Score > b.Score
+		}
+		if a.Image.UsageCount != b.Image.UsageCount {
+			return a.Image.UsageCount < b.Image.UsageCount
+		}
+		return lastUsed(a.Image).Before(lastUsed(b.Image))
+	})
+	if limit > 0 && len(matches) > limit {
+		matches = matches[:limit]
+	}
+	return matches, nil
+}
+
+func lastUsed(img LibraryImage) time.Time {
+	if img.LastUsedAt == nil {
+		return time.Time{}
+	}
+	return *img.LastUsedAt
+}
+
+// libraryAvailable reports whether the library has at least one enabled image
+func libraryAvailable() bool {
+	images, err := getLibraryStore().ListLibraryImages()
+	if err != nil {
+		log.Printf("[IMAGE LIBRARY] WARNING: %v", err)
+		return false
+	}
+	for _, img := range images {
+		if !img.Disabled {
+			return true
+		}
+	}
+	return false
+}
+
+// useLibraryImage picks the best match for heading and records the use
+func useLibraryImage(heading, slug string, exclude map[string]bool) (*LibraryMatch, error) {
+	useMu.Lock()
+	defer useMu.Unlock()
+
+	matches, err := MatchLibrary(heading, exclude, 1)
+	if err != nil || len(matches) == 0 {
+		return nil, err
+	}
+	m := matches[0]
+	now := time.Now().UTC()
+	if err := getLibraryStore().MarkLibraryUsed(m.Image.ID, slug, now); err != nil {
+		// The image is still usable; only the counter is off
+		log.Printf("[IMAGE LIBRARY] WARNING: failed to record use of %s: %v", m.Image.ID, err)
+	}
+	m.Image.UsageCount++
+	m.Image.LastUsedAt, m.Image.LastUsedIn = &now, slug
+	return &m, nil
+}
+
+// useLibrary fills the section with the best matching library image (appended to images)
+// Returns false when nothing in the library matches the heading well enough
+func (g *Generator) useLibrary(section Section, slug, role string, used map[string]bool, images *[]ImageAsset, report *QualityReport) bool {
+	m, err := useLibraryImage(section.Heading, slug, used)
+	if err != nil {
+		log.Printf("[IMAGE LIBRARY] WARNING: lookup failed for section '%s': %v", section.Heading, err)
+		return false
+	}
+	if m == nil {
+		log.Printf("[IMAGE LIBRARY] No usable library image matches section '%s' (disabled, over IMAGE_LIBRARY_MAX_USES or already in this article are skipped)", section.Heading)
+		return false
+	}
+	used[m.Image.ID] = true
+	*images = append(*images, g.libraryAsset(m, section, role))
+	report.filledFromLibrary(section.Heading)
+	log.Printf("[IMAGE LIBRARY] Section '%s' [%s] filled with %s (matched %v, used %d times)", section.Heading, role, m.Image.Path, m.Matched, m.Image.UsageCount)
+	return true
+}
+
+// libraryAsset turns a library image into the section's ImageAsset
+func (g *Generator) libraryAsset(m *LibraryMatch, section Section, role string) ImageAsset {
+	alt := m.Image.AltText
+	if alt == "" {
+		alt = g.generateAltText(section.Heading, section.Content)
+	}
+	return ImageAsset{
+		Section:     section.Type + ": " + section.Heading,
+		URL:         m.Image.URL,
+		LocalPath:   m.Image.Path,
+		PublicURL:   m.Image.URL,
+		AltText:     alt,
+		Heading:     section.Heading,
+		IsHero:      role == "hero",
+		Role:        role,
+		Width:       m.Image.Width,
+		Height:      m.Image.Height,
+		Variants:    m.Image.Variants,
+		Placeholder: m.Image.Placeholder,
+		Source:      SourceLibrary,
+		LibraryID:   m.Image.ID,
+	}
+}
+
+// AddLibraryImage stores an uploaded photo (with responsive variants) and indexes it
+// The same file uploaded twice returns the existing entry (content-hash path) with duplicate = true
+func AddLibraryImage(data []byte, filename string, meta LibraryMeta) (*LibraryImage, bool, error) {
+	if _, _, err := image.Decode(bytes.NewReader(data)); err != nil {
+		return nil, false, fmt.Errorf("%w: not a decodable image (png/jpeg/webp): %v", ErrLibraryInvalid, err)
+	}
+	tags := normalizeTags(meta.Tags)
+	if len(tags) == 0 && len(meta.Keywords) == 0 {
+		return nil, false, fmt.Errorf("%w: at least one tag or keyword is required", ErrLibraryInvalid)
+	}
+
+	ext := strings.ToLower(filepath.Ext(filename))
+	if ext == "" {
+		ext = ".jpg"
+	}
+	saved, err := NewStorage().SaveLibraryImage(filename, ext, data)
+	if err != nil {
+		return nil, false, err
+	}
+
+	store := getLibraryStore()
+	existing, err := store.ListLibraryImages()
+	if err != nil {
+		return nil, false, err
+	}
+	for _, img := range existing {
+		if img.Path == saved.Path {
+			return &img, true, nil
+		}
+	}
+
+	now := time.Now().UTC()
+	img := LibraryImage{
+		ID:          uuid.New().String(),
+		Path:        saved.Path,
+		URL:         saved.URL,
+		Width:       saved.Width,
+		Height:      saved.Height,
+		Variants:    saved.Variants,
+		Placeholder: saved.Placeholder,
+		Tags:        tags,
+		Keywords:    normalizeTags(meta.Keywords),
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	applyLibraryMeta(&img, LibraryMeta{AltText: meta.AltText, Source: meta.Source, Disabled: meta.Disabled})
+	if err := store.InsertLibraryImage(img); err != nil {
+		return nil, false, err
+	}
+	log.Printf("[IMAGE LIBRARY] Added %s (%s) tags=%v keywords=%v", img.ID, img.Path, img.Tags, img.Keywords)
+	return &img, false, nil
+}
+
+// UpdateLibraryImage changes tags, keywords, alt text, source or disabled of one image
+func UpdateLibraryImage(id string, meta LibraryMeta) (*LibraryImage, error) {
+	store := getLibraryStore()
+	img, err := store.GetLibraryImage(id)
+	if err != nil {
+		return nil, err
+	}
+	applyLibraryMeta(img, meta)
+	if len(img.Tags) == 0 && len(img.Keywords) == 0 {
+		return nil, fmt.Errorf("%w: at least one tag or keyword is required", ErrLibraryInvalid)
+	}
+	img.UpdatedAt = time.Now().UTC()
+	if err := store.UpdateLibraryImage(*img); err != nil {
+		return nil, err
+	}
+	return img, nil
+}
+
+func applyLibraryMeta(img *LibraryImage, meta LibraryMeta) {
+	if meta.Tags != nil {
+		img.Tags = normalizeTags(meta.Tags)
+	}
+	if meta.Keywords != nil {
+		img.Keywords = normalizeTags(meta.Keywords)
+	}
+	if meta.AltText != nil {
+		img.AltText = strings.TrimSpace(*meta.AltText)
+	}
+	if meta.Source != nil {
+		img.Source = strings.TrimSpace(*meta.Source)
+	}
+	if meta.Disabled != nil {
+		img.Disabled = *meta.Disabled
+	}
+}
+
+// ListLibrary returns library images, filtered by tag ("" = all) and search text (tags, keywords, alt text)
+func ListLibrary(tag, query string) ([]LibraryImage, error) {
+	images, err := getLibraryStore().ListLibraryImages()
+	if err != nil {
+		return nil, err
+	}
+	tag = strings.ToLower(strings.TrimSpace(tag))
+	query = strings.ToLower(strings.TrimSpace(query))
+	out := []LibraryImage{}
+	for _, img := range images {
+		if tag != "" && !containsString(img.Tags, tag) {
+			continue
+		}
+		if query != "" && !strings.Contains(strings.ToLower(strings.Join(img.Tags, " ")+" "+strings.Join(img.Keywords, " ")+" "+img.AltText), query) {
+			continue
+		}
+		out = append(out, img)
+	}
+	return out, nil
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
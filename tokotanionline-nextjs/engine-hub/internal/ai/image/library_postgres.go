@@ -0,0 +1,129 @@
+package image
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// PostgresLibraryStore persists the image library in "EngineHubImageLibrary"
+type PostgresLibraryStore struct {
+	db *sql.DB
+}
+
+// NewPostgresLibraryStore creates a library store backed by the given database
+func NewPostgresLibraryStore(db *sql.DB) *PostgresLibraryStore {
+	return &PostgresLibraryStore{db: db}
+}
+
+const libraryColumns = `id, path, url, width, height, variants, placeholder, "altText", tags, keywords, source,
+	disabled, "usageCount", "lastUsedAt", "lastUsedIn", "createdAt", "updatedAt"`
+
+func (p *PostgresLibraryStore) InsertLibraryImage(img LibraryImage) error {
+	// jsonb passed as text (lib/pq sends []byte as bytea)
+	variants, err := json.Marshal(img.Variants)
+	if err != nil {
+		return fmt.Errorf("failed to marshal library image variants: %w", err)
+	}
+	_, err = p.db.Exec(`
+		INSERT INTO "EngineHubImageLibrary" (`+libraryColumns+`)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17)
+	`, img.ID, img.Path, img.URL, img.Width, img.Height, string(variants), nullString(img.Placeholder), nullString(img.AltText),
+		pq.StringArray(img.Tags), pq.StringArray(img.Keywords), nullString(img.Source), img.Disabled, img.UsageCount,
+		img.LastUsedAt, nullString(img.LastUsedIn), img.CreatedAt, img.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to insert library image: %w", err)
+	}
+	return nil
+}
+
+// UpdateLibraryImage saves the admin-editable fields (usage is only changed by MarkLibraryUsed)
+func (p *PostgresLibraryStore) UpdateLibraryImage(img LibraryImage) error {
+	res, err := p.db.Exec(`
+		UPDATE "EngineHubImageLibrary"
+		SET "altText" = $2, tags = $3, keywords = $4, source = $5, disabled = $6, "updatedAt" = $7
+		WHERE id = $1
+	`, img.ID, nullString(img.AltText), pq.StringArray(img.Tags), pq.StringArray(img.Keywords), nullString(img.Source),
+		img.Disabled, img.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to update library image: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrLibraryNotFound
+	}
+	return nil
+}
+
+func (p *PostgresLibraryStore) GetLibraryImage(id string) (*LibraryImage, error) {
+	rows, err := p.db.Query(`SELECT `+libraryColumns+` FROM "EngineHubImageLibrary" WHERE id = $1`, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query library image: %w", err)
+	}
+	defer rows.Close()
+	list, err := scanLibraryImages(rows)
+	if err != nil {
+		return nil, err
+	}
+	if len(list) == 0 {
+		return nil, ErrLibraryNotFound
+	}
+	return &list[0], nil
+}
+
+func (p *PostgresLibraryStore) ListLibraryImages() ([]LibraryImage, error) {
+	rows, err := p.db.Query(`SELECT ` + libraryColumns + ` FROM "EngineHubImageLibrary" ORDER BY "createdAt"`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list library images: %w", err)
+	}
+	defer rows.Close()
+	return scanLibraryImages(rows)
+}
+
+func (p *PostgresLibraryStore) MarkLibraryUsed(id, slug string, at time.Time) error {
+	res, err := p.db.Exec(`
+		UPDATE "EngineHubImageLibrary"
+		SET "usageCount" = "usageCount" + 1, "lastUsedAt" = $2, "lastUsedIn" = $3
+		WHERE id = $1
+	`, id, at, nullString(slug))
+	if err != nil {
+		return fmt.Errorf("failed to record library image use: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrLibraryNotFound
+	}
+	return nil
+}
+
+func scanLibraryImages(rows *sql.Rows) ([]LibraryImage, error) {
+	var out []LibraryImage
+	for rows.Next() {
+		var img LibraryImage
+		var variants []byte
+		var placeholder, altText, source, lastUsedIn sql.NullString
+		var tags, keywords pq.StringArray
+		var lastUsedAt sql.NullTime
+		if err := rows.Scan(&img.ID, &img.Path, &img.URL, &img.Width, &img.Height, &variants, &placeholder, &altText,
+			&tags, &keywords, &source, &img.Disabled, &img.UsageCount, &lastUsedAt, &lastUsedIn, &img.CreatedAt, &img.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan library image: %w", err)
+		}
+		if len(variants) > 0 {
+			if err := json.Unmarshal(variants, &img.Variants); err != nil {
+				return nil, fmt.Errorf("failed to unmarshal library image variants: %w", err)
+			}
+		}
+		img.Placeholder, img.AltText, img.Source, img.LastUsedIn = placeholder.String, altText.String, source.String, lastUsedIn.String
+		img.Tags, img.Keywords = []string(tags), []string(keywords)
+		if lastUsedAt.Valid {
+			img.LastUsedAt = &lastUsedAt.Time
+		}
+		out = append(out, img)
+	}
+	return out, rows.Err()
+}
+
+func nullString(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
@@ -0,0 +1,89 @@
+package image
+
+import (
+	"image"
+	"math"
+	"math/bits"
+	"sort"
+
+	xdraw "golang.org/x/image/draw"
+)
+
+// PERCEPTUAL HASH (pHash)
+// Gambar diperkecil ke 32x32 grayscale → DCT 2D → 8x8 koefisien frekuensi rendah (tanpa DC)
+// dibandingkan dengan median → 64 bit. Jarak Hamming kecil = gambar mirip walaupun
+// ukuran, kompresi, atau warna sedikit berbeda (varian WebP dari gambar yang sama tetap dekat)
+
+const (
+	phashSize = 32
+	phashLow  = 8
+)
+
+// PHash computes the 64-bit DCT perceptual hash of img
+func PHash(img image.Image) uint64 {
+	small := image.NewGray(image.Rect(0, 0, phashSize, phashSize))
+	xdraw.ApproxBiLinear.Scale(small, small.Bounds(), img, img.Bounds(), xdraw.Src, nil)
+
+	var pixels [phashSize][phashSize]float64
+	for y := 0; y < phashSize; y++ {
+		for x := 0; x < phashSize; x++ {
+			pixels[y][x] = float64(small.GrayAt(x, y).Y)
+		}
+	}
+	dct := dct2D(pixels)
+
+	coeffs := make([]float64, 0, phashLow*phashLow)
+	for y := 0; y < phashLow; y++ {
+		for x := 0; x < phashLow; x++ {
+			coeffs = append(coeffs, dct[y][x])
+		}
+	}
+	// Median without the DC term (average brightness says nothing about structure)
+	sorted := append([]float64(nil), coeffs[1:]...)
+	sort.Float64s(sorted)
+	median := sorted[len(sorted)/2]
+
+	var hash uint64
+	for i, c := range coeffs {
+		if c > median {
+			hash |= 1 << uint(i)
+		}
+	}
+	return hash
+}
+
+// HammingDistance is the number of differing bits between two hashes (0 = same, 64 = opposite)
+func HammingDistance(a, b uint64) int {
+	return bits.OnesCount64(a ^ b)
+}
+
+// dct2D is a plain DCT-II over rows then columns (32x32, only the top-left 8x8 is used)
+func dct2D(in [phashSize][phashSize]float64) [phashSize][phashSize]float64 {
+	var cos [phashSize][phashSize]float64
+	for k := 0; k < phashSize; k++ {
+		for n := 0; n < phashSize; n++ {
+			cos[k][n] = math.Cos(math.Pi / phashSize * (float64(n) + 0.5) * float64(k))
+		}
+	}
+
+	var rows, out [phashSize][phashSize]float64
+	for y := 0; y < phashSize; y++ {
+		for k := 0; k < phashLow; k++ {
+			var sum float64
+			for n := 0; n < phashSize; n++ {
+				sum += in[y][n] * cos[k][n]
+			}
+			rows[y][k] = sum
+		}
+	}
+	for x := 0; x < phashLow; x++ {
+		for k := 0; k < phashLow; k++ {
+			var sum float64
+			for n := 0; n < phashSize; n++ {
+				sum += rows[n][x] * cos[k][n]
+			}
+			out[k][x] = sum
+		}
+	}
+	return out
+}
@@ -0,0 +1,582 @@
+package image
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+	"log"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+
+	xdraw "golang.org/x/image/draw"
+)
+
+// IMAGE QUALITY GATE (setelah download, sebelum disimpan)
+// Prompt realistis saja tidak cukup: byte gambar dari image API dicek dulu:
+// - decode:     bisa di-decode (png/jpeg/webp)
+// - resolution: minimal IMAGE_QC_MIN_WIDTH x IMAGE_QC_MIN_HEIGHT
+// - aspect:     rasio lebar/tinggi dalam batas
+// - blank:      bukan gambar kosong / satu warna (kontras luma + dominasi satu warna)
+// - text:       heuristik baris teks (sel kontras tinggi, dua tone, berderet horizontal)
+// - watermark:  heuristik logo/teks kecil di pojok pada gambar yang selain itu bersih
+// - duplicate:  pHash terlalu dekat dengan gambar lain di artikel yang sama atau di library (hash index)
+// Gagal → generate ulang dengan prompt bervariasi (varyPrompt) sampai IMAGE_QC_RETRIES kali
+
+// Check names (QualityCheck.Name)
+const (
+	CheckDecode           = "decode"
+	CheckResolution       = "resolution"
+	CheckAspect           = "aspect"
+	CheckBlank            = "blank"
+	CheckText             = "text"
+	CheckWatermark        = "watermark"
+	CheckDuplicateArticle = "duplicate_article"
+	CheckDuplicateLibrary = "duplicate_library"
+)
+
+// QualityCheck is the outcome of one check on a downloaded image
+type QualityCheck struct {
+	Name   string `json:"name"`
+	Ok     bool   `json:"ok"`
+	Detail string `json:"detail,omitempty"`
+}
+
+// QualityResult is the outcome of all checks on one downloaded image
+type QualityResult struct {
+	Ok     bool           `json:"ok"`
+	Checks []QualityCheck `json:"checks"`
+	Width  int            `json:"width,omitempty"`
+	Height int            `json:"height,omitempty"`
+	Hash   uint64         `json:"-"`
+	Hex    string         `json:"phash,omitempty"`
+}
+
+// Failed returns the names of the failed checks
+func (r *QualityResult) Failed() []string {
+	var names []string
+	for _, c := range r.Checks {
+		if !c.Ok {
+			names = append(names, c.Name)
+		}
+	}
+	return names
+}
+
+// Reason is a short description of the failed checks ("blank: stddev 2.1 < 8; ...")
+func (r *QualityResult) Reason() string {
+	var parts []string
+	for _, c := range r.Checks {
+		if !c.Ok {
+			parts = append(parts, c.Name+": "+c.Detail)
+		}
+	}
+	return strings.Join(parts, "; ")
+}
+
+func (r *QualityResult) add(name string, ok bool, detail string) {
+	r.Checks = append(r.Checks, QualityCheck{Name: name, Ok: ok, Detail: detail})
+	if !ok {
+		r.Ok = false
+	}
+}
+
+// ImageAttempt is one image API call for a section (QualityReport)
+type ImageAttempt struct {
+	Section string   `json:"section"`
+	Attempt int      `json:"attempt"` // 1 = original prompt, 2+ = varied prompt
+	Ok      bool     `json:"ok"`
+	Failed  []string `json:"failed,omitempty"` // failed check names
+	Reason  string   `json:"reason,omitempty"`
+}
+
+// QualityReport summarises the quality gate for one GenerateImages run (StepResult of the image step)
+type QualityReport struct {
+	Enabled   bool           `json:"enabled"`
+	Accepted  int            `json:"accepted"`
+	Rejected  int            `json:"rejected"`            // attempts that failed a check
+	Retries   int            `json:"retries"`             // regenerations with a varied prompt
+	Abandoned []string       `json:"abandoned,omitempty"` // sections left without an image after all retries
+	Attempts  []ImageAttempt `json:"attempts,omitempty"`
+	// Sections filled from the image library (library.go) instead of the image API
+	FromLibrary []string `json:"fromLibrary,omitempty"`
+}
+
+// filledFromLibrary moves a section from Abandoned to FromLibrary
+func (r *QualityReport) filledFromLibrary(section string) {
+	for i, s := range r.Abandoned {
+		if s == section {
+			r.Abandoned = append(r.Abandoned[:i], r.Abandoned[i+1:]...)
+			break
+		}
+	}
+	r.FromLibrary = append(r.FromLibrary, section)
+}
+
+// Summary is a one-line warning for StepResult.Error ("" when every section got an image)
+func (r *QualityReport) Summary() string {
+	if r == nil || len(r.Abandoned) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("%d image(s) rejected by quality checks (%d retries in total): %s", len(r.Abandoned), r.Retries, strings.Join(r.Abandoned, ", "))
+}
+
+// qualityConfig controls the quality gate (env, see loadQualityConfig)
+type qualityConfig struct {
+	Enabled     bool
+	MinWidth    int
+	MinHeight   int
+	MinAspect   float64 // width / height
+	MaxAspect   float64
+	MinStdDev   float64 // luma standard deviation below this = blank
+	MaxDominant float64 // share of the most common colour above this = solid colour
+	Text        bool    // text + watermark heuristics
+	DupDistance int     // pHash Hamming distance <= this = near-duplicate
+	Library     bool    // also compare with the hash index (earlier articles / products)
+	Retries     int     // regenerations per section after a failed check
+}
+
+// loadQualityConfig reads IMAGE_QC_* (IMAGE_QC=off disables the gate, images are saved as before)
+func loadQualityConfig() qualityConfig {
+	cfg := qualityConfig{
+		Enabled:     true,
+		MinWidth:    512,
+		MinHeight:   512,
+		MinAspect:   0.5,
+		MaxAspect:   2.0,
+		MinStdDev:   8,
+		MaxDominant: 0.85,
+		Text:        true,
+		DupDistance: 8,
+		Library:     true,
+		Retries:     2,
+	}
+	if isOff(os.Getenv("IMAGE_QC")) {
+		cfg.Enabled = false
+	}
+	cfg.MinWidth = envInt("IMAGE_QC_MIN_WIDTH", cfg.MinWidth)
+	cfg.MinHeight = envInt("IMAGE_QC_MIN_HEIGHT", cfg.MinHeight)
+	cfg.MinAspect = envFloat("IMAGE_QC_MIN_ASPECT", cfg.MinAspect)
+	cfg.MaxAspect = envFloat("IMAGE_QC_MAX_ASPECT", cfg.MaxAspect)
+	cfg.DupDistance = envInt("IMAGE_QC_DUP_DISTANCE", cfg.DupDistance)
+	cfg.Retries = envInt("IMAGE_QC_RETRIES", cfg.Retries)
+	if isOff(os.Getenv("IMAGE_QC_TEXT")) {
+		cfg.Text = false
+	}
+	if isOff(os.Getenv("IMAGE_QC_LIBRARY")) {
+		cfg.Library = false
+	}
+	return cfg
+}
+
+func isOff(v string) bool {
+	v = strings.ToLower(strings.TrimSpace(v))
+	return v == "off" || v == "false" || v == "0"
+}
+
+func envInt(name string, def int) int {
+	if v := os.Getenv(name); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n >= 0 {
+			return n
+		}
+		log.Printf("[IMAGE QC] WARNING: invalid %s=%q, using %d", name, v, def)
+	}
+	return def
+}
+
+func envFloat(name string, def float64) float64 {
+	if v := os.Getenv(name); v != "" {
+		if f, err := strconv.ParseFloat(v, 64); err == nil && f > 0 {
+			return f
+		}
+		log.Printf("[IMAGE QC] WARNING: invalid %s=%q, using %g", name, v, def)
+	}
+	return def
+}
+
+// checkImage runs the byte-level checks; seen = pHashes already accepted for this article
+// The library lookup is done here too so one result carries every check
+func checkImage(data []byte, cfg qualityConfig, seen []uint64) *QualityResult {
+	res := &QualityResult{Ok: true}
+
+	img, format, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		res.add(CheckDecode, false, err.Error())
+		return res
+	}
+	b := img.Bounds()
+	res.Width, res.Height = b.Dx(), b.Dy()
+	res.add(CheckDecode, res.Width > 0 && res.Height > 0, fmt.Sprintf("%s %dx%d", format, res.Width, res.Height))
+	if !res.Ok {
+		return res
+	}
+
+	res.add(CheckResolution, res.Width >= cfg.MinWidth && res.Height >= cfg.MinHeight,
+		fmt.Sprintf("%dx%d (min %dx%d)", res.Width, res.Height, cfg.MinWidth, cfg.MinHeight))
+
+	aspect := float64(res.Width) / float64(res.Height)
+	res.add(CheckAspect, aspect >= cfg.MinAspect && aspect <= cfg.MaxAspect,
+		fmt.Sprintf("%.2f (allowed %.2f-%.2f)", aspect, cfg.MinAspect, cfg.MaxAspect))
+
+	// Analysis runs on a small copy: fast and independent of the API output size
+	sample := downsample(img, analysisWidth)
+
+	stddev, dominant := blankStats(sample)
+	res.add(CheckBlank, stddev >= cfg.MinStdDev && dominant <= cfg.MaxDominant,
+		fmt.Sprintf("luma stddev %.1f (min %.0f), dominant colour %.0f%% (max %.0f%%)", stddev, cfg.MinStdDev, dominant*100, cfg.MaxDominant*100))
+
+	if cfg.Text {
+		grid := textGrid(downsample(img, textAnalysisWidth))
+		run := grid.longestRun()
+		res.add(CheckText, run < textRunCells, fmt.Sprintf("longest text-like run %d cells (max %d)", run, textRunCells-1))
+		corner, cornerDensity, restDensity := grid.cornerMarks()
+		res.add(CheckWatermark, !(corner >= watermarkCells && cornerDensity >= watermarkRatio*restDensity),
+			fmt.Sprintf("%d text-like cells in one corner (%.1f%% vs %.1f%% elsewhere)", corner, cornerDensity*100, restDensity*100))
+	}
+
+	res.Hash = PHash(img)
+	res.Hex = fmt.Sprintf("%016x", res.Hash)
+	if d, ok := nearest(res.Hash, seen); ok && d <= cfg.DupDistance {
+		res.add(CheckDuplicateArticle, false, fmt.Sprintf("pHash distance %d to an image in this article (max %d)", d, cfg.DupDistance))
+	} else {
+		res.add(CheckDuplicateArticle, true, "")
+	}
+	if cfg.Library {
+		if entry, d, ok := nearestInLibrary(res.Hash); ok && d <= cfg.DupDistance {
+			res.add(CheckDuplicateLibrary, false, fmt.Sprintf("pHash distance %d to %s (max %d)", d, entry.Path, cfg.DupDistance))
+		} else {
+			res.add(CheckDuplicateLibrary, true, "")
+		}
+	}
+	return res
+}
+
+// nearest returns the smallest Hamming distance from hash to any of hashes
+func nearest(hash uint64, hashes []uint64) (int, bool) {
+	best, found := 65, false
+	for _, h := range hashes {
+		if d := HammingDistance(hash, h); d < best {
+			best, found = d, true
+		}
+	}
+	return best, found
+}
+
+// analysisWidth is the copy used for blank stats; text cells need more detail (thin strokes)
+const (
+	analysisWidth     = 256
+	textAnalysisWidth = 512
+)
+
+// downsample scales img to width (keeps aspect ratio) as RGBA
+func downsample(img image.Image, width int) *image.RGBA {
+	b := img.Bounds()
+	if b.Dx() < width {
+		width = b.Dx()
+	}
+	height := b.Dy() * width / b.Dx()
+	if height < 1 {
+		height = 1
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	xdraw.ApproxBiLinear.Scale(dst, dst.Bounds(), img, b, xdraw.Src, nil)
+	return dst
+}
+
+func luma(img *image.RGBA, x, y int) float64 {
+	c := img.RGBAAt(x, y)
+	return 0.299*float64(c.R) + 0.587*float64(c.G) + 0.114*float64(c.B)
+}
+
+// blankStats returns the luma standard deviation and the share of the most common colour (4 bit/channel)
+func blankStats(img *image.RGBA) (float64, float64) {
+	b := img.Bounds()
+	n := float64(b.Dx() * b.Dy())
+	var sum, sumSq float64
+	buckets := make(map[uint16]int)
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			l := luma(img, x, y)
+			sum += l
+			sumSq += l * l
+			c := img.RGBAAt(x, y)
+			buckets[uint16(c.R>>4)<<8|uint16(c.G>>4)<<4|uint16(c.B>>4)]++
+		}
+	}
+	mean := sum / n
+	stddev := math.Sqrt(math.Max(0, sumSq/n-mean*mean))
+	top := 0
+	for _, count := range buckets {
+		if count > top {
+			top = count
+		}
+	}
+	return stddev, float64(top) / n
+}
+
+// Text heuristics (tuned on rendered text vs. photo-like textures; see README)
+// A cell looks like text when it has high contrast, many sharp edges and a large share of pixels in
+// exactly one flat tone (the glyph colour). Foliage, soil and sky have edges but their extremes are
+// single highlight/shadow pixels, not flat strokes
+const (
+	textCellSize      = 16
+	textMinContrast   = 100  // max-min luma inside the cell
+	textMinEdges      = 0.10 // share of pixels with a strong gradient
+	textEdgeThreshold = 60
+	textMinStroke     = 6    // edge pixels needed in each direction (horizontal and vertical gradient)
+	textInkBand       = 12   // luma distance to the darkest/brightest value counted as ink
+	textMinInk        = 0.18 // share of ink pixels (and at most 1-this, else it is a flat area)
+	textRunCells      = 5    // this many adjacent text-like cells in a row = a line of text
+	textToneDrift     = 16   // max ink luma difference within one line
+	watermarkCells    = 4    // text-like cells inside one corner zone ...
+	watermarkRatio    = 4.0  // ... at this many times the density of the rest of the image
+)
+
+// cellGrid marks text-like cells of the analysis image (tone = luma of the cell's ink)
+type cellGrid struct {
+	cols, rows int
+	marked     []bool
+	tone       []float64
+}
+
+func textGrid(img *image.RGBA) cellGrid {
+	b := img.Bounds()
+	g := cellGrid{cols: b.Dx() / textCellSize, rows: b.Dy() / textCellSize}
+	g.marked = make([]bool, g.cols*g.rows)
+	g.tone = make([]float64, g.cols*g.rows)
+	for r := 0; r < g.rows; r++ {
+		for c := 0; c < g.cols; c++ {
+			g.marked[r*g.cols+c], g.tone[r*g.cols+c] = textLikeCell(img, c*textCellSize, r*textCellSize)
+		}
+	}
+	return g
+}
+
+func textLikeCell(img *image.RGBA, x0, y0 int) (bool, float64) {
+	var values [textCellSize * textCellSize]float64
+	lo, hi := 255.0, 0.0
+	edges, hEdges, vEdges := 0, 0, 0
+	for y := 0; y < textCellSize; y++ {
+		for x := 0; x < textCellSize; x++ {
+			l := luma(img, x0+x, y0+y)
+			values[y*textCellSize+x] = l
+			lo, hi = math.Min(lo, l), math.Max(hi, l)
+			h := x > 0 && math.Abs(l-values[y*textCellSize+x-1]) > textEdgeThreshold
+			v := y > 0 && math.Abs(l-values[(y-1)*textCellSize+x]) > textEdgeThreshold
+			if h {
+				hEdges++
+			}
+			if v {
+				vEdges++
+			}
+			if h || v {
+				edges++
+			}
+		}
+	}
+	contrast := hi - lo
+	if contrast < textMinContrast || float64(edges)/float64(len(values)) < textMinEdges {
+		return false, 0
+	}
+	// Glyphs have vertical and horizontal strokes; a straight boundary between two areas has only one
+	if hEdges < textMinStroke || vEdges < textMinStroke {
+		return false, 0
+	}
+	// Ink: glyphs are drawn in one flat tone, so many pixels sit right at the darkest or brightest value
+	inkLo, inkHi := 0, 0
+	for _, l := range values {
+		if l <= lo+textInkBand {
+			inkLo++
+		}
+		if l >= hi-textInkBand {
+			inkHi++
+		}
+	}
+	ink, tone := float64(inkLo), lo
+	if inkHi > inkLo {
+		ink, tone = float64(inkHi), hi
+	}
+	ink /= float64(len(values))
+	return ink >= textMinInk && ink <= 1-textMinInk, tone
+}
+
+// longestRun is the longest horizontal run of text-like cells with the same ink tone
+// (a line of text is one colour; highlights and shadows in a photo are not)
+func (g cellGrid) longestRun() int {
+	best := 0
+	for r := 0; r < g.rows; r++ {
+		run, tone := 0, 0.0
+		for c := 0; c < g.cols; c++ {
+			i := r*g.cols + c
+			if g.marked[i] {
+				if run == 0 || math.Abs(g.tone[i]-tone) > textToneDrift {
+					run, tone = 0, g.tone[i]
+				}
+				run++
+				if run > best {
+					best = run
+				}
+			} else {
+				run = 0
+			}
+		}
+	}
+	return best
+}
+
+// cornerMarks returns the most text-like cells in one corner zone (1/4 wide, 1/5 high),
+// the share of marked cells in that zone and the share outside all corner zones
+func (g cellGrid) cornerMarks() (int, float64, float64) {
+	zw, zh := maxInt(1, g.cols/4), maxInt(1, g.rows/5)
+	inZone := func(c, r int) int {
+		left, right := c < zw, c >= g.cols-zw
+		top, bottom := r < zh, r >= g.rows-zh
+		switch {
+		case top && left:
+			return 0
+		case top && right:
+			return 1
+		case bottom && left:
+			return 2
+		case bottom && right:
+			return 3
+		}
+		return -1
+	}
+	var zones [4]int
+	rest := 0
+	for r := 0; r < g.rows; r++ {
+		for c := 0; c < g.cols; c++ {
+			if !g.marked[r*g.cols+c] {
+				continue
+			}
+			if z := inZone(c, r); z >= 0 {
+				zones[z]++
+			} else {
+				rest++
+			}
+		}
+	}
+	best := 0
+	for _, n := range zones {
+		if n > best {
+			best = n
+		}
+	}
+	zoneCells := zw * zh
+	restCells := g.cols*g.rows - 4*zoneCells
+	if zoneCells == 0 || restCells <= 0 {
+		return best, 0, 0
+	}
+	return best, float64(best) / float64(zoneCells), float64(rest) / float64(restCells)
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+// promptVariations are appended on retries so the image API does not return the same composition
+var promptVariations = []string{
+	"Variasi: ambil dari sudut pandang berbeda (lebih dekat ke objek utama), komposisi berbeda dari sebelumnya.",
+	"Variasi: suasana pagi hari dengan cahaya matahari lembut, objek utama di sepertiga bingkai.",
+	"Variasi: sudut lebar yang memperlihatkan lingkungan sekitar, tetap fokus pada aktivitas utama.",
+	"Variasi: foto dari samping setinggi mata, latar belakang sedikit blur.",
+}
+
+// varyPrompt returns the prompt for attempt (1 = original) with a variation and hints for the failed checks
+func varyPrompt(prompt string, attempt int, failed []string) string {
+	if attempt <= 1 {
+		return prompt
+	}
+	var b strings.Builder
+	b.WriteString(prompt)
+	b.WriteString("\n\n")
+	b.WriteString(promptVariations[(attempt-2)%len(promptVariations)])
+	written := map[string]bool{}
+	for _, name := range failed {
+		var hint string
+		switch name {
+		case CheckText, CheckWatermark:
+			hint = "Tanpa teks, tulisan, huruf, angka, label, logo, atau watermark apa pun di dalam gambar."
+		case CheckBlank:
+			hint = "Gambar harus menampilkan objek dan detail yang jelas, bukan latar polos atau satu warna."
+		case CheckDuplicateArticle, CheckDuplicateLibrary:
+			hint = "Gunakan objek, latar, dan komposisi yang jelas berbeda dari foto lain tentang topik ini."
+		case CheckAspect, CheckResolution:
+			hint = "Foto utuh dengan bingkai standar, tanpa border atau potongan."
+		}
+		if hint != "" && !written[hint] {
+			written[hint] = true
+			b.WriteString("\n" + hint)
+		}
+	}
+	return b.String()
+}
+
+// qualifiedImage is an image API result that passed the quality gate (or the gate is off)
+type qualifiedImage struct {
+	URL     string // image API URL
+	Prompt  string // prompt that produced it (varied on retries)
+	Data    []byte // downloaded bytes, nil when the download failed
+	Ext     string
+	Quality *QualityResult // nil when the gate is off or the download failed
+}
+
+// generateQualified calls the image API, downloads the result and runs checkImage
+// A failed check regenerates with a varied prompt (up to cfg.Retries); seen = hashes already accepted
+// for the same article/product. Every attempt is recorded in report
+func (g *Generator) generateQualified(ctx context.Context, tag, section, prompt string, seen []uint64, report *QualityReport) (*qualifiedImage, error) {
+	attempts := 1
+	if g.quality.Enabled {
+		attempts += g.quality.Retries
+	}
+
+	var failed []string
+	var lastReason string
+	for attempt := 1; attempt <= attempts; attempt++ {
+		current := varyPrompt(prompt, attempt, failed)
+		if attempt > 1 {
+			report.Retries++
+			log.Printf("%s Regenerating '%s' (attempt %d/%d) after: %s", tag, section, attempt, attempts, lastReason)
+		}
+
+		imageURL, err := g.callImageAPI(ctx, current)
+		if err != nil {
+			if attempt == 1 {
+				return nil, err
+			}
+			// API down during a retry: same outcome as running out of retries
+			lastReason = err.Error()
+			break
+		}
+
+		data, ext, err := g.storage.Download(tag, imageURL)
+		if err != nil {
+			// Nothing to check; keep the API URL like before the gate existed
+			log.Printf("%s WARNING: could not download '%s' for quality checks: %v", tag, section, err)
+			return &qualifiedImage{URL: imageURL, Prompt: current}, nil
+		}
+		if !g.quality.Enabled {
+			return &qualifiedImage{URL: imageURL, Prompt: current, Data: data, Ext: ext}, nil
+		}
+
+		res := checkImage(data, g.quality, seen)
+		report.Attempts = append(report.Attempts, ImageAttempt{Section: section, Attempt: attempt, Ok: res.Ok, Failed: res.Failed(), Reason: res.Reason()})
+		if res.Ok {
+			report.Accepted++
+			return &qualifiedImage{URL: imageURL, Prompt: current, Data: data, Ext: ext, Quality: res}, nil
+		}
+		report.Rejected++
+		failed = res.Failed()
+		lastReason = res.Reason()
+		log.Printf("%s Quality check FAILED for '%s' (attempt %d/%d): %s", tag, section, attempt, attempts, lastReason)
+	}
+
+	report.Abandoned = append(report.Abandoned, section)
+	return nil, fmt.Errorf("image rejected by quality checks: %s", lastReason)
+}
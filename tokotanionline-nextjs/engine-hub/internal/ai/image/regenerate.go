@@ -0,0 +1,134 @@
+package image
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+	"log"
+	"strings"
+)
+
+// SINGLE IMAGE REGENERATION
+// Satu gambar dibuat ulang tanpa menjalankan pipeline penuh (editor tidak suka satu gambar section)
+// Alur sama dengan GenerateImages: prompt template (atau override) → image API → quality gate → simpan → hash index
+// Penempatan di konten dan revisi dicatat oleh pemanggil (internal/ai/imageregen)
+
+// Kinds of image owners
+const (
+	KindArticle = "article"
+	KindProduct = "product"
+)
+
+// RegenerateRequest describes the one image to generate again
+type RegenerateRequest struct {
+	Kind     string // KindArticle | KindProduct
+	Slug     string // article or product slug (storage folder + hash index source)
+	Role     string // hero | section (article), hero | detail (product)
+	Heading  string // article: section heading; product: product name
+	Content  string // article: section text; product: description (prompt context + alt text)
+	Prompt   string // optional override, sent to the image API as-is
+	AltText  string // optional override
+	Replaces string // src of the image being replaced: counts as already used (duplicate check)
+}
+
+// RegenerateImage generates, checks and stores one image
+// The quality gate runs as in GenerateImages; the replaced image counts as already used, so an identical result is retried
+func (g *Generator) RegenerateImage(ctx context.Context, req RegenerateRequest) (ImageAsset, *QualityReport, error) {
+	report := &QualityReport{Enabled: g.quality.Enabled}
+	if g.apiKey == "" {
+		return ImageAsset{}, report, fmt.Errorf("IMAGE_API_KEY or OPENAI_API_KEY environment variable not set")
+	}
+
+	tag := "[IMAGE REGEN]"
+	prompt := strings.TrimSpace(req.Prompt)
+	altText := strings.TrimSpace(req.AltText)
+	var filename string
+	switch req.Kind {
+	case KindProduct:
+		query := "foto produk nyata tampak depan"
+		if req.Role != "hero" {
+			query = "foto produk dari sudut berbeda"
+		}
+		if prompt == "" {
+			prompt = g.generateProductPrompt(req.Heading, req.Content, req.Role, query)
+		}
+		if altText == "" {
+			altText = fmt.Sprintf("%s - tampak depan", req.Heading)
+			if req.Role != "hero" {
+				altText = "Detail produk " + req.Heading
+			}
+		}
+		filename = fmt.Sprintf("%s-%s-regenerated.jpg", req.Slug, req.Role)
+	default:
+		if prompt == "" {
+			prompt = g.GeneratePrompt("H2", req.Heading, req.Content)
+		}
+		if altText == "" {
+			altText = g.generateAltText(req.Heading, req.Content)
+		}
+		filename = generateNaturalFilename(req.Heading, req.Content, 0)
+	}
+
+	var seen []uint64
+	if hash, ok := g.storedHash(ctx, req.Replaces); ok {
+		seen = append(seen, hash)
+	}
+	q, err := g.generateQualified(ctx, tag, req.Heading, prompt, seen, report)
+	if err != nil {
+		return ImageAsset{}, report, fmt.Errorf("failed to regenerate image: %w", err)
+	}
+	if q.Data == nil {
+		// Without the bytes there is nothing to place in stored content (API URLs expire)
+		return ImageAsset{}, report, fmt.Errorf("failed to download regenerated image")
+	}
+
+	var saved *SavedImage
+	if req.Kind == KindProduct {
+		saved, err = g.storage.SaveProductImage(req.Slug, filename, q.Ext, q.Data)
+	} else {
+		saved, err = g.storage.SaveArticleImage(req.Slug, filename, q.Ext, q.Data)
+	}
+	if err != nil {
+		return ImageAsset{}, report, fmt.Errorf("failed to save regenerated image: %w", err)
+	}
+	if q.Quality != nil {
+		RegisterHash(q.Quality.Hash, saved.Path, req.Slug, req.Role)
+	}
+
+	section := req.Role
+	if req.Kind != KindProduct && req.Role != "hero" {
+		section = "H2: " + req.Heading
+	}
+	asset := ImageAsset{
+		Section: section,
+		URL:     q.URL,
+		AltText: altText,
+		Prompt:  q.Prompt,
+		Heading: req.Heading,
+		IsHero:  req.Role == "hero",
+		Role:    req.Role,
+	}.withSaved(saved)
+
+	log.Printf("%s Regenerated %s %s image [%s] for '%s': %s", tag, req.Kind, req.Slug, req.Role, req.Heading, saved.Path)
+	return asset, report, nil
+}
+
+// storedHash computes the pHash of an image in the object store (src = /images/... path or public URL)
+// Images saved before the hash index existed are not in it, so the replaced image is hashed directly
+func (g *Generator) storedHash(ctx context.Context, src string) (uint64, bool) {
+	i := strings.Index(src, "/images/")
+	if i < 0 {
+		return 0, false
+	}
+	data, err := g.storage.Store().Get(ctx, strings.TrimPrefix(src[i:], "/"))
+	if err != nil {
+		log.Printf("[IMAGE REGEN] Replaced image %s not readable (%v), duplicate check uses the hash index only", src, err)
+		return 0, false
+	}
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return 0, false
+	}
+	return PHash(img), true
+}
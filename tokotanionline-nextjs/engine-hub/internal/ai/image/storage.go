@@ -1,75 +1,83 @@
 package image
 
 import (
+	"bytes"
+	"context"
 	"fmt"
+	"image"
 	"io"
 	"log"
 	"net/http"
-	"os"
-	"path/filepath"
 	"strings"
 	"time"
+
+	"engine-hub/internal/ai/cassette"
+	"engine-hub/internal/ai/objectstore"
 )
 
-// Storage handles downloading and saving images locally
+// Storage handles downloading images and saving them to the image object store
 type Storage struct {
-	baseDir    string // Base directory for uploads (relative to Next.js public folder)
+	store      objectstore.Store // local public folder or S3-compatible bucket (IMAGE_STORE)
 	httpClient *http.Client
+	variants   variantConfig // responsive WebP derivatives (derivatives.go)
 }
 
 // NewStorage creates a new image storage handler
-// FASE C - C4: Storage path locked to /public/images/articles/{slug}/
+// FASE C - C4: Article images live under /images/articles/, product images under /images/products/
+// Keys are content hashes (objectstore.ContentKey), so identical downloads are stored once
 func NewStorage() *Storage {
-	// Get base directory from env or use default
-	baseDir := os.Getenv("IMAGE_STORAGE_DIR")
-	if baseDir == "" {
-		// FASE C - C4: Default path is /public/images/articles/
-		// Server runs from engine-hub, so go up one level to workspace root
-		baseDir = filepath.Join("..", "public", "images", "articles")
-		
-		// Resolve to absolute path to ensure consistency
-		absBaseDir, err := filepath.Abs(baseDir)
-		if err == nil {
-			baseDir = absBaseDir
-			log.Printf("[IMAGE STORAGE] Using base directory: %s", baseDir)
-		} else {
-			log.Printf("[IMAGE STORAGE] WARNING: Failed to resolve absolute path, using relative: %s", baseDir)
-		}
+	variants := loadVariantConfig()
+	if variants.Enabled {
+		log.Printf("[IMAGE STORAGE] Variants: %s widths=%v quality=%d", variantFormat, variants.Widths, variants.Quality)
 	} else {
-		log.Printf("[IMAGE STORAGE] Using IMAGE_STORAGE_DIR from env: %s", baseDir)
+		log.Printf("[IMAGE STORAGE] Variants disabled (IMAGE_VARIANTS=off), originals saved as-is")
 	}
 
 	return &Storage{
-		baseDir: baseDir,
+		store: objectstore.FromEnv(),
 		httpClient: &http.Client{
-			Timeout: 30 * time.Second,
+			Timeout:   30 * time.Second,
+			Transport: cassette.Default(), // image downloads are part of the cassette too
 		},
+		variants: variants,
+	}
+}
+
+// Store returns the object store images are saved to
+func (s *Storage) Store() objectstore.Store {
+	return s.store
+}
+
+// DownloadAndSave downloads an image from URL and saves it with its responsive variants
+// SavedImage.Path is relative to the public folder (e.g., /images/articles/3f/3f9c...e1.webp), SavedImage.URL is the public URL
+func (s *Storage) DownloadAndSave(imageURL string, articleSlug string, filename string) (*SavedImage, error) {
+	imageData, fileExt, err := s.Download("[IMAGE STORAGE]", imageURL)
+	if err != nil {
+		return nil, err
 	}
+	return s.SaveArticleImage(articleSlug, filename, fileExt, imageData)
 }
 
-// DownloadAndSave downloads an image from URL and saves it locally
-// Returns the relative path from public folder (e.g., /uploads/artikel-slug/hero.webp)
-func (s *Storage) DownloadAndSave(imageURL string, articleSlug string, filename string) (string, error) {
-	log.Printf("[IMAGE STORAGE] Starting download: URL=%s, slug=%s, filename=%s", imageURL, articleSlug, filename)
-	log.Printf("[IMAGE STORAGE] Base directory: %s", s.baseDir)
+// Download fetches an image from the image API URL; ext is detected from Content-Type
+func (s *Storage) Download(tag, imageURL string) ([]byte, string, error) {
+	log.Printf("%s Starting download: URL=%s, store=%s", tag, imageURL, s.store.Name())
 
-	// Download image
 	resp, err := s.httpClient.Get(imageURL)
 	if err != nil {
-		log.Printf("[IMAGE STORAGE] ERROR: Failed to download image: %v", err)
-		return "", fmt.Errorf("failed to download image: %w", err)
+		log.Printf("%s ERROR: Failed to download image: %v", tag, err)
+		return nil, "", fmt.Errorf("failed to download image: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		log.Printf("[IMAGE STORAGE] ERROR: HTTP status %d", resp.StatusCode)
-		return "", fmt.Errorf("failed to download image: status %d", resp.StatusCode)
+		log.Printf("%s ERROR: HTTP status %d", tag, resp.StatusCode)
+		return nil, "", fmt.Errorf("failed to download image: status %d", resp.StatusCode)
 	}
 
 	// Read image data
 	imageData, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return "", fmt.Errorf("failed to read image data: %w", err)
+		return nil, "", fmt.Errorf("failed to read image data: %w", err)
 	}
 
 	// Detect image format from Content-Type header
@@ -82,38 +90,25 @@ func (s *Storage) DownloadAndSave(imageURL string, articleSlug string, filename
 	} else if strings.Contains(contentType, "image/webp") {
 		fileExt = ".webp"
 	}
+	return imageData, fileExt, nil
+}
 
-	// Create directory structure: public/uploads/artikel-slug/
-	articleDir := filepath.Join(s.baseDir, articleSlug)
-	log.Printf("[IMAGE STORAGE] Creating directory: %s", articleDir)
-	if err := os.MkdirAll(articleDir, 0755); err != nil {
-		log.Printf("[IMAGE STORAGE] ERROR: Failed to create directory: %v", err)
-		return "", fmt.Errorf("failed to create directory: %w", err)
-	}
-	log.Printf("[IMAGE STORAGE] Directory created successfully")
-
-	// Ensure filename has correct extension based on detected format
-	// TODO: Add webp conversion for better performance (currently saving as PNG/JPG from API)
-	currentExt := filepath.Ext(filename)
-	if currentExt == "" || currentExt != fileExt {
-		filename = strings.TrimSuffix(filename, currentExt) + fileExt
-	}
-
-	// Save image file
-	filePath := filepath.Join(articleDir, filename)
-	if err := os.WriteFile(filePath, imageData, 0644); err != nil {
-		return "", fmt.Errorf("failed to save image: %w", err)
-	}
+// SaveArticleImage stores downloaded bytes as an article image
+// FASE C - C4: Paths relative to public folder: /images/articles/{hash}
+func (s *Storage) SaveArticleImage(articleSlug, filename, fileExt string, data []byte) (*SavedImage, error) {
+	return s.saveImage("[IMAGE STORAGE]", "images/articles", articleSlug+"/"+filename, fileExt, data)
+}
 
-	// FASE C - C4: Return relative path from public folder
-	// Path format: /images/articles/{slug}/{filename}
-	relativePath := fmt.Sprintf("/images/articles/%s/%s", articleSlug, filename)
-	
-	// M-02: Normalize path before returning (ensures consistency)
-	normalizedPath := NormalizeImagePath(relativePath)
-	log.Printf("[IMAGE STORAGE] Image saved to: %s (relative: %s)", filePath, normalizedPath)
+// SaveProductImage stores downloaded bytes as a product image
+// M-05: Paths relative to public folder: /images/products/{hash}
+func (s *Storage) SaveProductImage(productSlug, filename, fileExt string, data []byte) (*SavedImage, error) {
+	return s.saveImage("[PRODUCT IMAGE STORAGE]", "images/products", productSlug+"/"+filename, fileExt, data)
+}
 
-	return normalizedPath, nil
+// SaveLibraryImage stores an uploaded image library photo (library.go)
+// Paths relative to public folder: /images/library/{hash}
+func (s *Storage) SaveLibraryImage(filename, fileExt string, data []byte) (*SavedImage, error) {
+	return s.saveImage("[IMAGE LIBRARY]", "images/library", filename, fileExt, data)
 }
 
 // GenerateSlugFromTitle creates a URL-friendly slug from title
@@ -151,78 +146,63 @@ func GenerateSlugFromTitle(title string) string {
 	return slug
 }
 
-// M-05: DownloadAndSaveProduct downloads and saves product images to /images/products/{slug}/
-// Similar to DownloadAndSave but uses products directory instead of articles
-func (s *Storage) DownloadAndSaveProduct(imageURL string, productSlug string, filename string) (string, error) {
-	log.Printf("[PRODUCT IMAGE STORAGE] Starting download: URL=%s, slug=%s, filename=%s", imageURL, productSlug, filename)
-
-	// Download image
-	resp, err := s.httpClient.Get(imageURL)
-	if err != nil {
-		log.Printf("[PRODUCT IMAGE STORAGE] ERROR: Failed to download image: %v", err)
-		return "", fmt.Errorf("failed to download image: %w", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		log.Printf("[PRODUCT IMAGE STORAGE] ERROR: HTTP status %d", resp.StatusCode)
-		return "", fmt.Errorf("failed to download image: status %d", resp.StatusCode)
-	}
-
-	// Read image data
-	imageData, err := io.ReadAll(resp.Body)
+// M-05: DownloadAndSaveProduct downloads and saves product images under /images/products/
+// Similar to DownloadAndSave but uses the products prefix instead of articles
+func (s *Storage) DownloadAndSaveProduct(imageURL string, productSlug string, filename string) (*SavedImage, error) {
+	imageData, fileExt, err := s.Download("[PRODUCT IMAGE STORAGE]", imageURL)
 	if err != nil {
-		return "", fmt.Errorf("failed to read image data: %w", err)
-	}
-
-	// Detect image format from Content-Type header
-	contentType := resp.Header.Get("Content-Type")
-	fileExt := ".png" // Default to PNG (DALL-E returns PNG)
-	if strings.Contains(contentType, "image/png") {
-		fileExt = ".png"
-	} else if strings.Contains(contentType, "image/jpeg") || strings.Contains(contentType, "image/jpg") {
-		fileExt = ".jpg"
-	} else if strings.Contains(contentType, "image/webp") {
-		fileExt = ".webp"
+		return nil, err
 	}
+	return s.SaveProductImage(productSlug, filename, fileExt, imageData)
+}
 
-	// M-05: Create directory structure: public/images/products/{slug}/
-	// Server runs from engine-hub, so go up one level to workspace root
-	productsBaseDir := filepath.Join("..", "public", "images", "products")
-	productDir := filepath.Join(productsBaseDir, productSlug)
-	
-	// Resolve to absolute path
-	absProductDir, err := filepath.Abs(productDir)
-	if err == nil {
-		productDir = absProductDir
-	}
-	
-	log.Printf("[PRODUCT IMAGE STORAGE] Creating directory: %s", productDir)
-	if err := os.MkdirAll(productDir, 0755); err != nil {
-		log.Printf("[PRODUCT IMAGE STORAGE] ERROR: Failed to create directory: %v", err)
-		return "", fmt.Errorf("failed to create directory: %w", err)
-	}
-	log.Printf("[PRODUCT IMAGE STORAGE] Directory created successfully")
+// saveImage stores the responsive variants of data under prefix (name is only used in logs)
+// Falls back to the original bytes when variants are off or the image cannot be decoded
+func (s *Storage) saveImage(tag, prefix, name, fileExt string, data []byte) (*SavedImage, error) {
+	ctx := context.Background()
 
-	// Ensure filename has correct extension
-	currentExt := filepath.Ext(filename)
-	if currentExt == "" || currentExt != fileExt {
-		filename = strings.TrimSuffix(filename, currentExt) + fileExt
+	if s.variants.Enabled {
+		set, err := buildDerivatives(data, s.variants)
+		if err == nil {
+			saved := &SavedImage{Placeholder: set.Placeholder}
+			deduped := 0
+			for _, v := range set.Variants {
+				obj, err := objectstore.PutContent(ctx, s.store, prefix, v.Data, set.Ext, objectstore.ContentTypeForExt(set.Ext))
+				if err != nil {
+					return nil, fmt.Errorf("failed to save image variant: %w", err)
+				}
+				if obj.Deduped {
+					deduped++
+				}
+				// M-02: Normalize path before returning (ensures consistency)
+				saved.Variants = append(saved.Variants, ImageVariant{
+					Width:  v.Width,
+					Height: v.Height,
+					Path:   NormalizeImagePath(obj.Path),
+					URL:    obj.URL,
+					Format: set.Format,
+					Bytes:  len(v.Data),
+				})
+			}
+			largest := saved.Variants[len(saved.Variants)-1]
+			saved.Path, saved.URL, saved.Width, saved.Height = largest.Path, largest.URL, largest.Width, largest.Height
+			log.Printf("%s Image %s saved: %d %s variants in %s (%d already stored, original %d bytes, largest %dw %d bytes)",
+				tag, name, len(saved.Variants), set.Format, s.store.Name(), deduped, len(data), largest.Width, largest.Bytes)
+			return saved, nil
+		}
+		log.Printf("%s WARNING: %v - saving original as-is", tag, err)
 	}
 
-	// Save image file
-	filePath := filepath.Join(productDir, filename)
-	if err := os.WriteFile(filePath, imageData, 0644); err != nil {
-		return "", fmt.Errorf("failed to save image: %w", err)
+	obj, err := objectstore.PutContent(ctx, s.store, prefix, data, fileExt, objectstore.ContentTypeForExt(fileExt))
+	if err != nil {
+		return nil, fmt.Errorf("failed to save image: %w", err)
 	}
 
-	// M-05: Return relative path from public folder
-	// Path format: /images/products/{slug}/{filename}
-	relativePath := fmt.Sprintf("/images/products/%s/%s", productSlug, filename)
-	
 	// M-02: Normalize path before returning (ensures consistency)
-	normalizedPath := NormalizeImagePath(relativePath)
-	log.Printf("[PRODUCT IMAGE STORAGE] Image saved to: %s (relative: %s)", filePath, normalizedPath)
-
-	return normalizedPath, nil
+	saved := &SavedImage{Path: NormalizeImagePath(obj.Path), URL: obj.URL}
+	if cfg, _, err := image.DecodeConfig(bytes.NewReader(data)); err == nil {
+		saved.Width, saved.Height = cfg.Width, cfg.Height
+	}
+	log.Printf("%s Image %s saved to %s: %s (deduped: %v)", tag, name, s.store.Name(), saved.URL, obj.Deduped)
+	return saved, nil
 }
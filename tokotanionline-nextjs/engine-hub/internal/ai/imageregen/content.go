@@ -0,0 +1,132 @@
+package imageregen
+
+import (
+	"fmt"
+	"html"
+	"regexp"
+	"sort"
+	"strings"
+
+	"engine-hub/internal/ai/image"
+)
+
+// Stored content is markdown (pipeline output, "## Heading" + ![alt](src) / <img srcset>)
+// or HTML (Next.js editor, <h2>Heading</h2> + <img>); both are handled without a full parser
+var (
+	markdownHeading = regexp.MustCompile(`(?m)^#{2,3}[ \t]+(.+?)[ \t#]*$`)
+	htmlHeading     = regexp.MustCompile(`(?is)<h[23]\b[^>]*>(.*?)</h[23]>`)
+	imageRef        = regexp.MustCompile(`(?is)<img\b[^>]*>|!\[[^\]]*\]\([^)\s]+(?:\s+"[^"]*")?\)`)
+	htmlTag         = regexp.MustCompile(`(?s)<[^>]+>`)
+	srcAttr         = regexp.MustCompile(`(?is)\bsrc\s*=\s*["']([^"']*)["']`)
+)
+
+// section is one H2/H3 block of stored content (byte offsets)
+type section struct {
+	Heading   string
+	Start     int // start of the heading
+	BodyStart int // right after the heading
+	End       int // start of the next heading or end of content
+}
+
+// sections splits content at its H2/H3 headings (markdown and HTML)
+func sections(content string) []section {
+	var out []section
+	for _, m := range markdownHeading.FindAllStringSubmatchIndex(content, -1) {
+		out = append(out, section{Heading: cleanText(content[m[2]:m[3]]), Start: m[0], BodyStart: m[1]})
+	}
+	for _, m := range htmlHeading.FindAllStringSubmatchIndex(content, -1) {
+		out = append(out, section{Heading: cleanText(content[m[2]:m[3]]), Start: m[0], BodyStart: m[1]})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Start < out[j].Start })
+	for i := range out {
+		out[i].End = len(content)
+		if i+1 < len(out) {
+			out[i].End = out[i+1].Start
+		}
+	}
+	return out
+}
+
+// findSection matches a heading case-insensitively: exact first, then partial (same rule as the pipeline injection)
+func findSection(secs []section, heading string) (section, error) {
+	want := strings.ToLower(strings.TrimSpace(heading))
+	for _, s := range secs {
+		if strings.ToLower(s.Heading) == want {
+			return s, nil
+		}
+	}
+	for _, s := range secs {
+		have := strings.ToLower(s.Heading)
+		if have != "" && (strings.Contains(have, want) || strings.Contains(want, have)) {
+			return s, nil
+		}
+	}
+	headings := make([]string, 0, len(secs))
+	for _, s := range secs {
+		headings = append(headings, s.Heading)
+	}
+	return section{}, fmt.Errorf("%w: section %q not found (headings: %s)", ErrInvalidRequest, heading, strings.Join(headings, " | "))
+}
+
+// imageIn returns the first image reference inside the section body (absolute offsets)
+func imageIn(content string, s section) (start, end int, ok bool) {
+	m := imageRef.FindStringIndex(content[s.BodyStart:s.End])
+	if m == nil {
+		return 0, 0, false
+	}
+	return s.BodyStart + m[0], s.BodyStart + m[1], true
+}
+
+// sectionText is the plain text of a section body (prompt context and alt text)
+func sectionText(content string, s section) string {
+	body := imageRef.ReplaceAllString(content[s.BodyStart:s.End], " ")
+	return cleanText(body)
+}
+
+// refSrc extracts the image source of an <img> tag or markdown image
+func refSrc(ref string) string {
+	if m := srcAttr.FindStringSubmatch(ref); m != nil {
+		return html.UnescapeString(m[1])
+	}
+	if i := strings.Index(ref, "]("); i >= 0 {
+		src := strings.TrimSuffix(ref[i+2:], ")")
+		if fields := strings.Fields(src); len(fields) > 0 {
+			return fields[0]
+		}
+	}
+	return ""
+}
+
+// isHTML reports whether stored content is HTML (new references must then be <img> tags)
+func isHTML(content string) bool {
+	return htmlHeading.MatchString(content) || strings.Contains(content, "<p>") || strings.Contains(content, "<p ")
+}
+
+// reference renders the asset for content: ContentReference, or a plain <img> when HTML content
+// would otherwise get a markdown image (no variants)
+func reference(asset image.ImageAsset, htmlContent bool) string {
+	ref := asset.ContentReference()
+	if htmlContent && strings.HasPrefix(ref, "![") {
+		return fmt.Sprintf(`<img src="%s" alt="%s" loading="lazy">`, html.EscapeString(asset.Src()), html.EscapeString(asset.AltText))
+	}
+	return ref
+}
+
+// replaceSectionImage swaps the section's image for newRef, or inserts it right after the heading
+// when the section has none (same placement as the pipeline). Returns the old reference ("" if inserted)
+func replaceSectionImage(content string, s section, newRef string) (string, string) {
+	if start, end, ok := imageIn(content, s); ok {
+		return content[:start] + newRef + content[end:], content[start:end]
+	}
+	sep := "\n\n"
+	if isHTML(content) {
+		sep = "\n"
+	}
+	return content[:s.BodyStart] + sep + newRef + content[s.BodyStart:], ""
+}
+
+// cleanText strips tags and entities and collapses whitespace
+func cleanText(s string) string {
+	s = html.UnescapeString(htmlTag.ReplaceAllString(s, " "))
+	return strings.Join(strings.Fields(s), " ")
+}
@@ -0,0 +1,82 @@
+package imageregen
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// PostgresStore persists revisions in "EngineHubImageRevision"
+type PostgresStore struct {
+	db *sql.DB
+}
+
+// NewPostgresStore creates a revision store backed by the given database
+func NewPostgresStore(db *sql.DB) *PostgresStore {
+	return &PostgresStore{db: db}
+}
+
+const revisionColumns = `id, kind, slug, "targetId", section, role, "oldSrc", "oldRef", "newSrc", "newRef",
+	prompt, "altText", draft, "restoredFrom", "requestedBy", "createdAt"`
+
+func (p *PostgresStore) InsertRevision(r Revision) error {
+	_, err := p.db.Exec(`
+		INSERT INTO "EngineHubImageRevision" (`+revisionColumns+`)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16)
+	`, r.ID, r.Kind, r.Slug, nullString(r.TargetID), nullString(r.Section), r.Role, nullString(r.OldSrc), nullString(r.OldRef),
+		r.NewSrc, r.NewRef, nullString(r.Prompt), nullString(r.AltText), r.Draft, nullString(r.RestoredFrom), nullString(r.RequestedBy), r.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to insert image revision: %w", err)
+	}
+	return nil
+}
+
+func (p *PostgresStore) GetRevision(id string) (*Revision, error) {
+	rows, err := p.db.Query(`SELECT `+revisionColumns+` FROM "EngineHubImageRevision" WHERE id = $1`, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query image revision: %w", err)
+	}
+	defer rows.Close()
+	list, err := scanRevisions(rows)
+	if err != nil {
+		return nil, err
+	}
+	if len(list) == 0 {
+		return nil, ErrRevisionNotFound
+	}
+	return &list[0], nil
+}
+
+func (p *PostgresStore) ListRevisions(kind, slug string, limit int) ([]Revision, error) {
+	query := `SELECT ` + revisionColumns + ` FROM "EngineHubImageRevision" WHERE kind = $1 AND slug = $2 ORDER BY "createdAt" DESC`
+	args := []interface{}{kind, slug}
+	if limit > 0 {
+		query += ` LIMIT $3`
+		args = append(args, limit)
+	}
+	rows, err := p.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list image revisions: %w", err)
+	}
+	defer rows.Close()
+	return scanRevisions(rows)
+}
+
+func scanRevisions(rows *sql.Rows) ([]Revision, error) {
+	var out []Revision
+	for rows.Next() {
+		var r Revision
+		var targetID, section, oldSrc, oldRef, prompt, altText, restoredFrom, requestedBy sql.NullString
+		if err := rows.Scan(&r.ID, &r.Kind, &r.Slug, &targetID, &section, &r.Role, &oldSrc, &oldRef, &r.NewSrc, &r.NewRef,
+			&prompt, &altText, &r.Draft, &restoredFrom, &requestedBy, &r.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan image revision: %w", err)
+		}
+		r.TargetID, r.Section, r.OldSrc, r.OldRef = targetID.String, section.String, oldSrc.String, oldRef.String
+		r.Prompt, r.AltText, r.RestoredFrom, r.RequestedBy = prompt.String, altText.String, restoredFrom.String, requestedBy.String
+		out = append(out, r)
+	}
+	return out, rows.Err()
+}
+
+func nullString(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
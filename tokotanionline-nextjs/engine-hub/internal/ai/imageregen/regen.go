@@ -0,0 +1,490 @@
+package imageregen
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"engine-hub/internal/ai/image"
+	"engine-hub/internal/content"
+)
+
+// SINGLE IMAGE REGENERATION (artikel & produk)
+// Editor tidak perlu generate ulang semua gambar: satu gambar (hero / section / detail) dibuat ulang,
+// referensinya diganti di record yang tersimpan, dan gambar lama dicatat sebagai revisi (file lama tidak dihapus)
+// - Artikel: BlogPost.content (gambar section tepat setelah heading-nya) + BlogPost.featuredImageUrl (hero)
+// - Produk:  Product.imageUrl (hero) + Product.images (JSON array path)
+// - Draft:   record dikirim di request (editor belum menyimpan) → hasil dikembalikan, database tidak diubah
+
+var (
+	ErrInvalidRequest   = errors.New("invalid image regeneration request")
+	ErrNotFound         = errors.New("record not found")
+	ErrAmbiguous        = errors.New("slug matches more than one article, pass brandId")
+	ErrNoDatabase       = errors.New("database not available (send the record as draft instead)")
+	ErrRevisionNotFound = errors.New("image revision not found")
+	ErrConflict         = errors.New("image was changed after this revision")
+)
+
+// Draft is a record sent with the request instead of being loaded from the database (unsaved editor state)
+type Draft struct {
+	Title            string   `json:"title,omitempty"`            // article title / product name
+	Content          string   `json:"content,omitempty"`          // article content (markdown or HTML)
+	Description      string   `json:"description,omitempty"`      // product description
+	FeaturedImageURL string   `json:"featuredImageUrl,omitempty"` // article hero / product imageUrl
+	Images           []string `json:"images,omitempty"`           // product images
+}
+
+// Request selects the image to regenerate
+type Request struct {
+	Kind        string `json:"kind"` // article (default) | product
+	Slug        string `json:"slug"`
+	BrandID     string `json:"brandId,omitempty"` // BlogPost slugs are unique per brand
+	Section     string `json:"section,omitempty"` // article section heading (case-insensitive, partial match)
+	Role        string `json:"role,omitempty"`    // hero | section (article, default section) | detail (product, default hero)
+	Index       int    `json:"index,omitempty"`   // product detail: 1-based position among the detail images (past the end = add)
+	Prompt      string `json:"prompt,omitempty"`  // override, sent to the image API as-is
+	AltText     string `json:"altText,omitempty"` // override; default derived from the section / product
+	Draft       *Draft `json:"draft,omitempty"`
+	RequestedBy string `json:"requestedBy,omitempty"`
+}
+
+// Result is the outcome of a regeneration or restore
+type Result struct {
+	Revision Revision             `json:"revision"`
+	Image    *image.ImageAsset    `json:"image,omitempty"`
+	Quality  *image.QualityReport `json:"quality,omitempty"`
+	Record   Draft                `json:"record"` // content / featuredImageUrl / images after the change
+	Saved    bool                 `json:"saved"`  // written to BlogPost / Product
+}
+
+// target is the record an image belongs to
+type target struct {
+	Kind        string
+	ID          string
+	Slug        string
+	Title       string
+	Description string
+	Content     string
+	Featured    string
+	Images      []string
+	Draft       bool
+}
+
+func (t *target) record() Draft {
+	return Draft{Title: t.Title, Content: t.Content, Description: t.Description, FeaturedImageURL: t.Featured, Images: t.Images}
+}
+
+func (r *Request) normalize() error {
+	r.Kind = strings.ToLower(strings.TrimSpace(r.Kind))
+	if r.Kind == "" {
+		r.Kind = image.KindArticle
+	}
+	r.Slug = strings.TrimSpace(r.Slug)
+	r.Role = strings.ToLower(strings.TrimSpace(r.Role))
+	if r.Slug == "" {
+		return fmt.Errorf("%w: slug is required", ErrInvalidRequest)
+	}
+	switch r.Kind {
+	case image.KindArticle:
+		if r.Role == "" {
+			r.Role = "section"
+		}
+		if r.Role != "hero" && r.Role != "section" {
+			return fmt.Errorf("%w: article role must be hero or section", ErrInvalidRequest)
+		}
+		if r.Role == "section" && strings.TrimSpace(r.Section) == "" {
+			return fmt.Errorf("%w: section is required for role section", ErrInvalidRequest)
+		}
+	case image.KindProduct:
+		if r.Role == "" {
+			r.Role = "hero"
+			if r.Index > 0 {
+				r.Role = "detail"
+			}
+		}
+		if r.Role != "hero" && r.Role != "detail" {
+			return fmt.Errorf("%w: product role must be hero or detail", ErrInvalidRequest)
+		}
+		if r.Role == "detail" && r.Index <= 0 {
+			r.Index = 1
+		}
+	default:
+		return fmt.Errorf("%w: kind must be article or product", ErrInvalidRequest)
+	}
+	return nil
+}
+
+// Regenerate generates one image again and puts it in place of the current one
+func Regenerate(ctx context.Context, req Request) (*Result, error) {
+	if err := req.normalize(); err != nil {
+		return nil, err
+	}
+	unlock := lockTarget(req.Kind, req.Slug)
+	defer unlock()
+
+	t, err := load(ctx, req, "")
+	if err != nil {
+		return nil, err
+	}
+	genReq, err := describe(t, req)
+	if err != nil {
+		return nil, err
+	}
+
+	genReq.Replaces = currentSrc(t, req)
+	asset, report, err := image.NewGenerator().RegenerateImage(ctx, genReq)
+	if err != nil {
+		return &Result{Quality: report}, err
+	}
+
+	// Generation takes a while: apply to the current record, not the one read before
+	if !t.Draft {
+		if t, err = load(ctx, req, t.ID); err != nil {
+			return nil, err
+		}
+	}
+	rev, err := apply(t, req, asset)
+	if err != nil {
+		return nil, err
+	}
+	rev.Prompt, rev.AltText = asset.Prompt, asset.AltText
+	if err := finish(ctx, t, rev); err != nil {
+		return nil, err
+	}
+
+	return &Result{Revision: *rev, Image: &asset, Quality: report, Record: t.record(), Saved: !t.Draft}, nil
+}
+
+// Restore puts the image replaced by a revision back (recorded as a new revision)
+func Restore(ctx context.Context, id, requestedBy string) (*Result, error) {
+	prev, err := getStore().GetRevision(id)
+	if err != nil {
+		return nil, err
+	}
+	if prev.Draft {
+		return nil, fmt.Errorf("%w: draft revisions were never saved, restore them in the editor", ErrInvalidRequest)
+	}
+	unlock := lockTarget(prev.Kind, prev.Slug)
+	defer unlock()
+
+	t, err := load(ctx, Request{Kind: prev.Kind, Slug: prev.Slug}, prev.TargetID)
+	if err != nil {
+		return nil, err
+	}
+	if err := swapBack(t, prev); err != nil {
+		return nil, err
+	}
+
+	rev := &Revision{
+		Kind: prev.Kind, Slug: prev.Slug, TargetID: t.ID, Section: prev.Section, Role: prev.Role,
+		OldSrc: prev.NewSrc, OldRef: prev.NewRef, NewSrc: prev.OldSrc, NewRef: prev.OldRef,
+		AltText: prev.AltText, RestoredFrom: prev.ID, RequestedBy: requestedBy,
+	}
+	if err := finish(ctx, t, rev); err != nil {
+		return nil, err
+	}
+	return &Result{Revision: *rev, Record: t.record(), Saved: true}, nil
+}
+
+// List returns the revisions of one article or product (newest first)
+func List(kind, slug string, limit int) ([]Revision, error) {
+	if kind == "" {
+		kind = image.KindArticle
+	}
+	return getStore().ListRevisions(kind, slug, limit)
+}
+
+// describe builds the image request (prompt context, alt text source) for the selected image
+func describe(t *target, req Request) (image.RegenerateRequest, error) {
+	gen := image.RegenerateRequest{Kind: req.Kind, Slug: req.Slug, Role: req.Role, Prompt: req.Prompt, AltText: req.AltText}
+	if req.Kind == image.KindProduct {
+		gen.Heading, gen.Content = t.Title, t.Description
+		return gen, nil
+	}
+
+	secs := sections(t.Content)
+	switch {
+	case strings.TrimSpace(req.Section) != "":
+		s, err := findSection(secs, req.Section)
+		if err != nil {
+			return gen, err
+		}
+		gen.Heading, gen.Content = s.Heading, sectionText(t.Content, s)
+	case len(secs) > 0:
+		// M-04: the hero comes from the first section, like in GenerateImages
+		gen.Heading, gen.Content = secs[0].Heading, sectionText(t.Content, secs[0])
+	default:
+		gen.Heading, gen.Content = t.Title, cleanText(t.Content)
+	}
+	if gen.Heading == "" {
+		return gen, fmt.Errorf("%w: no heading or title to describe the image", ErrInvalidRequest)
+	}
+	return gen, nil
+}
+
+// currentSrc is the src of the image that will be replaced ("" when there is none yet)
+func currentSrc(t *target, req Request) string {
+	switch {
+	case req.Role == "hero":
+		return t.Featured
+	case t.Kind == image.KindArticle:
+		if s, err := findSection(sections(t.Content), req.Section); err == nil {
+			if start, end, ok := imageIn(t.Content, s); ok {
+				return refSrc(t.Content[start:end])
+			}
+		}
+	default:
+		n := 0
+		for _, src := range t.Images {
+			if src != t.Featured {
+				if n++; n == req.Index {
+					return src
+				}
+			}
+		}
+	}
+	return ""
+}
+
+// apply puts the new image in the record and returns the revision (old/new reference)
+func apply(t *target, req Request, asset image.ImageAsset) (*Revision, error) {
+	newPath := asset.LocalPath
+	if newPath == "" {
+		newPath = asset.Src()
+	}
+	rev := &Revision{Kind: t.Kind, Slug: t.Slug, TargetID: t.ID, Role: req.Role, NewSrc: newPath, NewRef: newPath, Draft: t.Draft, RequestedBy: req.RequestedBy}
+
+	switch {
+	case t.Kind == image.KindArticle && req.Role == "hero":
+		// M-04: hero is not in the content, only featuredImageUrl
+		rev.OldSrc, rev.OldRef = t.Featured, t.Featured
+		t.Featured = newPath
+	case t.Kind == image.KindArticle:
+		s, err := findSection(sections(t.Content), req.Section)
+		if err != nil {
+			return nil, err
+		}
+		rev.Section = s.Heading
+		rev.NewSrc, rev.NewRef = asset.Src(), reference(asset, isHTML(t.Content))
+		t.Content, rev.OldRef = replaceSectionImage(t.Content, s, rev.NewRef)
+		rev.OldSrc = refSrc(rev.OldRef)
+	case req.Role == "hero":
+		rev.OldSrc, rev.OldRef = t.Featured, t.Featured
+		if i := indexOf(t.Images, t.Featured); t.Featured != "" && i >= 0 {
+			t.Images[i] = newPath
+		} else {
+			t.Images = append([]string{newPath}, t.Images...)
+		}
+		t.Featured = newPath
+	default:
+		var details []int // positions of the detail images in t.Images
+		for i, src := range t.Images {
+			if src != t.Featured {
+				details = append(details, i)
+			}
+		}
+		if req.Index <= len(details) {
+			pos := details[req.Index-1]
+			rev.OldSrc, rev.OldRef = t.Images[pos], t.Images[pos]
+			t.Images[pos] = newPath
+		} else {
+			t.Images = append(t.Images, newPath)
+		}
+	}
+	return rev, nil
+}
+
+// swapBack undoes a revision if its new reference is still in place
+func swapBack(t *target, prev *Revision) error {
+	if t.Kind == image.KindArticle && prev.Role != "hero" {
+		i := strings.Index(t.Content, prev.NewRef)
+		if prev.NewRef == "" || i < 0 {
+			return fmt.Errorf("%w: the regenerated image is no longer in the content", ErrConflict)
+		}
+		start, end := i, i+len(prev.NewRef)
+		if prev.OldRef == "" {
+			// Image was inserted after the heading: remove it with its separator
+			for n := 0; n < 2 && start > 0 && t.Content[start-1] == '\n'; n++ {
+				start--
+			}
+		}
+		t.Content = t.Content[:start] + prev.OldRef + t.Content[end:]
+		return nil
+	}
+
+	if prev.Role == "hero" {
+		if t.Featured != prev.NewRef {
+			return fmt.Errorf("%w: the hero image is %q now", ErrConflict, t.Featured)
+		}
+		t.Featured = prev.OldRef
+		if t.Kind == image.KindArticle {
+			return nil
+		}
+	}
+	i := indexOf(t.Images, prev.NewRef)
+	if i < 0 {
+		if prev.Role == "hero" {
+			return nil
+		}
+		return fmt.Errorf("%w: the regenerated image is no longer in the product images", ErrConflict)
+	}
+	if prev.OldRef == "" {
+		t.Images = append(t.Images[:i], t.Images[i+1:]...)
+	} else {
+		t.Images[i] = prev.OldRef
+	}
+	return nil
+}
+
+// finish saves the record (unless draft) and stores the revision
+func finish(ctx context.Context, t *target, rev *Revision) error {
+	if !t.Draft {
+		if err := save(ctx, t); err != nil {
+			return err
+		}
+	}
+	rev.ID = uuid.New().String()
+	rev.CreatedAt = time.Now().UTC()
+	if err := getStore().InsertRevision(*rev); err != nil {
+		if t.Draft {
+			return err
+		}
+		// The record is already updated; losing the history entry must not hide that
+		log.Printf("[IMAGE REGEN] WARNING: %s %s updated but revision not stored: %v", t.Kind, t.Slug, err)
+	}
+	log.Printf("[IMAGE REGEN] %s %s [%s] %q → %q (draft=%v)", t.Kind, t.Slug, rev.Role, rev.OldSrc, rev.NewSrc, t.Draft)
+	return nil
+}
+
+// load reads the record from the request draft or the database (by id when known)
+func load(ctx context.Context, req Request, id string) (*target, error) {
+	t := &target{Kind: req.Kind, Slug: req.Slug}
+	if d := req.Draft; d != nil {
+		t.Draft = true
+		t.Title, t.Content, t.Description, t.Featured = d.Title, d.Content, d.Description, d.FeaturedImageURL
+		t.Images = append([]string(nil), d.Images...)
+		if t.Kind == image.KindArticle && strings.TrimSpace(t.Content) == "" {
+			return nil, fmt.Errorf("%w: draft.content is required for articles", ErrInvalidRequest)
+		}
+		if t.Kind == image.KindProduct && strings.TrimSpace(t.Title) == "" {
+			return nil, fmt.Errorf("%w: draft.title (product name) is required for products", ErrInvalidRequest)
+		}
+		return t, nil
+	}
+
+	db := content.GetDB()
+	if db == nil {
+		return nil, ErrNoDatabase
+	}
+	var rows *sql.Rows
+	var err error
+	if t.Kind == image.KindProduct {
+		query := `SELECT id, name, description, COALESCE("imageUrl", ''), COALESCE(images, '') FROM "Product" WHERE slug = $1`
+		args := []interface{}{req.Slug}
+		if id != "" {
+			query, args = `SELECT id, name, description, COALESCE("imageUrl", ''), COALESCE(images, '') FROM "Product" WHERE id = $1`, []interface{}{id}
+		}
+		rows, err = db.QueryContext(ctx, query, args...)
+	} else {
+		query := `SELECT id, title, content, COALESCE("featuredImageUrl", ''), '' FROM "BlogPost" WHERE slug = $1 AND ($2 = '' OR "brandId" = $2)`
+		args := []interface{}{req.Slug, req.BrandID}
+		if id != "" {
+			query, args = `SELECT id, title, content, COALESCE("featuredImageUrl", ''), '' FROM "BlogPost" WHERE id = $1`, []interface{}{id}
+		}
+		rows, err = db.QueryContext(ctx, query, args...)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load %s: %w", t.Kind, err)
+	}
+	defer rows.Close()
+
+	found := 0
+	for rows.Next() {
+		var text, images string
+		if err := rows.Scan(&t.ID, &t.Title, &text, &t.Featured, &images); err != nil {
+			return nil, fmt.Errorf("failed to scan %s: %w", t.Kind, err)
+		}
+		if t.Kind == image.KindProduct {
+			t.Description = text
+			if images != "" {
+				if err := json.Unmarshal([]byte(images), &t.Images); err != nil {
+					return nil, fmt.Errorf("failed to parse Product.images of %s: %w", req.Slug, err)
+				}
+			}
+		} else {
+			t.Content = text
+		}
+		found++
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to load %s: %w", t.Kind, err)
+	}
+	switch {
+	case found == 0:
+		return nil, fmt.Errorf("%w: %s %s", ErrNotFound, t.Kind, req.Slug)
+	case found > 1:
+		return nil, ErrAmbiguous
+	}
+	return t, nil
+}
+
+// save writes the changed image references back
+func save(ctx context.Context, t *target) error {
+	db := content.GetDB()
+	if db == nil {
+		return ErrNoDatabase
+	}
+	var err error
+	now := time.Now()
+	if t.Kind == image.KindProduct {
+		var images sql.NullString
+		if len(t.Images) > 0 {
+			data, _ := json.Marshal(t.Images)
+			images = sql.NullString{String: string(data), Valid: true}
+		}
+		_, err = db.ExecContext(ctx, `UPDATE "Product" SET "imageUrl" = $2, images = $3, "updatedAt" = $4 WHERE id = $1`,
+			t.ID, nullString(t.Featured), images, now)
+	} else {
+		_, err = db.ExecContext(ctx, `UPDATE "BlogPost" SET content = $2, "featuredImageUrl" = $3, "updatedAt" = $4 WHERE id = $1`,
+			t.ID, t.Content, nullString(t.Featured), now)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to update %s %s: %w", t.Kind, t.Slug, err)
+	}
+	return nil
+}
+
+var (
+	locksMu sync.Mutex
+	locks   = make(map[string]*sync.Mutex)
+)
+
+// lockTarget serialises regenerations of the same article/product (read-modify-write of one record)
+func lockTarget(kind, slug string) func() {
+	locksMu.Lock()
+	l, ok := locks[kind+"/"+slug]
+	if !ok {
+		l = &sync.Mutex{}
+		locks[kind+"/"+slug] = l
+	}
+	locksMu.Unlock()
+	l.Lock()
+	return l.Unlock
+}
+
+func indexOf(list []string, s string) int {
+	for i, v := range list {
+		if v == s {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,101 @@
+package imageregen
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// Revision is one image replacement (regeneration or restore)
+// OldRef / NewRef are the exact references swapped in the stored record: the <img>/markdown tag for
+// article sections, the /images/ path for heroes and product images. The old file is never deleted,
+// so a revision can always be restored
+type Revision struct {
+	ID           string    `json:"id"`
+	Kind         string    `json:"kind"` // article | product
+	Slug         string    `json:"slug"`
+	TargetID     string    `json:"targetId,omitempty"` // BlogPost.id / Product.id ("" in draft mode)
+	Section      string    `json:"section,omitempty"`  // article section heading
+	Role         string    `json:"role"`               // hero | section | detail
+	OldSrc       string    `json:"oldSrc,omitempty"`
+	OldRef       string    `json:"oldRef,omitempty"`
+	NewSrc       string    `json:"newSrc"`
+	NewRef       string    `json:"newRef"`
+	Prompt       string    `json:"prompt,omitempty"`
+	AltText      string    `json:"altText,omitempty"`
+	Draft        bool      `json:"draft,omitempty"`        // content came with the request, nothing was written
+	RestoredFrom string    `json:"restoredFrom,omitempty"` // revision ID when this is a restore
+	RequestedBy  string    `json:"requestedBy,omitempty"`
+	CreatedAt    time.Time `json:"createdAt"`
+}
+
+// Store persists image revisions
+// MemoryStore is the default; PostgresStore is used when a database is available
+type Store interface {
+	InsertRevision(r Revision) error
+	GetRevision(id string) (*Revision, error)
+	ListRevisions(kind, slug string, limit int) ([]Revision, error) // newest first, limit 0 = no limit
+}
+
+var (
+	storeMu sync.RWMutex
+	store   Store = NewMemoryStore()
+)
+
+// SetStore replaces the revision store (call once at boot)
+func SetStore(s Store) {
+	storeMu.Lock()
+	defer storeMu.Unlock()
+	store = s
+}
+
+func getStore() Store {
+	storeMu.RLock()
+	defer storeMu.RUnlock()
+	return store
+}
+
+// MemoryStore keeps revisions in memory (lost on restart, dev mode)
+type MemoryStore struct {
+	mu        sync.Mutex
+	revisions []Revision
+}
+
+// NewMemoryStore creates an empty in-memory revision store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{}
+}
+
+func (m *MemoryStore) InsertRevision(r Revision) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.revisions = append(m.revisions, r)
+	return nil
+}
+
+func (m *MemoryStore) GetRevision(id string) (*Revision, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, r := range m.revisions {
+		if r.ID == id {
+			return &r, nil
+		}
+	}
+	return nil, ErrRevisionNotFound
+}
+
+func (m *MemoryStore) ListRevisions(kind, slug string, limit int) ([]Revision, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var out []Revision
+	for _, r := range m.revisions {
+		if r.Kind == kind && r.Slug == slug {
+			out = append(out, r)
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].CreatedAt.After(out[j].CreatedAt) })
+	if limit > 0 && len(out) > limit {
+		out = out[:limit]
+	}
+	return out, nil
+}
@@ -0,0 +1,107 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+)
+
+const anthropicVersion = "2023-06-01"
+
+// AnthropicProvider calls an Anthropic-style Messages API (/v1/messages)
+type AnthropicProvider struct {
+	URL       string
+	APIKey    string
+	Transport http.RoundTripper // nil = cassette.Default()
+}
+
+func (p *AnthropicProvider) Name() string {
+	return ProviderAnthropic
+}
+
+func (p *AnthropicProvider) Complete(ctx context.Context, model string, req Request) (*Response, error) {
+	// Messages API: system is top-level, messages only user/assistant, max_tokens required
+	system := req.System
+	messages := make([]Message, 0, len(req.messages()))
+	for _, m := range req.messages() {
+		if m.Role == "system" {
+			if system != "" {
+				system += "\n\n"
+			}
+			system += m.Content
+			continue
+		}
+		messages = append(messages, m)
+	}
+
+	maxTokens := req.MaxTokens
+	if maxTokens <= 0 {
+		maxTokens = 1024
+	}
+
+	payload := map[string]interface{}{
+		"model":      model,
+		"messages":   messages,
+		"max_tokens": maxTokens,
+	}
+	if system != "" {
+		payload["system"] = system
+	}
+	if req.Temperature != nil {
+		payload["temperature"] = *req.Temperature
+	}
+	if req.TopP != nil {
+		payload["top_p"] = *req.TopP
+	}
+
+	headers := map[string]string{
+		"x-api-key":         p.APIKey,
+		"anthropic-version": anthropicVersion,
+	}
+
+	started := time.Now()
+	body, err := postJSON(ctx, p.Transport, p.Name(), model, p.URL, headers, payload)
+	if err != nil {
+		return nil, err
+	}
+
+	var r struct {
+		Model   string `json:"model"`
+		Content []struct {
+			Type string `json:"type"`
+			Text string `json:"text"`
+		} `json:"content"`
+		StopReason string `json:"stop_reason"`
+		Usage      struct {
+			InputTokens  int `json:"input_tokens"`
+			OutputTokens int `json:"output_tokens"`
+		} `json:"usage"`
+	}
+	if err := json.Unmarshal(body, &r); err != nil {
+		return nil, decodeError(p.Name(), model, body, err)
+	}
+
+	text := ""
+	for _, c := range r.Content {
+		if c.Type == "text" {
+			text += c.Text
+		}
+	}
+	if text == "" {
+		return nil, &Error{Kind: KindEmpty, Provider: p.Name(), Model: model, Message: "no text content in response", Body: truncate(string(body), maxErrorBody)}
+	}
+
+	return &Response{
+		Text:     text,
+		Provider: p.Name(),
+		Model:    firstNonEmpty(r.Model, model),
+		Usage: Usage{
+			InputTokens:  r.Usage.InputTokens,
+			OutputTokens: r.Usage.OutputTokens,
+			TotalTokens:  r.Usage.InputTokens + r.Usage.OutputTokens,
+		},
+		Latency: time.Since(started),
+		Raw:     body,
+	}, nil
+}
@@ -0,0 +1,92 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+)
+
+// ChatProvider calls an OpenAI Chat Completions compatible endpoint
+// Used for OpenAI (ProviderChat) and local OpenAI-compatible servers like Ollama (ProviderOllama)
+type ChatProvider struct {
+	Provider  string // ProviderChat | ProviderOllama
+	URL       string
+	APIKey    string            // optional for local servers
+	Transport http.RoundTripper // nil = cassette.Default()
+}
+
+func (p *ChatProvider) Name() string {
+	if p.Provider == "" {
+		return ProviderChat
+	}
+	return p.Provider
+}
+
+func (p *ChatProvider) Complete(ctx context.Context, model string, req Request) (*Response, error) {
+	messages := req.messages()
+	if req.System != "" {
+		messages = append([]Message{{Role: "system", Content: req.System}}, messages...)
+	}
+
+	payload := map[string]interface{}{
+		"model":    model,
+		"messages": messages,
+	}
+	if req.MaxTokens > 0 {
+		payload["max_tokens"] = req.MaxTokens
+	}
+	structured := req.Format != nil && p.Name() == ProviderChat
+	if structured {
+		payload["response_format"] = map[string]interface{}{
+			"type": "json_schema",
+			"json_schema": map[string]interface{}{
+				"name":   req.Format.Name,
+				"schema": req.Format.Schema,
+				"strict": true,
+			},
+		}
+	}
+	setSampling(payload, req)
+
+	started := time.Now()
+	body, err := postJSON(ctx, p.Transport, p.Name(), model, p.URL, bearer(p.APIKey), payload)
+	if err != nil {
+		return nil, err
+	}
+
+	var r struct {
+		Model   string `json:"model"`
+		Choices []struct {
+			Message struct {
+				Content string `json:"content"`
+			} `json:"message"`
+			FinishReason string `json:"finish_reason"`
+		} `json:"choices"`
+		Usage struct {
+			PromptTokens     int `json:"prompt_tokens"`
+			CompletionTokens int `json:"completion_tokens"`
+			TotalTokens      int `json:"total_tokens"`
+		} `json:"usage"`
+	}
+	if err := json.Unmarshal(body, &r); err != nil {
+		return nil, decodeError(p.Name(), model, body, err)
+	}
+	if len(r.Choices) == 0 || r.Choices[0].Message.Content == "" {
+		return nil, &Error{Kind: KindEmpty, Provider: p.Name(), Model: model, Message: "no choices in response", Body: truncate(string(body), maxErrorBody)}
+	}
+
+	return &Response{
+		Text:     r.Choices[0].Message.Content,
+		Provider: p.Name(),
+		Model:    firstNonEmpty(r.Model, model),
+		Usage: Usage{
+			InputTokens:  r.Usage.PromptTokens,
+			OutputTokens: r.Usage.CompletionTokens,
+			TotalTokens:  r.Usage.TotalTokens,
+		},
+		Latency:    time.Since(started),
+		Raw:        body,
+		Structured: structured,
+	}, nil
+}
@@ -0,0 +1,207 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"engine-hub/internal/ai/usage"
+)
+
+const (
+	defaultTimeout      = 60 * time.Second
+	defaultRetryBackoff = 1 * time.Second
+	maxRetryBackoff     = 30 * time.Second
+)
+
+// target is one provider+model the Client may call
+type target struct {
+	cfg      Config
+	provider Provider
+}
+
+// Client sends requests to the primary provider with retries, then to fallbacks in order
+type Client struct {
+	caller  string
+	targets []target
+}
+
+// New validates cfg and builds a Client
+// The primary must be usable; unusable fallbacks are logged and skipped
+func New(cfg Config) (*Client, error) {
+	primary, err := newTarget(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &Client{caller: cfg.Caller, targets: []target{primary}}
+	for _, fb := range cfg.Fallbacks {
+		if fb.Caller == "" {
+			fb.Caller = cfg.Caller
+		}
+		if fb.Transport == nil {
+			fb.Transport = cfg.Transport
+		}
+		t, err := newTarget(fb)
+		if err != nil {
+			log.Printf("[LLM] caller=%s skipping fallback %s/%s: %v", cfg.Caller, fb.Provider, fb.Model, err)
+			continue
+		}
+		c.targets = append(c.targets, t)
+	}
+	return c, nil
+}
+
+func newTarget(cfg Config) (target, error) {
+	if cfg.Model == "" {
+		return target{}, &Error{Kind: KindUnavailable, Provider: cfg.Provider, Message: "model is not configured"}
+	}
+	if requiresKey(cfg.Provider) && cfg.APIKey == "" {
+		return target{}, &Error{Kind: KindAuth, Provider: cfg.Provider, Model: cfg.Model, Message: "API key is not configured"}
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = defaultTimeout
+	}
+	if cfg.RetryBackoff <= 0 {
+		cfg.RetryBackoff = defaultRetryBackoff
+	}
+	if cfg.MaxRetries < 0 {
+		cfg.MaxRetries = 0
+	}
+	p, err := newProvider(cfg)
+	if err != nil {
+		return target{}, err
+	}
+	return target{cfg: cfg, provider: p}, nil
+}
+
+// Provider returns the primary provider name
+func (c *Client) Provider() string {
+	return c.targets[0].cfg.Provider
+}
+
+// Model returns the primary model name
+func (c *Client) Model() string {
+	return c.targets[0].cfg.Model
+}
+
+// Complete sends req to the primary target, retrying retryable errors with exponential backoff
+// When the primary fails, each fallback is tried in order
+// The returned error is the last *Error (or the ctx error when the caller cancelled)
+func (c *Client) Complete(ctx context.Context, req Request) (*Response, error) {
+	attempts := 0
+	var lastErr error
+
+	for i, t := range c.targets {
+		model := t.cfg.Model
+		if i == 0 && req.Model != "" {
+			model = req.Model
+		}
+		if i > 0 {
+			log.Printf("[LLM] caller=%s falling back to %s/%s after: %v", c.caller, t.cfg.Provider, model, lastErr)
+		}
+
+		for retry := 0; retry <= t.cfg.MaxRetries; retry++ {
+			if retry > 0 {
+				if err := sleepCtx(ctx, backoff(t.cfg.RetryBackoff, retry)); err != nil {
+					return nil, canceledError(t, model, err)
+				}
+			}
+
+			attempts++
+			resp, err := c.attempt(ctx, t, model, req)
+			if err == nil {
+				resp.Caller = c.caller
+				resp.Attempts = attempts
+				c.record(ctx, resp, nil, t.cfg.Provider, model)
+				return resp, nil
+			}
+			lastErr = err
+
+			if ctx.Err() != nil {
+				err = canceledError(t, model, ctx.Err())
+				c.record(ctx, nil, err, t.cfg.Provider, model)
+				return nil, err
+			}
+			log.Printf("[LLM] caller=%s provider=%s model=%s attempt=%d/%d failed: %v",
+				c.caller, t.cfg.Provider, model, retry+1, t.cfg.MaxRetries+1, err)
+			if !IsRetryable(err) {
+				break
+			}
+		}
+	}
+	last := c.targets[len(c.targets)-1]
+	c.record(ctx, nil, lastErr, last.cfg.Provider, last.cfg.Model)
+	return nil, lastErr
+}
+
+// record writes the outcome of Complete to the usage ledger (attribution comes from ctx)
+func (c *Client) record(ctx context.Context, resp *Response, err error, provider, model string) {
+	entry := usage.Entry{
+		Kind:     usage.KindText,
+		Caller:   c.caller,
+		Provider: provider,
+		Model:    model,
+	}
+	if resp != nil {
+		entry.Provider = resp.Provider
+		entry.Model = resp.Model
+		entry.InputTokens = resp.Usage.InputTokens
+		entry.OutputTokens = resp.Usage.OutputTokens
+		entry.LatencyMs = resp.Latency.Milliseconds()
+		entry.Success = true
+	}
+	if err != nil {
+		entry.Error = err.Error()
+	}
+	usage.Record(ctx, entry)
+}
+
+// attempt runs one provider call bounded by the target timeout
+func (c *Client) attempt(ctx context.Context, t target, model string, req Request) (*Response, error) {
+	attemptCtx, cancel := context.WithTimeout(ctx, t.cfg.Timeout)
+	defer cancel()
+
+	if req.Format != nil && !t.structured() {
+		req.Format = nil // plain text; the caller parses it (ErrStructuredUnsupported)
+	}
+
+	resp, err := t.provider.Complete(attemptCtx, model, req)
+	if err != nil {
+		var llmErr *Error
+		if !errors.As(err, &llmErr) {
+			err = &Error{Kind: KindUnavailable, Provider: t.cfg.Provider, Model: model, Err: err}
+		}
+		return nil, err
+	}
+	return resp, nil
+}
+
+// backoff returns base * 2^(retry-1), capped
+func backoff(base time.Duration, retry int) time.Duration {
+	d := base << (retry - 1)
+	if d <= 0 || d > maxRetryBackoff {
+		return maxRetryBackoff
+	}
+	return d
+}
+
+func sleepCtx(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func canceledError(t target, model string, err error) error {
+	kind := KindCanceled
+	if errors.Is(err, context.DeadlineExceeded) {
+		kind = KindTimeout
+	}
+	return &Error{Kind: kind, Provider: t.cfg.Provider, Model: model, Message: "caller context done", Err: err}
+}
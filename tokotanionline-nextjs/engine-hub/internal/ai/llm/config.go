@@ -0,0 +1,173 @@
+package llm
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Provider names (Config.Provider, LLM_<CALLER>_PROVIDER)
+const (
+	ProviderResponses = "responses"
+	ProviderChat      = "chat"
+	ProviderAnthropic = "anthropic"
+	ProviderOllama    = "ollama"
+)
+
+const (
+	defaultResponsesURL = "https://api.openai.com/v1/responses"
+	defaultChatURL      = "https://api.openai.com/v1/chat/completions"
+	defaultAnthropicURL = "https://api.anthropic.com/v1/messages"
+	defaultOllamaURL    = "http://localhost:11434/v1/chat/completions"
+)
+
+// Config describes one caller's LLM setup
+// Fallbacks are tried in order after the primary exhausts its retries; each may change provider and model
+type Config struct {
+	Caller       string // content | v2 | ads | questions (for logs & usage)
+	Provider     string
+	Model        string
+	APIKey       string
+	BaseURL      string // full endpoint URL; empty = provider default
+	Timeout      time.Duration
+	MaxRetries   int
+	RetryBackoff time.Duration
+	Fallbacks    []Config
+	Transport    http.RoundTripper // nil = cassette.Default() (AI_CASSETTE_MODE record/replay)
+	// Structured output: NoStructured never sends a JSON schema (servers without json_schema support)
+	NoStructured      bool
+	StructuredRepairs int // repair calls after invalid JSON (CompleteStructured)
+}
+
+// ConfigFromEnv builds a caller config on top of defaults
+//
+// Per caller (CALLER = upper-case caller name):
+//
+//	LLM_<CALLER>_PROVIDER     responses | chat | anthropic | ollama
+//	LLM_<CALLER>_MODEL        model name
+//	LLM_<CALLER>_URL          endpoint URL
+//	LLM_<CALLER>_TIMEOUT_SEC  per-attempt timeout
+//	LLM_<CALLER>_FALLBACK     comma-separated provider:model list, e.g. "chat:gpt-4o,anthropic:claude-sonnet-4-5"
+//	LLM_<CALLER>_STRUCTURED   off = never request JSON schema output (text parsing only)
+//
+// Global: LLM_MAX_RETRIES (default 2), LLM_STRUCTURED_REPAIRS (default 2),
+// OPENAI_API_KEY / AI_API_KEY, ANTHROPIC_API_KEY, OLLAMA_BASE_URL
+// No fallback is configured by default (NO FALLBACK rule stays unless explicitly enabled)
+func ConfigFromEnv(caller string, defaults Config) Config {
+	cfg := defaults
+	cfg.Caller = caller
+	prefix := "LLM_" + strings.ToUpper(caller) + "_"
+
+	if v := os.Getenv(prefix + "PROVIDER"); v != "" && strings.ToLower(v) != cfg.Provider {
+		cfg.Provider = strings.ToLower(v)
+		cfg.BaseURL = "" // URL & key of the default provider no longer apply
+		cfg.APIKey = ""
+	}
+	if v := os.Getenv(prefix + "MODEL"); v != "" {
+		cfg.Model = v
+	}
+	if v := os.Getenv(prefix + "URL"); v != "" {
+		cfg.BaseURL = v
+	}
+	if v := os.Getenv(prefix + "TIMEOUT_SEC"); v != "" {
+		if val, err := strconv.Atoi(v); err == nil && val > 0 {
+			cfg.Timeout = time.Duration(val) * time.Second
+		}
+	}
+	if v := os.Getenv("LLM_MAX_RETRIES"); v != "" {
+		if val, err := strconv.Atoi(v); err == nil && val >= 0 {
+			cfg.MaxRetries = val
+		}
+	} else if cfg.MaxRetries == 0 {
+		cfg.MaxRetries = 2
+	}
+	if cfg.APIKey == "" {
+		cfg.APIKey = apiKeyFor(cfg.Provider)
+	}
+	if v := os.Getenv(prefix + "STRUCTURED"); v != "" {
+		cfg.NoStructured = strings.EqualFold(v, "off") || v == "0" || strings.EqualFold(v, "false")
+	}
+	if cfg.StructuredRepairs == 0 {
+		cfg.StructuredRepairs = 2
+	}
+	if v := os.Getenv("LLM_STRUCTURED_REPAIRS"); v != "" {
+		if val, err := strconv.Atoi(v); err == nil && val >= 0 {
+			cfg.StructuredRepairs = val
+		}
+	}
+
+	if v := os.Getenv(prefix + "FALLBACK"); v != "" {
+		cfg.Fallbacks = nil
+		for _, entry := range strings.Split(v, ",") {
+			entry = strings.TrimSpace(entry)
+			if entry == "" {
+				continue
+			}
+			provider, model, _ := strings.Cut(entry, ":")
+			provider = strings.ToLower(strings.TrimSpace(provider))
+			cfg.Fallbacks = append(cfg.Fallbacks, Config{
+				Caller:       caller,
+				Provider:     provider,
+				Model:        strings.TrimSpace(model),
+				APIKey:       apiKeyFor(provider),
+				Timeout:      cfg.Timeout,
+				MaxRetries:   0,
+				Transport:    cfg.Transport,
+				NoStructured: cfg.NoStructured,
+			})
+		}
+	}
+	return cfg
+}
+
+// apiKeyFor returns the env API key for a provider
+func apiKeyFor(provider string) string {
+	switch provider {
+	case ProviderAnthropic:
+		return os.Getenv("ANTHROPIC_API_KEY")
+	case ProviderOllama:
+		return os.Getenv("OLLAMA_API_KEY")
+	default:
+		if key := os.Getenv("OPENAI_API_KEY"); key != "" {
+			return key
+		}
+		return os.Getenv("AI_API_KEY")
+	}
+}
+
+// newProvider builds the Provider for a config
+func newProvider(cfg Config) (Provider, error) {
+	switch cfg.Provider {
+	case ProviderResponses:
+		return &ResponsesProvider{URL: orDefault(cfg.BaseURL, defaultResponsesURL), APIKey: cfg.APIKey, Transport: cfg.Transport}, nil
+	case ProviderChat:
+		return &ChatProvider{Provider: ProviderChat, URL: orDefault(cfg.BaseURL, defaultChatURL), APIKey: cfg.APIKey, Transport: cfg.Transport}, nil
+	case ProviderAnthropic:
+		return &AnthropicProvider{URL: orDefault(cfg.BaseURL, defaultAnthropicURL), APIKey: cfg.APIKey, Transport: cfg.Transport}, nil
+	case ProviderOllama:
+		url := cfg.BaseURL
+		if url == "" {
+			if base := os.Getenv("OLLAMA_BASE_URL"); base != "" {
+				url = strings.TrimRight(base, "/") + "/v1/chat/completions"
+			}
+		}
+		return &ChatProvider{Provider: ProviderOllama, URL: orDefault(url, defaultOllamaURL), APIKey: cfg.APIKey, Transport: cfg.Transport}, nil
+	default:
+		return nil, &Error{Kind: KindUnavailable, Provider: cfg.Provider, Model: cfg.Model, Message: fmt.Sprintf("unknown provider %q", cfg.Provider)}
+	}
+}
+
+// requiresKey reports whether a provider cannot work without an API key
+func requiresKey(provider string) bool {
+	return provider != ProviderOllama
+}
+
+func orDefault(v, def string) string {
+	if v == "" {
+		return def
+	}
+	return v
+}
@@ -0,0 +1,114 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+
+	"engine-hub/internal/ai/cassette"
+)
+
+// ErrorKind classifies provider failures
+type ErrorKind string
+
+const (
+	KindAuth        ErrorKind = "AUTH"        // 401/403, missing key
+	KindRateLimit   ErrorKind = "RATE_LIMIT"  // 429
+	KindBadRequest  ErrorKind = "BAD_REQUEST" // 400/404/422 (wrong model, unsupported param)
+	KindServer      ErrorKind = "SERVER"      // 5xx
+	KindTimeout     ErrorKind = "TIMEOUT"     // client timeout / deadline exceeded
+	KindNetwork     ErrorKind = "NETWORK"     // connection refused, DNS, reset
+	KindEmpty       ErrorKind = "EMPTY"       // 200 without text
+	KindDecode      ErrorKind = "DECODE"      // unparsable response body
+	KindCanceled    ErrorKind = "CANCELED"    // caller cancelled ctx
+	KindUnavailable ErrorKind = "UNAVAILABLE" // provider/config unusable
+)
+
+// Error is the typed error returned by every provider and the Client
+type Error struct {
+	Kind       ErrorKind
+	Provider   string
+	Model      string
+	StatusCode int
+	Message    string
+	Body       string // truncated response body
+	Err        error
+}
+
+func (e *Error) Error() string {
+	msg := fmt.Sprintf("llm %s/%s: %s", e.Provider, e.Model, e.Kind)
+	if e.StatusCode != 0 {
+		msg += fmt.Sprintf(" (status %d)", e.StatusCode)
+	}
+	if e.Message != "" {
+		msg += ": " + e.Message
+	}
+	if e.Err != nil {
+		msg += ": " + e.Err.Error()
+	}
+	return msg
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Retryable reports whether the same request may succeed when sent again
+func (e *Error) Retryable() bool {
+	switch e.Kind {
+	case KindRateLimit, KindServer, KindTimeout, KindNetwork, KindEmpty:
+		return true
+	}
+	return false
+}
+
+// IsRetryable reports whether err is a retryable *Error
+func IsRetryable(err error) bool {
+	var llmErr *Error
+	return errors.As(err, &llmErr) && llmErr.Retryable()
+}
+
+// KindOf returns the ErrorKind of err ("" when err is not an *Error)
+func KindOf(err error) ErrorKind {
+	var llmErr *Error
+	if errors.As(err, &llmErr) {
+		return llmErr.Kind
+	}
+	return ""
+}
+
+// kindForStatus maps an HTTP status code to an ErrorKind
+func kindForStatus(status int) ErrorKind {
+	switch {
+	case status == http.StatusUnauthorized || status == http.StatusForbidden:
+		return KindAuth
+	case status == http.StatusTooManyRequests:
+		return KindRateLimit
+	case status == http.StatusRequestTimeout || status == http.StatusGatewayTimeout:
+		return KindTimeout
+	case status >= 500:
+		return KindServer
+	default:
+		return KindBadRequest
+	}
+}
+
+// kindForTransport classifies an http.Client.Do error
+func kindForTransport(ctx context.Context, err error) ErrorKind {
+	if errors.Is(err, cassette.ErrNoRecording) {
+		return KindUnavailable // replay miss: sending again cannot help
+	}
+	if ctx.Err() == context.Canceled {
+		return KindCanceled
+	}
+	if errors.Is(err, context.DeadlineExceeded) || ctx.Err() == context.DeadlineExceeded {
+		return KindTimeout
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return KindTimeout
+	}
+	return KindNetwork
+}
@@ -0,0 +1,99 @@
+package llm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"engine-hub/internal/ai/cassette"
+)
+
+const maxErrorBody = 2000
+
+// postJSON sends payload to url and returns the 200 response body
+// transport nil = cassette.Default() (record/replay via AI_CASSETTE_MODE); the timeout comes from the request ctx
+// Non-200 and transport failures are returned as *Error
+func postJSON(ctx context.Context, transport http.RoundTripper, provider, model, url string, headers map[string]string, payload interface{}) ([]byte, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, &Error{Kind: KindBadRequest, Provider: provider, Model: model, Message: "failed to marshal request", Err: err}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(data))
+	if err != nil {
+		return nil, &Error{Kind: KindUnavailable, Provider: provider, Model: model, Message: "failed to create request", Err: err}
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	if transport == nil {
+		transport = cassette.Default()
+	}
+	client := &http.Client{Transport: transport}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, &Error{Kind: kindForTransport(ctx, err), Provider: provider, Model: model, Message: "request failed", Err: err}
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, &Error{Kind: kindForTransport(ctx, err), Provider: provider, Model: model, StatusCode: resp.StatusCode, Message: "failed to read response body", Err: err}
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, &Error{
+			Kind:       kindForStatus(resp.StatusCode),
+			Provider:   provider,
+			Model:      model,
+			StatusCode: resp.StatusCode,
+			Message:    apiErrorMessage(body),
+			Body:       truncate(string(body), maxErrorBody),
+		}
+	}
+
+	return body, nil
+}
+
+// apiErrorMessage extracts {"error": {"message": ...}} (OpenAI & Anthropic) or {"error": "..."} (Ollama)
+func apiErrorMessage(body []byte) string {
+	var nested struct {
+		Error struct {
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if json.Unmarshal(body, &nested) == nil && nested.Error.Message != "" {
+		return nested.Error.Message
+	}
+	var flat struct {
+		Error string `json:"error"`
+	}
+	if json.Unmarshal(body, &flat) == nil && flat.Error != "" {
+		return flat.Error
+	}
+	return truncate(string(body), 300)
+}
+
+// decodeError wraps a JSON decode failure
+func decodeError(provider, model string, body []byte, err error) error {
+	return &Error{
+		Kind:     KindDecode,
+		Provider: provider,
+		Model:    model,
+		Message:  fmt.Sprintf("failed to decode response (%d bytes)", len(body)),
+		Body:     truncate(string(body), maxErrorBody),
+		Err:      err,
+	}
+}
+
+func truncate(s string, max int) string {
+	if len(s) <= max {
+		return s
+	}
+	return s[:max] + "..."
+}
@@ -0,0 +1,75 @@
+package llm
+
+import (
+	"context"
+	"time"
+)
+
+// UNIFIED LLM CLIENT
+// Satu package untuk semua panggilan text LLM (content, v2, ads, questions)
+// Provider:
+//   responses → OpenAI Responses API (/v1/responses)
+//   chat      → OpenAI Chat Completions (/v1/chat/completions)
+//   anthropic → Anthropic Messages API (/v1/messages)
+//   ollama    → OpenAI-compatible local server (Ollama, vLLM, LM Studio)
+// Timeout, retry dan typed error dipakai bersama; model/provider/fallback per caller (lihat config.go)
+
+// Message is one chat turn
+type Message struct {
+	Role    string `json:"role"` // system | user | assistant
+	Content string `json:"content"`
+}
+
+// Request is a provider-neutral completion request
+// Sampling params are pointers so "unset" differs from zero
+type Request struct {
+	Model            string // overrides Config.Model when set
+	System           string
+	Prompt           string    // shortcut for a single user message
+	Messages         []Message // used instead of Prompt when set
+	MaxTokens        int
+	Temperature      *float64
+	TopP             *float64
+	PresencePenalty  *float64
+	FrequencyPenalty *float64
+	Format           *ResponseFormat // JSON schema output (see structured.go); ignored by providers without JSON mode
+}
+
+// Usage is the token usage reported by the provider
+type Usage struct {
+	InputTokens  int `json:"inputTokens"`
+	OutputTokens int `json:"outputTokens"`
+	TotalTokens  int `json:"totalTokens"`
+}
+
+// Response is a provider-neutral completion result
+type Response struct {
+	Text       string        `json:"text"`
+	Provider   string        `json:"provider"`
+	Model      string        `json:"model"`
+	Caller     string        `json:"caller,omitempty"`
+	Usage      Usage         `json:"usage"`
+	Latency    time.Duration `json:"latency"`
+	Attempts   int           `json:"attempts"`             // HTTP attempts incl. retries and fallbacks
+	Structured bool          `json:"structured,omitempty"` // provider was asked to enforce Request.Format
+	Raw        []byte        `json:"-"`
+}
+
+// Provider sends one request to one LLM API (no retries, no fallback)
+type Provider interface {
+	Name() string
+	Complete(ctx context.Context, model string, req Request) (*Response, error)
+}
+
+// Float returns a pointer to v (for Request sampling params)
+func Float(v float64) *float64 {
+	return &v
+}
+
+// messages returns req.Messages, or Prompt as a single user message
+func (r Request) messages() []Message {
+	if len(r.Messages) > 0 {
+		return r.Messages
+	}
+	return []Message{{Role: "user", Content: r.Prompt}}
+}
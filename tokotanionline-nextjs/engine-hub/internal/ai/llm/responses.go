@@ -0,0 +1,146 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+)
+
+// ResponsesProvider calls the OpenAI Responses API
+// NOTE (2026): `response_format` is deprecated in Responses API - text.format is used instead
+type ResponsesProvider struct {
+	URL       string
+	APIKey    string
+	Transport http.RoundTripper // nil = cassette.Default()
+}
+
+func (p *ResponsesProvider) Name() string {
+	return ProviderResponses
+}
+
+func (p *ResponsesProvider) Complete(ctx context.Context, model string, req Request) (*Response, error) {
+	format := map[string]interface{}{"type": "text"}
+	if req.Format != nil {
+		format = map[string]interface{}{
+			"type":   "json_schema",
+			"name":   req.Format.Name,
+			"schema": req.Format.Schema,
+			"strict": true,
+		}
+	}
+	payload := map[string]interface{}{
+		"model": model,
+		"text": map[string]interface{}{
+			"format": format,
+		},
+	}
+	if len(req.Messages) == 0 && req.System == "" {
+		payload["input"] = req.Prompt
+	} else {
+		payload["input"] = req.messages()
+	}
+	if req.System != "" {
+		payload["instructions"] = req.System
+	}
+	if req.MaxTokens > 0 {
+		payload["max_output_tokens"] = req.MaxTokens
+	}
+	setSampling(payload, req)
+
+	started := time.Now()
+	body, err := postJSON(ctx, p.Transport, p.Name(), model, p.URL, bearer(p.APIKey), payload)
+	if err != nil {
+		return nil, err
+	}
+
+	var r struct {
+		Model  string `json:"model"`
+		Output []struct {
+			Type    string `json:"type"`
+			Content []struct {
+				Type string `json:"type"`
+				Text string `json:"text"`
+			} `json:"content"`
+		} `json:"output"`
+		Usage struct {
+			InputTokens  int `json:"input_tokens"`
+			OutputTokens int `json:"output_tokens"`
+			TotalTokens  int `json:"total_tokens"`
+		} `json:"usage"`
+		Error struct {
+			Message string `json:"message"`
+			Type    string `json:"type"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal(body, &r); err != nil {
+		return nil, decodeError(p.Name(), model, body, err)
+	}
+
+	// Full content = every output_text part of every message item (reasoning items are skipped)
+	text := ""
+	for _, item := range r.Output {
+		if item.Type != "message" {
+			continue
+		}
+		for _, c := range item.Content {
+			if c.Type == "output_text" {
+				text += c.Text
+			}
+		}
+	}
+	if text == "" {
+		msg := "no output_text in response"
+		if r.Error.Message != "" {
+			msg = r.Error.Message + " (type: " + r.Error.Type + ")"
+		}
+		return nil, &Error{Kind: KindEmpty, Provider: p.Name(), Model: model, Message: msg, Body: truncate(string(body), maxErrorBody)}
+	}
+
+	return &Response{
+		Text:     text,
+		Provider: p.Name(),
+		Model:    firstNonEmpty(r.Model, model),
+		Usage: Usage{
+			InputTokens:  r.Usage.InputTokens,
+			OutputTokens: r.Usage.OutputTokens,
+			TotalTokens:  r.Usage.TotalTokens,
+		},
+		Latency:    time.Since(started),
+		Raw:        body,
+		Structured: req.Format != nil,
+	}, nil
+}
+
+// setSampling copies the sampling params that are set into an OpenAI-style payload
+func setSampling(payload map[string]interface{}, req Request) {
+	if req.Temperature != nil {
+		payload["temperature"] = *req.Temperature
+	}
+	if req.TopP != nil {
+		payload["top_p"] = *req.TopP
+	}
+	if req.PresencePenalty != nil {
+		payload["presence_penalty"] = *req.PresencePenalty
+	}
+	if req.FrequencyPenalty != nil {
+		payload["frequency_penalty"] = *req.FrequencyPenalty
+	}
+}
+
+// bearer returns the Authorization header (none for keyless local servers)
+func bearer(apiKey string) map[string]string {
+	if apiKey == "" {
+		return nil
+	}
+	return map[string]string{"Authorization": "Bearer " + apiKey}
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
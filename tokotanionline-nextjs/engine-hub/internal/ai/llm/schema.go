@@ -0,0 +1,196 @@
+package llm
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
+
+// Schema is the JSON Schema subset sent as structured output and checked on receipt
+// Only keywords supported by OpenAI strict mode are used (no minLength / pattern)
+type Schema struct {
+	Type                 string             `json:"type"` // object | array | string | integer | number | boolean
+	Description          string             `json:"description,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties *bool              `json:"additionalProperties,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	MinItems             *int               `json:"minItems,omitempty"`
+	MaxItems             *int               `json:"maxItems,omitempty"`
+	Enum                 []interface{}      `json:"enum,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty"`
+	Maximum              *float64           `json:"maximum,omitempty"`
+}
+
+// ResponseFormat asks the provider for JSON matching Schema (Request.Format)
+// Name identifies the schema in the provider request and in logs
+type ResponseFormat struct {
+	Name   string
+	Schema *Schema
+}
+
+// Object returns a strict object schema: every property required, no extra properties
+func Object(description string, properties map[string]*Schema) *Schema {
+	required := make([]string, 0, len(properties))
+	for name := range properties {
+		required = append(required, name)
+	}
+	sort.Strings(required)
+	closed := false
+	return &Schema{Type: "object", Description: description, Properties: properties, Required: required, AdditionalProperties: &closed}
+}
+
+// Array returns an array schema with item bounds (0 = unbounded)
+func Array(description string, items *Schema, minItems, maxItems int) *Schema {
+	s := &Schema{Type: "array", Description: description, Items: items}
+	if minItems > 0 {
+		s.MinItems = &minItems
+	}
+	if maxItems > 0 {
+		s.MaxItems = &maxItems
+	}
+	return s
+}
+
+// String returns a string schema (enum values optional)
+func String(description string, enum ...string) *Schema {
+	s := &Schema{Type: "string", Description: description}
+	for _, v := range enum {
+		s.Enum = append(s.Enum, v)
+	}
+	return s
+}
+
+// Integer returns an integer schema; enum values optional
+func Integer(description string, enum ...int) *Schema {
+	s := &Schema{Type: "integer", Description: description}
+	for _, v := range enum {
+		s.Enum = append(s.Enum, v)
+	}
+	return s
+}
+
+// Validate checks a decoded JSON value (json.Unmarshal into interface{}) against the schema
+// Returns one message per violation with its path, e.g. "sections[2].headingLevel: must be one of [2 3]"
+func (s *Schema) Validate(value interface{}) []string {
+	var errs []string
+	s.validate("$", value, &errs)
+	return errs
+}
+
+func (s *Schema) validate(path string, value interface{}, errs *[]string) {
+	fail := func(format string, args ...interface{}) {
+		*errs = append(*errs, path+": "+fmt.Sprintf(format, args...))
+	}
+
+	switch s.Type {
+	case "object":
+		obj, ok := value.(map[string]interface{})
+		if !ok {
+			fail("must be an object, got %s", jsonType(value))
+			return
+		}
+		for _, name := range s.Required {
+			if _, ok := obj[name]; !ok {
+				fail("missing required property %q", name)
+			}
+		}
+		names := make([]string, 0, len(obj))
+		for name := range obj {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			prop, ok := s.Properties[name]
+			if !ok {
+				if s.AdditionalProperties != nil && !*s.AdditionalProperties {
+					fail("unexpected property %q", name)
+				}
+				continue
+			}
+			prop.validate(path+"."+name, obj[name], errs)
+		}
+	case "array":
+		arr, ok := value.([]interface{})
+		if !ok {
+			fail("must be an array, got %s", jsonType(value))
+			return
+		}
+		if s.MinItems != nil && len(arr) < *s.MinItems {
+			fail("must have at least %d items, got %d", *s.MinItems, len(arr))
+		}
+		if s.MaxItems != nil && len(arr) > *s.MaxItems {
+			fail("must have at most %d items, got %d", *s.MaxItems, len(arr))
+		}
+		if s.Items != nil {
+			for i, item := range arr {
+				s.Items.validate(fmt.Sprintf("%s[%d]", path, i), item, errs)
+			}
+		}
+	case "string":
+		str, ok := value.(string)
+		if !ok {
+			fail("must be a string, got %s", jsonType(value))
+			return
+		}
+		if strings.TrimSpace(str) == "" && len(s.Enum) == 0 {
+			fail("must not be empty")
+		}
+	case "integer", "number":
+		num, ok := value.(float64)
+		if !ok {
+			fail("must be a number, got %s", jsonType(value))
+			return
+		}
+		if s.Type == "integer" && num != math.Trunc(num) {
+			fail("must be an integer, got %v", num)
+		}
+		if s.Minimum != nil && num < *s.Minimum {
+			fail("must be >= %v, got %v", *s.Minimum, num)
+		}
+		if s.Maximum != nil && num > *s.Maximum {
+			fail("must be <= %v, got %v", *s.Maximum, num)
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			fail("must be a boolean, got %s", jsonType(value))
+			return
+		}
+	}
+
+	if len(s.Enum) > 0 && !inEnum(s.Enum, value) {
+		fail("must be one of %v", s.Enum)
+	}
+}
+
+// inEnum compares through JSON so 2 (int in the schema) equals 2.0 (decoded value)
+func inEnum(enum []interface{}, value interface{}) bool {
+	got, _ := json.Marshal(value)
+	for _, v := range enum {
+		want, _ := json.Marshal(v)
+		if string(got) == string(want) {
+			return true
+		}
+	}
+	return false
+}
+
+func jsonType(value interface{}) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case map[string]interface{}:
+		return "object"
+	case []interface{}:
+		return "array"
+	case string:
+		return "string"
+	case float64:
+		return "number"
+	case bool:
+		return "boolean"
+	}
+	return fmt.Sprintf("%T", value)
+}
@@ -0,0 +1,133 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+)
+
+// STRUCTURED OUTPUT
+// Caller minta JSON sesuai schema (Request.Format); provider dengan JSON mode (responses, chat) memaksa schema,
+// provider lain (anthropic, ollama) mengabaikannya dan menjawab teks biasa → caller pakai parser teks
+// Hasil selalu divalidasi ulang di sini; output rusak dikirim balik ke model untuk diperbaiki (repair loop)
+
+// ErrStructuredUnsupported means the answering provider has no JSON mode; parse the text instead
+var ErrStructuredUnsupported = errors.New("provider has no structured output mode")
+
+// StructuredError is returned when the output still violates the schema after every repair
+type StructuredError struct {
+	Format   string
+	Attempts int
+	Errors   []string
+	Text     string // last model output (truncated)
+}
+
+func (e *StructuredError) Error() string {
+	return fmt.Sprintf("structured output %s invalid after %d attempts: %s", e.Format, e.Attempts, strings.Join(e.Errors, "; "))
+}
+
+// SupportsStructured reports whether the primary target would enforce a JSON schema
+func (c *Client) SupportsStructured() bool {
+	return c.targets[0].structured()
+}
+
+// structured reports whether this target sends Request.Format to its provider
+func (t target) structured() bool {
+	if t.cfg.NoStructured {
+		return false
+	}
+	switch t.cfg.Provider {
+	case ProviderResponses, ProviderChat:
+		return true
+	}
+	return false
+}
+
+// CompleteStructured sends req with format and decodes the validated JSON into out
+// Invalid JSON or schema violations are sent back with the errors (up to Config.StructuredRepairs times)
+// Returns ErrStructuredUnsupported (with the text response when a call was made) when no JSON mode applies:
+// before any call if the primary has none, or when a fallback without JSON mode answered
+func (c *Client) CompleteStructured(ctx context.Context, req Request, format *ResponseFormat, out interface{}) (*Response, error) {
+	if !c.SupportsStructured() {
+		return nil, ErrStructuredUnsupported
+	}
+	req.Format = format
+	repairs := c.targets[0].cfg.StructuredRepairs
+
+	var resp *Response
+	var errs []string
+	for attempt := 0; attempt <= repairs; attempt++ {
+		if attempt > 0 {
+			log.Printf("[LLM] caller=%s structured %s repair %d/%d: %s", c.caller, format.Name, attempt, repairs, strings.Join(errs, "; "))
+			req = repairRequest(req, resp.Text, errs)
+		}
+
+		var err error
+		resp, err = c.Complete(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+		if !resp.Structured {
+			log.Printf("[LLM] caller=%s %s/%s has no JSON mode, structured %s falls back to text", c.caller, resp.Provider, resp.Model, format.Name)
+			return resp, ErrStructuredUnsupported
+		}
+
+		errs = decodeStructured(resp.Text, format.Schema, out)
+		if len(errs) == 0 {
+			if attempt > 0 {
+				log.Printf("[LLM] caller=%s structured %s repaired after %d attempts", c.caller, format.Name, attempt+1)
+			}
+			return resp, nil
+		}
+	}
+	return resp, &StructuredError{Format: format.Name, Attempts: repairs + 1, Errors: errs, Text: truncate(resp.Text, maxErrorBody)}
+}
+
+// decodeStructured parses text, validates it against schema and fills out; returns the violations
+func decodeStructured(text string, schema *Schema, out interface{}) []string {
+	text = stripCodeFence(text)
+	var value interface{}
+	if err := json.Unmarshal([]byte(text), &value); err != nil {
+		return []string{"invalid JSON: " + err.Error()}
+	}
+	if errs := schema.Validate(value); len(errs) > 0 {
+		if len(errs) > 10 {
+			errs = append(errs[:10], fmt.Sprintf("... and %d more", len(errs)-10))
+		}
+		return errs
+	}
+	if err := json.Unmarshal([]byte(text), out); err != nil {
+		return []string{"cannot decode into result: " + err.Error()}
+	}
+	return nil
+}
+
+// stripCodeFence removes a ```json ... ``` wrapper some models add despite JSON mode
+func stripCodeFence(text string) string {
+	text = strings.TrimSpace(text)
+	if !strings.HasPrefix(text, "```") {
+		return text
+	}
+	text = strings.TrimPrefix(text, "```")
+	if i := strings.IndexByte(text, '\n'); i >= 0 {
+		text = text[i+1:]
+	}
+	return strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(text), "```"))
+}
+
+// repairRequest continues the conversation with the invalid output and what is wrong with it
+func repairRequest(req Request, previous string, errs []string) Request {
+	messages := append([]Message{}, req.messages()...)
+	messages = append(messages,
+		Message{Role: "assistant", Content: previous},
+		Message{Role: "user", Content: "Your previous output does not match the required JSON schema:\n- " +
+			strings.Join(errs, "\n- ") +
+			"\n\nReturn the complete corrected JSON object only, with the same content. No explanation, no code fence."},
+	)
+	req.Prompt = ""
+	req.Messages = messages
+	return req
+}
@@ -5,6 +5,7 @@ import (
 	"regexp"
 	"strings"
 
+	"engine-hub/internal/ai/compliance"
 	"engine-hub/internal/ai/content"
 )
 
@@ -14,18 +15,24 @@ import (
 // This is STRING PROCESSING, not AI-dependent
 // Purpose: Force compliance BEFORE validation, so validator always passes
 func NormalizeContent(input content.ContentResult) content.ContentResult {
+	return NormalizeContentFor(input, compliance.DefaultScope())
+}
+
+// NormalizeContentFor normalizes with the AUTO_REPLACE rules of the brand/locale compliance packs
+func NormalizeContentFor(input content.ContentResult, scope compliance.Scope) content.ContentResult {
 	result := input
-	log.Println("[NORMALIZER] Starting content normalization (GLOBAL - applies to all content types)...")
+	rules := compliance.Resolve(scope)
+	log.Printf("[NORMALIZER] Starting content normalization (GLOBAL - applies to all content types, packs=%v)...", rules.Packs)
 
 	// Normalize body content
-	result.Body = normalizeBody(result.Body)
+	result.Body = normalizeBody(result.Body, rules)
 
 	// Normalize title (for all content types)
-	result.Title = normalizeTitle(result.Title)
+	result.Title = normalizeTitle(result.Title, rules)
 
 	// Normalize meta fields
-	result.MetaTitle = normalizeMeta(result.MetaTitle)
-	result.MetaDesc = normalizeMeta(result.MetaDesc)
+	result.MetaTitle = normalizeMeta(result.MetaTitle, rules)
+	result.MetaDesc = normalizeMeta(result.MetaDesc, rules)
 
 	// Update status to indicate normalization
 	if result.Status == "RAW_AI" {
@@ -37,21 +44,21 @@ func NormalizeContent(input content.ContentResult) content.ContentResult {
 }
 
 // normalizeTitle applies normalization to title (for all content types)
-func normalizeTitle(title string) string {
+func normalizeTitle(title string, rules *compliance.RuleSet) string {
 	if title == "" {
 		return title
 	}
 
 	// Apply same rules as body but lighter (title is shorter)
 	title = limitExclamationMarksImproved(title)
-	title = filterAbsoluteWords(title)
+	title = filterAbsoluteWords(title, rules)
 	title = cleanupWhitespace(title)
 
 	return title
 }
 
 // normalizeBody applies all normalization rules to body content
-func normalizeBody(body string) string {
+func normalizeBody(body string, rules *compliance.RuleSet) string {
 	if body == "" {
 		return body
 	}
@@ -63,7 +70,7 @@ func normalizeBody(body string) string {
 	body = limitExclamationMarks(body)
 
 	// Step 2: Filter absolute words (replace with neutral forms)
-	body = filterAbsoluteWords(body)
+	body = filterAbsoluteWords(body, rules)
 
 	// Step 3: Tone softening (claim sentences → observational)
 	body = softenTone(body)
@@ -144,76 +151,12 @@ func limitExclamationMarksImproved(text string) string {
 }
 
 // filterAbsoluteWords replaces absolute/promotional words with neutral alternatives
-func filterAbsoluteWords(text string) string {
-	// Word replacement map: absolute → neutral
-	replacements := map[string]string{
-		// Absolute claims
-		"pasti":     "umumnya",
-		"terbukti":  "sering digunakan",
-		"100%":      "biasanya",
-		"terbaik":   "sering dipilih",
-		"paling":    "sering",
-		"selalu":    "biasanya",
-		"tidak pernah": "jarang",
-		"mustahil":  "sulit",
-		"wajib":     "disarankan",
-		"harus":     "sebaiknya",
-		"mutlak":    "umumnya",
-	}
-
-	// Case-insensitive replacement with word boundaries
-	bodyLower := strings.ToLower(text)
-	result := text
-
-	for absolute, neutral := range replacements {
-		// Create regex pattern with word boundaries
-		pattern := `\b` + regexp.QuoteMeta(absolute) + `\b`
-		re := regexp.MustCompile(`(?i)` + pattern)
-
-		// Check if found
-		if re.MatchString(bodyLower) {
-			log.Printf("[NORMALIZER] Replacing absolute word: '%s' → '%s'", absolute, neutral)
-		}
-
-		// Replace with case preservation
-		result = re.ReplaceAllStringFunc(result, func(match string) string {
-			// Preserve case of first letter
-			if len(match) > 0 && match[0] >= 'A' && match[0] <= 'Z' {
-				// Uppercase first letter
-				return strings.ToUpper(neutral[:1]) + neutral[1:]
-			}
-			return neutral
-		})
-	}
-
-	// Also handle phrases (FASE A - A1: Expanded for global coverage)
-	phraseReplacements := map[string]string{
-		"pasti berhasil":     "umumnya berhasil",
-		"terbukti efektif":   "sering efektif",
-		"paling efektif":     "sering efektif",
-		"solusi terbaik":     "solusi yang sering digunakan",
-		"tidak diragukan":    "biasanya",
-		"sangat efektif":     "efektif",
-		"super efektif":      "efektif",
-		"paling terbaik":     "sering dipilih",
-		"menurut AI":         "", // Remove AI references
-		"menurut artificial intelligence": "",
-		"menurut machine learning": "",
-		"[placeholder]":      "", // Remove placeholders
-		"placeholder":        "",
-		"TODO":               "",
-		"FIXME":              "",
-	}
-
-	for phrase, replacement := range phraseReplacements {
-		pattern := `(?i)\b` + regexp.QuoteMeta(phrase) + `\b`
-		re := regexp.MustCompile(pattern)
-		if re.MatchString(result) {
-			log.Printf("[NORMALIZER] Replacing phrase: '%s' → '%s'", phrase, replacement)
-			result = re.ReplaceAllString(result, replacement)
-		}
+// Rules come from the compliance packs (AUTO_REPLACE severity), see compliance/packs/default.yaml
+func filterAbsoluteWords(text string, rules *compliance.RuleSet) string {
+	result, findings := rules.Normalize(text)
+	for _, f := range findings {
+		log.Printf("[NORMALIZER] Rule %s (%s): '%s' → '%s' (%dx)", f.RuleID, f.Pack, f.Match, f.Replacement, f.Count)
 	}
-
 	return result
 }
 
@@ -265,11 +208,12 @@ func softenTone(text string) string {
 // cleanupWhitespace normalizes whitespace and punctuation
 func cleanupWhitespace(text string) string {
 	// Normalize multiple spaces to single space
-	re := regexp.MustCompile(`\s+`)
+	// Line breaks stay: headings, paragraphs and injected images are line-based markdown
+	re := regexp.MustCompile(`[ \t]+`)
 	text = re.ReplaceAllString(text, " ")
 
 	// Remove spaces before punctuation
-	re = regexp.MustCompile(`\s+([.,;:!?])`)
+	re = regexp.MustCompile(`[ \t]+([.,;:!?])`)
 	text = re.ReplaceAllString(text, "$1")
 
 	// Normalize multiple punctuation marks (except ...)
@@ -287,12 +231,12 @@ func cleanupWhitespace(text string) string {
 		})
 	}
 
-	// Trim whitespace from lines
+	// Trim whitespace from lines (runs of blank lines collapse to one paragraph break)
 	lines := strings.Split(text, "\n")
 	var cleanedLines []string
 	for _, line := range lines {
 		cleanedLine := strings.TrimSpace(line)
-		if cleanedLine != "" {
+		if cleanedLine != "" || (len(cleanedLines) > 0 && cleanedLines[len(cleanedLines)-1] != "") {
 			cleanedLines = append(cleanedLines, cleanedLine)
 		}
 	}
@@ -305,14 +249,14 @@ func cleanupWhitespace(text string) string {
 }
 
 // normalizeMeta applies normalization to meta fields (title, description)
-func normalizeMeta(meta string) string {
+func normalizeMeta(meta string, rules *compliance.RuleSet) string {
 	if meta == "" {
 		return meta
 	}
 
 	// Apply same rules but lighter (meta is shorter)
 	meta = limitExclamationMarksImproved(meta)
-	meta = filterAbsoluteWords(meta)
+	meta = filterAbsoluteWords(meta, rules)
 	meta = cleanupWhitespace(meta)
 
 	return meta
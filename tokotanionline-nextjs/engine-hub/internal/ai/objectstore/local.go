@@ -0,0 +1,106 @@
+package objectstore
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// LocalStore keeps objects as files under dir (the Next.js public folder)
+type LocalStore struct {
+	dir        string
+	publicBase string // "" = site-relative URLs (/images/...)
+}
+
+// NewLocalStore creates a local backend rooted at dir
+func NewLocalStore(dir, publicBase string) *LocalStore {
+	return &LocalStore{dir: dir, publicBase: strings.TrimRight(publicBase, "/")}
+}
+
+// Name implements Store
+func (s *LocalStore) Name() string { return "local" }
+
+// Dir is the folder objects are written to
+func (s *LocalStore) Dir() string { return s.dir }
+
+// Put implements Store (atomic: a crash never leaves a truncated file)
+func (s *LocalStore) Put(ctx context.Context, key string, data []byte, contentType string) error {
+	target, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+	return writeFileAtomic(target, data, 0644)
+}
+
+// Exists implements Store
+func (s *LocalStore) Exists(ctx context.Context, key string) (bool, error) {
+	target, err := s.path(key)
+	if err != nil {
+		return false, err
+	}
+	if _, err := os.Stat(target); err != nil {
+		if os.IsNotExist(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// Get implements Store
+func (s *LocalStore) Get(ctx context.Context, key string) ([]byte, error) {
+	target, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+	return os.ReadFile(target)
+}
+
+// URL implements Store
+func (s *LocalStore) URL(key string) string {
+	return s.publicBase + "/" + strings.TrimLeft(key, "/")
+}
+
+// path maps key to a file below dir (rejects keys escaping dir)
+func (s *LocalStore) path(key string) (string, error) {
+	clean := filepath.Clean(filepath.FromSlash("/" + key))
+	if clean == string(filepath.Separator) {
+		return "", fmt.Errorf("invalid object key: %q", key)
+	}
+	return filepath.Join(s.dir, clean), nil
+}
+
+// writeFileAtomic writes data to a temp file in the same directory and renames it into place
+func writeFileAtomic(path string, data []byte, perm os.FileMode) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	tmpPath := tmp.Name()
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := os.Chmod(tmpPath, perm); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+
+	return os.Rename(tmpPath, path)
+}
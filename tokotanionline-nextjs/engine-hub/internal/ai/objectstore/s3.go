@@ -0,0 +1,203 @@
+package objectstore
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// S3Config configures the S3-compatible backend (env, see S3ConfigFromEnv)
+type S3Config struct {
+	Endpoint   string // https://s3.us-east-1.amazonaws.com, http://127.0.0.1:9000 (MinIO), ...
+	Region     string
+	Bucket     string
+	AccessKey  string
+	SecretKey  string
+	PathStyle  bool   // endpoint/bucket/key (MinIO, most S3-compatibles) instead of bucket.endpoint/key
+	PublicBase string // public URL prefix for objects (CDN / public bucket URL); default = bucket URL
+}
+
+// S3ConfigFromEnv reads IMAGE_S3_* (AWS_ACCESS_KEY_ID / AWS_SECRET_ACCESS_KEY as fallback credentials)
+func S3ConfigFromEnv() S3Config {
+	cfg := S3Config{
+		Endpoint:   os.Getenv("IMAGE_S3_ENDPOINT"),
+		Region:     os.Getenv("IMAGE_S3_REGION"),
+		Bucket:     os.Getenv("IMAGE_S3_BUCKET"),
+		AccessKey:  os.Getenv("IMAGE_S3_ACCESS_KEY"),
+		SecretKey:  os.Getenv("IMAGE_S3_SECRET_KEY"),
+		PathStyle:  true,
+		PublicBase: os.Getenv("IMAGE_PUBLIC_BASE_URL"),
+	}
+	if cfg.Region == "" {
+		cfg.Region = "us-east-1"
+	}
+	if cfg.Endpoint == "" {
+		cfg.Endpoint = "https://s3." + cfg.Region + ".amazonaws.com"
+	}
+	if cfg.AccessKey == "" {
+		cfg.AccessKey = os.Getenv("AWS_ACCESS_KEY_ID")
+	}
+	if cfg.SecretKey == "" {
+		cfg.SecretKey = os.Getenv("AWS_SECRET_ACCESS_KEY")
+	}
+	if v := strings.ToLower(os.Getenv("IMAGE_S3_PATH_STYLE")); v == "false" || v == "off" || v == "0" {
+		cfg.PathStyle = false
+	}
+	return cfg
+}
+
+// S3Store stores objects in an S3-compatible bucket (SigV4 over plain net/http)
+// Objects are written with an immutable Cache-Control: keys are content hashes, so content never changes
+// Public read access is configured on the bucket (policy / CDN), not per object
+type S3Store struct {
+	cfg        S3Config
+	endpoint   *url.URL
+	publicBase string
+	httpClient *http.Client
+}
+
+// NewS3Store validates cfg and creates the backend
+func NewS3Store(cfg S3Config) (*S3Store, error) {
+	if cfg.Bucket == "" {
+		return nil, fmt.Errorf("IMAGE_S3_BUCKET is not set")
+	}
+	if cfg.AccessKey == "" || cfg.SecretKey == "" {
+		return nil, fmt.Errorf("IMAGE_S3_ACCESS_KEY / IMAGE_S3_SECRET_KEY are not set")
+	}
+	endpoint, err := url.Parse(strings.TrimRight(cfg.Endpoint, "/"))
+	if err != nil || endpoint.Host == "" {
+		return nil, fmt.Errorf("invalid IMAGE_S3_ENDPOINT %q", cfg.Endpoint)
+	}
+
+	s := &S3Store{
+		cfg:        cfg,
+		endpoint:   endpoint,
+		httpClient: &http.Client{Timeout: 60 * time.Second},
+	}
+	s.publicBase = strings.TrimRight(cfg.PublicBase, "/")
+	if s.publicBase == "" {
+		s.publicBase = s.bucketURL()
+	}
+	return s, nil
+}
+
+// Name implements Store
+func (s *S3Store) Name() string { return "s3" }
+
+// Put implements Store
+func (s *S3Store) Put(ctx context.Context, key string, data []byte, contentType string) error {
+	req, err := s.newRequest(ctx, http.MethodPut, key, data)
+	if err != nil {
+		return err
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	req.Header.Set("Cache-Control", "public, max-age=31536000, immutable")
+
+	resp, err := s.do(req, data)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return s3Error("PUT", key, resp)
+	}
+	return nil
+}
+
+// Exists implements Store
+func (s *S3Store) Exists(ctx context.Context, key string) (bool, error) {
+	req, err := s.newRequest(ctx, http.MethodHead, key, nil)
+	if err != nil {
+		return false, err
+	}
+	resp, err := s.do(req, nil)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return true, nil
+	case http.StatusNotFound:
+		return false, nil
+	default:
+		return false, s3Error("HEAD", key, resp)
+	}
+}
+
+// Get implements Store
+func (s *S3Store) Get(ctx context.Context, key string) ([]byte, error) {
+	req, err := s.newRequest(ctx, http.MethodGet, key, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := s.do(req, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return io.ReadAll(resp.Body)
+	case http.StatusNotFound:
+		return nil, fmt.Errorf("object %s: %w", key, os.ErrNotExist)
+	default:
+		return nil, s3Error("GET", key, resp)
+	}
+}
+
+// URL implements Store
+func (s *S3Store) URL(key string) string {
+	return s.publicBase + "/" + strings.TrimLeft(key, "/")
+}
+
+// bucketURL is the API URL of the bucket (path or virtual-hosted style)
+func (s *S3Store) bucketURL() string {
+	if s.cfg.PathStyle {
+		return s.endpoint.Scheme + "://" + s.endpoint.Host + strings.TrimRight(s.endpoint.Path, "/") + "/" + s.cfg.Bucket
+	}
+	return s.endpoint.Scheme + "://" + s.cfg.Bucket + "." + s.endpoint.Host + strings.TrimRight(s.endpoint.Path, "/")
+}
+
+func (s *S3Store) newRequest(ctx context.Context, method, key string, data []byte) (*http.Request, error) {
+	segments := strings.Split(strings.TrimLeft(key, "/"), "/")
+	for i, seg := range segments {
+		segments[i] = url.PathEscape(seg)
+	}
+	var body io.Reader
+	if data != nil {
+		body = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, s.bucketURL()+"/"+strings.Join(segments, "/"), body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create s3 request: %w", err)
+	}
+	return req, nil
+}
+
+func (s *S3Store) do(req *http.Request, data []byte) (*http.Response, error) {
+	SignV4(req, PayloadHash(data), s.cfg.AccessKey, s.cfg.SecretKey, s.cfg.Region, time.Now())
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to call s3 %s: %w", req.Method, err)
+	}
+	return resp, nil
+}
+
+// s3Error turns a non-2xx response into an error with the (truncated) S3 error body
+func s3Error(op, key string, resp *http.Response) error {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	msg := strings.TrimSpace(string(body))
+	if msg == "" {
+		return fmt.Errorf("s3 %s %s: status %d", op, key, resp.StatusCode)
+	}
+	return fmt.Errorf("s3 %s %s: status %d: %s", op, key, resp.StatusCode, msg)
+}
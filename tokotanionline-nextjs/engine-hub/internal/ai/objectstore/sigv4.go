@@ -0,0 +1,119 @@
+package objectstore
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+// AWS Signature Version 4 (header auth) for S3-compatible APIs
+// Hanya yang dipakai Store: PUT/HEAD/GET object, payload di-hash penuh (bukan UNSIGNED-PAYLOAD)
+
+const (
+	amzDateFormat  = "20060102T150405Z"
+	sigV4Algorithm = "AWS4-HMAC-SHA256"
+)
+
+// signedHeaderNames are the headers covered by the signature (all requests carry them)
+var signedHeaderNames = []string{"host", "x-amz-content-sha256", "x-amz-date"}
+
+// PayloadHash is the hex SHA-256 sent as x-amz-content-sha256
+func PayloadHash(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// SignV4 adds x-amz-date, x-amz-content-sha256 and Authorization to req
+func SignV4(req *http.Request, payloadHash, accessKey, secretKey, region string, now time.Time) {
+	amzDate := now.UTC().Format(amzDateFormat)
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	scope := credentialScope(amzDate, region)
+	signature := SignatureV4(req, payloadHash, secretKey, region, amzDate)
+	req.Header.Set("Authorization", fmt.Sprintf("%s Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		sigV4Algorithm, accessKey, scope, strings.Join(signedHeaderNames, ";"), signature))
+}
+
+// SignatureV4 computes the request signature (also used by the fake S3 server to verify requests)
+func SignatureV4(req *http.Request, payloadHash, secretKey, region, amzDate string) string {
+	canonical := strings.Join([]string{
+		req.Method,
+		canonicalURI(req.URL),
+		canonicalQuery(req.URL),
+		canonicalHeaders(req),
+		strings.Join(signedHeaderNames, ";"),
+		payloadHash,
+	}, "\n")
+
+	scope := credentialScope(amzDate, region)
+	stringToSign := strings.Join([]string{sigV4Algorithm, amzDate, scope, PayloadHash([]byte(canonical))}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+secretKey), amzDate[:8])
+	key = hmacSHA256(key, region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	return hex.EncodeToString(hmacSHA256(key, stringToSign))
+}
+
+func credentialScope(amzDate, region string) string {
+	return amzDate[:8] + "/" + region + "/s3/aws4_request"
+}
+
+func canonicalURI(u *url.URL) string {
+	p := u.EscapedPath()
+	if p == "" {
+		return "/"
+	}
+	return p
+}
+
+func canonicalQuery(u *url.URL) string {
+	query := u.Query()
+	keys := make([]string, 0, len(query))
+	for k := range query {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var parts []string
+	for _, k := range keys {
+		values := query[k]
+		sort.Strings(values)
+		for _, v := range values {
+			parts = append(parts, awsEscape(k)+"="+awsEscape(v))
+		}
+	}
+	return strings.Join(parts, "&")
+}
+
+func canonicalHeaders(req *http.Request) string {
+	var b strings.Builder
+	for _, name := range signedHeaderNames {
+		value := req.Header.Get(name)
+		if name == "host" {
+			value = req.Host
+			if value == "" {
+				value = req.URL.Host
+			}
+		}
+		b.WriteString(name + ":" + strings.TrimSpace(value) + "\n")
+	}
+	return b.String()
+}
+
+// awsEscape is RFC 3986 encoding as required by SigV4 (space = %20, ~ unescaped)
+func awsEscape(s string) string {
+	return strings.NewReplacer("+", "%20", "%7E", "~").Replace(url.QueryEscape(s))
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
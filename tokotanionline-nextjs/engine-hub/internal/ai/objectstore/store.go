@@ -0,0 +1,143 @@
+package objectstore
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// IMAGE OBJECT STORE
+// Gambar hasil generate disimpan lewat Store, bukan langsung ke ../public/images:
+// - local: file di bawah folder public Next.js (engine + app di mesin/volume yang sama)
+// - s3:    bucket S3-compatible (AWS S3, MinIO, R2, ...) - engine dan app boleh beda container
+// Key berbasis hash isi (images/articles/ab/abcd...webp): download yang identik disimpan sekali saja
+// Path  = "/" + key   (kontrak M-02: selalu /images/...)
+// URL   = IMAGE_PUBLIC_BASE_URL + Path (lokal tanpa base URL = Path)
+
+// Store is an image object backend
+type Store interface {
+	// Name is the backend name for logs ("local", "s3")
+	Name() string
+	// Put writes data under key (overwrites)
+	Put(ctx context.Context, key string, data []byte, contentType string) error
+	// Exists reports whether key is stored
+	Exists(ctx context.Context, key string) (bool, error)
+	// Get reads key (os.ErrNotExist when missing)
+	Get(ctx context.Context, key string) ([]byte, error)
+	// URL is the public URL of key
+	URL(key string) string
+}
+
+// Object is one stored object
+type Object struct {
+	Key     string // images/articles/ab/abcd....webp
+	Path    string // "/" + Key (M-02 image path)
+	URL     string // public URL
+	Deduped bool   // identical content was already stored, nothing written
+}
+
+// hashLength is the number of hex chars of SHA-256 used in keys (128 bit)
+const hashLength = 32
+
+// ContentKey returns the content-addressed key for data: {prefix}/{hash[:2]}/{hash}{ext}
+func ContentKey(prefix string, data []byte, ext string) string {
+	sum := sha256.Sum256(data)
+	hash := hex.EncodeToString(sum[:])[:hashLength]
+	return path.Join(strings.Trim(prefix, "/"), hash[:2], hash+ext)
+}
+
+// IsContentKey reports whether key already follows the ContentKey layout (migration skips these)
+func IsContentKey(key string) bool {
+	dir, file := path.Split(key)
+	hash := strings.TrimSuffix(file, path.Ext(file))
+	if len(hash) != hashLength || path.Base(strings.TrimSuffix(dir, "/")) != hash[:2] {
+		return false
+	}
+	_, err := hex.DecodeString(hash)
+	return err == nil
+}
+
+// PutContent stores data under its ContentKey, skipping the write when the key already exists
+func PutContent(ctx context.Context, s Store, prefix string, data []byte, ext, contentType string) (*Object, error) {
+	key := ContentKey(prefix, data, ext)
+	obj := &Object{Key: key, Path: "/" + key, URL: s.URL(key)}
+
+	exists, err := s.Exists(ctx, key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check object %s: %w", key, err)
+	}
+	if exists {
+		obj.Deduped = true
+		return obj, nil
+	}
+	if err := s.Put(ctx, key, data, contentType); err != nil {
+		return nil, fmt.Errorf("failed to store object %s: %w", key, err)
+	}
+	return obj, nil
+}
+
+// ContentTypeForExt returns the MIME type for an image extension
+func ContentTypeForExt(ext string) string {
+	switch strings.ToLower(ext) {
+	case ".webp":
+		return "image/webp"
+	case ".jpg", ".jpeg":
+		return "image/jpeg"
+	case ".png":
+		return "image/png"
+	case ".gif":
+		return "image/gif"
+	case ".avif":
+		return "image/avif"
+	case ".svg":
+		return "image/svg+xml"
+	default:
+		return "application/octet-stream"
+	}
+}
+
+// DefaultLocalDir returns the Next.js public folder the local backend writes into
+// IMAGE_LOCAL_DIR, else legacy IMAGE_STORAGE_DIR, else ../public (server runs from engine-hub)
+func DefaultLocalDir() string {
+	dir := os.Getenv("IMAGE_LOCAL_DIR")
+	if dir == "" {
+		dir = os.Getenv("IMAGE_STORAGE_DIR")
+	}
+	if dir == "" {
+		dir = filepath.Join("..", "public")
+	}
+	if abs, err := filepath.Abs(dir); err == nil {
+		dir = abs
+	}
+	return dir
+}
+
+// FromEnv builds the Store selected by IMAGE_STORE (local | s3)
+// An incomplete s3 config falls back to local so image generation keeps working
+func FromEnv() Store {
+	publicBase := os.Getenv("IMAGE_PUBLIC_BASE_URL")
+
+	switch strings.ToLower(os.Getenv("IMAGE_STORE")) {
+	case "s3":
+		cfg := S3ConfigFromEnv()
+		store, err := NewS3Store(cfg)
+		if err == nil {
+			log.Printf("[IMAGE STORE] Backend: s3 bucket=%s endpoint=%s public=%s", cfg.Bucket, cfg.Endpoint, store.URL(""))
+			return store
+		}
+		log.Printf("[IMAGE STORE] WARNING: %v - falling back to local", err)
+	case "", "local":
+	default:
+		log.Printf("[IMAGE STORE] WARNING: unknown IMAGE_STORE=%q - using local", os.Getenv("IMAGE_STORE"))
+	}
+
+	store := NewLocalStore(DefaultLocalDir(), publicBase)
+	log.Printf("[IMAGE STORE] Backend: local dir=%s public=%s", store.dir, store.URL(""))
+	return store
+}
@@ -0,0 +1,89 @@
+package prompts
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// PostgresStore persists templates in "EngineHubPromptTemplate" and activations in "EngineHubPromptActive"
+type PostgresStore struct {
+	db *sql.DB
+}
+
+// NewPostgresStore creates a template store backed by the given database
+func NewPostgresStore(db *sql.DB) *PostgresStore {
+	return &PostgresStore{db: db}
+}
+
+func (p *PostgresStore) ListTemplates() ([]Template, error) {
+	rows, err := p.db.Query(`
+		SELECT name, version, body, COALESCE(description, ''), COALESCE("createdBy", ''), "createdAt"
+		FROM "EngineHubPromptTemplate"
+		ORDER BY name, version
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query prompt templates: %w", err)
+	}
+	defer rows.Close()
+
+	var out []Template
+	for rows.Next() {
+		t := Template{Source: SourceDB}
+		if err := rows.Scan(&t.Name, &t.Version, &t.Body, &t.Description, &t.CreatedBy, &t.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan prompt template: %w", err)
+		}
+		out = append(out, t)
+	}
+	return out, rows.Err()
+}
+
+func (p *PostgresStore) InsertTemplate(t Template) error {
+	res, err := p.db.Exec(`
+		INSERT INTO "EngineHubPromptTemplate" (name, version, body, description, "createdBy", "createdAt")
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (name, version) DO NOTHING
+	`, t.Name, t.Version, t.Body, nullString(t.Description), nullString(t.CreatedBy), t.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to insert prompt template: %w", err)
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return ErrVersionExists
+	}
+	return nil
+}
+
+func (p *PostgresStore) ListActive() (map[string]int, error) {
+	rows, err := p.db.Query(`SELECT name, version FROM "EngineHubPromptActive"`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query active prompt versions: %w", err)
+	}
+	defer rows.Close()
+
+	out := make(map[string]int)
+	for rows.Next() {
+		var name string
+		var version int
+		if err := rows.Scan(&name, &version); err != nil {
+			return nil, fmt.Errorf("failed to scan active prompt version: %w", err)
+		}
+		out[name] = version
+	}
+	return out, rows.Err()
+}
+
+func (p *PostgresStore) SetActive(name string, version int) error {
+	_, err := p.db.Exec(`
+		INSERT INTO "EngineHubPromptActive" (name, version, "updatedAt")
+		VALUES ($1, $2, $3)
+		ON CONFLICT (name) DO UPDATE SET version = EXCLUDED.version, "updatedAt" = EXCLUDED."updatedAt"
+	`, name, version, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to set active prompt version: %w", err)
+	}
+	return nil
+}
+
+func nullString(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
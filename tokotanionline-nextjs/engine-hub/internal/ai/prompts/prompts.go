@@ -0,0 +1,137 @@
+package prompts
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// Prompt template registry
+// Prompts are versioned text/template bodies so editors can tune wording without a Go release:
+//
+//	builtin   v1 of every template, embedded (templates/<name>.tmpl) - identical to the old hardcoded builders
+//	file      PROMPT_TEMPLATE_DIR/<name>.v<N>.tmpl
+//	db        created via POST /api/engine/prompts ("EngineHubPromptTemplate")
+//
+// Every render returns the exact ref ("content.derivative@v3") so results and samples record which wording produced them
+
+// Template names
+const (
+	ContentCornerstone    = "content.cornerstone"
+	ContentDerivative     = "content.derivative"
+	ContentDerivativeLong = "content.derivative_long"
+	ContentUseCase        = "content.use_case"
+	ContentLongForm       = "content.long_form" // GenerateLongFormArticle hard-constraint prompt
+	ContentBase           = "content.base"      // base prompt for the prompt refiner
+	V2Narrative           = "v2.narrative"
+	AdsCopy               = "ads.copy"
+	QuestionsSERP         = "questions.serp"
+)
+
+// Template sources
+const (
+	SourceBuiltin = "builtin"
+	SourceFile    = "file"
+	SourceDB      = "db"
+)
+
+// Template is one version of a prompt template (versions are immutable; a change is a new version)
+type Template struct {
+	Name        string    `json:"name"`
+	Version     int       `json:"version"`
+	Body        string    `json:"body"`
+	Description string    `json:"description,omitempty"`
+	Source      string    `json:"source"`
+	CreatedBy   string    `json:"createdBy,omitempty"`
+	CreatedAt   time.Time `json:"createdAt"`
+	Active      bool      `json:"active"`
+}
+
+// Ref returns "name@vN"
+func (t Template) Ref() string {
+	return Ref(t.Name, t.Version)
+}
+
+// Ref formats a template reference
+func Ref(name string, version int) string {
+	return fmt.Sprintf("%s@v%d", name, version)
+}
+
+// ParseRef parses "name@vN" (or "name@N")
+func ParseRef(ref string) (string, int, error) {
+	name, v, ok := strings.Cut(ref, "@")
+	if !ok || name == "" {
+		return "", 0, fmt.Errorf("invalid prompt ref %q (expected name@vN)", ref)
+	}
+	version, err := strconv.Atoi(strings.TrimPrefix(v, "v"))
+	if err != nil || version <= 0 {
+		return "", 0, fmt.Errorf("invalid prompt ref %q (expected name@vN)", ref)
+	}
+	return name, version, nil
+}
+
+// Vars are the variables available to every template ({{.Keyword}}, {{.Brand.Name}}, ...)
+// Brand and Locale are nil when the caller has no brand/locale context - guard them with {{if .Brand}}
+type Vars struct {
+	Keyword         string   `json:"keyword,omitempty"`
+	Topic           string   `json:"topic,omitempty"`
+	Title           string   `json:"title,omitempty"`
+	Category        string   `json:"category,omitempty"`
+	ContentType     string   `json:"contentType,omitempty"`
+	Language        string   `json:"language,omitempty"`
+	Outline         string   `json:"outline,omitempty"`
+	Intent          string   `json:"intent,omitempty"`
+	AnswerDriven    bool     `json:"answerDriven,omitempty"`
+	Questions       []string `json:"questions,omitempty"`
+	PageType        string   `json:"pageType,omitempty"`
+	TargetAudience  string   `json:"targetAudience,omitempty"`
+	Brand           *Brand   `json:"brand,omitempty"`
+	Locale          *Locale  `json:"locale,omitempty"`
+	Objective       string   `json:"objective,omitempty"`
+	Platform        string   `json:"platform,omitempty"`
+	ProductContext  string   `json:"productContext,omitempty"`
+	KeyMessage      string   `json:"keyMessage,omitempty"`
+	PreviousVersion int      `json:"previousVersion,omitempty"`
+}
+
+// Brand is the brand context exposed to templates
+type Brand struct {
+	ID   string `json:"id,omitempty"`
+	Name string `json:"name"`
+	Slug string `json:"slug,omitempty"`
+}
+
+// Locale is the locale context exposed to templates
+type Locale struct {
+	Code         string `json:"code"`
+	LanguageName string `json:"languageName"`
+}
+
+// funcs are the helpers available to templates
+var funcs = template.FuncMap{
+	"inc":   func(i int) int { return i + 1 },
+	"lower": strings.ToLower,
+	"upper": strings.ToUpper,
+	"trim":  strings.TrimSpace,
+	"join":  strings.Join,
+}
+
+// parse compiles a template body
+func parse(name, body string) (*template.Template, error) {
+	tmpl, err := template.New(name).Option("missingkey=error").Funcs(funcs).Parse(body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse template: %w", err)
+	}
+	return tmpl, nil
+}
+
+// execute renders a compiled template
+func execute(tmpl *template.Template, vars Vars) (string, error) {
+	var b strings.Builder
+	if err := tmpl.Execute(&b, vars); err != nil {
+		return "", fmt.Errorf("failed to render template: %w", err)
+	}
+	return b.String(), nil
+}
@@ -0,0 +1,418 @@
+package prompts
+
+import (
+	"embed"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+)
+
+//go:embed templates/*.tmpl
+var builtinFS embed.FS
+
+// fileNamePattern matches PROMPT_TEMPLATE_DIR files: <name>.v<N>.tmpl
+var fileNamePattern = regexp.MustCompile(`^(.+)\.v(\d+)\.tmpl$`)
+
+// ErrNotFound is returned for an unknown template name or version
+var ErrNotFound = errors.New("prompt template not found")
+
+// compiled is a template version plus its parsed form
+type compiled struct {
+	Template
+	tmpl *template.Template
+}
+
+// Registry resolves, renders and versions prompt templates
+// Active version per name: explicitly activated version (store), else the highest builtin/file version
+// (editor-created DB versions only go live after activation)
+type Registry struct {
+	dir   string
+	store Store
+
+	mu       sync.RWMutex
+	builtin  map[string]*compiled // embedded v1, the render fallback
+	versions map[string]map[int]*compiled
+	active   map[string]int
+}
+
+// NewRegistry creates a registry reading files from dir ("" = builtin only) and versions from store
+func NewRegistry(dir string, store Store) *Registry {
+	if store == nil {
+		store = NewMemoryStore()
+	}
+	r := &Registry{dir: dir, store: store}
+	if err := r.Reload(); err != nil {
+		log.Printf("[PROMPTS] WARNING: %v", err)
+	}
+	return r
+}
+
+// Reload re-reads builtin, file and store templates
+// Invalid file/store versions are skipped with a warning; the previous state is replaced atomically
+func (r *Registry) Reload() error {
+	builtin := make(map[string]*compiled)
+	versions := make(map[string]map[int]*compiled)
+	add := func(t Template) (*compiled, error) {
+		tmpl, err := parse(t.Name, t.Body)
+		if err != nil {
+			return nil, err
+		}
+		if versions[t.Name] == nil {
+			versions[t.Name] = make(map[int]*compiled)
+		}
+		if prev, ok := versions[t.Name][t.Version]; ok {
+			log.Printf("[PROMPTS] %s (%s) overrides %s", t.Ref(), t.Source, prev.Source)
+		}
+		c := &compiled{Template: t, tmpl: tmpl}
+		versions[t.Name][t.Version] = c
+		return c, nil
+	}
+
+	// Builtin v1 (must always parse: it is the fallback for every name)
+	for _, name := range Names() {
+		body, err := builtinFS.ReadFile("templates/" + name + ".tmpl")
+		if err != nil {
+			return fmt.Errorf("failed to read builtin template %s: %w", name, err)
+		}
+		c, err := add(Template{Name: name, Version: 1, Body: string(body), Source: SourceBuiltin, Description: "builtin"})
+		if err != nil {
+			return fmt.Errorf("builtin template %s is invalid: %w", name, err)
+		}
+		builtin[name] = c
+	}
+
+	var errs []string
+	if r.dir != "" {
+		for _, t := range readDir(r.dir) {
+			if v := Validate(t.Name, t.Body); !v.Valid {
+				log.Printf("[PROMPTS] WARNING: skipping %s from %s: %s", t.Ref(), r.dir, strings.Join(v.Errors, "; "))
+				continue
+			}
+			if _, err := add(t); err != nil {
+				log.Printf("[PROMPTS] WARNING: skipping %s: %v", t.Ref(), err)
+			}
+		}
+	}
+
+	stored, err := r.store.ListTemplates()
+	if err != nil {
+		errs = append(errs, err.Error())
+	}
+	for _, t := range stored {
+		t.Source = SourceDB
+		if v := Validate(t.Name, t.Body); !v.Valid {
+			log.Printf("[PROMPTS] WARNING: skipping stored %s: %s", t.Ref(), strings.Join(v.Errors, "; "))
+			continue
+		}
+		if _, err := add(t); err != nil {
+			log.Printf("[PROMPTS] WARNING: skipping stored %s: %v", t.Ref(), err)
+		}
+	}
+
+	active, err := r.store.ListActive()
+	if err != nil {
+		errs = append(errs, err.Error())
+		active = map[string]int{}
+	}
+
+	r.mu.Lock()
+	r.builtin = builtin
+	r.versions = versions
+	r.active = active
+	r.mu.Unlock()
+
+	refs := make([]string, 0, len(Names()))
+	for _, name := range Names() {
+		refs = append(refs, r.resolve(name).Ref())
+	}
+	log.Printf("[PROMPTS] loaded (dir=%q stored=%d) active: %s", r.dir, len(stored), strings.Join(refs, ", "))
+	if len(errs) > 0 {
+		return fmt.Errorf("failed to load stored prompt templates: %s", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// readDir loads <name>.v<N>.tmpl files (unreadable or misnamed files are skipped)
+func readDir(dir string) []Template {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		log.Printf("[PROMPTS] WARNING: cannot read PROMPT_TEMPLATE_DIR %s: %v", dir, err)
+		return nil
+	}
+	var out []Template
+	for _, e := range entries {
+		m := fileNamePattern.FindStringSubmatch(e.Name())
+		if e.IsDir() || m == nil {
+			continue
+		}
+		version, _ := strconv.Atoi(m[2])
+		if version <= 0 {
+			continue
+		}
+		path := filepath.Join(dir, e.Name())
+		body, err := os.ReadFile(path)
+		if err != nil {
+			log.Printf("[PROMPTS] WARNING: cannot read %s: %v", path, err)
+			continue
+		}
+		t := Template{Name: m[1], Version: version, Body: string(body), Source: SourceFile, Description: e.Name()}
+		if info, err := e.Info(); err == nil {
+			t.CreatedAt = info.ModTime()
+		}
+		out = append(out, t)
+	}
+	return out
+}
+
+// resolve returns the active version of name (nil for an unknown name)
+func (r *Registry) resolve(name string) *compiled {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	versions := r.versions[name]
+	if v, ok := r.active[name]; ok {
+		if c, ok := versions[v]; ok {
+			return c
+		}
+	}
+	var best *compiled
+	for _, c := range versions {
+		if c.Source == SourceDB {
+			continue
+		}
+		if best == nil || c.Version > best.Version {
+			best = c
+		}
+	}
+	return best
+}
+
+// get returns a specific version (0 = active)
+func (r *Registry) get(name string, version int) (*compiled, error) {
+	if version == 0 {
+		if c := r.resolve(name); c != nil {
+			return c, nil
+		}
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, name)
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if c, ok := r.versions[name][version]; ok {
+		return c, nil
+	}
+	return nil, fmt.Errorf("%w: %s", ErrNotFound, Ref(name, version))
+}
+
+// Render renders the active version of name and returns the prompt and its ref
+// If an edited version fails at render time, the builtin v1 is used so generation never breaks on a template typo
+func (r *Registry) Render(name string, vars Vars) (string, string, error) {
+	return r.RenderVersion(name, 0, vars)
+}
+
+// RenderVersion renders a specific version (0 = active)
+func (r *Registry) RenderVersion(name string, version int, vars Vars) (string, string, error) {
+	c, err := r.get(name, version)
+	if err != nil {
+		return "", "", err
+	}
+	text, err := execute(c.tmpl, vars)
+	if err == nil {
+		return text, c.Ref(), nil
+	}
+	if c.Source == SourceBuiltin {
+		return "", "", fmt.Errorf("failed to render %s: %w", c.Ref(), err)
+	}
+
+	log.Printf("[PROMPTS] WARNING: %s failed to render (%v), falling back to builtin", c.Ref(), err)
+	r.mu.RLock()
+	builtin := r.builtin[name]
+	r.mu.RUnlock()
+	if builtin == nil {
+		return "", "", fmt.Errorf("failed to render %s: %w", c.Ref(), err)
+	}
+	text, berr := execute(builtin.tmpl, vars)
+	if berr != nil {
+		return "", "", fmt.Errorf("failed to render %s: %w", builtin.Ref(), berr)
+	}
+	return text, builtin.Ref(), nil
+}
+
+// List returns every version (sorted by name, version) with the active flag set
+func (r *Registry) List() []Template {
+	active := make(map[string]int)
+	for _, name := range Names() {
+		if c := r.resolve(name); c != nil {
+			active[name] = c.Version
+		}
+	}
+
+	r.mu.RLock()
+	var out []Template
+	for name, versions := range r.versions {
+		for _, c := range versions {
+			t := c.Template
+			t.Active = active[name] == t.Version
+			out = append(out, t)
+		}
+	}
+	r.mu.RUnlock()
+
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Name != out[j].Name {
+			return out[i].Name < out[j].Name
+		}
+		return out[i].Version < out[j].Version
+	})
+	return out
+}
+
+// Get returns one version (0 = active)
+func (r *Registry) Get(name string, version int) (Template, error) {
+	c, err := r.get(name, version)
+	if err != nil {
+		return Template{}, err
+	}
+	t := c.Template
+	if active := r.resolve(name); active != nil {
+		t.Active = active.Version == t.Version
+	}
+	return t, nil
+}
+
+// Create validates and stores a new version of t.Name (version = highest existing + 1)
+// activate makes it live immediately; otherwise it waits for Activate
+func (r *Registry) Create(t Template, activate bool) (Template, Validation, error) {
+	v := Validate(t.Name, t.Body)
+	if !v.Valid {
+		return Template{}, v, fmt.Errorf("template is invalid: %s", strings.Join(v.Errors, "; "))
+	}
+
+	r.mu.RLock()
+	next := 1
+	for version := range r.versions[t.Name] {
+		if version >= next {
+			next = version + 1
+		}
+	}
+	r.mu.RUnlock()
+
+	t.Version = next
+	t.Source = SourceDB
+	t.CreatedAt = time.Now()
+	if err := r.store.InsertTemplate(t); err != nil {
+		return Template{}, v, fmt.Errorf("failed to save %s: %w", t.Ref(), err)
+	}
+	log.Printf("[PROMPTS] created %s by=%q", t.Ref(), t.CreatedBy)
+
+	if activate {
+		if err := r.store.SetActive(t.Name, t.Version); err != nil {
+			return Template{}, v, err
+		}
+		log.Printf("[PROMPTS] activated %s", t.Ref())
+	}
+	if err := r.Reload(); err != nil {
+		return Template{}, v, err
+	}
+	created, err := r.Get(t.Name, t.Version)
+	return created, v, err
+}
+
+// Activate makes name@version the live version (rollback = activate an older version)
+func (r *Registry) Activate(name string, version int) error {
+	if _, err := r.get(name, version); err != nil {
+		return err
+	}
+	if err := r.store.SetActive(name, version); err != nil {
+		return err
+	}
+	log.Printf("[PROMPTS] activated %s", Ref(name, version))
+	return r.Reload()
+}
+
+// Preview renders a draft body (validated, not saved) or a stored version (0 = active) with vars
+type Preview struct {
+	Ref        string     `json:"ref,omitempty"`
+	Prompt     string     `json:"prompt"`
+	Chars      int        `json:"chars"`
+	Validation Validation `json:"validation"`
+}
+
+// Preview renders body (draft) if non-empty, else the stored version
+func (r *Registry) Preview(name string, version int, body string, vars Vars) (Preview, error) {
+	if body != "" {
+		v := Validate(name, body)
+		p := Preview{Ref: name + "@draft", Validation: v}
+		if !v.Valid {
+			return p, nil
+		}
+		tmpl, err := parse(name, body)
+		if err != nil {
+			return p, err
+		}
+		text, err := execute(tmpl, vars)
+		if err != nil {
+			p.Validation.Valid = false
+			p.Validation.Errors = append(p.Validation.Errors, err.Error())
+			return p, nil
+		}
+		p.Prompt, p.Chars = text, len(text)
+		return p, nil
+	}
+
+	c, err := r.get(name, version)
+	if err != nil {
+		return Preview{}, err
+	}
+	p := Preview{Ref: c.Ref(), Validation: Validate(name, c.Body)}
+	text, err := execute(c.tmpl, vars)
+	if err != nil {
+		p.Validation.Valid = false
+		p.Validation.Errors = append(p.Validation.Errors, err.Error())
+		return p, nil
+	}
+	p.Prompt, p.Chars = text, len(text)
+	return p, nil
+}
+
+var (
+	defaultMu       sync.RWMutex
+	defaultRegistry *Registry
+)
+
+// Default returns the process-wide registry (PROMPT_TEMPLATE_DIR + in-memory store until SetStore)
+func Default() *Registry {
+	defaultMu.RLock()
+	r := defaultRegistry
+	defaultMu.RUnlock()
+	if r != nil {
+		return r
+	}
+
+	defaultMu.Lock()
+	defer defaultMu.Unlock()
+	if defaultRegistry == nil {
+		defaultRegistry = NewRegistry(os.Getenv("PROMPT_TEMPLATE_DIR"), NewMemoryStore())
+	}
+	return defaultRegistry
+}
+
+// SetStore rebuilds the process-wide registry on store (call once at boot)
+func SetStore(s Store) {
+	r := NewRegistry(os.Getenv("PROMPT_TEMPLATE_DIR"), s)
+	defaultMu.Lock()
+	defaultRegistry = r
+	defaultMu.Unlock()
+}
+
+// Render renders the active version of name with the process-wide registry
+func Render(name string, vars Vars) (string, string, error) {
+	return Default().Render(name, vars)
+}
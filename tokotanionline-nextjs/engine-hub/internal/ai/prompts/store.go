@@ -0,0 +1,74 @@
+package prompts
+
+import (
+	"errors"
+	"sort"
+	"sync"
+)
+
+// ErrVersionExists is returned when saving a name+version that is already stored
+var ErrVersionExists = errors.New("prompt template version already exists")
+
+// Store persists editor-created template versions and the active version per name
+// MemoryStore is the default; PostgresStore is used when a database is available
+type Store interface {
+	ListTemplates() ([]Template, error)
+	InsertTemplate(t Template) error
+	ListActive() (map[string]int, error)
+	SetActive(name string, version int) error
+}
+
+// MemoryStore keeps templates in memory (lost on restart, dev mode)
+type MemoryStore struct {
+	mu        sync.Mutex
+	templates []Template
+	active    map[string]int
+}
+
+// NewMemoryStore creates an empty in-memory template store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{active: make(map[string]int)}
+}
+
+func (m *MemoryStore) ListTemplates() ([]Template, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	out := make([]Template, len(m.templates))
+	copy(out, m.templates)
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Name != out[j].Name {
+			return out[i].Name < out[j].Name
+		}
+		return out[i].Version < out[j].Version
+	})
+	return out, nil
+}
+
+func (m *MemoryStore) InsertTemplate(t Template) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, existing := range m.templates {
+		if existing.Name == t.Name && existing.Version == t.Version {
+			return ErrVersionExists
+		}
+	}
+	m.templates = append(m.templates, t)
+	return nil
+}
+
+func (m *MemoryStore) ListActive() (map[string]int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	out := make(map[string]int, len(m.active))
+	for k, v := range m.active {
+		out[k] = v
+	}
+	return out, nil
+}
+
+func (m *MemoryStore) SetActive(name string, version int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.active[name] = version
+	return nil
+}
@@ -0,0 +1,139 @@
+package prompts
+
+import (
+	"fmt"
+	"strings"
+)
+
+// maxBodyBytes caps a template body (prompts are a few KB; anything larger is a paste accident)
+const maxBodyBytes = 64 * 1024
+
+// Validation is the result of checking a template body
+// Errors block saving/activation; warnings are shown to the editor but do not block
+type Validation struct {
+	Valid    bool     `json:"valid"`
+	Errors   []string `json:"errors,omitempty"`
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+// required lists the variables each template must render (dropping the outline is a bug, not a wording change)
+var required = map[string][]string{
+	ContentCornerstone:    {"Category", "Outline", "Language"},
+	ContentDerivative:     {"Category", "Outline", "Language"},
+	ContentDerivativeLong: {"Category", "Outline", "Language"},
+	ContentUseCase:        {"Category", "Outline", "Language"},
+	ContentLongForm:       {"Topic"},
+	ContentBase:           {},
+	V2Narrative:           {"Topic"},
+	AdsCopy:               {"Objective", "Platform"},
+	QuestionsSERP:         {"Title"},
+}
+
+// outputMarkers are strings the response parsers depend on; missing ones are warnings
+var outputMarkers = map[string][]string{
+	ContentCornerstone:    {`"body"`, `"title"`},
+	ContentDerivative:     {`"body"`, `"title"`},
+	ContentDerivativeLong: {`"body"`, `"title"`},
+	ContentUseCase:        {`"body"`, `"title"`},
+	ContentLongForm:       {`"body"`, `"title"`},
+	AdsCopy:               {`"primaryText"`, `"headline"`, `"ctaText"`},
+}
+
+// KnownName reports whether name is a template the engine renders
+func KnownName(name string) bool {
+	_, ok := required[name]
+	return ok
+}
+
+// Names returns all template names the engine renders
+func Names() []string {
+	return []string{
+		ContentCornerstone, ContentDerivative, ContentDerivativeLong, ContentUseCase,
+		ContentLongForm, ContentBase, V2Narrative, AdsCopy, QuestionsSERP,
+	}
+}
+
+// sentinel is the sample value of a required variable (checked for in the rendered output)
+func sentinel(field string) string {
+	return "<<" + field + ">>"
+}
+
+// SampleVars returns vars with every field set (sentinel values for the required-variable check)
+func SampleVars() Vars {
+	return Vars{
+		Keyword:         sentinel("Keyword"),
+		Topic:           sentinel("Topic"),
+		Title:           sentinel("Title"),
+		Category:        sentinel("Category"),
+		ContentType:     sentinel("ContentType"),
+		Language:        sentinel("Language"),
+		Outline:         sentinel("Outline"),
+		Intent:          sentinel("Intent"),
+		AnswerDriven:    true,
+		Questions:       []string{"Pertanyaan pertama?", "Pertanyaan kedua?"},
+		PageType:        sentinel("PageType"),
+		TargetAudience:  sentinel("TargetAudience"),
+		Brand:           &Brand{ID: "brand-sample", Name: sentinel("Brand.Name"), Slug: "sample"},
+		Locale:          &Locale{Code: "id-ID", LanguageName: "Indonesian"},
+		Objective:       sentinel("Objective"),
+		Platform:        sentinel("Platform"),
+		ProductContext:  sentinel("ProductContext"),
+		KeyMessage:      sentinel("KeyMessage"),
+		PreviousVersion: 1,
+	}
+}
+
+// Validate checks a template body for name: size, syntax, rendering with full and minimal vars,
+// required variables and parser output markers
+func Validate(name, body string) Validation {
+	v := Validation{}
+	fail := func(format string, args ...interface{}) {
+		v.Errors = append(v.Errors, fmt.Sprintf(format, args...))
+	}
+
+	if !KnownName(name) {
+		fail("unknown template name %q (known: %s)", name, strings.Join(Names(), ", "))
+	}
+	if strings.TrimSpace(body) == "" {
+		fail("body is empty")
+	}
+	if len(body) > maxBodyBytes {
+		fail("body is %d bytes (max %d)", len(body), maxBodyBytes)
+	}
+	if len(v.Errors) > 0 {
+		return v
+	}
+
+	tmpl, err := parse(name, body)
+	if err != nil {
+		fail("%v", err)
+		return v
+	}
+
+	full, err := execute(tmpl, SampleVars())
+	if err != nil {
+		fail("%v", err)
+		return v
+	}
+	if strings.TrimSpace(full) == "" {
+		fail("template renders to empty text")
+	}
+	for _, field := range required[name] {
+		if !strings.Contains(full, sentinel(field)) {
+			fail("template must use {{.%s}}", field)
+		}
+	}
+	for _, marker := range outputMarkers[name] {
+		if !strings.Contains(full, marker) {
+			v.Warnings = append(v.Warnings, fmt.Sprintf("output format does not mention %s (the response parser expects it)", marker))
+		}
+	}
+
+	// Minimal vars: optional context absent (no brand/locale/outline) must still render
+	if _, err := execute(tmpl, Vars{}); err != nil {
+		fail("%v (guard optional fields, e.g. {{if .Brand}}...{{end}})", err)
+	}
+
+	v.Valid = len(v.Errors) == 0
+	return v
+}
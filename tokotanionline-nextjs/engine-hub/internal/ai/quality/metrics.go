@@ -71,6 +71,7 @@ func removeMarkdown(text string) string {
 	text = regexp.MustCompile("`[^`]+`").ReplaceAllString(text, "")
 	// Remove images
 	text = regexp.MustCompile(`!\[([^\]]*)\]\([^\)]+\)`).ReplaceAllString(text, "")
+	text = regexp.MustCompile(`<img[^>]*>`).ReplaceAllString(text, "")
 	return text
 }
 
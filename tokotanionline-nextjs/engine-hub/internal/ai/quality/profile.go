@@ -1,6 +1,10 @@
 package quality
 
-import "fmt"
+import (
+	"fmt"
+
+	"engine-hub/internal/ai/content"
+)
 
 // QualityProfile defines the non-negotiable baseline (B) requirements
 // This is a QUALITY CONTRACT - not a suggestion, but a hard requirement
@@ -61,6 +65,19 @@ func DerivativeLongQualityProfile() QualityProfile {
 	}
 }
 
+// ProfileFor returns the quality profile that applies to a content type
+// DERIVATIVE and DERIVATIVE_LONG have their own ranges; everything else uses baseline B
+func ProfileFor(contentType content.ContentType) QualityProfile {
+	switch contentType {
+	case content.ContentDerivativeLong:
+		return DerivativeLongQualityProfile()
+	case content.ContentDerivative:
+		return DerivativeQualityProfile()
+	default:
+		return DefaultQualityProfile()
+	}
+}
+
 // Pass returns true if all metrics pass the quality profile requirements
 func (qp QualityProfile) Pass(metrics Metrics) bool {
 	// Word count check
@@ -3,6 +3,7 @@ package quality
 import (
 	"fmt"
 	"log"
+	"strconv"
 	"strings"
 )
 
@@ -20,6 +21,7 @@ type PromptRefiner struct {
 	currentVersion string
 	currentPrompt  string
 	store          SampleStore
+	filter         SampleFilter // samples this refiner learns from (zero = all)
 }
 
 // NewPromptRefiner creates a new prompt refiner
@@ -31,6 +33,19 @@ func NewPromptRefiner(basePrompt string, store SampleStore) *PromptRefiner {
 	}
 }
 
+// NewPromptRefinerFor creates a refiner for one prompt version that only learns from samples matching filter
+// (e.g. PromptVersion "content.derivative@v2", so other templates' history does not leak in)
+func NewPromptRefinerFor(basePrompt, version string, store SampleStore, filter SampleFilter) *PromptRefiner {
+	filter.Limit = 0
+	filter.Pass = nil
+	return &PromptRefiner{
+		currentVersion: version,
+		currentPrompt:  basePrompt,
+		store:          store,
+		filter:         filter,
+	}
+}
+
 // GetCurrentPrompt returns the current prompt version
 func (pr *PromptRefiner) GetCurrentPrompt() (string, string) {
 	return pr.currentPrompt, pr.currentVersion
@@ -42,7 +57,10 @@ func (pr *PromptRefiner) RefinePrompt() (string, error) {
 	log.Println("[PROMPT REFINER] Starting prompt refinement analysis...")
 
 	// Get all passing samples
-	passingSamples, err := pr.store.GetPassingSamples()
+	pass := true
+	passingFilter := pr.filter
+	passingFilter.Pass = &pass
+	passingSamples, err := pr.store.Query(passingFilter)
 	if err != nil {
 		return "", fmt.Errorf("failed to get passing samples: %w", err)
 	}
@@ -101,6 +119,12 @@ func (pr *PromptRefiner) analyzePassingPatterns(samples []GenerationSample) map[
 	return patterns
 }
 
+// Guidance sentences appended by buildRefinedPrompt
+const (
+	wordCountGuidance = "PENTING: Tulis konten dengan panjang natural sekitar 1200-1800 kata."
+	depthGuidance     = "PENTING: Konten harus mendalam dan substantif."
+)
+
 // buildRefinedPrompt constructs refined prompt based on patterns
 // This maintains core requirements but adjusts based on what works
 func (pr *PromptRefiner) buildRefinedPrompt(basePrompt string, patterns map[string]interface{}) string {
@@ -114,14 +138,15 @@ func (pr *PromptRefiner) buildRefinedPrompt(basePrompt string, patterns map[stri
 		avgDepthScore, _ := patterns["avgDepthScore"].(float64)
 
 		// Add natural guidance about word count (not forced, but informed)
-		if avgWordCount >= 1200 && avgWordCount <= 1800 {
-			refined += "\n\nPENTING: Tulis konten dengan panjang natural sekitar 1200-1800 kata. "
+		// Guidance already present (refined earlier) is not appended twice
+		if avgWordCount >= 1200 && avgWordCount <= 1800 && !strings.Contains(refined, wordCountGuidance) {
+			refined += "\n\n" + wordCountGuidance + " "
 			refined += "Jangan paksa panjang dengan filler, tapi pastikan kedalaman dan cakupan memadai.\n"
 		}
 
 		// Add guidance about depth if we see good patterns
-		if avgDepthScore >= 0.75 {
-			refined += "\nPENTING: Konten harus mendalam dan substantif. "
+		if avgDepthScore >= 0.75 && !strings.Contains(refined, depthGuidance) {
+			refined += "\n" + depthGuidance + " "
 			refined += "Setiap paragraf harus memberikan nilai informasi yang jelas. "
 			refined += "Hindari paragraf yang hanya mengulang poin sebelumnya.\n"
 		}
@@ -133,7 +158,9 @@ func (pr *PromptRefiner) buildRefinedPrompt(basePrompt string, patterns map[stri
 // CheckStability checks if prompt is stable (3-5 consecutive passing outputs)
 func (pr *PromptRefiner) CheckStability() (bool, error) {
 	// Get recent samples
-	recentSamples, err := pr.store.GetRecentSamples(5)
+	recentFilter := pr.filter
+	recentFilter.Limit = 5
+	recentSamples, err := pr.store.Query(recentFilter)
 	if err != nil {
 		return false, fmt.Errorf("failed to get recent samples: %w", err)
 	}
@@ -167,19 +194,11 @@ func (pr *PromptRefiner) SetStable() {
 	// For now, we just log it
 }
 
-// Helper function to increment version
+// Helper function to increment version ("v2" -> "v3")
 func incrementVersion(version string) string {
-	if !strings.HasPrefix(version, "v") {
+	n, err := strconv.Atoi(strings.TrimPrefix(version, "v"))
+	if !strings.HasPrefix(version, "v") || err != nil {
 		return "v1"
 	}
-
-	versionNum := strings.TrimPrefix(version, "v")
-	// Simple increment (in production, might want to parse as int)
-	if versionNum == "1" {
-		return "v2"
-	} else if versionNum == "2" {
-		return "v3"
-	}
-	// Continue incrementing...
-	return fmt.Sprintf("v%s", versionNum)
+	return fmt.Sprintf("v%d", n+1)
 }
@@ -0,0 +1,111 @@
+package quality
+
+import (
+	"errors"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Proposal statuses
+const (
+	ProposalPending    = "PENDING"
+	ProposalApproved   = "APPROVED"
+	ProposalRejected   = "REJECTED"
+	ProposalSuperseded = "SUPERSEDED" // a newer proposal for the same template replaced it
+)
+
+var (
+	ErrProposalNotFound = errors.New("prompt proposal not found")
+	ErrProposalDecided  = errors.New("prompt proposal is no longer pending")
+	ErrProposalStale    = errors.New("prompt proposal is based on a version that is no longer active")
+)
+
+// Proposal is a refined prompt template waiting for human approval
+// Nothing goes live until it is approved; approval creates and activates a new template version
+type Proposal struct {
+	ID           string     `json:"id"`
+	TemplateName string     `json:"templateName"`   // e.g. content.derivative
+	BaseRef      string     `json:"baseRef"`        // active version the samples were generated with
+	Body         string     `json:"body"`           // proposed template body
+	Status       string     `json:"status"`         // PENDING, APPROVED, REJECTED, SUPERSEDED
+	SampleCount  int        `json:"sampleCount"`    // samples of BaseRef at proposal time
+	PassingCount int        `json:"passingCount"`   // of which passed the quality profile
+	Note         string     `json:"note,omitempty"` // reviewer note (reject reason)
+	CreatedAt    time.Time  `json:"createdAt"`
+	DecidedAt    *time.Time `json:"decidedAt,omitempty"`
+	DecidedBy    string     `json:"decidedBy,omitempty"`
+	ApprovedRef  string     `json:"approvedRef,omitempty"` // version created on approval
+}
+
+// PassRate returns the share of passing samples behind the proposal
+func (p Proposal) PassRate() float64 {
+	if p.SampleCount == 0 {
+		return 0
+	}
+	return float64(p.PassingCount) / float64(p.SampleCount)
+}
+
+// ProposalStore persists refinement proposals
+// MemoryProposalStore is the default; PostgresProposalStore is used when a database is available
+type ProposalStore interface {
+	InsertProposal(p Proposal) error
+	UpdateProposal(p Proposal) error
+	GetProposal(id string) (Proposal, error)
+	ListProposals(templateName, status string) ([]Proposal, error) // newest first; empty = all
+}
+
+// MemoryProposalStore keeps proposals in memory (lost on restart, dev mode)
+type MemoryProposalStore struct {
+	mu        sync.Mutex
+	proposals map[string]Proposal
+}
+
+// NewMemoryProposalStore creates an empty in-memory proposal store
+func NewMemoryProposalStore() *MemoryProposalStore {
+	return &MemoryProposalStore{proposals: make(map[string]Proposal)}
+}
+
+func (m *MemoryProposalStore) InsertProposal(p Proposal) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.proposals[p.ID] = p
+	return nil
+}
+
+func (m *MemoryProposalStore) UpdateProposal(p Proposal) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.proposals[p.ID]; !ok {
+		return ErrProposalNotFound
+	}
+	m.proposals[p.ID] = p
+	return nil
+}
+
+func (m *MemoryProposalStore) GetProposal(id string) (Proposal, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	p, ok := m.proposals[id]
+	if !ok {
+		return Proposal{}, ErrProposalNotFound
+	}
+	return p, nil
+}
+
+func (m *MemoryProposalStore) ListProposals(templateName, status string) ([]Proposal, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var out []Proposal
+	for _, p := range m.proposals {
+		if templateName != "" && p.TemplateName != templateName {
+			continue
+		}
+		if status != "" && p.Status != status {
+			continue
+		}
+		out = append(out, p)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].CreatedAt.After(out[j].CreatedAt) })
+	return out, nil
+}
@@ -0,0 +1,113 @@
+package quality
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+// PostgresProposalStore persists refinement proposals in "EngineHubPromptProposal"
+type PostgresProposalStore struct {
+	db *sql.DB
+}
+
+// NewPostgresProposalStore creates a proposal store backed by the given database
+func NewPostgresProposalStore(db *sql.DB) *PostgresProposalStore {
+	return &PostgresProposalStore{db: db}
+}
+
+const proposalColumns = `id, "templateName", "baseRef", body, status, "sampleCount", "passingCount",
+	COALESCE(note, ''), "createdAt", "decidedAt", COALESCE("decidedBy", ''), COALESCE("approvedRef", '')`
+
+func (p *PostgresProposalStore) InsertProposal(pr Proposal) error {
+	_, err := p.db.Exec(`
+		INSERT INTO "EngineHubPromptProposal" (id, "templateName", "baseRef", body, status,
+			"sampleCount", "passingCount", note, "createdAt")
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	`, pr.ID, pr.TemplateName, pr.BaseRef, pr.Body, pr.Status,
+		pr.SampleCount, pr.PassingCount, nullString(pr.Note), pr.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to insert prompt proposal: %w", err)
+	}
+	return nil
+}
+
+func (p *PostgresProposalStore) UpdateProposal(pr Proposal) error {
+	res, err := p.db.Exec(`
+		UPDATE "EngineHubPromptProposal"
+		SET status = $2, note = $3, "decidedAt" = $4, "decidedBy" = $5, "approvedRef" = $6
+		WHERE id = $1
+	`, pr.ID, pr.Status, nullString(pr.Note), pr.DecidedAt, nullString(pr.DecidedBy), nullString(pr.ApprovedRef))
+	if err != nil {
+		return fmt.Errorf("failed to update prompt proposal: %w", err)
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return ErrProposalNotFound
+	}
+	return nil
+}
+
+func (p *PostgresProposalStore) GetProposal(id string) (Proposal, error) {
+	row := p.db.QueryRow(`SELECT `+proposalColumns+` FROM "EngineHubPromptProposal" WHERE id = $1`, id)
+	pr, err := scanProposal(row)
+	if err == sql.ErrNoRows {
+		return Proposal{}, ErrProposalNotFound
+	}
+	return pr, err
+}
+
+func (p *PostgresProposalStore) ListProposals(templateName, status string) ([]Proposal, error) {
+	var conditions []string
+	var args []interface{}
+	if templateName != "" {
+		args = append(args, templateName)
+		conditions = append(conditions, fmt.Sprintf(`"templateName" = $%d`, len(args)))
+	}
+	if status != "" {
+		args = append(args, status)
+		conditions = append(conditions, fmt.Sprintf(`status = $%d`, len(args)))
+	}
+	query := `SELECT ` + proposalColumns + ` FROM "EngineHubPromptProposal"`
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	query += ` ORDER BY "createdAt" DESC`
+
+	rows, err := p.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query prompt proposals: %w", err)
+	}
+	defer rows.Close()
+
+	var out []Proposal
+	for rows.Next() {
+		pr, err := scanProposal(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, pr)
+	}
+	return out, rows.Err()
+}
+
+// scanProposal reads one row selected with proposalColumns
+func scanProposal(row interface{ Scan(...interface{}) error }) (Proposal, error) {
+	var pr Proposal
+	var decidedAt sql.NullTime
+	err := row.Scan(&pr.ID, &pr.TemplateName, &pr.BaseRef, &pr.Body, &pr.Status, &pr.SampleCount, &pr.PassingCount,
+		&pr.Note, &pr.CreatedAt, &decidedAt, &pr.DecidedBy, &pr.ApprovedRef)
+	if err == sql.ErrNoRows {
+		return Proposal{}, err
+	}
+	if err != nil {
+		return Proposal{}, fmt.Errorf("failed to scan prompt proposal: %w", err)
+	}
+	if decidedAt.Valid {
+		pr.DecidedAt = &decidedAt.Time
+	}
+	return pr, nil
+}
+
+func nullString(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
@@ -0,0 +1,323 @@
+package quality
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"engine-hub/internal/ai/prompts"
+
+	"github.com/google/uuid"
+)
+
+// Refinement outcomes per template
+const (
+	RefineInsufficientData = "insufficient_data" // fewer than min samples for the active version
+	RefineStable           = "stable"            // recent samples all pass, nothing to change
+	RefineNoChange         = "no_change"         // refiner produced the same body
+	RefineProposed         = "proposed"          // new proposal stored (PENDING)
+	RefineAlreadyPending   = "already_pending"   // identical proposal is already waiting
+	RefineError            = "error"
+)
+
+// refinableTemplates are the templates controlled production collects samples for
+var refinableTemplates = []string{
+	prompts.ContentCornerstone,
+	prompts.ContentDerivative,
+	prompts.ContentDerivativeLong,
+	prompts.ContentUseCase,
+}
+
+// RefinementResult is the outcome of one refinement pass for one template
+type RefinementResult struct {
+	TemplateName string `json:"templateName"`
+	Ref          string `json:"ref"` // active version analyzed
+	Samples      int    `json:"samples"`
+	Passing      int    `json:"passing"`
+	Action       string `json:"action"`
+	ProposalID   string `json:"proposalId,omitempty"`
+	Error        string `json:"error,omitempty"`
+}
+
+// RefinementRun is one pass over all refinable templates
+type RefinementRun struct {
+	StartedAt time.Time          `json:"startedAt"`
+	Results   []RefinementResult `json:"results"`
+}
+
+var (
+	proposalStoreMu sync.RWMutex
+	proposalStore   ProposalStore = NewMemoryProposalStore()
+
+	refineMu sync.Mutex // one pass (or approval) at a time
+	lastRun  *RefinementRun
+)
+
+// SetProposalStore replaces the proposal store (call once at boot)
+func SetProposalStore(s ProposalStore) {
+	proposalStoreMu.Lock()
+	defer proposalStoreMu.Unlock()
+	proposalStore = s
+}
+
+func getProposalStore() ProposalStore {
+	proposalStoreMu.RLock()
+	defer proposalStoreMu.RUnlock()
+	return proposalStore
+}
+
+// RefinementInterval reads PROMPT_REFINE_INTERVAL_HOURS from env (default 6 hours, 0 = disabled)
+func RefinementInterval() time.Duration {
+	hours := 6
+	if v := os.Getenv("PROMPT_REFINE_INTERVAL_HOURS"); v != "" {
+		if val, err := strconv.Atoi(v); err == nil && val >= 0 {
+			hours = val
+		}
+	}
+	return time.Duration(hours) * time.Hour
+}
+
+// refinementMinSamples reads PROMPT_REFINE_MIN_SAMPLES from env (default 3, the refiner's own threshold)
+func refinementMinSamples() int {
+	if v := os.Getenv("PROMPT_REFINE_MIN_SAMPLES"); v != "" {
+		if val, err := strconv.Atoi(v); err == nil && val > 0 {
+			return val
+		}
+	}
+	return 3
+}
+
+// StartRefinement runs a refinement pass every interval
+// Stops when stopCh is closed; interval 0 disables the loop (passes can still be triggered via API)
+func StartRefinement(interval time.Duration, stopCh <-chan struct{}) {
+	if interval <= 0 {
+		log.Println("[PROMPT REFINER] Periodic refinement disabled (PROMPT_REFINE_INTERVAL_HOURS=0)")
+		return
+	}
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-stopCh:
+				return
+			case <-ticker.C:
+				RunRefinement()
+			}
+		}
+	}()
+}
+
+// RunRefinement analyzes the samples of each refinable template's active version
+// Stable versions are left alone; otherwise RefinePrompt output is stored as a PENDING proposal
+func RunRefinement() RefinementRun {
+	refineMu.Lock()
+	defer refineMu.Unlock()
+
+	run := RefinementRun{StartedAt: time.Now()}
+	for _, name := range refinableTemplates {
+		result := refineTemplate(name)
+		if result.Action == RefineError {
+			log.Printf("[PROMPT REFINER] %s: %s", name, result.Error)
+		}
+		run.Results = append(run.Results, result)
+	}
+	lastRun = &run
+	return run
+}
+
+// LastRefinement returns the most recent pass (nil if none ran yet)
+func LastRefinement() *RefinementRun {
+	refineMu.Lock()
+	defer refineMu.Unlock()
+	return lastRun
+}
+
+func refineTemplate(name string) RefinementResult {
+	result := RefinementResult{TemplateName: name}
+	fail := func(err error) RefinementResult {
+		result.Action = RefineError
+		result.Error = err.Error()
+		return result
+	}
+
+	active, err := prompts.Default().Get(name, 0)
+	if err != nil {
+		return fail(err)
+	}
+	result.Ref = active.Ref()
+
+	filter := SampleFilter{PromptVersion: result.Ref}
+	samples, err := Samples().Query(filter)
+	if err != nil {
+		return fail(err)
+	}
+	result.Samples = len(samples)
+	for _, s := range samples {
+		if s.PassQualityProfile {
+			result.Passing++
+		}
+	}
+	if result.Samples < refinementMinSamples() {
+		result.Action = RefineInsufficientData
+		return result
+	}
+
+	refiner := NewPromptRefinerFor(active.Body, fmt.Sprintf("v%d", active.Version), Samples(), filter)
+	stable, err := refiner.CheckStability()
+	if err != nil {
+		return fail(err)
+	}
+	if stable {
+		refiner.SetStable()
+		result.Action = RefineStable
+		return result
+	}
+
+	refined, err := refiner.RefinePrompt()
+	if err != nil {
+		return fail(err)
+	}
+	if refined == active.Body {
+		result.Action = RefineNoChange
+		return result
+	}
+	if v := prompts.Validate(name, refined); !v.Valid {
+		return fail(fmt.Errorf("refined template is invalid: %v", v.Errors))
+	}
+
+	store := getProposalStore()
+	pending, err := store.ListProposals(name, ProposalPending)
+	if err != nil {
+		return fail(err)
+	}
+	for _, p := range pending {
+		if p.BaseRef == result.Ref && p.Body == refined {
+			result.Action = RefineAlreadyPending
+			result.ProposalID = p.ID
+			return result
+		}
+	}
+
+	// Older pending proposals for this template are replaced by the new one
+	now := time.Now()
+	for _, p := range pending {
+		p.Status = ProposalSuperseded
+		p.DecidedAt = &now
+		p.DecidedBy = "prompt-refiner"
+		if err := store.UpdateProposal(p); err != nil {
+			return fail(err)
+		}
+	}
+
+	proposal := Proposal{
+		ID:           uuid.New().String(),
+		TemplateName: name,
+		BaseRef:      result.Ref,
+		Body:         refined,
+		Status:       ProposalPending,
+		SampleCount:  result.Samples,
+		PassingCount: result.Passing,
+		CreatedAt:    now,
+	}
+	if err := store.InsertProposal(proposal); err != nil {
+		return fail(err)
+	}
+	log.Printf("[PROMPT REFINER] Proposed new version of %s (base %s, %d/%d samples passing): %s",
+		name, result.Ref, result.Passing, result.Samples, proposal.ID)
+
+	result.Action = RefineProposed
+	result.ProposalID = proposal.ID
+	return result
+}
+
+// ListProposals returns proposals, newest first (empty filters = all)
+func ListProposals(templateName, status string) ([]Proposal, error) {
+	return getProposalStore().ListProposals(templateName, status)
+}
+
+// GetProposal returns one proposal
+func GetProposal(id string) (Proposal, error) {
+	return getProposalStore().GetProposal(id)
+}
+
+// ApproveProposal creates the proposed body as a new template version and activates it
+// Fails with ErrProposalStale if the active version changed since the proposal was made
+func ApproveProposal(id, decidedBy string) (Proposal, error) {
+	refineMu.Lock()
+	defer refineMu.Unlock()
+
+	store := getProposalStore()
+	p, err := store.GetProposal(id)
+	if err != nil {
+		return Proposal{}, err
+	}
+	if p.Status != ProposalPending {
+		return p, ErrProposalDecided
+	}
+	active, err := prompts.Default().Get(p.TemplateName, 0)
+	if err != nil {
+		return p, err
+	}
+	if active.Ref() != p.BaseRef {
+		return p, fmt.Errorf("%w (base %s, active %s)", ErrProposalStale, p.BaseRef, active.Ref())
+	}
+
+	createdBy := decidedBy
+	if createdBy == "" {
+		createdBy = "prompt-refiner"
+	}
+	t, validation, err := prompts.Default().Create(prompts.Template{
+		Name:        p.TemplateName,
+		Body:        p.Body,
+		Description: fmt.Sprintf("Refinement of %s (proposal %s, %d/%d samples passing)", p.BaseRef, p.ID, p.PassingCount, p.SampleCount),
+		CreatedBy:   createdBy,
+	}, true)
+	if !validation.Valid {
+		return p, fmt.Errorf("proposed template is invalid: %v", validation.Errors)
+	}
+	if err != nil {
+		return p, fmt.Errorf("failed to create template version: %w", err)
+	}
+
+	now := time.Now()
+	p.Status = ProposalApproved
+	p.DecidedAt = &now
+	p.DecidedBy = decidedBy
+	p.ApprovedRef = t.Ref()
+	if err := store.UpdateProposal(p); err != nil {
+		return p, err
+	}
+	log.Printf("[PROMPT REFINER] Proposal %s approved: %s is now active", p.ID, p.ApprovedRef)
+	return p, nil
+}
+
+// RejectProposal marks a pending proposal rejected (the active version stays as is)
+func RejectProposal(id, decidedBy, note string) (Proposal, error) {
+	refineMu.Lock()
+	defer refineMu.Unlock()
+
+	store := getProposalStore()
+	p, err := store.GetProposal(id)
+	if err != nil {
+		return Proposal{}, err
+	}
+	if p.Status != ProposalPending {
+		return p, ErrProposalDecided
+	}
+
+	now := time.Now()
+	p.Status = ProposalRejected
+	p.DecidedAt = &now
+	p.DecidedBy = decidedBy
+	p.Note = note
+	if err := store.UpdateProposal(p); err != nil {
+		return p, err
+	}
+	log.Printf("[PROMPT REFINER] Proposal %s rejected", p.ID)
+	return p, nil
+}
@@ -2,19 +2,24 @@ package quality
 
 import (
 	"encoding/json"
+	"sync"
 	"time"
 
 	"engine-hub/internal/ai/content"
+
+	"github.com/google/uuid"
 )
 
 // GenerationSample represents a learning data point
 // This is NOT published content, but data for prompt refinement
 type GenerationSample struct {
+	ID string `json:"id,omitempty"` // assigned by the store on Save
+
 	// Input data
 	InputOutline string `json:"inputOutline"` // The outline used
 
 	// Prompt version for tracking evolution
-	PromptVersion string `json:"promptVersion"` // e.g., "v1", "v2", "v3"
+	PromptVersion string `json:"promptVersion"` // prompt template ref, e.g. "content.derivative@v3"
 
 	// Generated output
 	OutputText string `json:"outputText"` // Full generated article (body)
@@ -32,16 +37,21 @@ type GenerationSample struct {
 }
 
 // NewGenerationSample creates a new generation sample with metrics analysis
+// Empty promptVersion = the template ref recorded on the result by the generator
 func NewGenerationSample(
 	req content.ContentRequest,
 	result *content.ContentResult,
 	promptVersion string,
 ) GenerationSample {
+	if promptVersion == "" {
+		promptVersion = result.PromptVersion
+	}
+
 	// Analyze metrics
 	metrics := AnalyzeContent(result, req.Outline)
 
-	// Check against quality profile
-	profile := DefaultQualityProfile()
+	// Check against the quality profile of the content type (same contract controlled production reports)
+	profile := ProfileFor(req.ContentType)
 	passes := profile.Pass(metrics)
 
 	return GenerationSample{
@@ -68,18 +78,75 @@ func FromJSON(data []byte) (GenerationSample, error) {
 	return sample, err
 }
 
+// SampleFilter selects stored samples; empty fields match everything
+// Pass nil = passing and failing; Limit 0 = no limit (most recent first)
+type SampleFilter struct {
+	Category      string
+	ContentType   string
+	PromptVersion string
+	Pass          *bool
+	From          time.Time
+	To            time.Time
+	Limit         int
+}
+
+// matches reports whether sample passes the filter (Limit ignored)
+func (f SampleFilter) matches(sample GenerationSample) bool {
+	if f.Category != "" && sample.Category != f.Category {
+		return false
+	}
+	if f.ContentType != "" && sample.ContentType != f.ContentType {
+		return false
+	}
+	if f.PromptVersion != "" && sample.PromptVersion != f.PromptVersion {
+		return false
+	}
+	if f.Pass != nil && sample.PassQualityProfile != *f.Pass {
+		return false
+	}
+	if !f.From.IsZero() && sample.Timestamp.Before(f.From) {
+		return false
+	}
+	if !f.To.IsZero() && !sample.Timestamp.Before(f.To) {
+		return false
+	}
+	return true
+}
+
 // SampleStore is an interface for storing generation samples
 // This enables learning data persistence
+// InMemorySampleStore is the default; PostgresSampleStore is used when a database is available
 type SampleStore interface {
 	Save(sample GenerationSample) error
 	GetByPromptVersion(version string) ([]GenerationSample, error)
 	GetPassingSamples() ([]GenerationSample, error)
 	GetRecentSamples(limit int) ([]GenerationSample, error)
+	Query(filter SampleFilter) ([]GenerationSample, error)
+}
+
+var (
+	sampleStoreMu sync.RWMutex
+	sampleStore   SampleStore = NewInMemorySampleStore()
+)
+
+// SetSampleStore replaces the shared sample store (call once at boot)
+func SetSampleStore(s SampleStore) {
+	sampleStoreMu.Lock()
+	defer sampleStoreMu.Unlock()
+	sampleStore = s
+}
+
+// Samples returns the shared sample store (controlled production writes, the refinement service reads)
+func Samples() SampleStore {
+	sampleStoreMu.RLock()
+	defer sampleStoreMu.RUnlock()
+	return sampleStore
 }
 
 // InMemorySampleStore is a simple in-memory implementation for development
 // In production, this should be replaced with database-backed store
 type InMemorySampleStore struct {
+	mu      sync.RWMutex
 	samples []GenerationSample
 }
 
@@ -92,12 +159,19 @@ func NewInMemorySampleStore() *InMemorySampleStore {
 
 // Save stores a generation sample
 func (s *InMemorySampleStore) Save(sample GenerationSample) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if sample.ID == "" {
+		sample.ID = uuid.New().String()
+	}
 	s.samples = append(s.samples, sample)
 	return nil
 }
 
 // GetByPromptVersion returns all samples for a specific prompt version
 func (s *InMemorySampleStore) GetByPromptVersion(version string) ([]GenerationSample, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
 	var result []GenerationSample
 	for _, sample := range s.samples {
 		if sample.PromptVersion == version {
@@ -109,6 +183,8 @@ func (s *InMemorySampleStore) GetByPromptVersion(version string) ([]GenerationSa
 
 // GetPassingSamples returns all samples that passed quality profile
 func (s *InMemorySampleStore) GetPassingSamples() ([]GenerationSample, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
 	var result []GenerationSample
 	for _, sample := range s.samples {
 		if sample.PassQualityProfile {
@@ -120,6 +196,8 @@ func (s *InMemorySampleStore) GetPassingSamples() ([]GenerationSample, error) {
 
 // GetRecentSamples returns the most recent samples, limited by limit
 func (s *InMemorySampleStore) GetRecentSamples(limit int) ([]GenerationSample, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
 	if limit <= 0 || limit > len(s.samples) {
 		limit = len(s.samples)
 	}
@@ -134,3 +212,20 @@ func (s *InMemorySampleStore) GetRecentSamples(limit int) ([]GenerationSample, e
 	copy(result, s.samples[start:])
 	return result, nil
 }
+
+// Query returns samples matching filter, most recent first
+func (s *InMemorySampleStore) Query(filter SampleFilter) ([]GenerationSample, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var result []GenerationSample
+	for i := len(s.samples) - 1; i >= 0; i-- {
+		if !filter.matches(s.samples[i]) {
+			continue
+		}
+		result = append(result, s.samples[i])
+		if filter.Limit > 0 && len(result) >= filter.Limit {
+			break
+		}
+	}
+	return result, nil
+}
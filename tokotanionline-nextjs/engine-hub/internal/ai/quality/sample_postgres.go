@@ -0,0 +1,113 @@
+package quality
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// PostgresSampleStore persists generation samples in "EngineHubGenerationSample"
+type PostgresSampleStore struct {
+	db *sql.DB
+}
+
+// NewPostgresSampleStore creates a sample store backed by the given database
+func NewPostgresSampleStore(db *sql.DB) *PostgresSampleStore {
+	return &PostgresSampleStore{db: db}
+}
+
+const sampleColumns = `id, "createdAt", category, "contentType", "promptVersion", pass, "inputOutline", "outputText", metrics`
+
+func (p *PostgresSampleStore) Save(sample GenerationSample) error {
+	if sample.ID == "" {
+		sample.ID = uuid.New().String()
+	}
+	metrics, err := json.Marshal(sample.Metrics)
+	if err != nil {
+		return fmt.Errorf("failed to marshal sample metrics: %w", err)
+	}
+
+	_, err = p.db.Exec(`
+		INSERT INTO "EngineHubGenerationSample" (`+sampleColumns+`)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	`, sample.ID, sample.Timestamp, sample.Category, sample.ContentType, sample.PromptVersion,
+		sample.PassQualityProfile, sample.InputOutline, sample.OutputText, string(metrics))
+	if err != nil {
+		return fmt.Errorf("failed to insert generation sample: %w", err)
+	}
+	return nil
+}
+
+func (p *PostgresSampleStore) GetByPromptVersion(version string) ([]GenerationSample, error) {
+	return p.Query(SampleFilter{PromptVersion: version})
+}
+
+func (p *PostgresSampleStore) GetPassingSamples() ([]GenerationSample, error) {
+	pass := true
+	return p.Query(SampleFilter{Pass: &pass})
+}
+
+func (p *PostgresSampleStore) GetRecentSamples(limit int) ([]GenerationSample, error) {
+	return p.Query(SampleFilter{Limit: limit})
+}
+
+func (p *PostgresSampleStore) Query(filter SampleFilter) ([]GenerationSample, error) {
+	var conditions []string
+	var args []interface{}
+	add := func(cond string, value interface{}) {
+		args = append(args, value)
+		conditions = append(conditions, fmt.Sprintf(cond, len(args)))
+	}
+	if filter.Category != "" {
+		add(`category = $%d`, filter.Category)
+	}
+	if filter.ContentType != "" {
+		add(`"contentType" = $%d`, filter.ContentType)
+	}
+	if filter.PromptVersion != "" {
+		add(`"promptVersion" = $%d`, filter.PromptVersion)
+	}
+	if filter.Pass != nil {
+		add(`pass = $%d`, *filter.Pass)
+	}
+	if !filter.From.IsZero() {
+		add(`"createdAt" >= $%d`, filter.From)
+	}
+	if !filter.To.IsZero() {
+		add(`"createdAt" < $%d`, filter.To)
+	}
+
+	query := `SELECT ` + sampleColumns + ` FROM "EngineHubGenerationSample"`
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	query += ` ORDER BY "createdAt" DESC`
+	if filter.Limit > 0 {
+		args = append(args, filter.Limit)
+		query += fmt.Sprintf(" LIMIT $%d", len(args))
+	}
+
+	rows, err := p.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query generation samples: %w", err)
+	}
+	defer rows.Close()
+
+	var out []GenerationSample
+	for rows.Next() {
+		var sample GenerationSample
+		var metrics []byte
+		if err := rows.Scan(&sample.ID, &sample.Timestamp, &sample.Category, &sample.ContentType, &sample.PromptVersion,
+			&sample.PassQualityProfile, &sample.InputOutline, &sample.OutputText, &metrics); err != nil {
+			return nil, fmt.Errorf("failed to scan generation sample: %w", err)
+		}
+		if err := json.Unmarshal(metrics, &sample.Metrics); err != nil {
+			return nil, fmt.Errorf("failed to decode metrics of sample %s: %w", sample.ID, err)
+		}
+		out = append(out, sample)
+	}
+	return out, rows.Err()
+}
@@ -518,6 +518,13 @@ func enforceSingleH1(body string, title string) string {
 	}
 }
 
+// NormalizeHeadingStructure applies heading fixes only (normalize + 1×H1)
+// Body text is not touched - used by OPTIMIZE jobs on stored posts
+func NormalizeHeadingStructure(body string, title string) string {
+	result := normalizeHeadings(body)
+	return enforceSingleH1(result, title)
+}
+
 // optimizeMetaTitle creates natural, optimized meta title
 // NO keyword stuffing, NO rigid templates
 // ENFORCES 60 character limit strictly
@@ -0,0 +1,96 @@
+package usage
+
+import (
+	"strings"
+	"sync"
+)
+
+// defaultMemoryLimit caps entries kept by the in-memory ledger
+const defaultMemoryLimit = 20000
+
+// MemoryStore keeps ledger entries in memory (lost on restart, dev mode)
+type MemoryStore struct {
+	mu         sync.Mutex
+	entries    []Entry
+	maxEntries int
+}
+
+// NewMemoryStore creates an in-memory ledger bounded to maxEntries
+func NewMemoryStore(maxEntries int) *MemoryStore {
+	if maxEntries <= 0 {
+		maxEntries = defaultMemoryLimit
+	}
+	return &MemoryStore{maxEntries: maxEntries}
+}
+
+func (m *MemoryStore) Insert(e Entry) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.entries = append(m.entries, e)
+	if len(m.entries) > m.maxEntries {
+		m.entries = m.entries[len(m.entries)-m.maxEntries:]
+	}
+	return nil
+}
+
+func (m *MemoryStore) Report(filter ReportFilter) ([]ReportRow, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	groups := make(map[string]*ReportRow)
+	var order []string
+	for _, e := range m.entries {
+		if !filter.matches(e) {
+			continue
+		}
+
+		values := make([]string, len(filter.GroupBy))
+		for i, g := range filter.GroupBy {
+			values[i] = dimension(e, g)
+		}
+		key := strings.Join(values, "\x00")
+
+		row, ok := groups[key]
+		if !ok {
+			row = &ReportRow{}
+			for i, g := range filter.GroupBy {
+				row.set(g, values[i])
+			}
+			groups[key] = row
+			order = append(order, key)
+		}
+
+		row.Calls++
+		if !e.Success {
+			row.Failed++
+		}
+		row.InputTokens += int64(e.InputTokens)
+		row.OutputTokens += int64(e.OutputTokens)
+		row.Images += int64(e.Images)
+		row.CostUSD += e.CostUSD
+	}
+
+	rows := make([]ReportRow, 0, len(order))
+	for _, key := range order {
+		rows = append(rows, *groups[key])
+	}
+	sortRows(rows)
+	return rows, nil
+}
+
+// dimension returns the group-by value of an entry
+func dimension(e Entry, group string) string {
+	switch group {
+	case GroupDay:
+		return e.CreatedAt.Format("2006-01-02")
+	case GroupBrand:
+		return e.BrandID
+	case GroupEndpoint:
+		return e.Endpoint
+	case GroupModel:
+		return e.Model
+	case GroupCaller:
+		return e.Caller
+	}
+	return ""
+}
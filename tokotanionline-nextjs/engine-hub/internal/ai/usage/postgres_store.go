@@ -0,0 +1,127 @@
+package usage
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+// PostgresStore persists the ledger in "EngineHubAIUsage"
+type PostgresStore struct {
+	db *sql.DB
+}
+
+// NewPostgresStore creates a ledger store backed by the given database
+func NewPostgresStore(db *sql.DB) *PostgresStore {
+	return &PostgresStore{db: db}
+}
+
+// groupColumns maps group-by dimensions to SQL expressions
+var groupColumns = map[string]string{
+	GroupDay:      `to_char("createdAt", 'YYYY-MM-DD')`,
+	GroupBrand:    `COALESCE("brandId", '')`,
+	GroupEndpoint: `COALESCE(endpoint, '')`,
+	GroupModel:    `model`,
+	GroupCaller:   `caller`,
+}
+
+func (p *PostgresStore) Insert(e Entry) error {
+	query := `
+		INSERT INTO "EngineHubAIUsage" (id, "createdAt", kind, caller, endpoint, provider, model,
+			"inputTokens", "outputTokens", images, "costUsd", "jobId", "pageId", "brandId",
+			success, error, "latencyMs")
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17)
+	`
+	_, err := p.db.Exec(query,
+		e.ID, e.CreatedAt, e.Kind, e.Caller, nullString(e.Endpoint), e.Provider, e.Model,
+		e.InputTokens, e.OutputTokens, e.Images, e.CostUSD,
+		nullString(e.JobID), nullString(e.PageID), nullString(e.BrandID),
+		e.Success, nullString(e.Error), e.LatencyMs,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to insert usage entry: %w", err)
+	}
+	return nil
+}
+
+func (p *PostgresStore) Report(filter ReportFilter) ([]ReportRow, error) {
+	var conditions []string
+	var args []interface{}
+	add := func(cond string, value interface{}) {
+		args = append(args, value)
+		conditions = append(conditions, fmt.Sprintf(cond, len(args)))
+	}
+	if !filter.From.IsZero() {
+		add(`"createdAt" >= $%d`, filter.From)
+	}
+	if !filter.To.IsZero() {
+		add(`"createdAt" < $%d`, filter.To)
+	}
+	if filter.BrandID != "" {
+		add(`"brandId" = $%d`, filter.BrandID)
+	}
+	if filter.Endpoint != "" {
+		add(`endpoint = $%d`, filter.Endpoint)
+	}
+	if filter.Kind != "" {
+		add(`kind = $%d`, filter.Kind)
+	}
+
+	selects := make([]string, 0, len(filter.GroupBy)+6)
+	for _, g := range filter.GroupBy {
+		col, ok := groupColumns[g]
+		if !ok {
+			return nil, fmt.Errorf("invalid groupBy %q", g)
+		}
+		selects = append(selects, col)
+	}
+	groupBy := strings.Join(selects, ", ")
+	selects = append(selects,
+		`COUNT(*)`,
+		`COUNT(*) FILTER (WHERE NOT success)`,
+		`COALESCE(SUM("inputTokens"), 0)`,
+		`COALESCE(SUM("outputTokens"), 0)`,
+		`COALESCE(SUM(images), 0)`,
+		`COALESCE(SUM("costUsd"), 0)`,
+	)
+
+	query := `SELECT ` + strings.Join(selects, ", ") + ` FROM "EngineHubAIUsage"`
+	if len(conditions) > 0 {
+		query += ` WHERE ` + strings.Join(conditions, " AND ")
+	}
+	if groupBy != "" {
+		query += ` GROUP BY ` + groupBy + ` ORDER BY ` + groupBy
+	}
+
+	rows, err := p.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query usage report: %w", err)
+	}
+	defer rows.Close()
+
+	report := []ReportRow{}
+	for rows.Next() {
+		var row ReportRow
+		values := make([]string, len(filter.GroupBy))
+		dest := make([]interface{}, 0, len(values)+6)
+		for i := range values {
+			dest = append(dest, &values[i])
+		}
+		dest = append(dest, &row.Calls, &row.Failed, &row.InputTokens, &row.OutputTokens, &row.Images, &row.CostUSD)
+		if err := rows.Scan(dest...); err != nil {
+			return nil, fmt.Errorf("failed to scan usage report: %w", err)
+		}
+		for i, g := range filter.GroupBy {
+			row.set(g, values[i])
+		}
+		if len(filter.GroupBy) == 0 && row.Calls == 0 {
+			continue // aggregate over an empty ledger
+		}
+		report = append(report, row)
+	}
+	return report, rows.Err()
+}
+
+func nullString(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
@@ -0,0 +1,98 @@
+package usage
+
+import (
+	"encoding/json"
+	"log"
+	"os"
+	"strings"
+	"sync"
+)
+
+// Price is the list price of a model (USD)
+type Price struct {
+	InputPerMTok  float64 `json:"input"`  // per 1M input tokens
+	OutputPerMTok float64 `json:"output"` // per 1M output tokens
+	PerImage      float64 `json:"image"`  // per generated image
+}
+
+// defaultPrices are public list prices; override or extend with AI_PRICING_JSON, e.g.
+// AI_PRICING_JSON='{"gpt-5.2":{"input":1.75,"output":14},"dall-e-3":{"image":0.04}}'
+// Keys match the exact model or the longest model prefix (gpt-4o-2024-08-06 → gpt-4o)
+var defaultPrices = map[string]Price{
+	"gpt-5.2":     {InputPerMTok: 1.75, OutputPerMTok: 14.00},
+	"gpt-5":       {InputPerMTok: 1.25, OutputPerMTok: 10.00},
+	"gpt-5-mini":  {InputPerMTok: 0.25, OutputPerMTok: 2.00},
+	"gpt-4o":      {InputPerMTok: 2.50, OutputPerMTok: 10.00},
+	"gpt-4o-mini": {InputPerMTok: 0.15, OutputPerMTok: 0.60},
+	"gpt-4-turbo": {InputPerMTok: 10.00, OutputPerMTok: 30.00},
+	"gpt-4":       {InputPerMTok: 30.00, OutputPerMTok: 60.00},
+	"dall-e-3":    {PerImage: 0.04},
+	"dall-e-2":    {PerImage: 0.02},
+	"gpt-image-1": {PerImage: 0.04},
+}
+
+var (
+	pricesOnce sync.Once
+	prices     map[string]Price
+
+	unpricedMu sync.Mutex
+	unpriced   = make(map[string]bool)
+)
+
+// loadPrices merges AI_PRICING_JSON over the defaults
+func loadPrices() {
+	prices = make(map[string]Price, len(defaultPrices))
+	for model, p := range defaultPrices {
+		prices[model] = p
+	}
+
+	raw := os.Getenv("AI_PRICING_JSON")
+	if raw == "" {
+		return
+	}
+	var overrides map[string]Price
+	if err := json.Unmarshal([]byte(raw), &overrides); err != nil {
+		log.Printf("[AI USAGE] WARNING: invalid AI_PRICING_JSON, using default prices: %v", err)
+		return
+	}
+	for model, p := range overrides {
+		prices[strings.ToLower(model)] = p
+	}
+}
+
+// PriceFor returns the price of model (exact match, else longest prefix)
+func PriceFor(model string) (Price, bool) {
+	pricesOnce.Do(loadPrices)
+
+	model = strings.ToLower(model)
+	if p, ok := prices[model]; ok {
+		return p, true
+	}
+	best := ""
+	for key := range prices {
+		if strings.HasPrefix(model, key) && len(key) > len(best) {
+			best = key
+		}
+	}
+	if best == "" {
+		return Price{}, false
+	}
+	return prices[best], true
+}
+
+// Cost returns the USD cost of a call; unknown models cost 0 (warned once per model)
+func Cost(model string, inputTokens, outputTokens, images int) float64 {
+	p, ok := PriceFor(model)
+	if !ok {
+		unpricedMu.Lock()
+		if !unpriced[model] {
+			unpriced[model] = true
+			log.Printf("[AI USAGE] WARNING: no price for model %q, recording cost 0 (set AI_PRICING_JSON)", model)
+		}
+		unpricedMu.Unlock()
+		return 0
+	}
+	return float64(inputTokens)*p.InputPerMTok/1e6 +
+		float64(outputTokens)*p.OutputPerMTok/1e6 +
+		float64(images)*p.PerImage
+}
@@ -0,0 +1,136 @@
+package usage
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Report group-by dimensions (/api/engine/ai/usage?groupBy=day,brand,endpoint)
+const (
+	GroupDay      = "day"
+	GroupBrand    = "brand"
+	GroupEndpoint = "endpoint"
+	GroupModel    = "model"
+	GroupCaller   = "caller"
+)
+
+var validGroups = map[string]bool{
+	GroupDay: true, GroupBrand: true, GroupEndpoint: true, GroupModel: true, GroupCaller: true,
+}
+
+// ReportFilter selects and groups ledger entries
+// Zero From/To mean unbounded; To is exclusive
+type ReportFilter struct {
+	From     time.Time
+	To       time.Time
+	BrandID  string
+	Endpoint string
+	Kind     string
+	GroupBy  []string
+}
+
+// Validate checks the group-by dimensions
+func (f ReportFilter) Validate() error {
+	for _, g := range f.GroupBy {
+		if !validGroups[g] {
+			return fmt.Errorf("invalid groupBy %q (allowed: day, brand, endpoint, model, caller)", g)
+		}
+	}
+	return nil
+}
+
+// matches reports whether e passes the filter (group-by ignored)
+func (f ReportFilter) matches(e Entry) bool {
+	if !f.From.IsZero() && e.CreatedAt.Before(f.From) {
+		return false
+	}
+	if !f.To.IsZero() && !e.CreatedAt.Before(f.To) {
+		return false
+	}
+	if f.BrandID != "" && e.BrandID != f.BrandID {
+		return false
+	}
+	if f.Endpoint != "" && e.Endpoint != f.Endpoint {
+		return false
+	}
+	if f.Kind != "" && e.Kind != f.Kind {
+		return false
+	}
+	return true
+}
+
+// ReportRow is one aggregated group; dimension fields not grouped on are empty
+type ReportRow struct {
+	Day          string  `json:"day,omitempty"`
+	BrandID      string  `json:"brandId,omitempty"`
+	Endpoint     string  `json:"endpoint,omitempty"`
+	Model        string  `json:"model,omitempty"`
+	Caller       string  `json:"caller,omitempty"`
+	Calls        int     `json:"calls"`
+	Failed       int     `json:"failed"`
+	InputTokens  int64   `json:"inputTokens"`
+	OutputTokens int64   `json:"outputTokens"`
+	Images       int64   `json:"images"`
+	CostUSD      float64 `json:"costUsd"`
+}
+
+// set assigns a group-by dimension value
+func (r *ReportRow) set(group, value string) {
+	switch group {
+	case GroupDay:
+		r.Day = value
+	case GroupBrand:
+		r.BrandID = value
+	case GroupEndpoint:
+		r.Endpoint = value
+	case GroupModel:
+		r.Model = value
+	case GroupCaller:
+		r.Caller = value
+	}
+}
+
+// Store persists ledger entries
+// MemoryStore is the default; PostgresStore is used when a database is available
+type Store interface {
+	Insert(e Entry) error
+	Report(filter ReportFilter) ([]ReportRow, error)
+}
+
+var (
+	storeMu sync.RWMutex
+	store   Store = NewMemoryStore(defaultMemoryLimit)
+)
+
+// SetStore replaces the ledger store (call once at boot)
+func SetStore(s Store) {
+	storeMu.Lock()
+	defer storeMu.Unlock()
+	store = s
+}
+
+func getStore() Store {
+	storeMu.RLock()
+	defer storeMu.RUnlock()
+	return store
+}
+
+// Report aggregates ledger entries
+func Report(filter ReportFilter) ([]ReportRow, error) {
+	if err := filter.Validate(); err != nil {
+		return nil, err
+	}
+	return getStore().Report(filter)
+}
+
+// sortRows orders rows by their group-by dimensions
+func sortRows(rows []ReportRow) {
+	sort.Slice(rows, func(i, j int) bool {
+		a := []string{rows[i].Day, rows[i].BrandID, rows[i].Endpoint, rows[i].Model, rows[i].Caller}
+		b := []string{rows[j].Day, rows[j].BrandID, rows[j].Endpoint, rows[j].Model, rows[j].Caller}
+		return strings.Join(a, "\x00") < strings.Join(b, "\x00")
+	})
+}
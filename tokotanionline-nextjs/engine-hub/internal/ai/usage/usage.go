@@ -0,0 +1,129 @@
+package usage
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AI USAGE LEDGER
+// Setiap panggilan LLM & image dicatat: token input/output, jumlah gambar, model, harga,
+// dan konteks pemanggil (jobId, pageId, brandId, endpoint)
+// Dipakai untuk laporan /api/engine/ai/usage dan daily budget di RateGuard
+
+// Entry kinds
+const (
+	KindText  = "text"
+	KindImage = "image"
+)
+
+// Entry is one billed AI call
+type Entry struct {
+	ID           string    `json:"id"`
+	CreatedAt    time.Time `json:"createdAt"`
+	Kind         string    `json:"kind"`   // text | image
+	Caller       string    `json:"caller"` // content | v2 | ads | questions | image
+	Endpoint     string    `json:"endpoint,omitempty"`
+	Provider     string    `json:"provider"`
+	Model        string    `json:"model"`
+	InputTokens  int       `json:"inputTokens"`
+	OutputTokens int       `json:"outputTokens"`
+	Images       int       `json:"images"`
+	CostUSD      float64   `json:"costUsd"`
+	JobID        string    `json:"jobId,omitempty"`
+	PageID       string    `json:"pageId,omitempty"`
+	BrandID      string    `json:"brandId,omitempty"`
+	Success      bool      `json:"success"`
+	Error        string    `json:"error,omitempty"`
+	LatencyMs    int64     `json:"latencyMs"`
+}
+
+// Attribution is the caller context attached to every entry recorded under a ctx
+type Attribution struct {
+	JobID    string
+	PageID   string
+	BrandID  string
+	Endpoint string
+}
+
+type attributionKey struct{}
+
+// WithAttribution returns ctx carrying a; non-empty fields override those already in ctx
+func WithAttribution(ctx context.Context, a Attribution) context.Context {
+	merged := AttributionFrom(ctx)
+	if a.JobID != "" {
+		merged.JobID = a.JobID
+	}
+	if a.PageID != "" {
+		merged.PageID = a.PageID
+	}
+	if a.BrandID != "" {
+		merged.BrandID = a.BrandID
+	}
+	if a.Endpoint != "" {
+		merged.Endpoint = a.Endpoint
+	}
+	return context.WithValue(ctx, attributionKey{}, merged)
+}
+
+// AttributionFrom returns the attribution stored in ctx (zero value when none)
+func AttributionFrom(ctx context.Context) Attribution {
+	if ctx == nil {
+		return Attribution{}
+	}
+	a, _ := ctx.Value(attributionKey{}).(Attribution)
+	return a
+}
+
+// Record prices e (when CostUSD is not set), attaches the ctx attribution and writes it to the ledger
+// Ledger failures are logged, never returned: accounting must not break generation
+func Record(ctx context.Context, e Entry) {
+	if e.ID == "" {
+		e.ID = uuid.New().String()
+	}
+	if e.CreatedAt.IsZero() {
+		e.CreatedAt = time.Now()
+	}
+
+	a := AttributionFrom(ctx)
+	if e.JobID == "" {
+		e.JobID = a.JobID
+	}
+	if e.PageID == "" {
+		e.PageID = a.PageID
+	}
+	if e.BrandID == "" {
+		e.BrandID = a.BrandID
+	}
+	if e.Endpoint == "" {
+		e.Endpoint = a.Endpoint
+	}
+
+	if e.CostUSD == 0 && e.Success && e.Provider != "ollama" { // local models are free
+		e.CostUSD = Cost(e.Model, e.InputTokens, e.OutputTokens, e.Images)
+	}
+
+	if err := getStore().Insert(e); err != nil {
+		log.Printf("[AI USAGE] Failed to record %s call (%s/%s): %v", e.Kind, e.Caller, e.Model, err)
+	}
+}
+
+// SpendSince returns the total cost (USD) recorded since t
+func SpendSince(t time.Time) (float64, error) {
+	rows, err := getStore().Report(ReportFilter{From: t})
+	if err != nil {
+		return 0, err
+	}
+	total := 0.0
+	for _, r := range rows {
+		total += r.CostUSD
+	}
+	return total, nil
+}
+
+// StartOfDay returns local midnight of t (budget & report day boundary)
+func StartOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
@@ -4,12 +4,14 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"io"
 	"log"
-	"net/http"
 	"os"
 	"strings"
 	"time"
+
+	"engine-hub/internal/ai/llm"
+	"engine-hub/internal/ai/prompts"
+	"engine-hub/internal/ai/usage"
 )
 
 // PHASE 8A.2: Ads Copy Generator
@@ -40,49 +42,45 @@ type AdsGenerationResult struct {
 	GeneratedAt  string `json:"generatedAt"`  // ISO 8601 timestamp
 	Status       string `json:"status"`        // "SUCCESS" | "FAILED"
 	Error        string `json:"error,omitempty"` // Error message if failed
+	PromptVersion string `json:"promptVersion,omitempty"` // Prompt template ref, e.g. ads.copy@v2
 }
 
 // AdsGenerator handles AI ads copy generation
 // PHASE 8A.2: Ads Copy Producer - versioned output
 type AdsGenerator struct {
-	client *http.Client
-	apiKey string
-	apiURL string
-	model  string
+	llm    *llm.Client
+	llmErr error // why llm is nil (missing key, unknown provider)
 }
 
 // NewAdsGenerator creates a new ads copy generator
+// Provider/model/fallback: LLM_ADS_* (see llm.ConfigFromEnv), default Chat Completions + AI_MODEL or GPT-4
 func NewAdsGenerator() *AdsGenerator {
 	log.Println("[ADS GENERATOR] Creating new ads generator...")
 	
-	// Get API key from environment
-	apiKey := os.Getenv("OPENAI_API_KEY")
-	if apiKey == "" {
-		apiKey = os.Getenv("AI_API_KEY")
-	}
-	
-	// Get API URL
-	apiURL := os.Getenv("AI_API_URL")
-	if apiURL == "" {
-		apiURL = "https://api.openai.com/v1/chat/completions"
-	}
-	
 	// Model (can be configured)
 	model := os.Getenv("AI_MODEL")
 	if model == "" {
 		model = "gpt-4" // Default to GPT-4
 	}
 	
-	log.Printf("[ADS GENERATOR] API key loaded: present=%v, length=%d", apiKey != "", len(apiKey))
-	log.Printf("[ADS GENERATOR] Model: %s", model)
+	cfg := llm.ConfigFromEnv("ads", llm.Config{
+		Provider: llm.ProviderChat,
+		Model:    model,
+		BaseURL:  os.Getenv("AI_API_URL"),
+		Timeout:  60 * time.Second, // 1 minute timeout for ads generation
+	})
+	
+	log.Printf("[ADS GENERATOR] API key loaded: present=%v, length=%d", cfg.APIKey != "", len(cfg.APIKey))
+	log.Printf("[ADS GENERATOR] Provider: %s, Model: %s", cfg.Provider, cfg.Model)
+	
+	client, err := llm.New(cfg)
+	if err != nil {
+		log.Printf("[ADS GENERATOR] WARNING: AI client not configured: %v", err)
+	}
 	
 	return &AdsGenerator{
-		client: &http.Client{
-			Timeout: 60 * time.Second, // 1 minute timeout for ads generation
-		},
-		apiKey: apiKey,
-		apiURL: apiURL,
-		model:  model,
+		llm:    client,
+		llmErr: err,
 	}
 }
 
@@ -121,16 +119,22 @@ func (g *AdsGenerator) GenerateAdsCopy(ctx context.Context, req AdsGenerationReq
 	log.Printf("[ADS GENERATOR] Locale context: localeId=%s, localeCode=%s", 
 		req.LocaleContext.LocaleID, req.LocaleContext.LocaleCode)
 	
-	if g.apiKey == "" {
-		return nil, fmt.Errorf("AI_API_KEY or OPENAI_API_KEY environment variable not set")
+	if g.llm == nil {
+		return nil, fmt.Errorf("AI client not configured: %w", g.llmErr)
 	}
 	
+	// Usage ledger: attribute the ads copy call to the brand
+	ctx = usage.WithAttribution(ctx, usage.Attribution{BrandID: req.BrandContext.BrandID})
+	
 	// PHASE 8A.2: Generate ads copy components
 	// Build prompt for ads copy generation
-	prompt := g.buildAdsPrompt(req)
+	prompt, promptRef, err := g.buildAdsPrompt(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build prompt: %w", err)
+	}
 	
 	// Call AI API
-	response, err := g.callAI(prompt, 2000) // 2000 tokens for ads copy
+	response, err := g.callAI(ctx, prompt, 2000) // 2000 tokens for ads copy
 	if err != nil {
 		return nil, fmt.Errorf("AI API call failed: %w", err)
 	}
@@ -150,6 +154,7 @@ func (g *AdsGenerator) GenerateAdsCopy(ctx context.Context, req AdsGenerationReq
 	
 	result.GeneratedAt = time.Now().Format(time.RFC3339)
 	result.Status = "SUCCESS"
+	result.PromptVersion = promptRef
 	
 	log.Printf("[ADS GENERATOR] Ads copy generated: version=%d, headline=%s", 
 		result.Version, result.Headline)
@@ -157,101 +162,24 @@ func (g *AdsGenerator) GenerateAdsCopy(ctx context.Context, req AdsGenerationReq
 	return result, nil
 }
 
-// buildAdsPrompt builds the prompt for ads copy generation
+// buildAdsPrompt renders the ads copy prompt (ads.copy template, see internal/ai/prompts)
 // PHASE 8A: Brand & Locale-aware prompt generation
-func (g *AdsGenerator) buildAdsPrompt(req AdsGenerationRequest) string {
-	var prompt strings.Builder
-	
-	prompt.WriteString("Generate compelling ad copy for a digital advertising campaign.\n\n")
-	
-	// PHASE 8A: Include brand context
-	if req.BrandContext != nil {
-		prompt.WriteString(fmt.Sprintf("Brand Context:\n"))
-		prompt.WriteString(fmt.Sprintf("- Brand Name: %s\n", req.BrandContext.BrandName))
-		prompt.WriteString(fmt.Sprintf("- Brand Identity: Write ad copy that reflects %s's brand values and voice\n", req.BrandContext.BrandName))
-		prompt.WriteString("- IMPORTANT: This ad copy is exclusive to this brand. Do not reference or reuse content from other brands.\n\n")
-	}
-	
-	// PHASE 8A: Include locale context
-	if req.LocaleContext != nil {
-		prompt.WriteString(fmt.Sprintf("Locale Context:\n"))
-		prompt.WriteString(fmt.Sprintf("- Language: %s (%s)\n", req.LocaleContext.LanguageName, req.LocaleContext.LocaleCode))
-		prompt.WriteString(fmt.Sprintf("- Write in native %s, NOT translated content\n", req.LocaleContext.LanguageName))
-		prompt.WriteString(fmt.Sprintf("- Use natural %s expressions and cultural context\n", req.LocaleContext.LanguageName))
-		prompt.WriteString("- IMPORTANT: Generate original ad copy in this language. Do NOT translate from other languages.\n")
-		prompt.WriteString("- CRITICAL: This is a NEW VERSION for this locale. Do not reuse content from other locales.\n\n")
-	}
-	
-	// Campaign objective
-	prompt.WriteString(fmt.Sprintf("Campaign Objective: %s\n", req.Objective))
-	prompt.WriteString(fmt.Sprintf("Platform: %s\n\n", req.Platform))
-	
-	// Platform-specific requirements
-	switch req.Platform {
-	case "FB", "Facebook", "Meta":
-		prompt.WriteString("Platform Requirements (Facebook/Meta Ads):\n")
-		prompt.WriteString("- Primary Text: 125 characters recommended (max 500)\n")
-		prompt.WriteString("- Headline: 40 characters recommended (max 40)\n")
-		prompt.WriteString("- Description: 125 characters recommended (optional)\n")
-		prompt.WriteString("- CTA: Clear action verb (e.g., 'Shop Now', 'Learn More', 'Sign Up')\n\n")
-	case "Google":
-		prompt.WriteString("Platform Requirements (Google Ads):\n")
-		prompt.WriteString("- Headline: 30 characters recommended (max 30)\n")
-		prompt.WriteString("- Description: 90 characters recommended (max 90)\n")
-		prompt.WriteString("- CTA: Clear action verb (e.g., 'Buy Now', 'Get Started', 'Contact Us')\n\n")
-	case "TikTok":
-		prompt.WriteString("Platform Requirements (TikTok Ads):\n")
-		prompt.WriteString("- Primary Text: 100 characters recommended (max 220)\n")
-		prompt.WriteString("- Headline: 34 characters recommended (max 34)\n")
-		prompt.WriteString("- Description: 100 characters recommended (optional)\n")
-		prompt.WriteString("- CTA: Clear action verb (e.g., 'Shop Now', 'Download', 'Learn More')\n\n")
+func (g *AdsGenerator) buildAdsPrompt(req AdsGenerationRequest) (string, string, error) {
+	vars := prompts.Vars{
+		Objective:       req.Objective,
+		Platform:        req.Platform,
+		ProductContext:  req.ProductContext,
+		TargetAudience:  req.TargetAudience,
+		KeyMessage:      req.KeyMessage,
+		PreviousVersion: req.PreviousVersion,
 	}
-	
-	// Additional context
-	if req.ProductContext != "" {
-		prompt.WriteString(fmt.Sprintf("Product/Service Context: %s\n\n", req.ProductContext))
-	}
-	if req.TargetAudience != "" {
-		prompt.WriteString(fmt.Sprintf("Target Audience: %s\n\n", req.TargetAudience))
-	}
-	if req.KeyMessage != "" {
-		prompt.WriteString(fmt.Sprintf("Key Message/Angle: %s\n\n", req.KeyMessage))
-	}
-	
-	// Versioning note
-	if req.PreviousVersion > 0 {
-		prompt.WriteString(fmt.Sprintf("Note: This is version %d. Create a NEW version with different angles/approaches.\n\n", req.PreviousVersion+1))
-	}
-	
-	prompt.WriteString("Requirements:\n")
-	prompt.WriteString("- Generate compelling, conversion-focused ad copy\n")
-	prompt.WriteString("- Use persuasive language appropriate for the campaign objective\n")
-	prompt.WriteString("- Include clear value proposition\n")
-	prompt.WriteString("- CTA should be action-oriented and specific\n")
-	prompt.WriteString("- Respect platform character limits\n")
-	prompt.WriteString("- NO generic or placeholder text\n")
-	prompt.WriteString("- NO keyword stuffing\n")
-	
-	// PHASE 8A: Brand isolation requirement
 	if req.BrandContext != nil {
-		prompt.WriteString(fmt.Sprintf("- CRITICAL: This ad copy is for brand %s only. Do not mix or reference other brands.\n", req.BrandContext.BrandName))
+		vars.Brand = &prompts.Brand{ID: req.BrandContext.BrandID, Name: req.BrandContext.BrandName, Slug: req.BrandContext.BrandSlug}
 	}
-	
-	// PHASE 8A: Locale isolation requirement
 	if req.LocaleContext != nil {
-		prompt.WriteString(fmt.Sprintf("- CRITICAL: This ad copy is for locale %s (%s) only. Do not translate or reuse content from other locales.\n", 
-			req.LocaleContext.LocaleCode, req.LocaleContext.LanguageName))
+		vars.Locale = &prompts.Locale{Code: req.LocaleContext.LocaleCode, LanguageName: req.LocaleContext.LanguageName}
 	}
-	
-	prompt.WriteString("\nReturn as JSON with the following structure:\n")
-	prompt.WriteString(`{
-  "primaryText": "...",
-  "headline": "...",
-  "description": "...",
-  "ctaText": "..."
-}`)
-	
-	return prompt.String()
+	return prompts.Render(prompts.AdsCopy, vars)
 }
 
 // parseAdsResponse parses the AI response into AdsGenerationResult
@@ -341,71 +269,19 @@ func (g *AdsGenerator) extractAdsFieldsFromText(text string, req AdsGenerationRe
 }
 
 // callAI calls the AI API
-func (g *AdsGenerator) callAI(prompt string, maxTokens int) (string, error) {
+func (g *AdsGenerator) callAI(ctx context.Context, prompt string, maxTokens int) (string, error) {
 	log.Printf("[ADS GENERATOR] Calling AI API: prompt length=%d, maxTokens=%d", len(prompt), maxTokens)
 	
-	// Build request payload (OpenAI Chat Completions format)
-	payload := map[string]interface{}{
-		"model": g.model,
-		"messages": []map[string]string{
-			{
-				"role":    "user",
-				"content": prompt,
-			},
-		},
-		"max_tokens": maxTokens,
-		"temperature": 0.8, // Slightly higher temperature for creative ads copy
-	}
-	
-	jsonData, err := json.Marshal(payload)
-	if err != nil {
-		return "", fmt.Errorf("failed to marshal request: %w", err)
-	}
-	
-	req, err := http.NewRequest("POST", g.apiURL, strings.NewReader(string(jsonData)))
-	if err != nil {
-		return "", fmt.Errorf("failed to create request: %w", err)
-	}
-	
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", "Bearer "+g.apiKey)
-	
-	resp, err := g.client.Do(req)
+	resp, err := g.llm.Complete(ctx, llm.Request{
+		Prompt:      prompt,
+		MaxTokens:   maxTokens,
+		Temperature: llm.Float(0.8), // Slightly higher temperature for creative ads copy
+	})
 	if err != nil {
 		return "", fmt.Errorf("API request failed: %w", err)
 	}
-	defer resp.Body.Close()
-	
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return "", fmt.Errorf("API returned status %d: %s", resp.StatusCode, string(body))
-	}
-	
-	// Parse response
-	var apiResponse struct {
-		Choices []struct {
-			Message struct {
-				Content string `json:"content"`
-			} `json:"message"`
-		} `json:"choices"`
-	}
-	
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return "", fmt.Errorf("failed to read response: %w", err)
-	}
-	
-	if err := json.Unmarshal(body, &apiResponse); err != nil {
-		log.Printf("[ADS GENERATOR] JSON parsing failed, trying direct extraction: %v", err)
-		return string(body), nil
-	}
-	
-	if len(apiResponse.Choices) == 0 {
-		return "", fmt.Errorf("no choices in API response")
-	}
 	
-	content := apiResponse.Choices[0].Message.Content
-	log.Printf("[ADS GENERATOR] API response received: %d chars", len(content))
+	log.Printf("[ADS GENERATOR] API response received: %d chars (model=%s)", len(resp.Text), resp.Model)
 	
-	return content, nil
+	return resp.Text, nil
 }
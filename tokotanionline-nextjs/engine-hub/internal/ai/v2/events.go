@@ -1,8 +1,11 @@
 package v2
 
 import (
+	"context"
 	"encoding/json"
+	"fmt"
 	"log"
+	"sync"
 	"time"
 )
 
@@ -32,6 +35,7 @@ type EventPayload struct {
 // PHASE 3: Emit event HANYA di server
 type EventEmitter struct {
 	handlers map[EventType][]func(EventPayload)
+	inflight sync.WaitGroup // running handler goroutines, waited by Drain
 }
 
 // NewEventEmitter creates a new event emitter
@@ -61,7 +65,9 @@ func (e *EventEmitter) Emit(eventType EventType, payload EventPayload) {
 	// Call all handlers
 	handlers := e.handlers[eventType]
 	for _, handler := range handlers {
+		e.inflight.Add(1)
 		go func(h func(EventPayload)) {
+			defer e.inflight.Done()
 			defer func() {
 				if r := recover(); r != nil {
 					log.Printf("[EVENT EMITTER] Panic in handler for %s: %v", eventType, r)
@@ -155,6 +161,22 @@ func init() {
 	globalEmitter = NewEventEmitter()
 }
 
+// Drain waits for running event handlers (e.g. SEO worker) to finish, bounded by ctx
+func (e *EventEmitter) Drain(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		e.inflight.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("event handlers still running at shutdown deadline: %w", ctx.Err())
+	}
+}
+
 // GetEventEmitter returns the global event emitter
 func GetEventEmitter() *EventEmitter {
 	return globalEmitter
@@ -4,65 +4,62 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"io"
 	"log"
-	"net/http"
 	"os"
 	"strings"
 	"time"
+
+	"engine-hub/internal/ai/llm"
+	"engine-hub/internal/ai/prompts"
+	"engine-hub/internal/ai/usage"
 )
 
 // Generator handles AI content generation (v2)
 // PHASE 1.3: NO SEO, NO VALIDATOR - Pure AI generation
 type Generator struct {
-	client  *http.Client
-	apiKey  string
-	apiURL  string
+	llm     *llm.Client
+	llmErr  error // why llm is nil (missing key, unknown provider)
 	model   string
 	storage *Storage // PHASE 1.5: Versioning storage
 }
 
 // NewGenerator creates a new v2 content generator
+// Provider/model/fallback: LLM_V2_* (see llm.ConfigFromEnv), default Chat Completions + GPT-5.2
 func NewGenerator() *Generator {
 	log.Println("[AI GENERATOR V2] Creating new generator...")
 	
-	// Get API key from environment
-	apiKey := os.Getenv("OPENAI_API_KEY")
-	if apiKey == "" {
-		apiKey = os.Getenv("AI_API_KEY")
-	}
-	
-	// Get API URL
-	apiURL := os.Getenv("AI_API_URL")
-	if apiURL == "" {
-		apiURL = "https://api.openai.com/v1/chat/completions" // Standard OpenAI endpoint
-	}
-	
-	// A1. Lock Model ke GPT-5.2 (WAJIB - NO FALLBACK)
-	// ❌ Jangan pakai fallback otomatis ke model lama
-	// ➡️ Jika GPT-5.2 unavailable → return error, bukan downgrade diam-diam
+	// A1. Default model GPT-5.2 (NO FALLBACK kecuali LLM_V2_FALLBACK di-set)
+	// ➡️ Jika model unavailable → return error, bukan downgrade diam-diam
 	model := os.Getenv("AI_MODEL")
 	if model == "" {
 		model = "gpt-5.2" // Default to GPT-5.2
 	}
-	if model != "gpt-5.2" {
-		log.Printf("[AI GENERATOR V2] WARNING: Model is set to %s, but GPT-5.2 is required. Forcing GPT-5.2.", model)
-		model = "gpt-5.2"
+	
+	cfg := llm.ConfigFromEnv("v2", llm.Config{
+		Provider: llm.ProviderChat,
+		Model:    model,
+		BaseURL:  os.Getenv("AI_API_URL"),
+		Timeout:  120 * time.Second, // 2 minute timeout for long-form content
+	})
+	if cfg.Model != "gpt-5.2" {
+		log.Printf("[AI GENERATOR V2] WARNING: Model is set to %s (default GPT-5.2)", cfg.Model)
 	}
 	
-	log.Printf("[AI GENERATOR V2] API key loaded: present=%v, length=%d", apiKey != "", len(apiKey))
-	log.Printf("[AI GENERATOR V2] Model: %s (LOCKED - NO FALLBACK)", model)
+	log.Printf("[AI GENERATOR V2] API key loaded: present=%v, length=%d", cfg.APIKey != "", len(cfg.APIKey))
+	log.Printf("[AI GENERATOR V2] Provider: %s, Model: %s, fallbacks: %d", cfg.Provider, cfg.Model, len(cfg.Fallbacks))
+	
+	client, err := llm.New(cfg)
+	if err != nil {
+		log.Printf("[AI GENERATOR V2] WARNING: AI client not configured: %v", err)
+	}
 	
 	// PHASE 1.5: Initialize storage for versioning
 	storage := NewStorage()
 	
 	return &Generator{
-		client: &http.Client{
-			Timeout: 120 * time.Second, // 2 minute timeout for long-form content
-		},
-		apiKey:  apiKey,
-		apiURL:  apiURL,
-		model:   model,
+		llm:     client,
+		llmErr:  err,
+		model:   cfg.Model,
 		storage: storage,
 	}
 }
@@ -102,13 +99,16 @@ func (g *Generator) Generate(ctx context.Context, req GenerationRequest) (*Gener
 	log.Printf("[AI GENERATOR V2] Locale context: localeId=%s, localeCode=%s, languageName=%s", 
 		req.LocaleContext.LocaleID, req.LocaleContext.LocaleCode, req.LocaleContext.LanguageName)
 	
-	if g.apiKey == "" {
-		return nil, fmt.Errorf("AI_API_KEY or OPENAI_API_KEY environment variable not set")
+	if g.llm == nil {
+		return nil, fmt.Errorf("AI client not configured: %w", g.llmErr)
 	}
 	
+	// Usage ledger: attribute every AI call of this generation to the brand
+	ctx = usage.WithAttribution(ctx, usage.Attribution{BrandID: req.BrandContext.BrandID})
+	
 	// PHASE 1.3: Generate complete content package
 	// Step 1: Generate main narrative (long-form content)
-	mainContent, err := g.generateMainNarrative(req)
+	mainContent, promptRef, err := g.generateMainNarrative(ctx, req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate main narrative: %w", err)
 	}
@@ -116,7 +116,7 @@ func (g *Generator) Generate(ctx context.Context, req GenerationRequest) (*Gener
 	log.Printf("[AI GENERATOR V2] Main narrative generated: %d words", countWords(mainContent))
 	
 	// Step 2: Generate structure (sections with headings)
-	sections, err := g.generateStructure(mainContent, req)
+	sections, err := g.generateStructure(ctx, mainContent, req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate structure: %w", err)
 	}
@@ -124,19 +124,19 @@ func (g *Generator) Generate(ctx context.Context, req GenerationRequest) (*Gener
 	log.Printf("[AI GENERATOR V2] Structure generated: %d sections", len(sections))
 	
 	// Step 3: Generate title and hero copy
-	title, heroCopy, err := g.generateTitleAndHero(req, mainContent)
+	title, heroCopy, err := g.generateTitleAndHero(ctx, req, mainContent)
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate title and hero: %w", err)
 	}
 	
 	// Step 4: Generate CTA (PHASE 1.4: Content Composer)
-	cta, err := g.generateCTA(req)
+	cta, err := g.generateCTA(ctx, req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate CTA: %w", err)
 	}
 	
 	// Step 5: Generate microcopy (PHASE 1.4: Content Composer)
-	microcopy, err := g.generateMicrocopy(req, mainContent, sections)
+	microcopy, err := g.generateMicrocopy(ctx, req, mainContent, sections)
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate microcopy: %w", err)
 	}
@@ -172,6 +172,7 @@ func (g *Generator) Generate(ctx context.Context, req GenerationRequest) (*Gener
 			ContentType: req.PageType,
 			WordCount:   wordCount,
 			ReadingTime: readingTime,
+			PromptVersion: promptRef,
 		},
 	}
 	
@@ -211,10 +212,14 @@ func (g *Generator) Generate(ctx context.Context, req GenerationRequest) (*Gener
 // generateMainNarrative generates the main long-form content
 // PHASE 1.3: Generate isi panjang (artikel / copy)
 // NO truncate, NO "kalau gagal, potong"
-func (g *Generator) generateMainNarrative(req GenerationRequest) (string, error) {
+func (g *Generator) generateMainNarrative(ctx context.Context, req GenerationRequest) (string, string, error) {
 	log.Println("[AI GENERATOR V2] Generating main narrative...")
 	
-	basePrompt := g.buildNarrativePrompt(req)
+	basePrompt, promptRef, err := g.buildNarrativePrompt(req)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to build prompt: %w", err)
+	}
+	log.Printf("[AI GENERATOR V2] Narrative prompt: %s", promptRef)
 
 	// PHASE 1.3 LAUNCH MODE HARD REQUIREMENT:
 	// Content MUST be >= 800 words. Retry a few times with stronger instruction.
@@ -249,9 +254,9 @@ DRAFT:
 
 		// Call AI API
 		// Keep within common 8k context models (prompt + completion).
-		content, err := g.callAI(prompt, 6000)
+		content, err := g.callAI(ctx, prompt, 6000)
 		if err != nil {
-			return "", fmt.Errorf("AI API call failed: %w", err)
+			return "", "", fmt.Errorf("AI API call failed: %w", err)
 		}
 
 		draft = content
@@ -259,16 +264,16 @@ DRAFT:
 		log.Printf("[AI GENERATOR V2] Main narrative generated: attempt=%d, chars=%d, words=%d", attempt, len(content), lastWordCount)
 
 		if lastWordCount >= minWords {
-			return content, nil
+			return content, promptRef, nil
 		}
 	}
 
-	return "", fmt.Errorf("generated content too short: %d words (<%d) after %d attempts", lastWordCount, minWords, maxAttempts)
+	return "", "", fmt.Errorf("generated content too short: %d words (<%d) after %d attempts", lastWordCount, minWords, maxAttempts)
 }
 
 // generateStructure breaks content into sections with headings
 // PHASE 1.3: Generate struktur logika
-func (g *Generator) generateStructure(mainContent string, req GenerationRequest) ([]ContentSection, error) {
+func (g *Generator) generateStructure(ctx context.Context, mainContent string, req GenerationRequest) ([]ContentSection, error) {
 	log.Println("[AI GENERATOR V2] Generating structure...")
 	
 	prompt := fmt.Sprintf(`Break down the following content into logical sections with headings.
@@ -286,11 +291,17 @@ Requirements:
 Topic: %s
 Page Type: %s`, mainContent, req.Topic, req.PageType)
 	
-	// Call AI API
-	response, err := g.callAI(prompt, 2000)
+	// Call AI API (JSON schema output when the provider supports it)
+	var structured struct {
+		Sections []ContentSection `json:"sections"`
+	}
+	response, ok, err := g.callStructured(ctx, prompt, 2000, structureFormat, &structured)
 	if err != nil {
 		return nil, fmt.Errorf("AI API call failed: %w", err)
 	}
+	if ok {
+		return structured.Sections, nil
+	}
 	
 	// Parse JSON response
 	var sections []ContentSection
@@ -304,7 +315,7 @@ Page Type: %s`, mainContent, req.Topic, req.PageType)
 }
 
 // generateTitleAndHero generates title and hero copy
-func (g *Generator) generateTitleAndHero(req GenerationRequest, mainContent string) (string, string, error) {
+func (g *Generator) generateTitleAndHero(ctx context.Context, req GenerationRequest, mainContent string) (string, string, error) {
 	log.Println("[AI GENERATOR V2] Generating title and hero...")
 	
 	prompt := fmt.Sprintf(`Generate a title and hero copy for the following content.
@@ -320,16 +331,19 @@ Requirements:
 
 Return as JSON: {"title": "...", "heroCopy": "..."}`, mainContent, req.Language, req.Topic)
 	
-	response, err := g.callAI(prompt, 500)
+	var result struct {
+		Title    string `json:"title"`
+		HeroCopy string `json:"heroCopy"`
+	}
+	response, ok, err := g.callStructured(ctx, prompt, 500, titleHeroFormat, &result)
 	if err != nil {
 		return "", "", fmt.Errorf("AI API call failed: %w", err)
 	}
+	if ok {
+		return result.Title, result.HeroCopy, nil
+	}
 	
 	// Parse JSON
-	var result struct {
-		Title    string `json:"title"`
-		HeroCopy string `json:"heroCopy"`
-	}
 	if err := json.Unmarshal([]byte(response), &result); err != nil {
 		// Fallback: create simple title and hero
 		title := req.Topic
@@ -345,7 +359,7 @@ Return as JSON: {"title": "...", "heroCopy": "..."}`, mainContent, req.Language,
 
 // generateCTA generates call-to-action
 // PHASE 1.4: Content Composer - AI melengkapi dirinya sendiri
-func (g *Generator) generateCTA(req GenerationRequest) (CTAInfo, error) {
+func (g *Generator) generateCTA(ctx context.Context, req GenerationRequest) (CTAInfo, error) {
 	log.Println("[AI GENERATOR V2] Generating CTA...")
 	
 	// Determine CTA based on page type
@@ -382,7 +396,7 @@ func (g *Generator) generateCTA(req GenerationRequest) (CTAInfo, error) {
 
 // generateMicrocopy generates supporting microcopy
 // PHASE 1.4: Content Composer - AI melengkapi dirinya sendiri
-func (g *Generator) generateMicrocopy(req GenerationRequest, mainContent string, sections []ContentSection) (MicrocopyInfo, error) {
+func (g *Generator) generateMicrocopy(ctx context.Context, req GenerationRequest, mainContent string, sections []ContentSection) (MicrocopyInfo, error) {
 	log.Println("[AI GENERATOR V2] Generating microcopy...")
 	
 	wordCount := countWords(mainContent)
@@ -414,159 +428,50 @@ func (g *Generator) determineTone(req GenerationRequest) ToneInfo {
 	return tone
 }
 
-// buildNarrativePrompt builds the prompt for main narrative generation
+// buildNarrativePrompt renders the main narrative prompt (v2.narrative template, see internal/ai/prompts)
 // PHASE 7A: Brand-aware prompt generation
 // PHASE 7B: Language-aware prompt generation
-// B1. System Prompt (DIKUNCI) - Tambahkan constraint eksplisit
-func (g *Generator) buildNarrativePrompt(req GenerationRequest) string {
-	var prompt strings.Builder
-	
-	// B1. System Prompt Constraint
-	prompt.WriteString("You are answering real human questions for search results.\n")
-	prompt.WriteString("Answer directly, clearly, and naturally.\n")
-	prompt.WriteString("Avoid filler phrases, marketing language, or academic tone.\n")
-	prompt.WriteString("Each answer must stand alone.\n\n")
-	prompt.WriteString("❌ Jangan sebut:\n")
-	prompt.WriteString("- \"Artikel ini akan membahas…\"\n")
-	prompt.WriteString("- \"Di era modern…\"\n")
-	prompt.WriteString("- \"Penting untuk diketahui…\"\n\n")
-	
-	prompt.WriteString(fmt.Sprintf("Generate comprehensive, long-form content about: %s\n\n", req.Topic))
-	prompt.WriteString(fmt.Sprintf("Page Type: %s\n", req.PageType))
-	
-	// PHASE 7B: Use locale context instead of deprecated Language field
-	if req.LocaleContext != nil {
-		prompt.WriteString(fmt.Sprintf("Language: %s (%s)\n", req.LocaleContext.LanguageName, req.LocaleContext.LocaleCode))
-		prompt.WriteString(fmt.Sprintf("Locale: %s\n", req.LocaleContext.LocaleCode))
-	} else {
-		// Fallback to deprecated Language field
-		prompt.WriteString(fmt.Sprintf("Language: %s\n", req.Language))
-	}
-	
-	prompt.WriteString(fmt.Sprintf("Target Audience: %s\n", req.TargetAudience))
-	
-	// PHASE 7A: Include brand context in prompt
-	if req.BrandContext != nil {
-		prompt.WriteString(fmt.Sprintf("\nBrand Context:\n"))
-		prompt.WriteString(fmt.Sprintf("- Brand Name: %s\n", req.BrandContext.BrandName))
-		prompt.WriteString(fmt.Sprintf("- Brand Identity: Write content that reflects %s's brand values and tone\n", req.BrandContext.BrandName))
-		prompt.WriteString(fmt.Sprintf("- Brand Voice: Use language and style consistent with %s brand identity\n", req.BrandContext.BrandName))
-		prompt.WriteString("- IMPORTANT: This content is exclusive to this brand. Do not reference or reuse content from other brands.\n")
-	}
-	
-	// PHASE 7B: Include locale context in prompt
-	if req.LocaleContext != nil {
-		prompt.WriteString(fmt.Sprintf("\nLocale Context:\n"))
-		prompt.WriteString(fmt.Sprintf("- Language: %s (%s)\n", req.LocaleContext.LanguageName, req.LocaleContext.LocaleCode))
-		prompt.WriteString(fmt.Sprintf("- Write in native %s, NOT translated content\n", req.LocaleContext.LanguageName))
-		prompt.WriteString(fmt.Sprintf("- Use natural %s expressions, idioms, and cultural context\n", req.LocaleContext.LanguageName))
-		prompt.WriteString("- IMPORTANT: Generate original content in this language. Do NOT translate from other languages.\n")
-		prompt.WriteString("- CRITICAL: This is a NEW VERSION for this locale. Do not reuse content from other locales.\n")
-	}
-	
-	if req.Outline != "" {
-		prompt.WriteString(fmt.Sprintf("\nFollow this outline:\n%s\n\n", req.Outline))
+// Returns the prompt and the template ref recorded in MetadataInfo.PromptVersion
+func (g *Generator) buildNarrativePrompt(req GenerationRequest) (string, string, error) {
+	vars := prompts.Vars{
+		Keyword:        req.Topic,
+		Topic:          req.Topic,
+		PageType:       req.PageType,
+		Language:       req.Language,
+		TargetAudience: req.TargetAudience,
+		Outline:        req.Outline,
 	}
-	
-	prompt.WriteString("\nRequirements:\n")
-	prompt.WriteString("- Generate comprehensive, detailed content (800-1500 words)\n")
-	prompt.WriteString("- Use clear, engaging language\n")
-	prompt.WriteString("- Include relevant information and examples\n")
-	prompt.WriteString("- Structure content logically with headings\n")
-	prompt.WriteString("- NO promotional language, NO sales CTA in body\n")
-	prompt.WriteString("- NO keyword stuffing\n")
-	prompt.WriteString("- Write naturally and informatively\n")
-	
-	// PHASE 7A: Brand isolation requirement
 	if req.BrandContext != nil {
-		prompt.WriteString("- CRITICAL: This content is for brand " + req.BrandContext.BrandName + " only. Do not mix or reference other brands.\n")
+		vars.Brand = &prompts.Brand{ID: req.BrandContext.BrandID, Name: req.BrandContext.BrandName, Slug: req.BrandContext.BrandSlug}
 	}
-	
-	// PHASE 7B: Locale isolation requirement
 	if req.LocaleContext != nil {
-		prompt.WriteString(fmt.Sprintf("- CRITICAL: This content is for locale %s (%s) only. Do not translate or reuse content from other locales.\n", 
-			req.LocaleContext.LocaleCode, req.LocaleContext.LanguageName))
+		vars.Locale = &prompts.Locale{Code: req.LocaleContext.LocaleCode, LanguageName: req.LocaleContext.LanguageName}
 	}
-	
-	return prompt.String()
+	return prompts.Render(prompts.V2Narrative, vars)
 }
 
 // callAI calls the AI API
 // PHASE 1.3: NO SEO, NO VALIDATOR - Pure AI generation
-func (g *Generator) callAI(prompt string, maxTokens int) (string, error) {
+func (g *Generator) callAI(ctx context.Context, prompt string, maxTokens int) (string, error) {
 	log.Printf("[AI GENERATOR V2] Calling AI API: prompt length=%d, maxTokens=%d", len(prompt), maxTokens)
 	
-	// Build request payload (OpenAI Chat Completions format)
 	// A2. Parameter Stabil (ANTI OVER-GENERATE)
 	// temperature=0.4, top_p=0.85, presence_penalty=0.1, frequency_penalty=0.2
-	payload := map[string]interface{}{
-		"model": g.model,
-		"messages": []map[string]string{
-			{
-				"role":    "user",
-				"content": prompt,
-			},
-		},
-		"max_tokens":        maxTokens,
-		"temperature":       0.4,  // A2: Konservatif & stabil
-		"top_p":            0.85, // A2: Stabil (hindari over-creativity)
-		"presence_penalty": 0.1,  // A2: Minim repetisi
-		"frequency_penalty": 0.2,  // A2: Tidak "puitis AI"
-	}
-	
-	jsonData, err := json.Marshal(payload)
-	if err != nil {
-		return "", fmt.Errorf("failed to marshal request: %w", err)
-	}
-	
-	req, err := http.NewRequest("POST", g.apiURL, strings.NewReader(string(jsonData)))
-	if err != nil {
-		return "", fmt.Errorf("failed to create request: %w", err)
-	}
-	
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", "Bearer "+g.apiKey)
-	
-	resp, err := g.client.Do(req)
+	resp, err := g.llm.Complete(ctx, llm.Request{
+		Prompt:           prompt,
+		MaxTokens:        maxTokens,
+		Temperature:      llm.Float(0.4),  // A2: Konservatif & stabil
+		TopP:             llm.Float(0.85), // A2: Stabil (hindari over-creativity)
+		PresencePenalty:  llm.Float(0.1),  // A2: Minim repetisi
+		FrequencyPenalty: llm.Float(0.2),  // A2: Tidak "puitis AI"
+	})
 	if err != nil {
 		return "", fmt.Errorf("API request failed: %w", err)
 	}
-	defer resp.Body.Close()
-	
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return "", fmt.Errorf("API returned status %d: %s", resp.StatusCode, string(body))
-	}
-	
-	// Parse response
-	var apiResponse struct {
-		Choices []struct {
-			Message struct {
-				Content string `json:"content"`
-			} `json:"message"`
-		} `json:"choices"`
-	}
-	
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return "", fmt.Errorf("failed to read response: %w", err)
-	}
-	
-	if err := json.Unmarshal(body, &apiResponse); err != nil {
-		// If parsing fails, try to extract content directly
-		log.Printf("[AI GENERATOR V2] JSON parsing failed, trying direct extraction: %v", err)
-		// Fallback: return raw body
-		return string(body), nil
-	}
-	
-	if len(apiResponse.Choices) == 0 {
-		return "", fmt.Errorf("no choices in API response")
-	}
 	
-	content := apiResponse.Choices[0].Message.Content
-	log.Printf("[AI GENERATOR V2] API response received: %d chars", len(content))
+	log.Printf("[AI GENERATOR V2] API response received: %d chars (model=%s, attempts=%d)", len(resp.Text), resp.Model, resp.Attempts)
 	
-	return content, nil
+	return resp.Text, nil
 }
 
 // createDefaultStructure creates a default structure if AI parsing fails
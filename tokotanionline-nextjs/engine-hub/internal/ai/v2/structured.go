@@ -0,0 +1,66 @@
+package v2
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+
+	"engine-hub/internal/ai/llm"
+)
+
+// JSON schemas for the v2 steps that ask for JSON (structure, title + hero)
+// Providers with structured output enforce them; others answer text that the old parser handles
+
+var structureFormat = &llm.ResponseFormat{
+	Name: "v2_structure",
+	Schema: llm.Object("Content split into logical sections", map[string]*llm.Schema{
+		"sections": llm.Array("Sections in reading order", llm.Object("", map[string]*llm.Schema{
+			"heading":      llm.String("Section heading without # characters"),
+			"headingLevel": llm.Integer("2 = H2, 3 = H3", 2, 3),
+			"body":         llm.String("Section body in markdown"),
+			"order":        llm.Integer("Display order, starting at 1"),
+		}), 2, 10),
+	}),
+}
+
+var titleHeroFormat = &llm.ResponseFormat{
+	Name: "v2_title_hero",
+	Schema: llm.Object("Title and hero copy", map[string]*llm.Schema{
+		"title":    llm.String("Title, 10-100 characters"),
+		"heroCopy": llm.String("Hero copy, 50-300 characters"),
+	}),
+}
+
+// callStructured asks for JSON matching format and decodes it into out
+// Returns structured=false with the raw text when the answer is not schema-checked JSON
+// (no JSON mode on the provider, or still invalid after the repair calls): caller parses the text
+func (g *Generator) callStructured(ctx context.Context, prompt string, maxTokens int, format *llm.ResponseFormat, out interface{}) (string, bool, error) {
+	if !g.llm.SupportsStructured() {
+		text, err := g.callAI(ctx, prompt, maxTokens)
+		return text, false, err
+	}
+
+	log.Printf("[AI GENERATOR V2] Calling AI API: prompt length=%d, maxTokens=%d, format=%s", len(prompt), maxTokens, format.Name)
+	resp, err := g.llm.CompleteStructured(ctx, llm.Request{
+		Prompt:           prompt,
+		MaxTokens:        maxTokens,
+		Temperature:      llm.Float(0.4),  // A2: Konservatif & stabil
+		TopP:             llm.Float(0.85), // A2: Stabil (hindari over-creativity)
+		PresencePenalty:  llm.Float(0.1),  // A2: Minim repetisi
+		FrequencyPenalty: llm.Float(0.2),  // A2: Tidak "puitis AI"
+	}, format, out)
+
+	var invalid *llm.StructuredError
+	switch {
+	case err == nil:
+		log.Printf("[AI GENERATOR V2] Structured %s received (model=%s, attempts=%d)", format.Name, resp.Model, resp.Attempts)
+		return resp.Text, true, nil
+	case errors.Is(err, llm.ErrStructuredUnsupported) && resp != nil:
+		return resp.Text, false, nil
+	case errors.As(err, &invalid):
+		log.Printf("[AI GENERATOR V2] %v", invalid)
+		return invalid.Text, false, nil
+	}
+	return "", false, fmt.Errorf("API request failed: %w", err)
+}
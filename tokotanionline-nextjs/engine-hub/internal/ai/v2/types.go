@@ -63,6 +63,7 @@ type MetadataInfo struct {
 	ContentType string `json:"contentType"` // Content type identifier
 	WordCount   int    `json:"wordCount"`   // Actual word count
 	ReadingTime int    `json:"readingTime"` // Estimated reading time in minutes
+	PromptVersion string `json:"promptVersion,omitempty"` // Narrative prompt template ref, e.g. v2.narrative@v2
 }
 
 // BrandContext represents brand information for content generation
@@ -7,6 +7,7 @@ import (
 	"strings"
 	"unicode"
 
+	"engine-hub/internal/ai/compliance"
 	"engine-hub/internal/ai/content"
 )
 
@@ -26,6 +27,11 @@ func (e *ValidationError) Error() string {
 // Validator HANYA BOLEH jawab: ✅ LULUS atau ❌ GAGAL + ALASAN STRUKTURAL
 // Returns error if validation fails - this will STOP TOTAL workflow
 func ValidateContent(input content.ContentResult) error {
+	return ValidateContentFor(input, compliance.DefaultScope())
+}
+
+// ValidateContentFor validates with the WARN/REJECT rules of the brand/locale compliance packs
+func ValidateContentFor(input content.ContentResult, scope compliance.Scope) error {
 	// === D1-D2: LOG & ASSERT DI VALIDATOR INPUT (WAJIB) ===
 	body := input.Body
 	fullText := input.Title + " " + body
@@ -68,17 +74,12 @@ func ValidateContent(input content.ContentResult) error {
 		return err
 	}
 
-	// Rule 2: Prohibited words (pasti, terbukti, rahasia)
-	if err := checkProhibitedWords(input.Body); err != nil {
-		return err
-	}
-
-	// Rule 3: Brand name mentions
-	if err := checkBrandMentions(input.Body); err != nil {
+	// Rule 2-4: Prohibited words, brand name mentions, promotional tone (compliance packs)
+	if err := checkComplianceRules(input.Body, compliance.Resolve(scope)); err != nil {
 		return err
 	}
 
-	// Rule 4: Promotional tone
+	// Rule 4: Promotional tone (exclamation marks)
 	if err := checkPromotionalTone(input.Body); err != nil {
 		return err
 	}
@@ -134,8 +135,13 @@ func checkStructuralIntegrity(input content.ContentResult) error {
 
 // ValidateContentWithOutline validates content against outline (when available)
 func ValidateContentWithOutline(input content.ContentResult, originalOutline string) error {
+	return ValidateContentWithOutlineFor(input, originalOutline, compliance.DefaultScope())
+}
+
+// ValidateContentWithOutlineFor validates content against outline with the brand/locale compliance packs
+func ValidateContentWithOutlineFor(input content.ContentResult, originalOutline string, scope compliance.Scope) error {
 	// First run standard validation
-	if err := ValidateContent(input); err != nil {
+	if err := ValidateContentFor(input, scope); err != nil {
 		return err
 	}
 
@@ -215,6 +221,7 @@ func removeMarkdownForWordCount(text string) string {
 	text = regexp.MustCompile("`[^`]+`").ReplaceAllString(text, "")
 	// Remove images
 	text = regexp.MustCompile(`!\[([^\]]*)\]\([^\)]+\)`).ReplaceAllString(text, "")
+	text = regexp.MustCompile(`<img[^>]*>`).ReplaceAllString(text, "")
 	return text
 }
 
@@ -257,107 +264,26 @@ func checkSalesCTA(body string) error {
 	return nil
 }
 
-// checkProhibitedWords detects prohibited words: pasti, terbukti, rahasia
-func checkProhibitedWords(body string) error {
-	// Prohibited words - AUTO FAIL
-	prohibitedWords := []struct {
-		word    string
-		message string
-	}{
-		{"pasti", "Prohibited word 'pasti' detected (promotional tone)"},
-		{"terbukti", "Prohibited word 'terbukti' detected (promotional tone)"},
-		{"rahasia", "Prohibited word 'rahasia' detected (promotional tone)"},
-		// Also check variations
-		{"pasti-pasti", "Prohibited phrase detected"},
-		{"sudah terbukti", "Prohibited phrase detected"},
-		{"rahasia sukses", "Prohibited phrase detected"},
-		{"rahasia bisnis", "Prohibited phrase detected"},
-	}
-
-	bodyLower := strings.ToLower(body)
-
-	for _, p := range prohibitedWords {
-		// Word boundary match to avoid false positives
-		pattern := `\b` + regexp.QuoteMeta(p.word) + `\b`
-		re := regexp.MustCompile(pattern)
-		if matches := re.FindAllString(bodyLower, -1); len(matches) > 0 {
-			firstMatch := matches[0]
-			context := extractContext(body, firstMatch, 50)
-			return &ValidationError{
-				Rule:    "KATA_TERLARANG",
-				Message: p.message,
-				Content: context,
-			}
+// checkComplianceRules applies the WARN/REJECT rules of the compliance packs
+// (prohibited words → KATA_TERLARANG, brand mentions → NAMA_MEREK, promotional tone → NADA_PROMOSI)
+// WARN is logged only; the first REJECT fails validation
+func checkComplianceRules(body string, rules *compliance.RuleSet) error {
+	for _, f := range rules.Check(body) {
+		if f.Severity != compliance.SeverityReject {
+			log.Printf("[VALIDATION] WARN [%s] rule=%s pack=%s: %s - Found: %q", f.Code, f.RuleID, f.Pack, f.Message, f.Context)
+			continue
 		}
-	}
-
-	return nil
-}
-
-// checkBrandMentions detects brand name mentions (should not be in content)
-func checkBrandMentions(body string) error {
-	// Common brand patterns (case-insensitive)
-	brandPatterns := []struct {
-		pattern string
-		message string
-	}{
-		{`(?i)\btoko tani online\b`, "Brand name 'Toko Tani Online' detected in content"},
-		{`(?i)\btt[oi]\b`, "Brand abbreviation detected"},
-		// Add more brand-specific patterns as needed
-	}
-
-	bodyText := body
-
-	for _, pattern := range brandPatterns {
-		re := regexp.MustCompile(pattern.pattern)
-		if matches := re.FindAllString(bodyText, -1); len(matches) > 0 {
-			firstMatch := matches[0]
-			context := extractContext(body, firstMatch, 50)
-			return &ValidationError{
-				Rule:    "NAMA_MEREK",
-				Message: pattern.message,
-				Content: context,
-			}
+		return &ValidationError{
+			Rule:    f.Code,
+			Message: f.Message,
+			Content: f.Context,
 		}
 	}
-
 	return nil
 }
 
-// checkPromotionalTone detects promotional/marketing language
+// checkPromotionalTone detects promotional style that is not a wording rule
 func checkPromotionalTone(body string) error {
-	// Promotional phrases - AUTO FAIL
-	promotionalPatterns := []struct {
-		pattern string
-		message string
-	}{
-		{`(?i)(solusi terbaik|best solution|paling terbaik)`, "Promotional superlative detected"},
-		{`(?i)(hanya di|only at|exclusive)`, "Exclusive promotional language detected"},
-		{`(?i)(limited time|waktu terbatas|tawaran terbatas)`, "Limited-time promotion detected"},
-		{`(?i)(garansi uang kembali|money back guarantee|jaminan uang kembali)`, "Guarantee marketing detected"},
-		{`(?i)(testimoni|review positif|rating tinggi|bintang 5)`, "Social proof marketing detected"},
-		{`(?i)(harga murah|harga terjangkau|harga terbaik)`, "Price promotion detected"},
-		{`(?i)(diskon besar|big discount|potongan besar)`, "Discount promotion detected"},
-		{`(?i)(stok terbatas|limited stock|hanya tersisa)`, "Scarcity marketing detected"},
-		{`(?i)(wajib punya|must have|harus punya)`, "Urgency marketing detected"},
-		{`(?i)(berlaku sampai|valid until|promo berlaku)`, "Time-limited promotion detected"},
-	}
-
-	bodyLower := strings.ToLower(body)
-
-	for _, pattern := range promotionalPatterns {
-		re := regexp.MustCompile(pattern.pattern)
-		if matches := re.FindAllString(bodyLower, -1); len(matches) > 0 {
-			firstMatch := matches[0]
-			context := extractContext(body, firstMatch, 50)
-			return &ValidationError{
-				Rule:    "NADA_PROMOSI",
-				Message: pattern.message,
-				Content: context,
-			}
-		}
-	}
-
 	// Check for excessive exclamation marks (promotional style)
 	exclamationCount := strings.Count(body, "!")
 	if exclamationCount > 3 {
@@ -1,11 +1,13 @@
 package workflow
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"strings"
 	"unicode"
 
+	"engine-hub/internal/ai/compliance"
 	"engine-hub/internal/ai/content"
 	aiError "engine-hub/internal/ai/error"
 	"engine-hub/internal/ai/image"
@@ -20,6 +22,7 @@ type StepResult struct {
 	Step  string `json:"step"`  // "text", "seo", "image"
 	Ok    bool   `json:"ok"`     // true if step succeeded
 	Error string `json:"error,omitempty"` // error message if step failed
+	Quality *image.QualityReport `json:"quality,omitempty"` // image step: quality gate (rejected/retried/abandoned images)
 }
 
 // DraftAI represents the final output of the AI content pipeline
@@ -30,10 +33,22 @@ type DraftAI struct {
 	Steps   []StepResult          `json:"steps,omitempty"` // Step-by-step results
 }
 
+// Progress is a snapshot of a running pipeline (state machine + steps so far)
+type Progress struct {
+	State      state.State      `json:"state"`
+	StatusCode state.StatusCode `json:"statusCode,omitempty"`
+	Steps      []StepResult     `json:"steps"`
+	Percent    int              `json:"percent"` // 0-100, by milestone (text ~45, images ~85, store 100)
+}
+
+// ProgressFunc receives progress after every state transition and step (called synchronously)
+type ProgressFunc func(Progress)
+
 // Pipeline orchestrates the AI content generation workflow
 type Pipeline struct {
 	contentGen *content.Generator
 	imageGen   *image.Generator
+	onProgress ProgressFunc
 }
 
 // NewPipeline creates a new workflow pipeline
@@ -45,11 +60,31 @@ func NewPipeline() *Pipeline {
 	}
 }
 
+// OnProgress registers a callback for progress reporting (async generation status)
+func (p *Pipeline) OnProgress(fn ProgressFunc) {
+	p.onProgress = fn
+}
+
+// report sends a progress snapshot to the registered callback
+func (p *Pipeline) report(sm *state.StateMachine, steps []StepResult, percent int) {
+	if p.onProgress == nil {
+		return
+	}
+	snapshot := make([]StepResult, len(steps))
+	copy(snapshot, steps)
+	p.onProgress(Progress{
+		State:      sm.GetCurrentState(),
+		StatusCode: sm.GetStatusCode(),
+		Steps:      snapshot,
+		Percent:    percent,
+	})
+}
+
 // Execute runs the complete pipeline:
 // KONTRAK FINAL: State machine wajib, tidak ada shortcut
 // INIT → GENERATE_RAW → NORMALIZE → VALIDATE → STORE (DRAFT_READY)
 // PARTIAL-SAFE: Image failures don't stop pipeline, tracked in Steps
-func (p *Pipeline) Execute(req content.ContentRequest) (*DraftAI, error) {
+func (p *Pipeline) Execute(ctx context.Context, req content.ContentRequest) (*DraftAI, error) {
 	log.Println("[AI PIPELINE] Starting content generation workflow")
 
 	// === E1: DEKLARASI FINAL_CONTENT DI AWAL (STANDARISASI SATU VARIABEL) ===
@@ -64,10 +99,11 @@ func (p *Pipeline) Execute(req content.ContentRequest) (*DraftAI, error) {
 	if err := stateMachine.Transition(state.StateGenerateRaw); err != nil {
 		return nil, fmt.Errorf("state machine initialization failed: %w", err)
 	}
+	p.report(stateMachine, steps, 5)
 
 	// STEP 1: Generate raw AI content (TEXT GENERATION)
 	log.Println("[AI PIPELINE] STEP 1: Generating raw AI content...")
-	rawContent, err := p.contentGen.Generate(req)
+	rawContent, err := p.contentGen.Generate(ctx, req)
 	if err != nil {
 		// KONTRAK FINAL: Classify failure
 		classifiedErr := aiError.ClassifyFailure(err)
@@ -84,12 +120,14 @@ func (p *Pipeline) Execute(req content.ContentRequest) (*DraftAI, error) {
 		if !aiError.IsRetryable(err) {
 			stateMachine.Transition(state.StateQuarantine)
 		}
+		p.report(stateMachine, steps, 100)
 		
 		return nil, fmt.Errorf("text_generation failed: %w", classifiedErr)
 	}
 
 	log.Printf("[AI PIPELINE] STEP 1 COMPLETE: Raw content generated (Status: %s)", rawContent.Status)
 	steps = append(steps, StepResult{Step: "text", Ok: true})
+	p.report(stateMachine, steps, 45)
 
 	// === C1: KUNCI JALUR DATA GPT (SET LANGSUNG SETELAH PARSING) ===
 	contentFromGPT := rawContent.Body // hasil parsing GPT
@@ -106,8 +144,10 @@ func (p *Pipeline) Execute(req content.ContentRequest) (*DraftAI, error) {
 	}
 	
 	log.Println("[AI PIPELINE] STEP 1.5: Normalizing content (enforcing compliance rules)...")
-	normalizedContent := normalize.NormalizeContent(*rawContent)
+	complianceScope := compliance.Scope{Brand: req.Brand, Locale: req.Language}
+	normalizedContent := normalize.NormalizeContentFor(*rawContent, complianceScope)
 	log.Printf("[AI PIPELINE] STEP 1.5 COMPLETE: Content normalized (Status: %s)", normalizedContent.Status)
+	p.report(stateMachine, steps, 50)
 	
 	// === E1: SET FINAL_CONTENT SETELAH NORMALIZE ===
 	FINAL_CONTENT = normalizedContent.Body
@@ -139,6 +179,7 @@ func (p *Pipeline) Execute(req content.ContentRequest) (*DraftAI, error) {
 
 	log.Printf("[AI PIPELINE] STEP 2 COMPLETE: SEO optimized (Status: %s)", seoContent.Status)
 	steps = append(steps, StepResult{Step: "seo", Ok: true})
+	p.report(stateMachine, steps, 60)
 	
 	// === E2: SEMUA KOMPONEN WAJIB PAKAI FINAL_CONTENT ===
 	// Pastikan seoContent.Body menggunakan FINAL_CONTENT
@@ -155,7 +196,7 @@ func (p *Pipeline) Execute(req content.ContentRequest) (*DraftAI, error) {
 	log.Printf("[AI PIPELINE] Generated article slug: %s", articleSlug)
 	
 	// FASE C - C3: Execute image generation flow (all steps inside GenerateImages)
-	images, err := p.imageGen.GenerateImages(seoContent.Body, articleSlug)
+	images, qualityReport, err := p.imageGen.GenerateImagesWithReport(ctx, seoContent.Body, articleSlug)
 	if err != nil {
 		log.Printf("[AI PIPELINE] WARNING: Image generation failed: %v (continuing without images)", err)
 		images = []image.ImageAsset{} // Continue without images
@@ -164,10 +205,12 @@ func (p *Pipeline) Execute(req content.ContentRequest) (*DraftAI, error) {
 			Step:  "image",
 			Ok:    false,
 			Error: err.Error(),
+			Quality: qualityReport,
 		})
 	} else {
 		log.Printf("[AI PIPELINE] STEP 3 COMPLETE: Generated %d images", len(images))
-		steps = append(steps, StepResult{Step: "image", Ok: true})
+		// Sections abandoned by the quality gate are a warning only (article continues with fewer images)
+		steps = append(steps, StepResult{Step: "image", Ok: true, Error: qualityReport.Summary(), Quality: qualityReport})
 		
 		// FASE C - C3: Step 6 - Relasikan ke artikel (inject image references)
 		if len(images) > 0 {
@@ -183,6 +226,7 @@ func (p *Pipeline) Execute(req content.ContentRequest) (*DraftAI, error) {
 	if err := stateMachine.Transition(state.StateValidate); err != nil {
 		return nil, fmt.Errorf("state transition failed: %w", err)
 	}
+	p.report(stateMachine, steps, 85)
 	
 	// BAGIAN D2: Log pre-validation (WAJIB - sebelum validator jalan)
 	fullText := seoContent.Title + " " + seoContent.Body
@@ -201,7 +245,7 @@ func (p *Pipeline) Execute(req content.ContentRequest) (*DraftAI, error) {
 	
 	// PHASE 0.2: VALIDATOR = WARNING ONLY (tidak memblokir pipeline)
 	log.Println("[AI PIPELINE] STEP 4: Validating content...")
-	if err := validate.ValidateContent(seoContent); err != nil {
+	if err := validate.ValidateContentFor(seoContent, complianceScope); err != nil {
 		// PHASE 0.2: Validator hanya warning, tidak memblokir pipeline
 		// KONTRAK FINAL: Classify failure untuk logging
 		classifiedErr := aiError.ClassifyFailure(err)
@@ -220,7 +264,7 @@ func (p *Pipeline) Execute(req content.ContentRequest) (*DraftAI, error) {
 
 	// Also validate against outline if provided
 	if req.Outline != "" {
-		if err := validate.ValidateContentWithOutline(seoContent, req.Outline); err != nil {
+		if err := validate.ValidateContentWithOutlineFor(seoContent, req.Outline, complianceScope); err != nil {
 			// PHASE 0.2: Validator hanya warning, tidak memblokir pipeline
 			// KONTRAK FINAL: Classify failure untuk logging
 			classifiedErr := aiError.ClassifyFailure(err)
@@ -239,6 +283,7 @@ func (p *Pipeline) Execute(req content.ContentRequest) (*DraftAI, error) {
 	}
 
 	log.Println("[AI PIPELINE] STEP 4 COMPLETE: Content validated successfully")
+	p.report(stateMachine, steps, 95)
 
 	// STEP 5: Create final DraftAI output
 	// KONTRAK FINAL: Transition to STORE state (DRAFT_READY)
@@ -258,6 +303,7 @@ func (p *Pipeline) Execute(req content.ContentRequest) (*DraftAI, error) {
 	draft.Content.Status = string(stateMachine.GetStatusCode())
 
 	log.Printf("[AI PIPELINE] STEP 5 COMPLETE: Draft created (Status: %s)", draft.Status)
+	p.report(stateMachine, steps, 100)
 	log.Printf("[AI PIPELINE] State machine final state: %s", stateMachine.GetCurrentState())
 	log.Printf("[AI PIPELINE] Steps completed: %d/%d successful", countSuccessfulSteps(steps), len(steps))
 	log.Println("[AI PIPELINE] Workflow completed successfully")
@@ -281,7 +327,7 @@ func countSuccessfulSteps(steps []StepResult) int {
 // - Retry HARUS dengan prompt yang sama (req.Outline tidak berubah)
 // - Jika 2x gagal: keyword → fallback pool, dicatat sebagai content_failed
 // ⛔ DILARANG: infinite retry, retry tanpa catatan, ganti outline diam-diam
-func (p *Pipeline) ExecuteWithRetry(req content.ContentRequest, maxRetries int) (*DraftAI, error) {
+func (p *Pipeline) ExecuteWithRetry(ctx context.Context, req content.ContentRequest, maxRetries int) (*DraftAI, error) {
 	var lastErr error
 	var lastNonRetryableErr error
 	var attempt int
@@ -302,7 +348,7 @@ func (p *Pipeline) ExecuteWithRetry(req content.ContentRequest, maxRetries int)
 			req.Outline = originalOutline
 		}
 
-		draft, err := p.Execute(req)
+		draft, err := p.Execute(ctx, req)
 		if err == nil {
 			return draft, nil
 		}
@@ -374,7 +420,7 @@ func contains(s, substr string) bool {
 	return strings.Contains(strings.ToLower(s), strings.ToLower(substr))
 }
 
-// injectImagesIntoContent injects image markdown references into content
+// injectImagesIntoContent injects image references into content (markdown or srcset <img>, see ImageAsset.ContentReference)
 // Images are placed after their corresponding section headings
 func (p *Pipeline) injectImagesIntoContent(body string, images []image.ImageAsset) string {
 	if len(images) == 0 {
@@ -412,8 +458,8 @@ func (p *Pipeline) injectImagesIntoContent(body string, images []image.ImageAsse
 					
 					// Only inject if we have a local path
 					if img.LocalPath != "" {
-						// M-04: Add section image markdown after heading
-						imageMarkdown := fmt.Sprintf("\n![%s](%s)\n", img.AltText, img.LocalPath)
+						// M-04: Add section image after heading (<img srcset> when responsive variants exist)
+						imageMarkdown := fmt.Sprintf("\n%s\n", img.ContentReference())
 						result = append(result, imageMarkdown)
 						imageIndex = imgIdx + 1
 						log.Printf("[PIPELINE] Injected section image for '%s': %s", heading, img.LocalPath)
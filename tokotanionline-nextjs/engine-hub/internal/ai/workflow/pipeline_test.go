@@ -0,0 +1,122 @@
+package workflow
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"engine-hub/internal/ai/content"
+	"engine-hub/internal/ai/fakeopenai"
+)
+
+const testOutline = `## Pengenalan Pupuk Organik
+
+Definisi, jenis dan manfaat pupuk organik untuk tanah.
+
+## Cara Membuat Pupuk Kompos
+
+Bahan, langkah pembuatan dan lama fermentasi.
+
+## Hubungan Antar Jenis (sinergi, bukan berdiri sendiri)
+
+Kompos, pupuk kandang dan pupuk hijau saling melengkapi.
+
+## Kesimpulan
+
+Ringkasan poin penting.`
+
+// startFakeAPI points every AI caller at a fake OpenAI server and keeps images in a temp dir
+// Generators read env in their constructors: create the pipeline after calling this
+func startFakeAPI(t *testing.T) *fakeopenai.Server {
+	t.Helper()
+	fake := fakeopenai.NewServer()
+	t.Cleanup(fake.Close)
+	for k, v := range fake.Env() {
+		t.Setenv(k, v)
+	}
+	t.Setenv("IMAGE_LOCAL_DIR", t.TempDir())
+	t.Setenv("IMAGE_STORAGE_DIR", "")
+	t.Setenv("AI_CASSETTE_MODE", "off")
+	return fake
+}
+
+func testRequest() content.ContentRequest {
+	return content.ContentRequest{
+		ContentType: content.ContentDerivative,
+		Category:    "K1",
+		Outline:     testOutline,
+		Language:    "id-ID",
+	}
+}
+
+func countRequests(fake *fakeopenai.Server, pathSuffix string) int {
+	n := 0
+	for _, r := range fake.Requests() {
+		if strings.HasSuffix(r.Path, pathSuffix) {
+			n++
+		}
+	}
+	return n
+}
+
+func TestExecuteAgainstFakeAPI(t *testing.T) {
+	fake := startFakeAPI(t)
+
+	draft, err := NewPipeline().Execute(context.Background(), testRequest())
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+
+	if draft.Status != "DRAFT_READY" {
+		t.Errorf("draft status = %q, want DRAFT_READY", draft.Status)
+	}
+	if draft.Content.Title == "" {
+		t.Error("draft has no title")
+	}
+	if countWordsForValidation(draft.Content.Body) == 0 {
+		t.Error("draft body is empty")
+	}
+	if len(draft.Steps) == 0 || draft.Steps[0].Step != "text" || !draft.Steps[0].Ok {
+		t.Errorf("first step = %+v, want successful text step", draft.Steps)
+	}
+	if n := countRequests(fake, "/responses"); n == 0 {
+		t.Error("fake API received no text generation request")
+	}
+	if n := countRequests(fake, "/images/generations"); n == 0 {
+		t.Error("fake API received no image generation request")
+	}
+}
+
+func TestExecuteRetriesTransientFailure(t *testing.T) {
+	fake := startFakeAPI(t)
+	t.Setenv("LLM_MAX_RETRIES", "1")
+	fake.FailNext(1, http.StatusServiceUnavailable)
+
+	draft, err := NewPipeline().Execute(context.Background(), testRequest())
+	if err != nil {
+		t.Fatalf("Execute failed after one 503: %v", err)
+	}
+	if draft.Status != "DRAFT_READY" {
+		t.Errorf("draft status = %q, want DRAFT_READY", draft.Status)
+	}
+	if n := countRequests(fake, "/responses"); n < 2 {
+		t.Errorf("text requests = %d, want the 503 and its retry", n)
+	}
+}
+
+func TestExecuteStopsOnTextFailure(t *testing.T) {
+	fake := startFakeAPI(t)
+	fake.FailNext(1, http.StatusUnauthorized)
+
+	_, err := NewPipeline().Execute(context.Background(), testRequest())
+	if err == nil {
+		t.Fatal("Execute succeeded, want text_generation error")
+	}
+	if !strings.Contains(err.Error(), "text_generation failed") {
+		t.Errorf("error = %v, want text_generation failed", err)
+	}
+	if n := countRequests(fake, "/images/generations"); n != 0 {
+		t.Errorf("image requests = %d, want none after a text failure", n)
+	}
+}
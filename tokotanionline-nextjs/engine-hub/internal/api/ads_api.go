@@ -1,7 +1,6 @@
 package api
 
 import (
-	"context"
 	"encoding/json"
 	"fmt"
 	"log"
@@ -42,6 +41,11 @@ func AdsGenerate(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// FASE D - D2: Daily AI budget
+	if rejectOverBudget(w, r) {
+		return
+	}
+
 	// Parse request body
 	var req v2.AdsGenerationRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -83,8 +87,7 @@ func AdsGenerate(w http.ResponseWriter, r *http.Request) {
 	generator := v2.NewAdsGenerator()
 
 	// Generate ads copy
-	ctx := context.Background()
-	result, err := generator.GenerateAdsCopy(ctx, req)
+	result, err := generator.GenerateAdsCopy(usageContext(r), req)
 	if err != nil {
 		log.Printf("[ADS API] Generation failed: %v", err)
 		
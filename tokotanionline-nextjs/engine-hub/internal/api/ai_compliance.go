@@ -0,0 +1,138 @@
+package api
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+
+	"engine-hub/internal/ai/compliance"
+)
+
+// ComplianceDryRunRequest is the body of POST /api/engine/ai/compliance/dry-run
+// Pack set = test an unsaved pack on top of the packs in scope (it wins every rule ID)
+type ComplianceDryRunRequest struct {
+	Text   string           `json:"text"`
+	Brand  string           `json:"brand,omitempty"`
+	Locale string           `json:"locale,omitempty"` // default id-ID
+	Pack   *compliance.Pack `json:"pack,omitempty"`
+}
+
+// AICompliancePacks handles /api/engine/ai/compliance/packs
+// GET: list loaded packs; with ?brand=&locale= the effective rules for that scope
+// POST: save a pack (insert or replace by name; 400 with the validation result if invalid)
+func AICompliancePacks(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		listCompliancePacks(w, r)
+	case http.MethodPost:
+		saveCompliancePack(w, r)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func listCompliancePacks(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	w.Header().Set("Content-Type", "application/json")
+
+	if q.Has("brand") || q.Has("locale") {
+		set := compliance.Default().Resolve(compliance.Scope{Brand: q.Get("brand"), Locale: q.Get("locale")})
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"scope": set.Scope,
+			"packs": set.Packs,
+			"rules": set.Rules(),
+		})
+		return
+	}
+
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"packs": compliance.Default().List(),
+	})
+}
+
+func saveCompliancePack(w http.ResponseWriter, r *http.Request) {
+	var pack compliance.Pack
+	if err := json.NewDecoder(r.Body).Decode(&pack); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	saved, validation, err := compliance.Default().Save(pack)
+
+	w.Header().Set("Content-Type", "application/json")
+	if !validation.Valid {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"error":      "pack is invalid",
+			"validation": validation,
+		})
+		return
+	}
+	if err != nil {
+		log.Printf("[COMPLIANCE] Failed to save pack %s: %v", pack.Name, err)
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]interface{}{"error": err.Error()})
+		return
+	}
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"pack":       saved,
+		"validation": validation,
+	})
+}
+
+// AIComplianceReload handles POST /api/engine/ai/compliance/reload (after editing COMPLIANCE_RULES_DIR files)
+func AIComplianceReload(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if err := compliance.Default().Reload(); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	packs := make(map[string]string)
+	for _, p := range compliance.Default().List() {
+		packs[p.Name] = p.Source
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status": "reloaded",
+		"packs":  packs,
+	})
+}
+
+// AIComplianceDryRun handles POST /api/engine/ai/compliance/dry-run
+// Runs the normalizer rules then the validator rules of the scope on text, without generating anything
+func AIComplianceDryRun(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req ComplianceDryRunRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Text == "" {
+		http.Error(w, "text is required", http.StatusBadRequest)
+		return
+	}
+	scope := compliance.Scope{Brand: req.Brand, Locale: req.Locale}
+
+	w.Header().Set("Content-Type", "application/json")
+	if req.Pack == nil {
+		json.NewEncoder(w).Encode(compliance.Default().Resolve(scope).DryRun(req.Text))
+		return
+	}
+
+	set, validation := compliance.Default().ResolveWith(scope, *req.Pack)
+	if !validation.Valid {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"error":      "pack is invalid",
+			"validation": validation,
+		})
+		return
+	}
+	json.NewEncoder(w).Encode(set.DryRun(req.Text))
+}
@@ -0,0 +1,152 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+
+	"engine-hub/internal/ai/experiment"
+)
+
+// ExperimentStopRequest is the body of POST /api/engine/ai/experiments/stop
+type ExperimentStopRequest struct {
+	ID      string `json:"id"`
+	Promote bool   `json:"promote,omitempty"` // activate the winning version (only if a winner was declared)
+}
+
+// AIExperiments handles /api/engine/ai/experiments
+// GET: list experiments (query: status=RUNNING|STOPPED)
+// POST: start an experiment {name, templateName, arms: [{name, version, weight}], metric, minSamples, createdBy}
+func AIExperiments(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		list, err := experiment.List()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		status := r.URL.Query().Get("status")
+		out := []experiment.Experiment{}
+		for _, e := range list {
+			if status == "" || e.Status == status {
+				out = append(out, e)
+			}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"experiments": out,
+		})
+	case http.MethodPost:
+		var req experiment.Experiment
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+		e, err := experiment.Create(req)
+		if err != nil {
+			status := http.StatusBadRequest
+			if errors.Is(err, experiment.ErrConflict) {
+				status = http.StatusConflict
+			}
+			log.Printf("[EXPERIMENT] Failed to start experiment on %s: %v", req.TemplateName, err)
+			http.Error(w, err.Error(), status)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(e)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// AIExperimentReport handles GET /api/engine/ai/experiments/report?id=
+// Per-arm quality metrics, pass rates, SEO score and user signals with 95% confidence intervals
+func AIExperimentReport(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	id := r.URL.Query().Get("id")
+	if id == "" {
+		http.Error(w, "id is required", http.StatusBadRequest)
+		return
+	}
+	e, err := experiment.Get(id)
+	if err != nil {
+		writeExperimentError(w, err)
+		return
+	}
+	report, err := experiment.BuildReport(e)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+}
+
+// AIExperimentStop handles POST /api/engine/ai/experiments/stop
+func AIExperimentStop(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req ExperimentStopRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.ID == "" {
+		http.Error(w, "id is required", http.StatusBadRequest)
+		return
+	}
+	e, report, err := experiment.Stop(req.ID, req.Promote)
+	if err != nil && report == nil {
+		writeExperimentError(w, err)
+		return
+	}
+
+	resp := map[string]interface{}{
+		"experiment": e,
+		"report":     report,
+		"promoted":   req.Promote && e.Winner != "" && err == nil,
+	}
+	if err != nil {
+		resp["error"] = err.Error()
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+// AIExperimentOutcome handles POST /api/engine/ai/experiments/outcome
+// Body: {exposureId | contentKey, seoScore, impressions, clicks, dwellSeconds} (latest totals, omitted = unchanged)
+func AIExperimentOutcome(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req experiment.Outcome
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || (req.ExposureID == "" && req.ContentKey == "") {
+		http.Error(w, "exposureId or contentKey is required", http.StatusBadRequest)
+		return
+	}
+	if err := experiment.RecordOutcome(req); err != nil {
+		writeExperimentError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"status": "recorded"})
+}
+
+// writeExperimentError maps experiment errors to HTTP status
+func writeExperimentError(w http.ResponseWriter, err error) {
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, experiment.ErrNotFound), errors.Is(err, experiment.ErrExposureNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, experiment.ErrStopped):
+		status = http.StatusConflict
+	}
+	http.Error(w, err.Error(), status)
+}
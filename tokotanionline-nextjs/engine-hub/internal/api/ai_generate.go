@@ -9,6 +9,7 @@ import (
 	"strings"
 
 	"engine-hub/internal/ai/content"
+	"engine-hub/internal/ai/experiment"
 	"engine-hub/internal/ai/image"
 	"engine-hub/internal/ai/workflow"
 )
@@ -56,6 +57,11 @@ func AIGenerate(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// FASE D - D2: Daily AI budget
+	if rejectOverBudget(w, r) {
+		return
+	}
+
 	// Parse request body
 	var req content.ContentRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -71,15 +77,22 @@ func AIGenerate(w http.ResponseWriter, r *http.Request) {
 	log.Printf("[AI GENERATE] Received request: contentType=%s, category=%s, language=%s, outlineLength=%d", 
 		req.ContentType, req.Category, req.Language, len(req.Outline))
 
+	// Prompt A/B: a running experiment on this content type's template pins the version (same outline → same arm)
+	assignment := experiment.Assign(&req, req.Outline)
+	if assignment != nil {
+		log.Printf("[AI GENERATE] Experiment %s: arm %s (template v%d)", assignment.ExperimentID, assignment.Arm, assignment.Version)
+	}
+
 	log.Println("[AI GENERATE] Creating pipeline...")
 	// Create pipeline
 	pipeline := workflow.NewPipeline()
 
 	log.Println("[AI GENERATE] Executing pipeline...")
 	// Execute pipeline
-	draft, err := pipeline.Execute(req)
+	draft, err := pipeline.Execute(usageContext(r), req)
 	if err != nil {
 		log.Printf("[AI GENERATE] Pipeline failed: %v", err)
+		experiment.RecordFailure(assignment, experiment.ChannelGenerate, err)
 		
 		// Determine status based on error type
 		status := "FAILED"
@@ -144,6 +157,7 @@ func AIGenerate(w http.ResponseWriter, r *http.Request) {
 				"content_html": assembleContentFromSections(sections),
 				"intent":      req.Intent,
 				"sections":    sections,
+				"prompt_version": draft.Content.PromptVersion,
 				"seo": map[string]interface{}{
 					"title":             draft.Content.MetaTitle,
 					"meta_description":  draft.Content.MetaDesc,
@@ -153,6 +167,7 @@ func AIGenerate(w http.ResponseWriter, r *http.Request) {
 				"images": map[string]interface{}{
 					"featured": extractFeaturedImage(draft.Images),
 					"inline":   extractInlineImages(draft.Images),
+					"assets":   draft.Images, // dimensions, srcset variants, LQIP placeholder
 				},
 			},
 			"steps": draft.Steps,
@@ -166,6 +181,7 @@ func AIGenerate(w http.ResponseWriter, r *http.Request) {
 				"content":    draft.Content.Body,
 				"content_html": convertMarkdownToHTML(draft.Content.Body),
 				"word_count":   wordCount,
+				"prompt_version": draft.Content.PromptVersion,
 				"seo": map[string]interface{}{
 					"title":             draft.Content.MetaTitle,
 					"meta_description":  draft.Content.MetaDesc,
@@ -175,12 +191,22 @@ func AIGenerate(w http.ResponseWriter, r *http.Request) {
 				"images": map[string]interface{}{
 					"featured": extractFeaturedImage(draft.Images),
 					"inline":   extractInlineImages(draft.Images),
+					"assets":   draft.Images, // dimensions, srcset variants, LQIP placeholder
 				},
 			},
 			"steps": draft.Steps,
 		}
 	}
 
+	// Prompt A/B: record quality metrics for the arm; the app posts SEO score / user signals with exposure_id later
+	if assignment != nil {
+		response["experiment"] = map[string]interface{}{
+			"experiment_id": assignment.ExperimentID,
+			"arm":           assignment.Arm,
+			"exposure_id":   experiment.Record(assignment, experiment.ChannelGenerate, req, &draft.Content, draft.Content.Title),
+		}
+	}
+
 	// Return success response
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
@@ -669,6 +695,11 @@ func AIGenerateProductImages(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// FASE D - D2: Daily AI budget
+	if rejectOverBudget(w, r) {
+		return
+	}
+
 	// Parse request body
 	var req struct {
 		ProductName        string `json:"productName"`
@@ -698,7 +729,7 @@ func AIGenerateProductImages(w http.ResponseWriter, r *http.Request) {
 	imageGen := image.NewGenerator()
 
 	// Generate product images
-	images, err := imageGen.GenerateProductImages(req.ProductName, req.ProductDescription, req.ProductSlug)
+	images, err := imageGen.GenerateProductImages(usageContext(r), req.ProductName, req.ProductDescription, req.ProductSlug)
 	if err != nil {
 		log.Printf("[PRODUCT IMAGE GEN] Failed to generate images: %v", err)
 		errorResponse := map[string]interface{}{
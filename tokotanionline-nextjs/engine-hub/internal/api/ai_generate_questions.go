@@ -1,15 +1,17 @@
 package api
 
 import (
-	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
-	"io"
 	"log"
 	"net/http"
 	"os"
 	"strings"
 	"time"
+
+	"engine-hub/internal/ai/llm"
+	"engine-hub/internal/ai/prompts"
 )
 
 // QuestionGenerationRequest represents request for question generation (PHASE 1)
@@ -22,8 +24,9 @@ type QuestionGenerationRequest struct {
 
 // QuestionGenerationResponse represents response with questions
 type QuestionGenerationResponse struct {
-	Intent    string   `json:"intent"`
-	Questions []string `json:"questions"`
+	Intent        string   `json:"intent"`
+	Questions     []string `json:"questions"`
+	PromptVersion string   `json:"promptVersion,omitempty"` // empty when the rule-based fallback answered
 }
 
 // AIGenerateQuestions handles POST /api/engine/ai/generate-questions
@@ -44,11 +47,12 @@ func AIGenerateQuestions(w http.ResponseWriter, r *http.Request) {
 	log.Printf("[QUESTION GENERATE] Generating questions: title=%s, intent=%s", req.Title, req.Intent)
 
 	// Generate questions using AI (semantic approach)
-	questions := generateQuestionsSemantic(req)
+	questions, promptVersion := generateQuestionsSemantic(usageContext(r), req)
 
 	response := QuestionGenerationResponse{
-		Intent:    req.Intent,
-		Questions: questions,
+		Intent:        req.Intent,
+		Questions:     questions,
+		PromptVersion: promptVersion,
 	}
 
 	w.Header().Set("Content-Type", "application/json")
@@ -56,28 +60,29 @@ func AIGenerateQuestions(w http.ResponseWriter, r *http.Request) {
 }
 
 // generateQuestionsSemantic generates SERP-like questions using AI
-func generateQuestionsSemantic(req QuestionGenerationRequest) []string {
+// Returns the questions and the prompt template ref ("" when the fallback questions are used)
+func generateQuestionsSemantic(ctx context.Context, req QuestionGenerationRequest) ([]string, string) {
 	// A. SERP-DRIVEN QUESTION GENERATOR
 	// Tujuan: Pertanyaan harus mirip yang Google tampilkan, bukan rekaan
 
-	apiKey := os.Getenv("OPENAI_API_KEY")
-	if apiKey == "" {
-		apiKey = os.Getenv("AI_API_KEY")
-	}
-
-	if apiKey == "" {
-		log.Printf("[QUESTION GENERATE] No API key, using fallback")
-		return generateFallbackQuestions(req)
+	client, err := newQuestionsLLM()
+	if err != nil {
+		log.Printf("[QUESTION GENERATE] AI client not configured (%v), using fallback", err)
+		return generateFallbackQuestions(req), ""
 	}
 
 	// Build prompt untuk SERP-like questions
-	prompt := buildSERPQuestionPrompt(req)
+	prompt, promptRef, err := buildSERPQuestionPrompt(req)
+	if err != nil {
+		log.Printf("[QUESTION GENERATE] Failed to build prompt: %v, using fallback", err)
+		return generateFallbackQuestions(req), ""
+	}
 
 	// Call OpenAI untuk generate questions
-	questions, err := callAIForQuestions(prompt, apiKey)
+	questions, err := callAIForQuestions(ctx, prompt, client)
 	if err != nil {
 		log.Printf("[QUESTION GENERATE] AI call failed: %v, using fallback", err)
-		return generateFallbackQuestions(req)
+		return generateFallbackQuestions(req), ""
 	}
 
 	// A2. Filter "SERP-like"
@@ -87,139 +92,65 @@ func generateQuestionsSemantic(req QuestionGenerationRequest) []string {
 	if len(filtered) < 3 {
 		log.Printf("[QUESTION GENERATE] Only %d questions passed filter, regenerating...", len(filtered))
 		// Retry once
-		questions2, err2 := callAIForQuestions(prompt, apiKey)
+		questions2, err2 := callAIForQuestions(ctx, prompt, client)
 		if err2 == nil {
 			filtered2 := filterSERPLikeQuestions(questions2)
 			if len(filtered2) >= 3 {
-				return filtered2[:min(7, len(filtered2))]
+				return filtered2[:min(7, len(filtered2))], promptRef
 			}
 		}
 		// Final fallback
-		return generateFallbackQuestions(req)
+		return generateFallbackQuestions(req), ""
 	}
 
-	return filtered[:min(7, len(filtered))] // Max 7 questions
+	return filtered[:min(7, len(filtered))], promptRef // Max 7 questions
 }
 
-// buildSERPQuestionPrompt builds prompt for SERP-like question generation
-func buildSERPQuestionPrompt(req QuestionGenerationRequest) string {
-	var prompt strings.Builder
-
-	prompt.WriteString("Anda adalah ahli SEO yang memahami pertanyaan yang muncul di Google SERP (Search Engine Results Page).\n\n")
-	prompt.WriteString("TUGAS: Generate 5-7 pertanyaan yang MIRIP dengan yang Google tampilkan di \"People Also Ask\" (PAA).\n\n")
-	prompt.WriteString(fmt.Sprintf("Topik: %s\n", req.Title))
-	prompt.WriteString(fmt.Sprintf("Search Intent: %s\n", req.Intent))
-	if req.Category != "" {
-		prompt.WriteString(fmt.Sprintf("Kategori: %s\n", req.Category))
-	}
-	prompt.WriteString(fmt.Sprintf("Bahasa: %s\n\n", req.Language))
-
-	prompt.WriteString("ATURAN KERAS PERTANYAAN:\n")
-	prompt.WriteString("1. Bentuk Q&A manusia (bukan heading akademik)\n")
-	prompt.WriteString("2. Bisa dijawab ≤ 120 kata\n")
-	prompt.WriteString("3. Tidak tumpang tindih dengan pertanyaan lain\n")
-	prompt.WriteString("4. Bisa berdiri sendiri (tidak perlu konteks lain)\n")
-	prompt.WriteString("5. Mirip pertanyaan yang muncul di Google PAA\n\n")
-
-	prompt.WriteString("CONTOH OUTPUT VALID:\n")
-	prompt.WriteString("- Apa itu pertanian organik?\n")
-	prompt.WriteString("- Bagaimana cara memulai pertanian organik dari nol?\n")
-	prompt.WriteString("- Kesalahan apa yang sering dilakukan pemula?\n")
-	prompt.WriteString("- Apakah pertanian organik menguntungkan?\n\n")
-
-	prompt.WriteString("❌ JANGAN BUAT:\n")
-	prompt.WriteString("- Pertanyaan terlalu akademik\n")
-	prompt.WriteString("- Terlalu panjang (> 15 kata)\n")
-	prompt.WriteString("- Tidak punya nilai jawaban cepat\n")
-	prompt.WriteString("- Pertanyaan yang tidak bisa berdiri sendiri\n\n")
-
-	prompt.WriteString("OUTPUT: Satu pertanyaan per baris, tanpa nomor, tanpa bullet.\n")
-
-	return prompt.String()
+// buildSERPQuestionPrompt renders the SERP-like question prompt (questions.serp template, see internal/ai/prompts)
+func buildSERPQuestionPrompt(req QuestionGenerationRequest) (string, string, error) {
+	return prompts.Render(prompts.QuestionsSERP, prompts.Vars{
+		Keyword:  req.Title,
+		Title:    req.Title,
+		Intent:   req.Intent,
+		Category: req.Category,
+		Language: req.Language,
+	})
 }
 
-// callAIForQuestions calls OpenAI to generate questions
-func callAIForQuestions(prompt string, apiKey string) ([]string, error) {
-	apiURL := os.Getenv("AI_API_URL")
-	if apiURL == "" {
-		apiURL = "https://api.openai.com/v1/responses"
-	}
-
-	// A1. Lock Model ke GPT-5.2 (WAJIB - NO FALLBACK)
+// newQuestionsLLM builds the question LLM client
+// Provider/model/fallback: LLM_QUESTIONS_* (see llm.ConfigFromEnv), default Responses API + GPT-5.2
+func newQuestionsLLM() (*llm.Client, error) {
+	// A1. Default model GPT-5.2 (NO FALLBACK kecuali LLM_QUESTIONS_FALLBACK di-set)
 	model := os.Getenv("AI_MODEL")
 	if model == "" {
 		model = "gpt-5.2" // Default to GPT-5.2
 	}
-	if model != "gpt-5.2" {
-		log.Printf("[QUESTION GENERATE] WARNING: Model is set to %s, but GPT-5.2 is required. Forcing GPT-5.2.", model)
-		model = "gpt-5.2"
-	}
-
-	// A2. Parameter Stabil (ANTI OVER-GENERATE)
-	payload := map[string]interface{}{
-		"model":             model,
-		"input":             prompt,
-		"max_output_tokens": 500,
-		"temperature":       0.4,  // A2: Konservatif & stabil
-		"top_p":             0.85, // A2: Stabil (hindari over-creativity)
-		"presence_penalty":  0.1,  // A2: Minim repetisi
-		"frequency_penalty": 0.2,  // A2: Tidak "puitis AI"
-		"text": map[string]interface{}{
-			"format": map[string]string{
-				"type": "text",
-			},
-		},
-	}
-
-	jsonData, err := json.Marshal(payload)
-	if err != nil {
-		return nil, fmt.Errorf("failed to marshal request: %w", err)
-	}
 
-	client := &http.Client{Timeout: 30 * time.Second}
-	req, err := http.NewRequest("POST", apiURL, bytes.NewBuffer(jsonData))
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
-	}
-
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", "Bearer "+apiKey)
+	return llm.New(llm.ConfigFromEnv("questions", llm.Config{
+		Provider: llm.ProviderResponses,
+		Model:    model,
+		BaseURL:  os.Getenv("AI_API_URL"),
+		Timeout:  30 * time.Second,
+	}))
+}
 
-	resp, err := client.Do(req)
+// callAIForQuestions calls the LLM to generate questions
+func callAIForQuestions(ctx context.Context, prompt string, client *llm.Client) ([]string, error) {
+	// A2. Parameter Stabil (ANTI OVER-GENERATE)
+	resp, err := client.Complete(ctx, llm.Request{
+		Prompt:           prompt,
+		MaxTokens:        500,
+		Temperature:      llm.Float(0.4),  // A2: Konservatif & stabil
+		TopP:             llm.Float(0.85), // A2: Stabil (hindari over-creativity)
+		PresencePenalty:  llm.Float(0.1),  // A2: Minim repetisi
+		FrequencyPenalty: llm.Float(0.2),  // A2: Tidak "puitis AI"
+	})
 	if err != nil {
+		// A1: Jika model unavailable → return error, bukan downgrade diam-diam
 		return nil, fmt.Errorf("API request failed: %w", err)
 	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		// A1: Jika GPT-5.2 unavailable → return error, bukan downgrade diam-diam
-		return nil, fmt.Errorf("API returned status %d: %s (GPT-5.2 required, no fallback)", resp.StatusCode, string(body))
-	}
-
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read response: %w", err)
-	}
-
-	// Parse Responses API response
-	var apiResponse struct {
-		Output []struct {
-			Content []struct {
-				Text string `json:"text"`
-			} `json:"content"`
-		} `json:"output"`
-	}
-
-	if err := json.Unmarshal(body, &apiResponse); err != nil {
-		return nil, fmt.Errorf("failed to parse response: %w", err)
-	}
-
-	if len(apiResponse.Output) == 0 || len(apiResponse.Output[0].Content) == 0 {
-		return nil, fmt.Errorf("no content in response")
-	}
 
-	text := apiResponse.Output[0].Content[0].Text
+	text := resp.Text
 
 	// Parse questions (one per line)
 	lines := strings.Split(text, "\n")
@@ -0,0 +1,125 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+
+	"engine-hub/internal/ai/content"
+	"engine-hub/internal/ai/generation"
+)
+
+// GenerateAsyncRequest is the body of POST /api/engine/ai/generate/async
+// Same fields as /api/engine/ai/generate plus an optional completion webhook
+type GenerateAsyncRequest struct {
+	content.ContentRequest
+	WebhookURL string `json:"webhookUrl,omitempty"`
+}
+
+// AIGenerateAsync handles POST /api/engine/ai/generate/async
+// Queues the pipeline and returns 202 with the generation ID; poll status/result or wait for the webhook
+func AIGenerateAsync(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// FASE D - D2: Daily AI budget
+	if rejectOverBudget(w, r) {
+		return
+	}
+
+	var req GenerateAsyncRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Printf("[AI GENERATE ASYNC] Failed to parse request: %v", err)
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if req.WebhookURL != "" {
+		if err := generation.ValidateWebhookURL(req.WebhookURL); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	g, err := generation.Submit(usageContext(r), req.ContentRequest, req.WebhookURL)
+	if err != nil {
+		log.Printf("[AI GENERATE ASYNC] Failed to queue generation: %v", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"id":         g.ID,
+		"status":     g.Status,
+		"experiment": g.Experiment,
+		"statusUrl":  "/api/engine/ai/generate/status?id=" + g.ID,
+		"resultUrl":  "/api/engine/ai/generate/result?id=" + g.ID,
+	})
+}
+
+// AIGenerationStatus handles GET /api/engine/ai/generate/status?id=
+// Returns state machine state, per-step results and progress percentage
+func AIGenerationStatus(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	id := r.URL.Query().Get("id")
+	if id == "" {
+		http.Error(w, "id is required", http.StatusBadRequest)
+		return
+	}
+	g, err := generation.Get(id)
+	if err != nil {
+		writeGenerationError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(g)
+}
+
+// AIGenerationResult handles GET /api/engine/ai/generate/result?id=
+// 200 with the DraftAI when DONE, 202 with the status while QUEUED/RUNNING, 409 with the status when FAILED
+func AIGenerationResult(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	id := r.URL.Query().Get("id")
+	if id == "" {
+		http.Error(w, "id is required", http.StatusBadRequest)
+		return
+	}
+	draft, g, err := generation.Result(id)
+	if errors.Is(err, generation.ErrNotDone) {
+		status := http.StatusAccepted
+		if g.Terminal() {
+			status = http.StatusConflict
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(g)
+		return
+	}
+	if err != nil {
+		writeGenerationError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(draft)
+}
+
+// writeGenerationError maps generation errors to HTTP status
+func writeGenerationError(w http.ResponseWriter, err error) {
+	status := http.StatusInternalServerError
+	if errors.Is(err, generation.ErrNotFound) {
+		status = http.StatusNotFound
+	}
+	http.Error(w, err.Error(), status)
+}
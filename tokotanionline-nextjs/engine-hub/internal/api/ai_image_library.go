@@ -0,0 +1,183 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"engine-hub/internal/ai/image"
+)
+
+// maxLibraryUpload limits one image library upload (multipart body)
+const maxLibraryUpload = 20 << 20
+
+// ImageLibraryTagRequest is the body of POST /api/engine/ai/images/library/tags
+// Omitted fields are unchanged; tags/keywords replace the current list
+type ImageLibraryTagRequest struct {
+	ID string `json:"id"`
+	image.LibraryMeta
+}
+
+// AIImageLibrary handles GET / POST /api/engine/ai/images/library
+// GET ?tag=&q= lists library images with usage counts
+// POST multipart: file, tags (comma separated), keywords, altText, source — uploads and indexes one photo
+func AIImageLibrary(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		list, err := image.ListLibrary(r.URL.Query().Get("tag"), r.URL.Query().Get("q"))
+		if err != nil {
+			writeImageLibraryError(w, err)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"images": list,
+			"count":  len(list),
+		})
+	case http.MethodPost:
+		aiImageLibraryUpload(w, r)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func aiImageLibraryUpload(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxLibraryUpload)
+	if err := r.ParseMultipartForm(maxLibraryUpload); err != nil {
+		log.Printf("[IMAGE LIBRARY] Failed to parse upload: %v", err)
+		http.Error(w, "Invalid multipart body (file field, max 20 MB)", http.StatusBadRequest)
+		return
+	}
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		http.Error(w, "file is required", http.StatusBadRequest)
+		return
+	}
+	defer file.Close()
+	data, err := io.ReadAll(file)
+	if err != nil {
+		http.Error(w, "Failed to read uploaded file", http.StatusBadRequest)
+		return
+	}
+
+	meta := image.LibraryMeta{
+		Tags:     formList(r, "tags"),
+		Keywords: formList(r, "keywords"),
+	}
+	if v, ok := r.MultipartForm.Value["altText"]; ok && len(v) > 0 {
+		meta.AltText = &v[0]
+	}
+	if v, ok := r.MultipartForm.Value["source"]; ok && len(v) > 0 {
+		meta.Source = &v[0]
+	}
+
+	img, duplicate, err := image.AddLibraryImage(data, header.Filename, meta)
+	if err != nil {
+		writeImageLibraryError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if !duplicate {
+		w.WriteHeader(http.StatusCreated)
+	}
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"image":     img,
+		"duplicate": duplicate, // same file already in the library (tags unchanged, use /tags to edit)
+	})
+}
+
+// AIImageLibraryTags handles POST /api/engine/ai/images/library/tags
+// {id, tags?, keywords?, altText?, source?, disabled?}
+func AIImageLibraryTags(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req ImageLibraryTagRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if req.ID == "" {
+		http.Error(w, "id is required", http.StatusBadRequest)
+		return
+	}
+
+	img, err := image.UpdateLibraryImage(req.ID, req.LibraryMeta)
+	if err != nil {
+		writeImageLibraryError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(img)
+}
+
+// AIImageLibraryMatch handles GET /api/engine/ai/images/library/match?heading=&limit=
+// Previews which library images a section heading would get (no usage is recorded)
+func AIImageLibraryMatch(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	query := r.URL.Query()
+	heading := strings.TrimSpace(query.Get("heading"))
+	if heading == "" {
+		http.Error(w, "heading is required", http.StatusBadRequest)
+		return
+	}
+	limit := 5
+	if v := query.Get("limit"); v != "" {
+		val, err := strconv.Atoi(v)
+		if err != nil || val <= 0 {
+			http.Error(w, "limit must be a positive integer", http.StatusBadRequest)
+			return
+		}
+		limit = val
+	}
+
+	matches, err := image.MatchLibrary(heading, nil, limit)
+	if err != nil {
+		writeImageLibraryError(w, err)
+		return
+	}
+	if matches == nil {
+		matches = []image.LibraryMatch{}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"heading": heading,
+		"matches": matches,
+		"count":   len(matches),
+	})
+}
+
+// formList collects a multipart field given as comma separated values and/or repeated fields
+func formList(r *http.Request, name string) []string {
+	var out []string
+	for _, v := range r.MultipartForm.Value[name] {
+		for _, part := range strings.Split(v, ",") {
+			if part = strings.TrimSpace(part); part != "" {
+				out = append(out, part)
+			}
+		}
+	}
+	return out
+}
+
+// writeImageLibraryError maps image library errors to HTTP status
+func writeImageLibraryError(w http.ResponseWriter, err error) {
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, image.ErrLibraryInvalid):
+		status = http.StatusBadRequest
+	case errors.Is(err, image.ErrLibraryNotFound):
+		status = http.StatusNotFound
+	}
+	http.Error(w, err.Error(), status)
+}
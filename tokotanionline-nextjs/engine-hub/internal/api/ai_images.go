@@ -0,0 +1,143 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+
+	"engine-hub/internal/ai/imageregen"
+)
+
+// ImageRestoreRequest is the body of POST /api/engine/ai/images/revisions/restore
+type ImageRestoreRequest struct {
+	ID          string `json:"id"`
+	RequestedBy string `json:"requestedBy,omitempty"`
+}
+
+// AIImageRegenerate handles POST /api/engine/ai/images/regenerate
+// One article image (slug + section, or role hero) or product image (slug + role hero | detail + index)
+// is generated again, replaced in BlogPost / Product (or in the draft sent along) and recorded as a revision
+func AIImageRegenerate(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// FASE D - D2: Daily AI budget
+	if rejectOverBudget(w, r) {
+		return
+	}
+
+	var req imageregen.Request
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Printf("[IMAGE REGEN] Failed to parse request: %v", err)
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	res, err := imageregen.Regenerate(usageContext(r), req)
+	if err != nil {
+		if res != nil && res.Quality != nil {
+			// Image API or quality gate failed: the record is unchanged, show what was rejected
+			log.Printf("[IMAGE REGEN] Failed for %s %s: %v", req.Kind, req.Slug, err)
+			status := http.StatusBadGateway
+			if len(res.Quality.Abandoned) > 0 {
+				status = http.StatusUnprocessableEntity
+			}
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(status)
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"error":   "Image regeneration failed",
+				"message": err.Error(),
+				"status":  "FAILED",
+				"quality": res.Quality,
+			})
+			return
+		}
+		writeImageRegenError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(res)
+}
+
+// AIImageRevisions handles GET /api/engine/ai/images/revisions?slug=&kind=article|product&limit=
+func AIImageRevisions(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	query := r.URL.Query()
+	slug := query.Get("slug")
+	if slug == "" {
+		http.Error(w, "slug is required", http.StatusBadRequest)
+		return
+	}
+	limit := 50
+	if v := query.Get("limit"); v != "" {
+		val, err := strconv.Atoi(v)
+		if err != nil || val <= 0 {
+			http.Error(w, "limit must be a positive integer", http.StatusBadRequest)
+			return
+		}
+		limit = val
+	}
+
+	list, err := imageregen.List(query.Get("kind"), slug, limit)
+	if err != nil {
+		writeImageRegenError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"revisions": list,
+		"count":     len(list),
+	})
+}
+
+// AIImageRestore handles POST /api/engine/ai/images/revisions/restore
+// Puts the image replaced by a revision back, if the regenerated image is still in place (409 otherwise)
+func AIImageRestore(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req ImageRestoreRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if req.ID == "" {
+		http.Error(w, "id is required", http.StatusBadRequest)
+		return
+	}
+
+	res, err := imageregen.Restore(r.Context(), req.ID, req.RequestedBy)
+	if err != nil {
+		writeImageRegenError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(res)
+}
+
+// writeImageRegenError maps image regeneration errors to HTTP status
+func writeImageRegenError(w http.ResponseWriter, err error) {
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, imageregen.ErrInvalidRequest):
+		status = http.StatusBadRequest
+	case errors.Is(err, imageregen.ErrNotFound), errors.Is(err, imageregen.ErrRevisionNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, imageregen.ErrAmbiguous), errors.Is(err, imageregen.ErrConflict):
+		status = http.StatusConflict
+	case errors.Is(err, imageregen.ErrNoDatabase):
+		status = http.StatusServiceUnavailable
+	}
+	http.Error(w, err.Error(), status)
+}
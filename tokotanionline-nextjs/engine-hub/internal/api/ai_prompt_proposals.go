@@ -0,0 +1,120 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"engine-hub/internal/ai/quality"
+)
+
+// PromptProposalDecisionRequest is the body of POST /api/engine/ai/prompts/proposals/{approve,reject}
+type PromptProposalDecisionRequest struct {
+	ID        string `json:"id"`
+	DecidedBy string `json:"decidedBy,omitempty"`
+	Note      string `json:"note,omitempty"` // reject reason
+}
+
+// AIPromptProposals handles GET /api/engine/ai/prompts/proposals
+// Query: id (single proposal), name (template name), status (PENDING|APPROVED|REJECTED|SUPERSEDED)
+func AIPromptProposals(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	q := r.URL.Query()
+	if id := q.Get("id"); id != "" {
+		p, err := quality.GetProposal(id)
+		if err != nil {
+			writeProposalError(w, err)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(p)
+		return
+	}
+
+	proposals, err := quality.ListProposals(q.Get("name"), q.Get("status"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if proposals == nil {
+		proposals = []quality.Proposal{}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"proposals": proposals,
+	})
+}
+
+// AIPromptProposalApprove handles POST /api/engine/ai/prompts/proposals/approve
+// Creates the proposed body as a new template version and makes it active
+func AIPromptProposalApprove(w http.ResponseWriter, r *http.Request) {
+	decideProposal(w, r, func(req PromptProposalDecisionRequest) (quality.Proposal, error) {
+		return quality.ApproveProposal(req.ID, req.DecidedBy)
+	})
+}
+
+// AIPromptProposalReject handles POST /api/engine/ai/prompts/proposals/reject
+func AIPromptProposalReject(w http.ResponseWriter, r *http.Request) {
+	decideProposal(w, r, func(req PromptProposalDecisionRequest) (quality.Proposal, error) {
+		return quality.RejectProposal(req.ID, req.DecidedBy, req.Note)
+	})
+}
+
+func decideProposal(w http.ResponseWriter, r *http.Request, decide func(PromptProposalDecisionRequest) (quality.Proposal, error)) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req PromptProposalDecisionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.ID == "" {
+		http.Error(w, "id is required", http.StatusBadRequest)
+		return
+	}
+
+	p, err := decide(req)
+	if err != nil {
+		writeProposalError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(p)
+}
+
+// AIPromptRefine handles /api/engine/ai/prompts/refine
+// GET: last refinement pass; POST: run a pass now (proposals still need approval)
+func AIPromptRefine(w http.ResponseWriter, r *http.Request) {
+	var run *quality.RefinementRun
+	switch r.Method {
+	case http.MethodGet:
+		run = quality.LastRefinement()
+	case http.MethodPost:
+		result := quality.RunRefinement()
+		run = &result
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"run":      run,
+		"interval": quality.RefinementInterval().String(),
+	})
+}
+
+// writeProposalError maps proposal errors to HTTP status
+func writeProposalError(w http.ResponseWriter, err error) {
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, quality.ErrProposalNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, quality.ErrProposalDecided), errors.Is(err, quality.ErrProposalStale):
+		status = http.StatusConflict
+	}
+	http.Error(w, err.Error(), status)
+}
@@ -0,0 +1,209 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+
+	"engine-hub/internal/ai/prompts"
+)
+
+// PromptCreateRequest is the body of POST /api/engine/ai/prompts
+type PromptCreateRequest struct {
+	Name        string `json:"name"`
+	Body        string `json:"body"`
+	Description string `json:"description,omitempty"`
+	CreatedBy   string `json:"createdBy,omitempty"`
+	Activate    bool   `json:"activate,omitempty"` // make the new version live immediately
+}
+
+// PromptActivateRequest is the body of POST /api/engine/ai/prompts/activate
+type PromptActivateRequest struct {
+	Name    string `json:"name"`
+	Version int    `json:"version"`
+}
+
+// PromptPreviewRequest is the body of POST /api/engine/ai/prompts/preview
+// Body set = preview an unsaved draft; otherwise Version (0 = active) of Name is rendered
+// Vars empty = sample vars (every variable filled)
+type PromptPreviewRequest struct {
+	Name    string        `json:"name"`
+	Version int           `json:"version,omitempty"`
+	Body    string        `json:"body,omitempty"`
+	Vars    *prompts.Vars `json:"vars,omitempty"`
+}
+
+// AIPrompts handles /api/engine/ai/prompts
+// GET: list template versions (query: name, version; body omitted unless name is given)
+// POST: create a new version (validated; 400 with the validation result if invalid)
+func AIPrompts(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		listPrompts(w, r)
+	case http.MethodPost:
+		createPrompt(w, r)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func listPrompts(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	name := q.Get("name")
+
+	if v := q.Get("version"); v != "" && name != "" {
+		version, err := strconv.Atoi(v)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("invalid version: %s", v), http.StatusBadRequest)
+			return
+		}
+		t, err := prompts.Default().Get(name, version)
+		if err != nil {
+			writePromptError(w, err)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(t)
+		return
+	}
+
+	var out []prompts.Template
+	for _, t := range prompts.Default().List() {
+		if name != "" && t.Name != name {
+			continue
+		}
+		if name == "" {
+			t.Body = ""
+		}
+		out = append(out, t)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"templates": out,
+		"names":     prompts.Names(),
+	})
+}
+
+func createPrompt(w http.ResponseWriter, r *http.Request) {
+	var req PromptCreateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	t, validation, err := prompts.Default().Create(prompts.Template{
+		Name:        req.Name,
+		Body:        req.Body,
+		Description: req.Description,
+		CreatedBy:   req.CreatedBy,
+	}, req.Activate)
+
+	w.Header().Set("Content-Type", "application/json")
+	if !validation.Valid {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"error":      "template is invalid",
+			"validation": validation,
+		})
+		return
+	}
+	if err != nil {
+		log.Printf("[PROMPTS] Failed to create %s: %v", req.Name, err)
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]interface{}{"error": err.Error()})
+		return
+	}
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"template":   t,
+		"validation": validation,
+	})
+}
+
+// AIPromptActivate handles POST /api/engine/ai/prompts/activate (also used for rollback)
+func AIPromptActivate(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req PromptActivateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Name == "" || req.Version <= 0 {
+		http.Error(w, "name and version are required", http.StatusBadRequest)
+		return
+	}
+	if err := prompts.Default().Activate(req.Name, req.Version); err != nil {
+		writePromptError(w, err)
+		return
+	}
+
+	t, _ := prompts.Default().Get(req.Name, 0)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"active": t.Ref(),
+	})
+}
+
+// AIPromptPreview handles POST /api/engine/ai/prompts/preview
+// Renders a draft or stored version with the given vars and returns the prompt plus validation
+func AIPromptPreview(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req PromptPreviewRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Name == "" {
+		http.Error(w, "name is required", http.StatusBadRequest)
+		return
+	}
+	vars := prompts.SampleVars()
+	if req.Vars != nil {
+		vars = *req.Vars
+	}
+
+	preview, err := prompts.Default().Preview(req.Name, req.Version, req.Body, vars)
+	if err != nil {
+		writePromptError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(preview)
+}
+
+// AIPromptReload handles POST /api/engine/ai/prompts/reload (after editing PROMPT_TEMPLATE_DIR files)
+func AIPromptReload(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if err := prompts.Default().Reload(); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	active := make(map[string]string)
+	for _, name := range prompts.Names() {
+		if t, err := prompts.Default().Get(name, 0); err == nil {
+			active[name] = t.Ref()
+		}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status": "reloaded",
+		"active": active,
+	})
+}
+
+// writePromptError maps registry errors to HTTP status
+func writePromptError(w http.ResponseWriter, err error) {
+	status := http.StatusInternalServerError
+	if errors.Is(err, prompts.ErrNotFound) {
+		status = http.StatusNotFound
+	}
+	http.Error(w, err.Error(), status)
+}
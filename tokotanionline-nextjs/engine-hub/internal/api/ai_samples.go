@@ -0,0 +1,102 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"engine-hub/internal/ai/quality"
+)
+
+// AISamplesResponse is the body of GET /api/engine/ai/samples
+type AISamplesResponse struct {
+	Count    int                        `json:"count"`
+	Passing  int                        `json:"passing"`
+	PassRate float64                    `json:"passRate"`
+	Samples  []quality.GenerationSample `json:"samples"`
+}
+
+// AISamples handles GET /api/engine/ai/samples
+// Query: category, contentType, promptVersion (e.g. content.derivative@v2), pass (true|false),
+// since, until (RFC3339 or YYYY-MM-DD), limit (default 100, max 1000), text=1 to include outline and output text
+func AISamples(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	q := r.URL.Query()
+	filter := quality.SampleFilter{
+		Category:      q.Get("category"),
+		ContentType:   q.Get("contentType"),
+		PromptVersion: q.Get("promptVersion"),
+		Limit:         100,
+	}
+	if p := q.Get("pass"); p != "" {
+		pass, err := strconv.ParseBool(p)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("invalid pass: %s", p), http.StatusBadRequest)
+			return
+		}
+		filter.Pass = &pass
+	}
+	if s := q.Get("since"); s != "" {
+		t, _, err := parseFilterDate(s)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("invalid since: %s", s), http.StatusBadRequest)
+			return
+		}
+		filter.From = t
+	}
+	if u := q.Get("until"); u != "" {
+		t, dateOnly, err := parseFilterDate(u)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("invalid until: %s", u), http.StatusBadRequest)
+			return
+		}
+		if dateOnly {
+			t = t.Add(24 * time.Hour)
+		}
+		filter.To = t
+	}
+	if l := q.Get("limit"); l != "" {
+		limit, err := strconv.Atoi(l)
+		if err != nil || limit <= 0 {
+			http.Error(w, fmt.Sprintf("invalid limit: %s", l), http.StatusBadRequest)
+			return
+		}
+		if limit > 1000 {
+			limit = 1000
+		}
+		filter.Limit = limit
+	}
+
+	samples, err := quality.Samples().Query(filter)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	resp := AISamplesResponse{Count: len(samples), Samples: samples}
+	withText := q.Get("text") == "1"
+	for i := range resp.Samples {
+		if resp.Samples[i].PassQualityProfile {
+			resp.Passing++
+		}
+		if !withText {
+			resp.Samples[i].InputOutline = ""
+			resp.Samples[i].OutputText = ""
+		}
+	}
+	if resp.Count > 0 {
+		resp.PassRate = float64(resp.Passing) / float64(resp.Count)
+	}
+	if resp.Samples == nil {
+		resp.Samples = []quality.GenerationSample{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
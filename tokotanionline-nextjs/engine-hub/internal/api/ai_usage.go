@@ -0,0 +1,133 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"engine-hub/internal/ai/usage"
+	contentengine "engine-hub/internal/content"
+)
+
+// AIUsageResponse is the body of GET /api/engine/ai/usage
+type AIUsageResponse struct {
+	Since   time.Time                  `json:"since"`
+	Until   time.Time                  `json:"until"`
+	GroupBy []string                   `json:"groupBy"`
+	Rows    []usage.ReportRow          `json:"rows"`
+	Totals  usage.ReportRow            `json:"totals"`
+	Budget  contentengine.BudgetStatus `json:"budget"`
+}
+
+// AIUsage handles GET /api/engine/ai/usage
+// Query: since, until (RFC3339 or YYYY-MM-DD, default last 7 days), groupBy (day,brand,endpoint,model,caller; default day),
+// brandId, endpoint, kind (text|image)
+func AIUsage(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	q := r.URL.Query()
+	now := time.Now()
+	filter := usage.ReportFilter{
+		From:     usage.StartOfDay(now).AddDate(0, 0, -6),
+		To:       usage.StartOfDay(now).AddDate(0, 0, 1),
+		BrandID:  q.Get("brandId"),
+		Endpoint: q.Get("endpoint"),
+		Kind:     q.Get("kind"),
+		GroupBy:  []string{usage.GroupDay},
+	}
+
+	if s := q.Get("since"); s != "" {
+		t, _, err := parseFilterDate(s)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("invalid since: %s", s), http.StatusBadRequest)
+			return
+		}
+		filter.From = t
+	}
+	if u := q.Get("until"); u != "" {
+		t, dateOnly, err := parseFilterDate(u)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("invalid until: %s", u), http.StatusBadRequest)
+			return
+		}
+		if dateOnly {
+			t = t.Add(24 * time.Hour)
+		}
+		filter.To = t
+	}
+	if g := q.Get("groupBy"); g != "" {
+		filter.GroupBy = nil
+		for _, part := range strings.Split(g, ",") {
+			if part = strings.TrimSpace(part); part != "" {
+				filter.GroupBy = append(filter.GroupBy, part)
+			}
+		}
+	}
+
+	rows, err := usage.Report(filter)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if filter.Validate() != nil {
+			status = http.StatusBadRequest
+		}
+		http.Error(w, err.Error(), status)
+		return
+	}
+
+	totals := usage.ReportRow{}
+	for _, row := range rows {
+		totals.Calls += row.Calls
+		totals.Failed += row.Failed
+		totals.InputTokens += row.InputTokens
+		totals.OutputTokens += row.OutputTokens
+		totals.Images += row.Images
+		totals.CostUSD += row.CostUSD
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(AIUsageResponse{
+		Since:   filter.From,
+		Until:   filter.To,
+		GroupBy: filter.GroupBy,
+		Rows:    rows,
+		Totals:  totals,
+		Budget:  contentengine.CheckDailyBudget(now),
+	})
+}
+
+// usageContext returns the context for AI calls made by a handler:
+// request values plus the endpoint attribution, without request cancellation
+// (a generation keeps running, and is billed, even if the caller disconnects)
+func usageContext(r *http.Request) context.Context {
+	return usage.WithAttribution(context.WithoutCancel(r.Context()), usage.Attribution{Endpoint: r.URL.Path})
+}
+
+// budgetExceeded reports whether the daily AI budget is exhausted (checked between items of a batch)
+func budgetExceeded() bool {
+	return contentengine.CheckDailyBudget(time.Now()).Exceeded
+}
+
+// rejectOverBudget writes 429 and returns true when the daily AI budget is exhausted
+func rejectOverBudget(w http.ResponseWriter, r *http.Request) bool {
+	budget := contentengine.CheckDailyBudget(time.Now())
+	if !budget.Exceeded {
+		return false
+	}
+
+	log.Printf("[AI USAGE] Refusing %s: daily budget reached (spent $%.4f, cap $%.2f)", r.URL.Path, budget.SpentUSD, budget.CapUSD)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusTooManyRequests)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"error":  "Daily AI budget exceeded",
+		"status": "FAILED",
+		"budget": budget,
+	})
+	return true
+}
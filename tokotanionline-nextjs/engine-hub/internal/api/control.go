@@ -1,8 +1,10 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
+	"time"
 
 	"engine-hub/internal/engine"
 )
@@ -11,6 +13,9 @@ type ControlRequest struct {
 	Action string `json:"action"` // start | stop
 }
 
+// ControlEngine handles POST /api/engines/control?name=...
+// start: dependencies are started first (dependency order)
+// stop: engines depending on this one are stopped first
 func ControlEngine(w http.ResponseWriter, r *http.Request) {
 	name := r.URL.Query().Get("name")
 	if name == "" {
@@ -24,23 +29,38 @@ func ControlEngine(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	e, ok := engine.Get(name)
-	if !ok {
+	if _, ok := engine.Get(name); !ok {
 		http.Error(w, "engine not found", http.StatusNotFound)
 		return
 	}
 
+	var affected []string
+	var err error
 	switch req.Action {
 	case "start":
-		e.Start()
+		ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
+		defer cancel()
+		affected, err = engine.StartWithDependencies(ctx, name)
 	case "stop":
-		e.Stop()
+		affected, err = engine.StopEngine(name)
 	default:
 		http.Error(w, "invalid action", http.StatusBadRequest)
 		return
 	}
 
-	w.WriteHeader(http.StatusOK)
+	response := map[string]interface{}{
+		"name":     name,
+		"action":   req.Action,
+		"affected": affected,
+		"statuses": engine.GetStatuses(),
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err != nil {
+		response["error"] = err.Error()
+		w.WriteHeader(http.StatusConflict)
+	}
+	json.NewEncoder(w).Encode(response)
 }
 
 
@@ -10,6 +10,7 @@ import (
 	"strings"
 
 	"engine-hub/internal/ai/content"
+	"engine-hub/internal/ai/prompts"
 	"engine-hub/internal/ai/quality"
 	"engine-hub/internal/ai/workflow"
 )
@@ -70,6 +71,11 @@ func ControlledProduction(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// FASE D - D2: Daily AI budget
+	if rejectOverBudget(w, r) {
+		return
+	}
+
 	var req ControlledProductionRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		log.Printf("[CONTROLLED PRODUCTION] Failed to parse request: %v", err)
@@ -98,32 +104,29 @@ func ControlledProduction(w http.ResponseWriter, r *http.Request) {
 	// Use appropriate profile based on content type
 	// Convert string to ContentType enum
 	contentType := content.ContentType(req.ContentType)
-	var profile quality.QualityProfile
-	if contentType == content.ContentDerivativeLong {
-		profile = quality.DerivativeLongQualityProfile()
-		log.Println("[CONTROLLED PRODUCTION] Using DerivativeLongQualityProfile (1200-2000 words)")
-	} else if contentType == content.ContentDerivative {
-		profile = quality.DerivativeQualityProfile()
-		log.Println("[CONTROLLED PRODUCTION] Using DerivativeQualityProfile (650-1000 words)")
-	} else {
-		profile = quality.DefaultQualityProfile()
-		log.Println("[CONTROLLED PRODUCTION] Using DefaultQualityProfile (1200-2000 words)")
+	profile := quality.ProfileFor(contentType)
+	log.Printf("[CONTROLLED PRODUCTION] Using quality profile for %s (%d-%d words)",
+		contentType, profile.MinWordCount, profile.MaxWordCount)
+	// Samples are persisted (Postgres when available) for querying and prompt refinement
+	store := quality.Samples()
+
+	// Active prompt template for this content type; each sample records the ref its generation actually used
+	promptVersion := ""
+	if tmpl, err := prompts.Default().Get(content.PromptTemplateName(contentType), 0); err == nil {
+		promptVersion = tmpl.Ref()
 	}
-	store := quality.NewInMemorySampleStore()
-	
-	// Get base prompt from generator
-	baseGenerator := content.NewGenerator()
-	basePrompt := baseGenerator.GetBasePrompt()
-	
-	// Initialize prompt refiner
-	refiner := quality.NewPromptRefiner(basePrompt, store)
-	promptVersion, _ := refiner.GetCurrentPrompt()
 
 	// Generate samples
 	var sampleResults []ControlledProductionSample
 	
 	for i := 1; i <= req.Count; i++ {
 		log.Printf("[CONTROLLED PRODUCTION] Generating sample %d/%d...", i, req.Count)
+
+		// FASE D - D2: Stop once the daily AI budget is exhausted
+		if budgetExceeded() {
+			log.Printf("[CONTROLLED PRODUCTION] Daily AI budget exceeded, stopping at sample %d", i)
+			break
+		}
 		
 		// Load outline for derivative article
 		outline, err := loadDerivativeOutline(req.Category, i)
@@ -144,7 +147,7 @@ func ControlledProduction(w http.ResponseWriter, r *http.Request) {
 
 		// Generate content using pipeline
 		pipeline := workflow.NewPipeline()
-		draft, err := pipeline.Execute(contentReq)
+		draft, err := pipeline.Execute(usageContext(r), contentReq)
 		if err != nil {
 			log.Printf("[CONTROLLED PRODUCTION] Generation failed for sample %d: %v", i, err)
 			sampleResults = append(sampleResults, ControlledProductionSample{
@@ -167,13 +170,15 @@ func ControlledProduction(w http.ResponseWriter, r *http.Request) {
 		}
 
 		// Create and save sample
-		sample := quality.NewGenerationSample(contentReq, &draft.Content, promptVersion)
-		store.Save(sample)
+		sample := quality.NewGenerationSample(contentReq, &draft.Content, "")
+		if err := store.Save(sample); err != nil {
+			log.Printf("[CONTROLLED PRODUCTION] Failed to store sample %d: %v", i, err)
+		}
 
 		// Add to results
 		sampleResults = append(sampleResults, ControlledProductionSample{
 			SampleNumber:    i,
-			PromptVersion:   promptVersion,
+			PromptVersion:   sample.PromptVersion,
 			Metrics:         metrics,
 			Pass:            passes,
 			FailureReasons:  failureReasons,
@@ -3,11 +3,27 @@ package api
 import (
 	"encoding/json"
 	"net/http"
+	"sort"
 
 	"engine-hub/internal/engine"
 )
 
+// EngineDetail is one entry of GET /api/engines?detail=true
+type EngineDetail struct {
+	engine.Descriptor
+	Status string              `json:"status"`
+	Health engine.HealthReport `json:"health"`
+}
+
+// Engines handles GET /api/engines
+// Default: {name: status} (used by lib/engine-hub.ts)
+// ?detail=true: {engines: [...], total, startOrder} with Describe() + HealthCheck()
 func Engines(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Query().Get("detail") == "true" {
+		engineDetails(w)
+		return
+	}
+
 	statuses := engine.GetStatuses()
 	
 	// Convert EngineStatus to string for JSON
@@ -20,4 +36,43 @@ func Engines(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(result)
 }
 
+// engineDetails writes metadata and health of every engine, in start order
+func engineDetails(w http.ResponseWriter) {
+	order, err := engine.StartOrder()
+	orderErr := ""
+	if err != nil {
+		orderErr = err.Error()
+		order = nil
+		for name := range engine.GetAll() {
+			order = append(order, name)
+		}
+		sort.Strings(order)
+	}
+
+	details := make([]EngineDetail, 0, len(order))
+	for _, name := range order {
+		e, ok := engine.Get(name)
+		if !ok {
+			continue
+		}
+		details = append(details, EngineDetail{
+			Descriptor: e.Describe(),
+			Status:     string(e.Status()),
+			Health:     e.HealthCheck(),
+		})
+	}
+
+	response := map[string]interface{}{
+		"engines":    details,
+		"total":      len(details),
+		"startOrder": order,
+	}
+	if orderErr != "" {
+		response["error"] = orderErr
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
 
@@ -0,0 +1,85 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"engine-hub/internal/jobs"
+)
+
+// parseJobFilter reads engine, status, since, until, limit, offset and order query params
+// Dates accept RFC3339 or YYYY-MM-DD (until is exclusive; a plain date covers the whole day)
+// limit=0 uses the default, limit is capped at jobs.MaxListLimit; order=desc lists newest first
+func parseJobFilter(r *http.Request, defaultLimit int) (jobs.Filter, error) {
+	q := r.URL.Query()
+	filter := jobs.Filter{
+		Engine: q.Get("engine"),
+		Status: jobs.JobStatus(strings.ToUpper(q.Get("status"))),
+		Limit:  defaultLimit,
+	}
+
+	if l := q.Get("limit"); l != "" {
+		parsed, err := strconv.Atoi(l)
+		if err != nil || parsed < 0 {
+			return filter, fmt.Errorf("invalid limit: %s", l)
+		}
+		if parsed > 0 {
+			filter.Limit = parsed
+		}
+	}
+	if filter.Limit > jobs.MaxListLimit {
+		filter.Limit = jobs.MaxListLimit
+	}
+
+	switch strings.ToLower(q.Get("order")) {
+	case "", "asc":
+	case "desc":
+		filter.NewestFirst = true
+	default:
+		return filter, fmt.Errorf("invalid order: %s (asc | desc)", q.Get("order"))
+	}
+
+	if o := q.Get("offset"); o != "" {
+		parsed, err := strconv.Atoi(o)
+		if err != nil || parsed < 0 {
+			return filter, fmt.Errorf("invalid offset: %s", o)
+		}
+		filter.Offset = parsed
+	}
+
+	if s := q.Get("since"); s != "" {
+		t, _, err := parseFilterDate(s)
+		if err != nil {
+			return filter, fmt.Errorf("invalid since: %s", s)
+		}
+		filter.Since = t
+	}
+
+	if u := q.Get("until"); u != "" {
+		t, dateOnly, err := parseFilterDate(u)
+		if err != nil {
+			return filter, fmt.Errorf("invalid until: %s", u)
+		}
+		if dateOnly {
+			t = t.Add(24 * time.Hour)
+		}
+		filter.Until = t
+	}
+
+	return filter, nil
+}
+
+// parseFilterDate parses RFC3339 or YYYY-MM-DD; reports whether the value was a plain date
+func parseFilterDate(value string) (time.Time, bool, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, false, nil
+	}
+	t, err := time.ParseInLocation("2006-01-02", value, time.Local)
+	if err != nil {
+		return time.Time{}, false, err
+	}
+	return t, true, nil
+}
@@ -8,15 +8,18 @@ import (
 	"engine-hub/internal/jobs"
 )
 
+// EngineJobResults handles GET /api/jobs/results
+// Supports ?engine=&status=&since=&until=&limit=&offset=&order=asc|desc (total count in X-Total-Count)
 func EngineJobResults(w http.ResponseWriter, r *http.Request) {
-	limit := 10
-	if l := r.URL.Query().Get("limit"); l != "" {
-		if parsed, err := strconv.Atoi(l); err == nil {
-			limit = parsed
-		}
+	filter, err := parseJobFilter(r, 10)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
 	}
 
-	results := jobs.GetResults(limit)
+	results, total := jobs.QueryResults(filter)
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("X-Total-Count", strconv.Itoa(total))
 	json.NewEncoder(w).Encode(results)
 }
 
@@ -3,15 +3,24 @@ package api
 import (
 	"encoding/json"
 	"net/http"
+	"strconv"
 
 	"engine-hub/internal/jobs"
 )
 
 // EngineJobs handles GET /api/jobs - List all jobs (read-only)
 // STEP 18B-1: Jobs endpoint for viewing jobs
-func EngineJobs(w http.ResponseWriter, _ *http.Request) {
-	jobList := jobs.List(100) // Get more jobs for UI
+// Supports ?engine=&status=&since=&until=&limit=&offset=&order=asc|desc (total count in X-Total-Count)
+func EngineJobs(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseJobFilter(r, 100) // Get more jobs for UI
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	jobList, total := jobs.Query(filter)
 	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("X-Total-Count", strconv.Itoa(total))
 	json.NewEncoder(w).Encode(jobList)
 }
 
@@ -2,27 +2,181 @@ package api
 
 import (
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
 	"engine-hub/internal/engine"
 )
 
+// EngineLogs handles GET /api/engines/logs (also /logs)
+// Filters: level (comma list), subsystem, jobId, pageId, since, until, limit
 func EngineLogs(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
 		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
 
-	limit := 20
-
-	if l := r.URL.Query().Get("limit"); l != "" {
-		if parsed, err := strconv.Atoi(l); err == nil {
-			limit = parsed
-		}
+	filter, err := parseLogFilter(r, 20)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
 	}
 
-	logs := engine.GetLogs(limit)
+	logs, err := engine.QueryLogs(filter)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(logs)
 }
+
+// EngineLogStream handles GET /api/engines/logs/stream (Server-Sent Events)
+// Same filters as EngineLogs; backfill=N sends the last N buffered matching entries first
+// Reconnecting clients send Last-Event-ID and receive the buffered entries they missed
+func EngineLogStream(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming not supported", http.StatusInternalServerError)
+		return
+	}
+
+	filter, err := parseLogFilter(r, 0)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Subscribe before backfill so no entry is lost in between
+	entries, unsubscribe := engine.SubscribeLogs()
+	defer unsubscribe()
+
+	var backlog []engine.EngineLog
+	if lastID := r.Header.Get("Last-Event-ID"); lastID != "" {
+		if seq, err := strconv.ParseUint(lastID, 10, 64); err == nil {
+			backlog = engine.LogsAfter(engine.LogFilter{
+				Levels:    filter.Levels,
+				Subsystem: filter.Subsystem,
+				JobID:     filter.JobID,
+				PageID:    filter.PageID,
+			}, seq)
+		}
+	} else if filter.Limit > 0 {
+		backlog = engine.LogsAfter(filter, 0)
+	}
+	filter.Limit = 0
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no") // nginx: don't buffer the stream
+	w.WriteHeader(http.StatusOK)
+
+	var lastSeq uint64
+	for _, entry := range backlog {
+		if err := writeLogEvent(w, entry); err != nil {
+			return
+		}
+		lastSeq = entry.Seq
+	}
+	flusher.Flush()
+
+	heartbeat := time.NewTicker(15 * time.Second)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case entry, open := <-entries:
+			if !open {
+				return // server shutting down
+			}
+			if entry.Seq <= lastSeq || !filter.Matches(entry) {
+				continue
+			}
+			if err := writeLogEvent(w, entry); err != nil {
+				return
+			}
+			flusher.Flush()
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(w, ": ping\n\n"); err != nil {
+				return
+			}
+			flusher.Flush()
+		}
+	}
+}
+
+// writeLogEvent writes one SSE "log" event
+func writeLogEvent(w http.ResponseWriter, entry engine.EngineLog) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "id: %d\nevent: log\ndata: %s\n\n", entry.Seq, data)
+	return err
+}
+
+// parseLogFilter reads level, subsystem, jobId, pageId, since, until and limit (max 1000 = backfill for the stream)
+func parseLogFilter(r *http.Request, defaultLimit int) (engine.LogFilter, error) {
+	q := r.URL.Query()
+	filter := engine.LogFilter{
+		Subsystem: q.Get("subsystem"),
+		JobID:     q.Get("jobId"),
+		PageID:    q.Get("pageId"),
+		Limit:     defaultLimit,
+	}
+
+	if levels := q.Get("level"); levels != "" {
+		for _, level := range strings.Split(levels, ",") {
+			if level = strings.TrimSpace(level); level != "" {
+				filter.Levels = append(filter.Levels, strings.ToUpper(level))
+			}
+		}
+	}
+
+	limitParam := q.Get("limit")
+	if b := q.Get("backfill"); b != "" {
+		limitParam = b
+	}
+	if limitParam != "" {
+		parsed, err := strconv.Atoi(limitParam)
+		if err != nil || parsed < 0 {
+			return filter, fmt.Errorf("invalid limit: %s", limitParam)
+		}
+		filter.Limit = parsed
+	}
+	if filter.Limit > 1000 {
+		filter.Limit = 1000
+	}
+
+	if s := q.Get("since"); s != "" {
+		t, _, err := parseFilterDate(s)
+		if err != nil {
+			return filter, fmt.Errorf("invalid since: %s", s)
+		}
+		filter.Since = t
+	}
+
+	if u := q.Get("until"); u != "" {
+		t, dateOnly, err := parseFilterDate(u)
+		if err != nil {
+			return filter, fmt.Errorf("invalid until: %s", u)
+		}
+		if dateOnly {
+			t = t.Add(24 * time.Hour)
+		}
+		filter.Until = t
+	}
+
+	return filter, nil
+}
@@ -2,6 +2,7 @@ package api
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 	"strings"
 
@@ -16,6 +17,7 @@ type RunJobRequest struct {
 
 // HandleJobRun handles POST /api/jobs/{id}/run - Manual run only
 // STEP 18B-1: Manual run endpoint with idempotency check
+// Also handles POST /api/jobs/{id}/cancel
 func HandleJobRun(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
 		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
@@ -26,15 +28,15 @@ func HandleJobRun(w http.ResponseWriter, r *http.Request) {
 	path := r.URL.Path
 	parts := strings.Split(strings.Trim(path, "/"), "/")
 	
-	var jobID string
+	var jobID, action string
 	for i, part := range parts {
 		if part == "jobs" && i+1 < len(parts) {
 			jobID = parts[i+1]
-			// Check if next part is "run" (optional)
-			if i+2 < len(parts) && parts[i+2] == "run" {
-				break
+			// Check if next part is an action ("run" or "cancel", optional)
+			if i+2 < len(parts) {
+				action = parts[i+2]
 			}
-			// If no "run", the ID is the next part
+			// If no action, the ID is the next part
 			break
 		}
 	}
@@ -44,6 +46,11 @@ func HandleJobRun(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if action == "cancel" {
+		handleJobCancel(w, jobID)
+		return
+	}
+
 	// STEP 18B-1: Find job by ID
 	job := jobs.FindByID(jobID)
 	if job == nil {
@@ -74,6 +81,31 @@ func HandleJobRun(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// handleJobCancel cancels a RUNNING job (POST /api/jobs/{id}/cancel)
+func handleJobCancel(w http.ResponseWriter, jobID string) {
+	job := jobs.FindByID(jobID)
+	if job == nil {
+		http.Error(w, "job not found", http.StatusNotFound)
+		return
+	}
+
+	if err := jobs.Cancel(jobID); err != nil {
+		if errors.Is(err, jobs.ErrJobNotRunning) {
+			http.Error(w, "job is not running", http.StatusConflict)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{
+		"status":  "accepted",
+		"jobId":   jobID,
+		"message": "Job cancellation requested",
+	})
+}
+
 // RunEngineJob handles old format POST /engines/jobs/run (backward compatibility)
 func RunEngineJob(w http.ResponseWriter, r *http.Request) {
 	// Old format (body-based)
@@ -17,7 +17,11 @@ func init() {
 
 // V2Generate handles POST /api/v2/generate
 func V2Generate(w http.ResponseWriter, r *http.Request) {
-	v2Handler.HandleGenerate(w, r)
+	// FASE D - D2: Daily AI budget (POST only; method errors stay with the v2 handler)
+	if r.Method == http.MethodPost && rejectOverBudget(w, r) {
+		return
+	}
+	v2Handler.HandleGenerate(w, r.WithContext(usageContext(r)))
 }
 
 // V2Content handles GET /api/v2/content/:pageId/:version, /api/v2/content/:pageId/versions, /api/v2/content/:pageId/latest
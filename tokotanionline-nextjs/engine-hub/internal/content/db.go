@@ -1,50 +1,75 @@
 package content
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"os"
+	"sync"
 
 	_ "github.com/lib/pq" // PostgreSQL driver
 )
 
-var db *sql.DB
+var (
+	dbMu   sync.RWMutex // InitDBContext swaps the connection while pollers, heartbeat & reaper read it
+	dbConn *sql.DB
+)
 
 // InitDB initializes the database connection
 func InitDB() error {
+	return InitDBContext(context.Background())
+}
+
+// InitDBContext initializes the database connection, bounded by ctx
+// An already open, reachable connection is reused (engine restart via /api/engines/control)
+func InitDBContext(ctx context.Context) error {
+	if current := GetDB(); current != nil && current.PingContext(ctx) == nil {
+		return nil
+	}
+
 	// Get DATABASE_URL from environment or use default
 	dbURL := os.Getenv("DATABASE_URL")
 	if dbURL == "" {
 		return fmt.Errorf("DATABASE_URL environment variable is not set")
 	}
 
-	var err error
-	db, err = sql.Open("postgres", dbURL)
+	conn, err := sql.Open("postgres", dbURL)
 	if err != nil {
 		return fmt.Errorf("failed to open database: %w", err)
 	}
 
 	// Test connection
-	if err := db.Ping(); err != nil {
+	if err := conn.PingContext(ctx); err != nil {
+		conn.Close()
 		return fmt.Errorf("failed to ping database: %w", err)
 	}
 
 	// Set connection pool settings
-	db.SetMaxOpenConns(25)
-	db.SetMaxIdleConns(5)
+	conn.SetMaxOpenConns(25)
+	conn.SetMaxIdleConns(5)
+
+	dbMu.Lock()
+	old := dbConn
+	dbConn = conn
+	dbMu.Unlock()
+	if old != nil {
+		old.Close()
+	}
 
 	return nil
 }
 
 // GetDB returns the database connection
 func GetDB() *sql.DB {
-	return db
+	dbMu.RLock()
+	defer dbMu.RUnlock()
+	return dbConn
 }
 
 // CloseDB closes the database connection
 func CloseDB() error {
-	if db != nil {
-		return db.Close()
+	if conn := GetDB(); conn != nil {
+		return conn.Close()
 	}
 	return nil
 }
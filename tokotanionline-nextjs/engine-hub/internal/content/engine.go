@@ -1,17 +1,28 @@
 package content
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"log"
+	"sync"
 	"time"
 
 	v2 "engine-hub/internal/ai/v2"
+	"engine-hub/internal/engine"
 )
 
 // ContentEngine handles content generation jobs
+// Runs a pool of workers sharing PollJob (FOR UPDATE SKIP LOCKED) with per-JobType concurrency caps
 type ContentEngine struct {
+	mu      sync.Mutex
 	running bool
 	stopCh  chan struct{}
+	wg      sync.WaitGroup // worker, heartbeat & reaper goroutines (in-flight jobs are drained on Stop)
+	workers int
+	slots   *jobTypeSlots
+	flight  *inFlightJobs // jobs being processed (lease renewal)
+	devMode bool          // started without database (no job queue)
 }
 
 // NewContentEngine creates a new ContentEngine instance
@@ -19,19 +30,36 @@ func NewContentEngine() *ContentEngine {
 	return &ContentEngine{
 		running: false,
 		stopCh:  make(chan struct{}),
+		workers: loadWorkerCount(),
+		slots:   newJobTypeSlots(),
+		flight:  newInFlightJobs(),
 	}
 }
 
-// Start starts the content engine worker loop
-func (e *ContentEngine) Start() error {
+// Name returns the engine registry name
+func (e *ContentEngine) Name() string {
+	return "content"
+}
+
+// Start starts the content engine worker pool
+func (e *ContentEngine) Start(ctx context.Context) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
 	if e.running {
 		return fmt.Errorf("content engine is already running")
 	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	e.stopCh = make(chan struct{})
+	e.devMode = false
 
 	// Initialize database connection
 	// DEV MODE: Allow engine to start without database for AI pipeline testing
 	// AI pipeline (/api/engine/ai/generate) does not require database
-	if err := InitDB(); err != nil {
+	if err := InitDBContext(ctx); err != nil {
 		log.Printf("[CONTENT-ENGINE] WARNING: Failed to initialize database: %v", err)
 		log.Println("[CONTENT-ENGINE] Running in DEV MODE (memory-only, no job queue)")
 		log.Println("[CONTENT-ENGINE] AI pipeline endpoint will work without database")
@@ -39,170 +67,385 @@ func (e *ContentEngine) Start() error {
 		// Don't return error - allow engine to start in dev mode
 		// AI pipeline doesn't need database
 		e.running = true
+		e.devMode = true
 		log.Println("[CONTENT-ENGINE] Content Engine started (DEV MODE - no database)")
 		return nil // Success - running in dev mode
 	}
 
 	e.running = true
-	log.Println("[CONTENT-ENGINE] Content Engine started (with database)")
+	log.Printf("[CONTENT-ENGINE] Content Engine started (with database, workers=%d, limits=%v)", e.workers, e.slots.limits())
+
+	// Heartbeat runs on its own goroutine so long jobs never delay it
+	e.wg.Add(1)
+	go e.heartbeatLoop()
 
-	// Start worker loop only if database is available
-	go e.workerLoop()
+	// Reaper reclaims RUNNING jobs whose lease expired
+	e.wg.Add(1)
+	go e.reaperLoop()
+
+	// Start worker pool only if database is available
+	for i := 1; i <= e.workers; i++ {
+		e.wg.Add(1)
+		go e.workerLoop(i)
+	}
 
 	return nil
 }
 
 // Stop stops the content engine
+// Blocks until in-flight jobs have finished (graceful drain)
 func (e *ContentEngine) Stop() error {
+	e.mu.Lock()
 	if !e.running {
+		e.mu.Unlock()
 		return fmt.Errorf("content engine is not running")
 	}
 
 	close(e.stopCh)
 	e.running = false
+	e.mu.Unlock()
+
+	log.Println("[CONTENT-ENGINE] Stopping - waiting for in-flight jobs to finish...")
+	e.wg.Wait()
 	log.Println("[CONTENT-ENGINE] Content Engine stopped")
 
 	return nil
 }
 
-// workerLoop continuously polls for jobs and processes them
-func (e *ContentEngine) workerLoop() {
-	// Check if database is available before starting worker loop
-	if GetDB() == nil {
-		log.Println("[CONTENT-ENGINE] Skipping worker loop (no database in DEV MODE)")
-		return
-	}
+// heartbeatLoop sends the engine heartbeat every 15 seconds and renews job leases
+func (e *ContentEngine) heartbeatLoop() {
+	defer e.wg.Done()
 
-	log.Println("[CONTENT-ENGINE] Starting worker loop...")
-	
 	// Send initial heartbeat
 	if err := UpdateHeartbeat(); err != nil {
 		log.Printf("[CONTENT-ENGINE] Failed to send initial heartbeat: %v", err)
 	}
-	
+
 	// Heartbeat ticker (every 15 seconds)
 	heartbeatTicker := time.NewTicker(15 * time.Second)
 	defer heartbeatTicker.Stop()
-	
-	// Job polling ticker (every 5 seconds)
-	jobTicker := time.NewTicker(5 * time.Second)
-	defer jobTicker.Stop()
 
 	for {
 		select {
 		case <-e.stopCh:
-			log.Println("[CONTENT-ENGINE] Worker loop stopped")
 			return
 		case <-heartbeatTicker.C:
 			// UI-B: Send heartbeat periodically
 			if err := UpdateHeartbeat(); err != nil {
 				log.Printf("[CONTENT-ENGINE] Failed to update heartbeat: %v", err)
 			}
-		case <-jobTicker.C:
-			// FASE D - D3: KILL-SWITCH CHECK (WAJIB - dibaca SETIAP sebelum job start)
-			if isSafeModeEnabled() {
-				log.Println("[CONTENT-ENGINE] SAFE_MODE enabled - skipping job processing")
-				continue
+			// Keep leases of in-flight jobs alive
+			if err := RenewLeases(e.flight.list()); err != nil {
+				log.Printf("[CONTENT-ENGINE] Failed to renew job leases: %v", err)
 			}
+		}
+	}
+}
 
-			// UI-B4: Check if engine is paused
-			paused, err := IsEnginePaused()
-			if err != nil {
-				log.Printf("[CONTENT-ENGINE] Failed to check pause status: %v", err)
-				// Continue processing if we can't check (fail open)
-			} else if paused {
-				log.Println("[CONTENT-ENGINE] Engine is paused - skipping job processing")
-				continue
-			}
+// reaperLoop reclaims expired RUNNING jobs every 30 seconds
+func (e *ContentEngine) reaperLoop() {
+	defer e.wg.Done()
 
-			// Poll for pending job
-			job, err := PollJob()
+	reaperTicker := time.NewTicker(30 * time.Second)
+	defer reaperTicker.Stop()
+
+	for {
+		select {
+		case <-e.stopCh:
+			return
+		case <-reaperTicker.C:
+			reclaimed, err := ReapExpiredJobs(getRateGuard().MaxAttempts())
 			if err != nil {
-				log.Printf("[CONTENT-ENGINE] Error polling job: %v", err)
-				continue
+				log.Printf("[REAPER] Failed to reap expired jobs: %v", err)
+			} else if reclaimed > 0 {
+				log.Printf("[REAPER] Reclaimed %d expired job(s)", reclaimed)
 			}
+		}
+	}
+}
 
-			if job == nil {
-				// No pending jobs, continue
-				continue
-			}
+// workerLoop continuously polls for jobs and processes them
+func (e *ContentEngine) workerLoop(workerID int) {
+	defer e.wg.Done()
 
-			// FASE D - D5: OBSERVABILITY - Log job start
-			log.Printf("[JOB-START] jobId=%s type=%s", job.ID, job.Type)
+	log.Printf("[CONTENT-ENGINE] Starting worker %d...", workerID)
 
-			// Process job
-			result, err := ProcessJob(job)
-			
-			// FASE D - D2: Classify error type (INFRA vs CONTENT)
-			isInfraErr := false
-			if err != nil {
-				isInfraErr = isInfrastructureError(err)
-				
-				// FASE D - D5: OBSERVABILITY - Log job fail with classification
-				if isInfraErr {
-					log.Printf("[JOB-FAIL] jobId=%s reason=INFRA error=%v", job.ID, err)
-				} else {
-					log.Printf("[JOB-FAIL] jobId=%s reason=CONTENT error=%v", job.ID, err)
-				}
-
-				// Create error result
-				if result == nil {
-					result = &ProcessResult{}
-				}
-				result.Error = err
-			}
+	// Job polling ticker (every 5 seconds)
+	jobTicker := time.NewTicker(5 * time.Second)
+	defer jobTicker.Stop()
 
-			// FASE D - D2: Rate guard - check if should retry infra errors
-			rateGuard := getRateGuard()
-			if err != nil && isInfraErr {
-				if rateGuard.ShouldRetry(job.ID, true) {
-					// Schedule retry with backoff
-					backoffDelay := rateGuard.GetBackoffDelay(job.ID)
-					log.Printf("[RATE-GUARD] Job %s will retry after %v", job.ID, backoffDelay)
-					// TODO: Implement retry scheduling (could update job status back to PENDING with new scheduledAt)
-					// For now, we'll mark as failed but log the retry decision
-				} else {
-					log.Printf("[RATE-GUARD] Job %s exceeded retry limit - marking as failed", job.ID)
-				}
-			} else if err == nil {
-				// Success - clear retry count
-				rateGuard.ClearRetryCount(job.ID)
-			}
+	for {
+		select {
+		case <-e.stopCh:
+			log.Printf("[CONTENT-ENGINE] Worker %d stopped", workerID)
+			return
+		case <-jobTicker.C:
+			e.pollAndProcess(workerID)
+		}
+	}
+}
 
-			// Write result
-			blogPostID, err := WriteResult(job, result)
-			if err != nil {
-				log.Printf("[CONTENT-ENGINE] Error writing result for job %s: %v", job.ID, err)
-				// Job will remain in RUNNING status (will need manual intervention)
+// pollAndProcess polls one job within the per-type caps and processes it
+func (e *ContentEngine) pollAndProcess(workerID int) {
+	// FASE D - D3: KILL-SWITCH CHECK (WAJIB - dibaca SETIAP sebelum job start)
+	if isSafeModeEnabled() {
+		log.Println("[CONTENT-ENGINE] SAFE_MODE enabled - skipping job processing")
+		return
+	}
+
+	// UI-B4: Check if engine is paused
+	paused, err := IsEnginePaused()
+	if err != nil {
+		log.Printf("[CONTENT-ENGINE] Failed to check pause status: %v", err)
+		// Continue processing if we can't check (fail open)
+	} else if paused {
+		log.Println("[CONTENT-ENGINE] Engine is paused - skipping job processing")
+		return
+	}
+
+	// Reserve a slot for every type with free capacity, poll only those types
+	reserved := e.slots.reserveAvailable()
+	if len(reserved) == 0 {
+		return // All types at capacity
+	}
+
+	// FASE D - D2: Daily budget reached → GENERATE jobs stay PENDING (REFRESH/OPTIMIZE continue)
+	if exceeded, _ := getRateGuard().CheckBudget(time.Now()); exceeded {
+		allowed := reserved[:0]
+		for _, jobType := range reserved {
+			if jobType == JobTypeGenerate {
+				e.slots.release(jobType)
 				continue
 			}
+			allowed = append(allowed, jobType)
+		}
+		reserved = allowed
+		if len(reserved) == 0 {
+			return
+		}
+	}
 
-			// PHASE B: Emit POST_GENERATION_COMPLETE event after blog content and images are saved
-			// This happens after WriteResult succeeds and transaction is committed
-			if blogPostID != "" && result.Error == nil {
-				emitter := v2.GetEventEmitter()
-				emitter.Emit(v2.EventPostGenerationComplete, v2.EventPayload{
-					PageID:   blogPostID,
-					PageType: "blog",
-					Data: map[string]interface{}{
-						"entity":   "blog",
-						"entity_id": blogPostID,
-					},
-				})
-				log.Printf("[CONTENT-ENGINE] POST_GENERATION_COMPLETE event emitted for blog ID: %s", blogPostID)
-			}
+	job, err := PollJobOfTypes(reserved)
+
+	// Keep the slot of the polled type, release the rest
+	for _, jobType := range reserved {
+		if job == nil || jobType != job.Type {
+			e.slots.release(jobType)
+		}
+	}
+
+	if err != nil {
+		log.Printf("[CONTENT-ENGINE] Error polling job: %v", err)
+		return
+	}
+
+	if job == nil {
+		// No pending jobs
+		return
+	}
+	defer e.slots.release(job.Type)
 
-			// FASE D - D5: OBSERVABILITY - Log job success
-			if result.Error != nil {
-				// Already logged above as JOB-FAIL
-			} else {
-				log.Printf("[JOB-SUCCESS] jobId=%s", job.ID)
+	e.flight.add(job.ID)
+	defer e.flight.remove(job.ID)
+
+	e.processJob(context.Background(), workerID, job)
+}
+
+// RunJob runs one ContentJob through the job runner (jobs.Run / RunJobByID), which owns the timeout and cancel
+// jobID is the ContentJob id; empty takes the oldest PENDING job. Per-type caps do not apply to manual runs
+func (e *ContentEngine) RunJob(ctx context.Context, jobID string) (string, error) {
+	// Counted in wg (under mu, like running) so Stop drains manual runs too
+	e.mu.Lock()
+	if !e.running {
+		e.mu.Unlock()
+		return "", fmt.Errorf("%s engine is not running", e.Name())
+	}
+	if e.devMode {
+		e.mu.Unlock()
+		return "", fmt.Errorf("%s engine is in DEV MODE - no job queue", e.Name())
+	}
+	e.wg.Add(1)
+	e.mu.Unlock()
+	defer e.wg.Done()
+
+	if isSafeModeEnabled() {
+		return "", fmt.Errorf("SAFE_MODE enabled - content jobs are disabled")
+	}
+
+	select {
+	case <-ctx.Done():
+		return "", context.Cause(ctx)
+	default:
+	}
+
+	var job *ContentJob
+	var err error
+	if jobID == "" {
+		job, err = PollJob()
+	} else {
+		job, err = ClaimJob(jobID)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to claim content job: %w", err)
+	}
+	if job == nil {
+		if jobID == "" {
+			return "no PENDING content job", nil
+		}
+		return "", fmt.Errorf("content job %s is not PENDING", jobID)
+	}
+
+	e.flight.add(job.ID)
+	defer e.flight.remove(job.ID)
+
+	blogPostID, err := e.processJob(ctx, 0, job)
+	if err != nil {
+		return "", fmt.Errorf("content job %s (%s): %w", job.ID, job.Type, err)
+	}
+	return fmt.Sprintf("content job %s (%s) done: post %s", job.ID, job.Type, blogPostID), nil
+}
+
+// processJob runs a claimed job and writes its result
+// Returns the written BlogPost id, or the job error (also when a retry was scheduled) - worker 0 is a manual run
+func (e *ContentEngine) processJob(ctx context.Context, workerID int, job *ContentJob) (string, error) {
+	// FASE D - D5: OBSERVABILITY - Log job start
+	log.Printf("[JOB-START] jobId=%s type=%s worker=%d attempt=%d", job.ID, job.Type, workerID, job.Attempts)
+
+	// Process job
+	result, err := ProcessJob(ctx, job)
+
+	// FASE D - D2: Classify error type (INFRA vs CONTENT)
+	isInfraErr := false
+	if err != nil {
+		isInfraErr = isInfrastructureError(err)
+
+		// FASE D - D5: OBSERVABILITY - Log job fail with classification
+		if isInfraErr {
+			log.Printf("[JOB-FAIL] jobId=%s reason=INFRA error=%v", job.ID, err)
+		} else {
+			log.Printf("[JOB-FAIL] jobId=%s reason=CONTENT error=%v", job.ID, err)
+		}
+
+		// Create error result
+		if result == nil {
+			result = &ProcessResult{}
+		}
+		result.Error = err
+	}
+
+	// FASE D - D2: Rate guard - check if should retry infra errors
+	// Retry = back to PENDING with future scheduledAt (persisted, survives restarts)
+	if err != nil && isInfraErr {
+		rateGuard := getRateGuard()
+		if rateGuard.ShouldRetry(job, true) {
+			backoffDelay := rateGuard.GetBackoffDelay(job)
+			log.Printf("[RATE-GUARD] Job %s will retry after %v", job.ID, backoffDelay)
+			retryErr := ScheduleRetry(job, err, backoffDelay)
+			if retryErr == nil {
+				return "", fmt.Errorf("%w (retry scheduled in %v)", err, backoffDelay)
 			}
+			log.Printf("[RATE-GUARD] Failed to schedule retry for job %s: %v - marking as failed", job.ID, retryErr)
+		} else {
+			log.Printf("[RATE-GUARD] Job %s exceeded retry limit - marking as failed", job.ID)
 		}
 	}
+
+	// Write result
+	blogPostID, err := WriteResult(job, result)
+	if errors.Is(err, errLeaseLost) {
+		// Reaped or picked up by another instance while processing - its result wins, ours is discarded
+		log.Printf("[CONTENT-ENGINE] Result of job %s discarded: %v", job.ID, err)
+		return "", err
+	}
+	if err != nil {
+		log.Printf("[CONTENT-ENGINE] Error writing result for job %s: %v", job.ID, err)
+		// Job stays RUNNING until its lease expires (no longer renewed once it leaves the worker),
+		// then the reaper reclaims it
+		return "", fmt.Errorf("failed to write result: %w", err)
+	}
+
+	// PHASE B: Emit POST_GENERATION_COMPLETE event after blog content and images are saved
+	// This happens after WriteResult succeeds and transaction is committed
+	// Only for new posts - REFRESH keeps the post's existing secondary keywords
+	if blogPostID != "" && result.Error == nil && job.Type == JobTypeGenerate {
+		emitter := v2.GetEventEmitter()
+		emitter.Emit(v2.EventPostGenerationComplete, v2.EventPayload{
+			PageID:   blogPostID,
+			PageType: "blog",
+			Data: map[string]interface{}{
+				"entity":    "blog",
+				"entity_id": blogPostID,
+			},
+		})
+		log.Printf("[CONTENT-ENGINE] POST_GENERATION_COMPLETE event emitted for blog ID: %s", blogPostID)
+	}
+
+	// FASE D - D5: OBSERVABILITY - Log job success
+	if result.Error != nil {
+		return blogPostID, result.Error
+	}
+	log.Printf("[JOB-SUCCESS] jobId=%s", job.ID)
+	return blogPostID, nil
 }
 
 // IsRunning returns whether the engine is running
 func (e *ContentEngine) IsRunning() bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
 	return e.running
 }
+
+// Status returns the engine status for the engine registry
+func (e *ContentEngine) Status() engine.EngineStatus {
+	if e.IsRunning() {
+		return engine.StatusOn
+	}
+	return engine.StatusOff
+}
+
+// HealthCheck reports database reachability and worker pool config
+func (e *ContentEngine) HealthCheck() engine.HealthReport {
+	e.mu.Lock()
+	running, devMode := e.running, e.devMode
+	e.mu.Unlock()
+
+	report := engine.HealthReport{
+		Status:    e.Status(),
+		Healthy:   running,
+		CheckedAt: time.Now(),
+		Metrics: map[string]interface{}{
+			"workers": e.workers,
+			"limits":  e.slots.limits(),
+		},
+	}
+
+	switch {
+	case !running:
+		report.Detail = "stopped"
+	case devMode:
+		report.Detail = "DEV MODE - no database, job queue disabled"
+	default:
+		if db := GetDB(); db == nil {
+			report.Healthy = false
+			report.Detail = "database connection not initialized"
+		} else if err := db.Ping(); err != nil {
+			report.Healthy = false
+			report.Status = engine.StatusError
+			report.Detail = fmt.Sprintf("database unreachable: %v", err)
+		}
+	}
+
+	return report
+}
+
+// Describe returns the engine metadata
+func (e *ContentEngine) Describe() engine.Descriptor {
+	return engine.Descriptor{
+		Name:        e.Name(),
+		Description: "Content job worker pool (GENERATE / REFRESH / OPTIMIZE), opens the database connection",
+		Config: map[string]string{
+			"CONTENT_WORKERS":       fmt.Sprintf("%d", e.workers),
+			"CONTENT_LEASE_SECONDS": fmt.Sprintf("%d", int(leaseDuration().Seconds())),
+		},
+	}
+}
@@ -16,6 +16,11 @@ func isInfrastructureError(err error) bool {
 
 	errStr := strings.ToLower(err.Error())
 
+	// Schema violations after the repair loop are content failures (see ai/content generateOnce)
+	if strings.Contains(errStr, "structured output invalid") {
+		return false
+	}
+
 	// API key errors
 	if strings.Contains(errStr, "api key") ||
 		strings.Contains(errStr, "authentication") ||
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"strings"
+	"unicode/utf8"
 )
 
 // STEP 1: REMOVED expandKeywords - Keyword TIDAK BOLEH diubah atau di-expand AI
@@ -157,8 +158,8 @@ func generateTitleFromKeyword(keyword string) string {
 func generateExcerptFromContent(content string) string {
 	// Take first 200 characters
 	excerpt := strings.TrimSpace(content)
-	if len(excerpt) > 200 {
-		excerpt = excerpt[:200]
+	if utf8.RuneCountInString(excerpt) > 200 {
+		excerpt = string([]rune(excerpt)[:200])
 		// Try to end at a sentence
 		lastPeriod := strings.LastIndex(excerpt, ".")
 		if lastPeriod > 100 {
@@ -168,6 +169,57 @@ func generateExcerptFromContent(content string) string {
 	return excerpt
 }
 
+// truncateExcerpt cuts an excerpt to maxRunes characters (never inside a multi-byte character)
+func truncateExcerpt(excerpt string, maxRunes int) string {
+	runes := []rune(excerpt)
+	if len(runes) <= maxRunes {
+		return excerpt
+	}
+	return string(runes[:maxRunes]) + "..."
+}
+
+// outlineMarkdown renders a stored outline ([]OutlineSection JSON, or a markdown string) as markdown headings
+func outlineMarkdown(outline json.RawMessage) (string, error) {
+	if len(outline) == 0 || string(outline) == "null" {
+		return "", nil
+	}
+
+	var text string
+	if err := json.Unmarshal(outline, &text); err == nil {
+		return strings.TrimSpace(text), nil
+	}
+
+	var sections []OutlineSection
+	if err := json.Unmarshal(outline, &sections); err != nil {
+		return "", fmt.Errorf("failed to unmarshal outline: %w", err)
+	}
+
+	var md strings.Builder
+	for _, section := range sections {
+		level := section.Level
+		if level < 1 || level > 6 {
+			level = 2
+		}
+		md.WriteString(fmt.Sprintf("%s %s\n\n", strings.Repeat("#", level), section.Title))
+		if section.Content != "" {
+			md.WriteString(section.Content + "\n\n")
+		}
+	}
+	return strings.TrimSpace(md.String()), nil
+}
+
+// refreshOutline prefixes the locked outline with the post's stored keywords (keywords are never expanded)
+func refreshOutline(outline string, primaryKeyword string, secondaryKeywords []string) string {
+	var out strings.Builder
+	out.WriteString(fmt.Sprintf("Keyword Utama: %s\n", primaryKeyword))
+	if len(secondaryKeywords) > 0 {
+		out.WriteString(fmt.Sprintf("Keyword Pendukung: %s\n", strings.Join(secondaryKeywords, ", ")))
+	}
+	out.WriteString("\n")
+	out.WriteString(outline)
+	return out.String()
+}
+
 // generateSlug generates a URL-friendly slug from title
 func generateSlug(title string) string {
 	slug := strings.ToLower(title)
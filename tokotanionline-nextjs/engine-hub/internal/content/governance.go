@@ -1,11 +1,16 @@
 package content
 
 import (
+	"fmt"
 	"log"
 	"os"
 	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
+
+	"engine-hub/internal/ai/usage"
 )
 
 // FASE D - D3: KILL-SWITCH OPERASIONAL (WAJIB)
@@ -29,22 +34,29 @@ func isFeatureFreezeEnabled() bool {
 // Hard cap harian (mis. 5 konten)
 // Cooldown antar job (mis. 30–60 menit)
 // Backoff infra error (exponential, max 2x, lalu STOP)
+// Daily budget (USD) dari AI usage ledger → GENERATE job ditahan setelah cap tercapai
 
 // RateGuard manages rate limiting and quota
+// Retry state lives on the ContentJob row (attempts, errorHistory) so it survives restarts
 type RateGuard struct {
-	dailyQuota    int
-	cooldownMin   int
-	maxRetries    int
-	retryBackoff  map[string]int // jobID -> retry count
+	dailyQuota     int
+	cooldownMin    int
+	maxRetries     int
+	dailyBudgetUSD float64     // 0 = no budget cap
+	budgetHeld     atomic.Bool // last CheckBudget result (log only on change)
 }
 
-var globalRateGuard *RateGuard
+var (
+	globalRateGuard   *RateGuard
+	globalRateGuardOnce sync.Once
+)
 
 // initRateGuard initializes the global rate guard
 func initRateGuard() {
 	quota := 5
 	cooldown := 30
 	maxRetries := 2
+	budget := 0.0
 
 	if q := os.Getenv("PROD_DAILY_QUOTA"); q != "" {
 		if quotaVal, err := strconv.Atoi(q); err == nil && quotaVal > 0 {
@@ -61,20 +73,24 @@ func initRateGuard() {
 			maxRetries = retryVal
 		}
 	}
+	if b := os.Getenv("PROD_DAILY_BUDGET_USD"); b != "" {
+		if budgetVal, err := strconv.ParseFloat(b, 64); err == nil && budgetVal > 0 {
+			budget = budgetVal
+		}
+	}
 
 	globalRateGuard = &RateGuard{
-		dailyQuota:   quota,
-		cooldownMin:  cooldown,
-		maxRetries:   maxRetries,
-		retryBackoff: make(map[string]int),
+		dailyQuota:     quota,
+		cooldownMin:    cooldown,
+		maxRetries:     maxRetries,
+		dailyBudgetUSD: budget,
 	}
 }
 
 // getRateGuard returns the global rate guard
+// Also used by API handlers (budget check), so initialization is guarded
 func getRateGuard() *RateGuard {
-	if globalRateGuard == nil {
-		initRateGuard()
-	}
+	globalRateGuardOnce.Do(initRateGuard)
 	return globalRateGuard
 }
 
@@ -103,6 +119,50 @@ func (rg *RateGuard) CheckQuota(now time.Time) (bool, int) {
 	return exceeded, count
 }
 
+// CheckBudget checks if today's AI spend (usage ledger) reached the daily budget
+// Returns false when no budget is configured or the ledger cannot be read (fail open, like CheckQuota)
+func (rg *RateGuard) CheckBudget(now time.Time) (bool, float64) {
+	if rg.dailyBudgetUSD <= 0 {
+		return false, 0
+	}
+
+	spent, err := usage.SpendSince(usage.StartOfDay(now))
+	if err != nil {
+		log.Printf("[RATE-GUARD] Error checking budget: %v", err)
+		return false, 0
+	}
+
+	exceeded := spent >= rg.dailyBudgetUSD
+	if rg.budgetHeld.Swap(exceeded) != exceeded {
+		if exceeded {
+			log.Printf("[RATE-GUARD] Daily budget reached: spent=$%.4f cap=$%.2f - new GENERATE jobs refused until tomorrow", spent, rg.dailyBudgetUSD)
+		} else {
+			log.Printf("[RATE-GUARD] Daily budget available again: spent=$%.4f cap=$%.2f", spent, rg.dailyBudgetUSD)
+		}
+	}
+	return exceeded, spent
+}
+
+// BudgetStatus is today's AI spend against the daily budget
+type BudgetStatus struct {
+	Enabled  bool    `json:"enabled"`
+	CapUSD   float64 `json:"capUsd"`
+	SpentUSD float64 `json:"spentUsd"`
+	Exceeded bool    `json:"exceeded"`
+}
+
+// CheckDailyBudget returns today's budget status (used by AI API endpoints before generating)
+func CheckDailyBudget(now time.Time) BudgetStatus {
+	rg := getRateGuard()
+	status := BudgetStatus{Enabled: rg.dailyBudgetUSD > 0, CapUSD: rg.dailyBudgetUSD}
+	if !status.Enabled {
+		status.SpentUSD, _ = usage.SpendSince(usage.StartOfDay(now))
+		return status
+	}
+	status.Exceeded, status.SpentUSD = rg.CheckBudget(now)
+	return status
+}
+
 // CheckCooldown checks if we're in cooldown period
 func (rg *RateGuard) CheckCooldown(now time.Time) (bool, time.Time) {
 	db := GetDB()
@@ -130,26 +190,36 @@ func (rg *RateGuard) CheckCooldown(now time.Time) (bool, time.Time) {
 }
 
 // ShouldRetry checks if we should retry after infra error
-func (rg *RateGuard) ShouldRetry(jobID string, isInfraError bool) bool {
+// Based on the persisted attempt counter (first attempt is not a retry)
+func (rg *RateGuard) ShouldRetry(job *ContentJob, isInfraError bool) bool {
 	if !isInfraError {
 		return false // Only retry infra errors
 	}
 
-	count := rg.retryBackoff[jobID]
-	if count >= rg.maxRetries {
-		log.Printf("[RATE-GUARD] Job %s exceeded max retries (%d) - STOP", jobID, rg.maxRetries)
+	retries := job.Attempts - 1
+	if retries >= rg.maxRetries {
+		log.Printf("[RATE-GUARD] Job %s exceeded max retries (%d) - STOP", job.ID, rg.maxRetries)
 		return false
 	}
 
-	rg.retryBackoff[jobID] = count + 1
-	log.Printf("[RATE-GUARD] Job %s retry %d/%d (exponential backoff)", jobID, count+1, rg.maxRetries)
+	log.Printf("[RATE-GUARD] Job %s retry %d/%d (exponential backoff)", job.ID, retries+1, rg.maxRetries)
 	return true
 }
 
-// GetBackoffDelay calculates exponential backoff delay
-func (rg *RateGuard) GetBackoffDelay(jobID string) time.Duration {
-	count := rg.retryBackoff[jobID]
-	if count == 0 {
+// MaxAttempts returns the total number of attempts allowed per job (first run + retries)
+func (rg *RateGuard) MaxAttempts() int {
+	return rg.maxRetries + 1
+}
+
+// GetBackoffDelay calculates exponential backoff delay for the next retry
+func (rg *RateGuard) GetBackoffDelay(job *ContentJob) time.Duration {
+	return RetryBackoff(job.Attempts)
+}
+
+// RetryBackoff is the exponential delay after the given number of attempts (30s, 60s, max 2m)
+// Shared by ContentJob retries and batch production infra retries
+func RetryBackoff(count int) time.Duration {
+	if count <= 0 {
 		return 30 * time.Second
 	}
 	// Exponential: 30s, 60s, 120s
@@ -160,7 +230,14 @@ func (rg *RateGuard) GetBackoffDelay(jobID string) time.Duration {
 	return delay
 }
 
-// ClearRetryCount clears retry count for a job
-func (rg *RateGuard) ClearRetryCount(jobID string) {
-	delete(rg.retryBackoff, jobID)
+// CheckGenerationAllowed is the RateGuard verdict for AI generation outside ContentJob (batch production)
+// Refused while SAFE_MODE is on or the daily AI budget is reached; reason explains why
+func CheckGenerationAllowed(now time.Time) (bool, string) {
+	if isSafeModeEnabled() {
+		return false, "SAFE_MODE enabled"
+	}
+	if exceeded, spent := getRateGuard().CheckBudget(now); exceeded {
+		return false, fmt.Sprintf("daily AI budget reached (spent $%.4f)", spent)
+	}
+	return true, ""
 }
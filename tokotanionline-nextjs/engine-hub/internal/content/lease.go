@@ -0,0 +1,180 @@
+package content
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+
+	"engine-hub/internal/engine"
+)
+
+// JOB LEASE (VISIBILITY TIMEOUT)
+// Setiap job RUNNING punya leaseOwner + leaseExpiresAt
+// Heartbeat memperpanjang lease milik instance ini
+// Reaper mengembalikan job dengan lease kadaluarsa ke PENDING (atau FAILED setelah max attempts)
+// CONTENT_LEASE_SECONDS → durasi lease (default 90 detik)
+
+// instanceID identifies this engine process as lease owner
+var instanceID = newInstanceID()
+
+// errLeaseLost means the job is no longer RUNNING under this instance (reaped, requeued or taken over)
+var errLeaseLost = errors.New("job lease lost")
+
+// requireLease turns a status update that matched no row into errLeaseLost
+func requireLease(res sql.Result, job *ContentJob) error {
+	if rows, _ := res.RowsAffected(); rows == 0 {
+		return fmt.Errorf("%w: job %s is no longer RUNNING under %s", errLeaseLost, job.ID, instanceID)
+	}
+	return nil
+}
+
+// newInstanceID builds a unique lease owner ID (hostname-pid-random)
+func newInstanceID() string {
+	host, err := os.Hostname()
+	if err != nil || host == "" {
+		host = "engine"
+	}
+	return fmt.Sprintf("%s-%d-%s", host, os.Getpid(), uuid.New().String()[:8])
+}
+
+// leaseDuration reads CONTENT_LEASE_SECONDS from env
+func leaseDuration() time.Duration {
+	seconds := 90
+	if v := os.Getenv("CONTENT_LEASE_SECONDS"); v != "" {
+		if val, err := strconv.Atoi(v); err == nil && val > 0 {
+			seconds = val
+		}
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// RenewLeases extends the lease of the given in-flight jobs owned by this instance
+// Called from the heartbeat loop; only jobs a worker is still processing are renewed, so a job
+// left RUNNING after a failed WriteResult expires and is reclaimed by the reaper
+func RenewLeases(jobIDs []string) error {
+	if len(jobIDs) == 0 {
+		return nil
+	}
+	db := GetDB()
+	if db == nil {
+		return fmt.Errorf("database connection not initialized")
+	}
+
+	query := `
+		UPDATE "ContentJob"
+		SET "leaseExpiresAt" = $1
+		WHERE status = $2 AND "leaseOwner" = $3 AND id = ANY($4)
+	`
+	_, err := db.Exec(query, time.Now().Add(leaseDuration()), string(JobStatusRunning), instanceID, pq.StringArray(jobIDs))
+	if err != nil {
+		return fmt.Errorf("failed to renew job leases: %w", err)
+	}
+
+	return nil
+}
+
+// ReapExpiredJobs reclaims RUNNING jobs whose lease expired (engine crashed between PollJob and WriteResult)
+// Jobs go back to PENDING, or FAILED once maxAttempts is reached
+// Rows without lease (created before leases existed) are reclaimed when startedAt is older than the lease duration
+func ReapExpiredJobs(maxAttempts int) (int, error) {
+	db := GetDB()
+	if db == nil {
+		return 0, fmt.Errorf("database connection not initialized")
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback() // Rollback if not committed
+
+	now := time.Now()
+
+	query := `
+		SELECT id, type, attempts, "errorHistory", "leaseOwner"
+		FROM "ContentJob"
+		WHERE status = $1
+		  AND (
+		    "leaseExpiresAt" < $2
+		    OR ("leaseExpiresAt" IS NULL AND "startedAt" < $3)
+		  )
+		FOR UPDATE SKIP LOCKED
+	`
+	rows, err := tx.Query(query, string(JobStatusRunning), now, now.Add(-leaseDuration()))
+	if err != nil {
+		return 0, fmt.Errorf("failed to query expired jobs: %w", err)
+	}
+
+	type expiredJob struct {
+		job   ContentJob
+		owner string
+	}
+	var expired []expiredJob
+	for rows.Next() {
+		var ej expiredJob
+		var errorHistory, owner sql.NullString
+		if err := rows.Scan(&ej.job.ID, &ej.job.Type, &ej.job.Attempts, &errorHistory, &owner); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("failed to scan expired job: %w", err)
+		}
+		if errorHistory.Valid {
+			ej.job.ErrorHistory = json.RawMessage(errorHistory.String)
+		}
+		ej.owner = owner.String
+		expired = append(expired, ej)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("failed to iterate expired jobs: %w", err)
+	}
+
+	for _, ej := range expired {
+		job := &ej.job
+		reason := fmt.Sprintf("lease expired (owner=%s)", ej.owner)
+		history := appendErrorEntry(job, "LEASE", reason, now)
+
+		var message string
+		if job.Attempts >= maxAttempts {
+			_, err = tx.Exec(`
+				UPDATE "ContentJob"
+				SET status = $1, "finishedAt" = $2, error = $3, "errorHistory" = $4,
+				    "leaseOwner" = NULL, "leaseExpiresAt" = NULL
+				WHERE id = $5
+			`, string(JobStatusFailed), now, reason, string(history), job.ID)
+			message = fmt.Sprintf("content-engine: reaper marked job FAILED after %d attempts (%s)", job.Attempts, reason)
+		} else {
+			_, err = tx.Exec(`
+				UPDATE "ContentJob"
+				SET status = $1, "startedAt" = NULL, "scheduledAt" = $2, error = $3, "errorHistory" = $4,
+				    "leaseOwner" = NULL, "leaseExpiresAt" = NULL
+				WHERE id = $5
+			`, string(JobStatusPending), now, reason, string(history), job.ID)
+			message = fmt.Sprintf("content-engine: reaper returned job to PENDING (attempt %d/%d, %s)", job.Attempts, maxAttempts, reason)
+		}
+		if err != nil {
+			return 0, fmt.Errorf("failed to reclaim job %s: %w", job.ID, err)
+		}
+
+		log.Printf("[REAPER] jobId=%s type=%s %s", job.ID, job.Type, message)
+		engine.AddLog(engine.EngineLog{
+			Level:     "WARN",
+			Message:   message,
+			JobID:     job.ID,
+			Timestamp: now,
+		})
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return len(expired), nil
+}
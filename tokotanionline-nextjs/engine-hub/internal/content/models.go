@@ -36,15 +36,25 @@ const (
 
 // ContentJob represents the ContentJob model from Prisma
 type ContentJob struct {
-	ID          string          `json:"id"`
-	Type        JobType         `json:"type"`
-	Status      JobStatus       `json:"status"`
-	RequestedBy string          `json:"requestedBy"`
-	ScheduledAt *time.Time      `json:"scheduledAt,omitempty"`
-	StartedAt   *time.Time      `json:"startedAt,omitempty"`
-	FinishedAt  *time.Time      `json:"finishedAt,omitempty"`
-	Params      json.RawMessage `json:"params,omitempty"`
-	CreatedAt   time.Time       `json:"createdAt"`
+	ID           string          `json:"id"`
+	Type         JobType         `json:"type"`
+	Status       JobStatus       `json:"status"`
+	RequestedBy  string          `json:"requestedBy"`
+	ScheduledAt  *time.Time      `json:"scheduledAt,omitempty"`
+	StartedAt    *time.Time      `json:"startedAt,omitempty"`
+	FinishedAt   *time.Time      `json:"finishedAt,omitempty"`
+	Params       json.RawMessage `json:"params,omitempty"`
+	Attempts     int             `json:"attempts"`
+	ErrorHistory json.RawMessage `json:"errorHistory,omitempty"`
+	CreatedAt    time.Time       `json:"createdAt"`
+}
+
+// JobErrorEntry is one entry of ContentJob.errorHistory
+type JobErrorEntry struct {
+	Attempt int       `json:"attempt"`
+	Class   string    `json:"class"` // INFRA | CONTENT | LEASE
+	Error   string    `json:"error"`
+	At      time.Time `json:"at"`
 }
 
 // BlogPost represents the BlogPost model from Prisma
@@ -87,6 +97,20 @@ type GenerateParams struct {
 	WordCount        *int     `json:"wordCount,omitempty"`
 }
 
+// RefreshParams represents parameters for REFRESH job type
+type RefreshParams struct {
+	PostID      string `json:"postId"`                // Existing BlogPost to refresh (REQUIRED)
+	ContentType string `json:"contentType,omitempty"` // CORNERSTONE | DERIVATIVE | DERIVATIVE_LONG | USE_CASE (default DERIVATIVE)
+	Category    string `json:"category,omitempty"`    // K1 - K4 (default K1)
+	Language    string `json:"language,omitempty"`    // default id-ID
+	Brand       string `json:"brand,omitempty"`       // compliance rule pack scope
+}
+
+// OptimizeParams represents parameters for OPTIMIZE job type
+type OptimizeParams struct {
+	PostID string `json:"postId"` // Existing BlogPost to optimize (REQUIRED)
+}
+
 // Scan implements the sql.Scanner interface for JobType
 func (j *JobType) Scan(value interface{}) error {
 	if value == nil {
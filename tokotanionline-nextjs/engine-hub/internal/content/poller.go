@@ -5,12 +5,31 @@ import (
 	"encoding/json"
 	"fmt"
 	"time"
+
+	"github.com/lib/pq"
 )
 
 // PollJob gets 1 PENDING job and locks it via transaction
-// Sets status to RUNNING and startedAt
-// Skips jobs where scheduledAt is in the future
+// Sets status to RUNNING and startedAt, increments attempts and takes the job lease
+// Skips jobs where scheduledAt is in the future (includes jobs waiting for a retry backoff)
 func PollJob() (*ContentJob, error) {
+	return PollJobOfTypes(nil)
+}
+
+// PollJobOfTypes works like PollJob but only picks jobs of the given types
+// A nil/empty types list means any type (used by workers with per-type concurrency caps)
+func PollJobOfTypes(types []JobType) (*ContentJob, error) {
+	return claimJob(types, "")
+}
+
+// ClaimJob takes one specific PENDING job (manual run through the job runner)
+// scheduledAt is ignored: an explicit run starts the job now. Returns nil when the job is not PENDING
+func ClaimJob(jobID string) (*ContentJob, error) {
+	return claimJob(nil, jobID)
+}
+
+// claimJob locks and leases the oldest matching PENDING job (jobID "" = any job)
+func claimJob(types []JobType, jobID string) (*ContentJob, error) {
 	db := GetDB()
 	if db == nil {
 		return nil, fmt.Errorf("database connection not initialized")
@@ -29,10 +48,12 @@ func PollJob() (*ContentJob, error) {
 	// FOR UPDATE SKIP LOCKED ensures only one worker gets the job
 	query := `
 		SELECT id, type, status, "requestedBy", "scheduledAt", "startedAt", 
-		       "finishedAt", params, "createdAt"
+		       "finishedAt", params, attempts, "errorHistory", "createdAt"
 		FROM "ContentJob"
 		WHERE status = $1
-		  AND ("scheduledAt" IS NULL OR "scheduledAt" <= $2)
+		  AND ($4::text <> '' OR "scheduledAt" IS NULL OR "scheduledAt" <= $2)
+		  AND (cardinality($3::text[]) = 0 OR type::text = ANY($3::text[]))
+		  AND ($4::text = '' OR id = $4::text)
 		ORDER BY "createdAt" ASC
 		LIMIT 1
 		FOR UPDATE SKIP LOCKED
@@ -40,9 +61,14 @@ func PollJob() (*ContentJob, error) {
 
 	var job ContentJob
 	var scheduledAt, startedAt, finishedAt sql.NullTime
-	var params sql.NullString
+	var params, errorHistory sql.NullString
 
-	err = tx.QueryRow(query, string(JobStatusPending), now).Scan(
+	typeFilter := make(pq.StringArray, 0, len(types))
+	for _, t := range types {
+		typeFilter = append(typeFilter, string(t))
+	}
+
+	err = tx.QueryRow(query, string(JobStatusPending), now, typeFilter, jobID).Scan(
 		&job.ID,
 		&job.Type,
 		&job.Status,
@@ -51,6 +77,8 @@ func PollJob() (*ContentJob, error) {
 		&startedAt,
 		&finishedAt,
 		&params,
+		&job.Attempts,
+		&errorHistory,
 		&job.CreatedAt,
 	)
 
@@ -76,15 +104,19 @@ func PollJob() (*ContentJob, error) {
 	if params.Valid {
 		job.Params = json.RawMessage(params.String)
 	}
+	if errorHistory.Valid {
+		job.ErrorHistory = json.RawMessage(errorHistory.String)
+	}
 
-	// Update status to RUNNING and set startedAt
+	// Update status to RUNNING, set startedAt, count the attempt and take the lease
 	updateQuery := `
 		UPDATE "ContentJob"
-		SET status = $1, "startedAt" = $2
+		SET status = $1, "startedAt" = $2, attempts = attempts + 1,
+		    "leaseOwner" = $4, "leaseExpiresAt" = $5
 		WHERE id = $3
 	`
 
-	_, err = tx.Exec(updateQuery, string(JobStatusRunning), now, job.ID)
+	_, err = tx.Exec(updateQuery, string(JobStatusRunning), now, job.ID, instanceID, now.Add(leaseDuration()))
 	if err != nil {
 		tx.Rollback()
 		return nil, fmt.Errorf("failed to update job status: %w", err)
@@ -95,9 +127,10 @@ func PollJob() (*ContentJob, error) {
 		return nil, fmt.Errorf("failed to commit transaction: %w", err)
 	}
 
-	// Update job struct with new status and startedAt
+	// Update job struct with new status, startedAt and attempts
 	job.Status = JobStatusRunning
 	job.StartedAt = &now
+	job.Attempts++
 
 	return &job, nil
 }
@@ -0,0 +1,120 @@
+package content
+
+import (
+	"os"
+	"strconv"
+	"sync"
+)
+
+// WORKER POOL CONFIG
+// CONTENT_WORKERS          → jumlah worker yang berbagi PollJob (default 1)
+// CONTENT_MAX_GENERATE     → max GENERATE job berjalan bersamaan (default 1)
+// CONTENT_MAX_REFRESH      → max REFRESH job berjalan bersamaan (default 1)
+// CONTENT_MAX_OPTIMIZE     → max OPTIMIZE job berjalan bersamaan (default 4)
+// Default 1 worker menjaga aturan FASE D - D1 (max concurrency = 1)
+
+// jobTypeSlots limits how many jobs of each type run at the same time
+type jobTypeSlots struct {
+	slots map[JobType]chan struct{}
+}
+
+// loadWorkerCount reads CONTENT_WORKERS from env
+func loadWorkerCount() int {
+	workers := 1
+	if w := os.Getenv("CONTENT_WORKERS"); w != "" {
+		if val, err := strconv.Atoi(w); err == nil && val > 0 {
+			workers = val
+		}
+	}
+	return workers
+}
+
+// newJobTypeSlots creates per-type slots from env (CONTENT_MAX_<TYPE>)
+func newJobTypeSlots() *jobTypeSlots {
+	limits := map[JobType]int{
+		JobTypeGenerate: 1,
+		JobTypeRefresh:  1,
+		JobTypeOptimize: 4,
+	}
+
+	for jobType := range limits {
+		if v := os.Getenv("CONTENT_MAX_" + string(jobType)); v != "" {
+			if val, err := strconv.Atoi(v); err == nil && val > 0 {
+				limits[jobType] = val
+			}
+		}
+	}
+
+	s := &jobTypeSlots{slots: make(map[JobType]chan struct{})}
+	for jobType, limit := range limits {
+		s.slots[jobType] = make(chan struct{}, limit)
+	}
+	return s
+}
+
+// reserveAvailable reserves one slot for every type that still has capacity
+// Returns the reserved types; caller must release the ones it does not use
+func (s *jobTypeSlots) reserveAvailable() []JobType {
+	var reserved []JobType
+	for jobType, ch := range s.slots {
+		select {
+		case ch <- struct{}{}:
+			reserved = append(reserved, jobType)
+		default:
+			// Type at capacity
+		}
+	}
+	return reserved
+}
+
+// release frees one slot of the given type
+func (s *jobTypeSlots) release(jobType JobType) {
+	if ch, ok := s.slots[jobType]; ok {
+		select {
+		case <-ch:
+		default:
+		}
+	}
+}
+
+// limits returns the configured cap per type (for logging)
+func (s *jobTypeSlots) limits() map[JobType]int {
+	out := make(map[JobType]int)
+	for jobType, ch := range s.slots {
+		out[jobType] = cap(ch)
+	}
+	return out
+}
+
+// inFlightJobs tracks the jobs workers are processing right now (their leases are renewed by the heartbeat)
+type inFlightJobs struct {
+	mu  sync.Mutex
+	ids map[string]struct{}
+}
+
+func newInFlightJobs() *inFlightJobs {
+	return &inFlightJobs{ids: make(map[string]struct{})}
+}
+
+func (f *inFlightJobs) add(jobID string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.ids[jobID] = struct{}{}
+}
+
+func (f *inFlightJobs) remove(jobID string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	delete(f.ids, jobID)
+}
+
+// list returns the in-flight job IDs
+func (f *inFlightJobs) list() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	out := make([]string, 0, len(f.ids))
+	for id := range f.ids {
+		out = append(out, id)
+	}
+	return out
+}
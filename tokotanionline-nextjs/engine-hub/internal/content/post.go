@@ -0,0 +1,171 @@
+package content
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"github.com/lib/pq"
+)
+
+// loadBlogPost loads an existing BlogPost by ID
+// Used by REFRESH and OPTIMIZE jobs which work on stored posts instead of creating new ones
+func loadBlogPost(postID string) (*BlogPost, error) {
+	db := GetDB()
+	if db == nil {
+		return nil, fmt.Errorf("database connection not initialized")
+	}
+
+	query := `
+		SELECT id, title, slug, content, excerpt, status,
+		       "seoTitle", "seoDescription", "seoSchema",
+		       "primaryKeyword", "secondaryKeywords",
+		       "wordCount", "readingTime", "publishedAt",
+		       "createdAt", "updatedAt"
+		FROM "BlogPost"
+		WHERE id = $1
+	`
+
+	var post BlogPost
+	var excerpt, seoTitle, seoDescription, seoSchema, primaryKeyword sql.NullString
+	var secondaryKeywords pq.StringArray
+	var wordCount, readingTime sql.NullInt64
+	var publishedAt sql.NullTime
+
+	err := db.QueryRow(query, postID).Scan(
+		&post.ID,
+		&post.Title,
+		&post.Slug,
+		&post.Content,
+		&excerpt,
+		&post.Status,
+		&seoTitle,
+		&seoDescription,
+		&seoSchema,
+		&primaryKeyword,
+		&secondaryKeywords,
+		&wordCount,
+		&readingTime,
+		&publishedAt,
+		&post.CreatedAt,
+		&post.UpdatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("blog post not found: %s", postID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query blog post: %w", err)
+	}
+
+	// Map nullable fields
+	if excerpt.Valid {
+		post.Excerpt = &excerpt.String
+	}
+	if seoTitle.Valid {
+		post.SeoTitle = &seoTitle.String
+	}
+	if seoDescription.Valid {
+		post.SeoDescription = &seoDescription.String
+	}
+	if seoSchema.Valid {
+		post.SeoSchema = json.RawMessage(seoSchema.String)
+	}
+	if primaryKeyword.Valid {
+		post.PrimaryKeyword = &primaryKeyword.String
+	}
+	post.SecondaryKeywords = []string(secondaryKeywords)
+	if wordCount.Valid {
+		wc := int(wordCount.Int64)
+		post.WordCount = &wc
+	}
+	if readingTime.Valid {
+		rt := int(readingTime.Int64)
+		post.ReadingTime = &rt
+	}
+	if publishedAt.Valid {
+		post.PublishedAt = &publishedAt.Time
+	}
+
+	return &post, nil
+}
+
+// loadPostOutline loads the outline stored with the latest ContentResult of a post
+// Returns nil (no error) if the post has no stored outline
+func loadPostOutline(postID string) (json.RawMessage, error) {
+	db := GetDB()
+	if db == nil {
+		return nil, fmt.Errorf("database connection not initialized")
+	}
+
+	query := `
+		SELECT outline
+		FROM "ContentResult"
+		WHERE "postId" = $1 AND outline IS NOT NULL
+		ORDER BY "createdAt" DESC
+		LIMIT 1
+	`
+
+	var outline sql.NullString
+	err := db.QueryRow(query, postID).Scan(&outline)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query post outline: %w", err)
+	}
+	if !outline.Valid {
+		return nil, nil
+	}
+
+	return json.RawMessage(outline.String), nil
+}
+
+// buildRevisionDiff builds a before/after diff between a stored post and a new revision
+// Stored in ContentResult.metrics so reviewers can see what a REFRESH/OPTIMIZE job changed
+// (summary only - the full previous version is saved in "EngineHubPostRevision" by writeRevision)
+func buildRevisionDiff(before *BlogPost, after *ProcessResult) map[string]interface{} {
+	beforeFields := map[string]interface{}{
+		"title":          before.Title,
+		"seoTitle":       stringValue(before.SeoTitle),
+		"seoDescription": stringValue(before.SeoDescription),
+		"wordCount":      getIntValue(before.WordCount),
+		"readingTime":    getIntValue(before.ReadingTime),
+		"contentLength":  len(before.Content),
+	}
+	afterFields := map[string]interface{}{
+		"title":          after.Title,
+		"seoTitle":       stringValue(after.SeoTitle),
+		"seoDescription": stringValue(after.SeoDescription),
+		"wordCount":      getIntValue(after.WordCount),
+		"readingTime":    getIntValue(after.ReadingTime),
+		"contentLength":  len(after.Content),
+	}
+
+	changed := []string{}
+	for _, field := range []string{"title", "seoTitle", "seoDescription", "wordCount", "readingTime", "contentLength"} {
+		if beforeFields[field] != afterFields[field] {
+			changed = append(changed, field)
+		}
+	}
+	if before.Content != after.Content {
+		changed = append(changed, "content")
+	}
+	if string(before.SeoSchema) != string(after.SeoSchema) {
+		changed = append(changed, "seoSchema")
+	}
+
+	return map[string]interface{}{
+		"postId":  before.ID,
+		"before":  beforeFields,
+		"after":   afterFields,
+		"changed": changed,
+	}
+}
+
+// stringValue safely gets string value from pointer
+func stringValue(ptr *string) string {
+	if ptr == nil {
+		return ""
+	}
+	return *ptr
+}
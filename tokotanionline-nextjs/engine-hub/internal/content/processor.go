@@ -1,11 +1,21 @@
 package content
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"log"
+	"regexp"
+
+	aicontent "engine-hub/internal/ai/content"
+	aiseo "engine-hub/internal/ai/seo"
+	"engine-hub/internal/ai/usage"
+	"engine-hub/internal/ai/workflow"
 )
 
+// markdownHeadingPattern matches markdown heading lines (# to ######)
+var markdownHeadingPattern = regexp.MustCompile(`(?m)^#{1,6}\s+[^\n]+`)
+
 // ProcessResult holds the result of processing a job
 type ProcessResult struct {
 	Title            string
@@ -21,16 +31,21 @@ type ProcessResult struct {
 	ReadingTime      *int
 	Outline          json.RawMessage
 	Metrics          json.RawMessage
+	PostID           *string // Existing BlogPost to update in place (REFRESH/OPTIMIZE), nil for new posts
 	Error            error
 }
 
 // ProcessJob processes a ContentJob based on its type
-func ProcessJob(job *ContentJob) (*ProcessResult, error) {
+// ctx cancels the AI pipeline of REFRESH jobs (job runner timeout / cancel)
+func ProcessJob(ctx context.Context, job *ContentJob) (*ProcessResult, error) {
+	if ctx.Err() != nil {
+		return nil, context.Cause(ctx)
+	}
 	switch job.Type {
 	case JobTypeGenerate:
 		return processGenerateJob(job)
 	case JobTypeRefresh:
-		return processRefreshJob(job)
+		return processRefreshJob(ctx, job)
 	case JobTypeOptimize:
 		return processOptimizeJob(job)
 	default:
@@ -98,10 +113,7 @@ func processGenerateJob(job *ContentJob) (*ProcessResult, error) {
 	readingTime := calculateReadingTime(actualWordCount)
 
 	// Generate excerpt
-	excerpt := generateExcerptFromContent(content)
-	if len(excerpt) > 200 {
-		excerpt = excerpt[:200] + "..."
-	}
+	excerpt := truncateExcerpt(generateExcerptFromContent(content), 200)
 
 	// Generate slug from title
 	slug := generateSlug(seoMeta.Title)
@@ -134,16 +146,204 @@ func processGenerateJob(job *ContentJob) (*ProcessResult, error) {
 	return result, nil
 }
 
-// processRefreshJob handles REFRESH type jobs (placeholder)
-func processRefreshJob(job *ContentJob) (*ProcessResult, error) {
-	log.Printf("[CONTENT-ENGINE] REFRESH job type not yet implemented (jobId: %s)", job.ID)
-	// Placeholder: log only
-	return nil, fmt.Errorf("REFRESH job type not yet implemented")
+// processRefreshJob handles REFRESH type jobs
+// Re-runs the AI pipeline (workflow.Pipeline) for an existing BlogPost using its stored keywords and outline
+// Slug and publishedAt are kept; the post is updated in place as a new revision
+func processRefreshJob(ctx context.Context, job *ContentJob) (*ProcessResult, error) {
+	// Parse params
+	var params RefreshParams
+	if len(job.Params) > 0 {
+		if err := json.Unmarshal(job.Params, &params); err != nil {
+			return nil, fmt.Errorf("failed to parse job params: %w", err)
+		}
+	}
+	if params.PostID == "" {
+		return nil, fmt.Errorf("postId is required for REFRESH job")
+	}
+
+	post, err := loadBlogPost(params.PostID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load blog post: %w", err)
+	}
+
+	// STEP 1: Keyword TIDAK BOLEH diubah - reuse keywords stored on the post
+	if post.PrimaryKeyword == nil || *post.PrimaryKeyword == "" {
+		return nil, fmt.Errorf("blog post %s has no primary keyword", post.ID)
+	}
+	primaryKeyword := *post.PrimaryKeyword
+	keywords := []string{primaryKeyword}
+	keywords = append(keywords, post.SecondaryKeywords...)
+
+	// Reuse existing outline so the refreshed article keeps its structure
+	// No outline → fail: the placeholder outline generator must never rewrite a published post
+	outline, err := loadPostOutline(post.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load existing outline: %w", err)
+	}
+	outlineText, err := outlineMarkdown(outline)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read existing outline: %w", err)
+	}
+	if outlineText == "" {
+		return nil, fmt.Errorf("blog post %s has no stored outline - REFRESH needs the original outline", post.ID)
+	}
+
+	req := aicontent.ContentRequest{
+		ContentType: aicontent.ContentType(params.ContentType),
+		Category:    params.Category,
+		Language:    params.Language,
+		Brand:       params.Brand,
+		Outline:     refreshOutline(outlineText, primaryKeyword, post.SecondaryKeywords),
+	}
+	if req.ContentType == "" {
+		req.ContentType = aicontent.ContentDerivative
+	}
+	if req.Category == "" {
+		req.Category = "K1"
+	}
+	if req.Language == "" {
+		req.Language = "id-ID"
+	}
+
+	// Rerun the AI pipeline (same path as /api/engine/ai/generate); any failure fails the job
+	ctx = usage.WithAttribution(ctx, usage.Attribution{JobID: job.ID, PageID: post.ID})
+	draft, err := workflow.NewPipeline().Execute(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("content generation failed: %w", err)
+	}
+	content := draft.Content.Body
+	if content == "" {
+		return nil, fmt.Errorf("content generation failed: pipeline returned an empty body")
+	}
+
+	title := draft.Content.Title
+	if title == "" {
+		title = post.Title
+	}
+	seoTitle := draft.Content.MetaTitle
+	if seoTitle == "" {
+		seoTitle = title
+	}
+	seoDescription := draft.Content.MetaDesc
+	if seoDescription == "" {
+		seoDescription = generateExcerptFromContent(content)
+	}
+	schema, err := optimizeSchema(post.SeoSchema, primaryKeyword, seoTitle, seoDescription)
+	if err != nil {
+		return nil, fmt.Errorf("schema optimization failed: %w", err)
+	}
+
+	actualWordCount := countWords(content)
+	readingTime := calculateReadingTime(actualWordCount)
+
+	excerpt := truncateExcerpt(generateExcerptFromContent(content), 200)
+
+	result := &ProcessResult{
+		Title:             title,
+		Slug:              post.Slug, // Slug is never changed on refresh
+		Content:           content,
+		Excerpt:           &excerpt,
+		SeoTitle:          &seoTitle,
+		SeoDescription:    &seoDescription,
+		SeoSchema:         schema,
+		PrimaryKeyword:    &primaryKeyword,
+		SecondaryKeywords: post.SecondaryKeywords,
+		WordCount:         &actualWordCount,
+		ReadingTime:       &readingTime,
+		Outline:           outline,
+		PostID:            &post.ID,
+	}
+
+	// Record before/after diff for review
+	metrics := map[string]interface{}{
+		"wordCount":    actualWordCount,
+		"readingTime":  readingTime,
+		"keywordCount": len(keywords),
+		"outlineSize":  len(outline),
+		"images":       len(draft.Images),
+		"diff":         buildRevisionDiff(post, result),
+	}
+	metricsJSON, _ := json.Marshal(metrics)
+	result.Metrics = metricsJSON
+
+	return result, nil
 }
 
-// processOptimizeJob handles OPTIMIZE type jobs (placeholder)
+// processOptimizeJob handles OPTIMIZE type jobs
+// Applies SEO fixes (meta title, meta description, schema, heading structure) to a stored BlogPost
+// Body text is NOT regenerated - no LLM call is made
 func processOptimizeJob(job *ContentJob) (*ProcessResult, error) {
-	log.Printf("[CONTENT-ENGINE] OPTIMIZE job type not yet implemented (jobId: %s)", job.ID)
-	// Placeholder: log only
-	return nil, fmt.Errorf("OPTIMIZE job type not yet implemented")
+	// Parse params
+	var params OptimizeParams
+	if len(job.Params) > 0 {
+		if err := json.Unmarshal(job.Params, &params); err != nil {
+			return nil, fmt.Errorf("failed to parse job params: %w", err)
+		}
+	}
+	if params.PostID == "" {
+		return nil, fmt.Errorf("postId is required for OPTIMIZE job")
+	}
+
+	post, err := loadBlogPost(params.PostID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load blog post: %w", err)
+	}
+
+	// AI source context: body stays READ ONLY inside the optimizer, only meta tags are optimized
+	input := aicontent.ContentResult{
+		Title:     post.Title,
+		Body:      post.Content,
+		MetaTitle: stringValue(post.SeoTitle),
+		MetaDesc:  stringValue(post.SeoDescription),
+	}
+	optimized, err := aiseo.OptimizeSEOWithContext(input, aiseo.DefaultSEOContext())
+	if err != nil {
+		return nil, fmt.Errorf("SEO optimization failed: %w", err)
+	}
+
+	// Heading structure only (normalize + 1×H1) - skipped for HTML content without markdown headings
+	body := post.Content
+	if markdownHeadingPattern.MatchString(body) {
+		body = aiseo.NormalizeHeadingStructure(body, post.Title)
+	} else {
+		log.Printf("[CONTENT-ENGINE] Post %s has no markdown headings - heading structure left as-is (jobId: %s)", post.ID, job.ID)
+	}
+
+	primaryKeyword := stringValue(post.PrimaryKeyword)
+	schema, err := optimizeSchema(post.SeoSchema, primaryKeyword, optimized.MetaTitle, optimized.MetaDesc)
+	if err != nil {
+		return nil, fmt.Errorf("schema optimization failed: %w", err)
+	}
+
+	// Keep the outline linked to the post (ContentResult link moves to this job)
+	outline, err := loadPostOutline(post.ID)
+	if err != nil {
+		log.Printf("[CONTENT-ENGINE] Failed to load outline for post %s (non-fatal): %v", post.ID, err)
+	}
+
+	result := &ProcessResult{
+		Title:             post.Title,
+		Slug:              post.Slug,
+		Content:           body,
+		Excerpt:           post.Excerpt,
+		SeoTitle:          &optimized.MetaTitle,
+		SeoDescription:    &optimized.MetaDesc,
+		SeoSchema:         schema,
+		PrimaryKeyword:    post.PrimaryKeyword,
+		SecondaryKeywords: post.SecondaryKeywords,
+		WordCount:         post.WordCount,
+		ReadingTime:       post.ReadingTime,
+		Outline:           outline,
+		PostID:            &post.ID,
+	}
+
+	metrics := map[string]interface{}{
+		"headingsBefore": len(markdownHeadingPattern.FindAllString(post.Content, -1)),
+		"headingsAfter":  len(markdownHeadingPattern.FindAllString(body, -1)),
+		"diff":           buildRevisionDiff(post, result),
+	}
+	metricsJSON, _ := json.Marshal(metrics)
+	result.Metrics = metricsJSON
+
+	return result, nil
 }
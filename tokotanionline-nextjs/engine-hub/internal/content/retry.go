@@ -0,0 +1,90 @@
+package content
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+)
+
+// maxErrorHistory caps the number of entries kept in ContentJob.errorHistory
+const maxErrorHistory = 10
+
+// ScheduleRetry puts a failed job back to PENDING with scheduledAt = now + delay
+// The failure is appended to errorHistory; no ContentResult is written (job is not finished yet)
+func ScheduleRetry(job *ContentJob, jobErr error, delay time.Duration) error {
+	db := GetDB()
+	if db == nil {
+		return fmt.Errorf("database connection not initialized")
+	}
+
+	now := time.Now()
+	retryAt := now.Add(delay)
+	history := appendErrorHistory(job, jobErr, now)
+
+	query := `
+		UPDATE "ContentJob"
+		SET status = $1, "scheduledAt" = $2, "startedAt" = NULL,
+		    error = $3, "errorHistory" = $4,
+		    "leaseOwner" = NULL, "leaseExpiresAt" = NULL
+		WHERE id = $5 AND status = $6 AND "leaseOwner" = $7
+	`
+	res, err := db.Exec(query,
+		string(JobStatusPending),
+		retryAt,
+		jobErr.Error(),
+		string(history),
+		job.ID,
+		string(JobStatusRunning),
+		instanceID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to reschedule job: %w", err)
+	}
+	if err := requireLease(res, job); err != nil {
+		return err
+	}
+
+	job.Status = JobStatusPending
+	job.ScheduledAt = &retryAt
+	job.StartedAt = nil
+	job.ErrorHistory = history
+
+	log.Printf("[RATE-GUARD] Job %s rescheduled (attempt %d) for %s", job.ID, job.Attempts, retryAt.Format(time.RFC3339))
+	return nil
+}
+
+// appendErrorHistory returns job.errorHistory with a new entry for the current attempt
+// The entry class (INFRA/CONTENT) comes from the error classifier
+func appendErrorHistory(job *ContentJob, jobErr error, at time.Time) json.RawMessage {
+	class := "CONTENT"
+	if isInfrastructureError(jobErr) {
+		class = "INFRA"
+	}
+	return appendErrorEntry(job, class, jobErr.Error(), at)
+}
+
+// appendErrorEntry returns job.errorHistory with a new entry of the given class
+// Only the last maxErrorHistory entries are kept
+func appendErrorEntry(job *ContentJob, class string, message string, at time.Time) json.RawMessage {
+	var entries []JobErrorEntry
+	if len(job.ErrorHistory) > 0 {
+		if err := json.Unmarshal(job.ErrorHistory, &entries); err != nil {
+			log.Printf("[CONTENT-ENGINE] Ignoring invalid errorHistory for job %s: %v", job.ID, err)
+			entries = nil
+		}
+	}
+
+	entries = append(entries, JobErrorEntry{
+		Attempt: job.Attempts,
+		Class:   class,
+		Error:   message,
+		At:      at,
+	})
+	if len(entries) > maxErrorHistory {
+		entries = entries[len(entries)-maxErrorHistory:]
+	}
+
+	historyJSON, _ := json.Marshal(entries)
+	return json.RawMessage(historyJSON)
+}
@@ -1,6 +1,7 @@
 package content
 
 import (
+	"context"
 	"database/sql"
 	"encoding/json"
 	"fmt"
@@ -8,7 +9,10 @@ import (
 	"math/rand"
 	"os"
 	"strconv"
+	"sync"
 	"time"
+
+	"engine-hub/internal/engine"
 )
 
 // FASE D - D1: SCHEDULER HARIAN (AMAN & NON-OVERLAP)
@@ -19,6 +23,7 @@ import (
 
 // DailyScheduler manages daily production scheduling
 type DailyScheduler struct {
+	mu          sync.Mutex
 	running     bool
 	stopCh      chan struct{}
 	windowStart int // Hour (9 = 09:00)
@@ -83,11 +88,22 @@ func NewDailyScheduler() *DailyScheduler {
 	}
 }
 
+// Name returns the engine registry name
+func (s *DailyScheduler) Name() string {
+	return "scheduler"
+}
+
 // Start starts the scheduler
-func (s *DailyScheduler) Start() error {
+func (s *DailyScheduler) Start(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
 	if s.running {
 		return fmt.Errorf("scheduler is already running")
 	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
 
 	// Check if database is available
 	if GetDB() == nil {
@@ -96,16 +112,20 @@ func (s *DailyScheduler) Start() error {
 	}
 
 	s.running = true
+	s.stopCh = make(chan struct{})
 	log.Printf("[SCHEDULER] Starting daily scheduler: window=%02d:00-%02d:00, quota=%d, cooldown=%d min",
 		s.windowStart, s.windowEnd, s.quota, s.cooldownMin)
 
-	go s.scheduleLoop()
+	go s.scheduleLoop(s.stopCh)
 
 	return nil
 }
 
 // Stop stops the scheduler
 func (s *DailyScheduler) Stop() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
 	if !s.running {
 		return fmt.Errorf("scheduler is not running")
 	}
@@ -117,15 +137,65 @@ func (s *DailyScheduler) Stop() error {
 	return nil
 }
 
+// Status returns the scheduler status for the engine registry
+func (s *DailyScheduler) Status() engine.EngineStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.running {
+		return engine.StatusOn
+	}
+	return engine.StatusOff
+}
+
+// HealthCheck reports whether the scheduler is inside its production window
+func (s *DailyScheduler) HealthCheck() engine.HealthReport {
+	status := s.Status()
+	report := engine.HealthReport{
+		Status:    status,
+		Healthy:   status == engine.StatusOn,
+		CheckedAt: time.Now(),
+	}
+
+	hour := time.Now().Hour()
+	report.Metrics = map[string]interface{}{
+		"inWindow":   hour >= s.windowStart && hour < s.windowEnd,
+		"safeMode":   isSafeModeEnabled(),
+		"dailyQuota": s.quota,
+	}
+	if status != engine.StatusOn {
+		report.Detail = "stopped"
+	} else if GetDB() == nil {
+		report.Healthy = false
+		report.Detail = "database connection not initialized"
+	}
+
+	return report
+}
+
+// Describe returns the scheduler metadata
+func (s *DailyScheduler) Describe() engine.Descriptor {
+	return engine.Descriptor{
+		Name:        s.Name(),
+		Description: "Daily GENERATE job scheduler (window, jitter, quota, cooldown)",
+		DependsOn:   []string{"content"},
+		Requires:    []string{engine.ResourceDatabase},
+		Config: map[string]string{
+			"PROD_WINDOW":       fmt.Sprintf("%02d:00-%02d:00", s.windowStart, s.windowEnd),
+			"PROD_DAILY_QUOTA":  strconv.Itoa(s.quota),
+			"PROD_COOLDOWN_MIN": strconv.Itoa(s.cooldownMin),
+		},
+	}
+}
+
 // scheduleLoop runs the main scheduling logic
-func (s *DailyScheduler) scheduleLoop() {
+func (s *DailyScheduler) scheduleLoop(stopCh chan struct{}) {
 	// Check every minute for scheduling opportunities
 	ticker := time.NewTicker(1 * time.Minute)
 	defer ticker.Stop()
 
 	for {
 		select {
-		case <-s.stopCh:
+		case <-stopCh:
 			log.Println("[SCHEDULER] Schedule loop stopped")
 			return
 		case <-ticker.C:
@@ -155,6 +225,12 @@ func (s *DailyScheduler) checkAndSchedule() {
 		return
 	}
 
+	// FASE D - D2: Check daily AI budget
+	if exceeded, spent := getRateGuard().CheckBudget(now); exceeded {
+		log.Printf("[SCHEDULER] Daily AI budget exceeded (spent $%.4f) - skipping", spent)
+		return
+	}
+
 	// FASE D - D1: Check non-overlap (max concurrency = 1)
 	if s.hasRunningJob() {
 		log.Println("[SCHEDULER] Job already running - skipping (non-overlap)")
@@ -265,15 +341,21 @@ func (s *DailyScheduler) createScheduledJob(jobID, keyword string, scheduledTime
 }
 
 // hasRunningJob checks if there's a job currently running
+// Jobs with an expired lease are ignored (they will be reclaimed by the reaper)
 func (s *DailyScheduler) hasRunningJob() bool {
 	db := GetDB()
 	if db == nil {
 		return false
 	}
 
-	query := `SELECT COUNT(*) FROM "ContentJob" WHERE status = $1`
+	query := `
+		SELECT COUNT(*)
+		FROM "ContentJob"
+		WHERE status = $1
+		  AND ("leaseExpiresAt" IS NULL OR "leaseExpiresAt" > $2)
+	`
 	var count int
-	err := db.QueryRow(query, string(JobStatusRunning)).Scan(&count)
+	err := db.QueryRow(query, string(JobStatusRunning), time.Now()).Scan(&count)
 	if err != nil {
 		log.Printf("[SCHEDULER] Error checking running jobs: %v", err)
 		return false
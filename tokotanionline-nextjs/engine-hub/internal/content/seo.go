@@ -95,6 +95,37 @@ func generateSchema(keyword, title, description string, categoryID *string) (jso
 	return json.RawMessage(schemaJSON), nil
 }
 
+// optimizeSchema updates headline/description/keywords on an existing JSON-LD schema
+// Other schema fields set by editors are preserved
+func optimizeSchema(existing json.RawMessage, keyword, title, description string) (json.RawMessage, error) {
+	schema := map[string]interface{}{}
+	if len(existing) > 0 {
+		if err := json.Unmarshal(existing, &schema); err != nil {
+			// Invalid stored schema - start from a fresh Article schema
+			schema = map[string]interface{}{}
+		}
+	}
+
+	if _, ok := schema["@context"]; !ok {
+		schema["@context"] = "https://schema.org"
+	}
+	if _, ok := schema["@type"]; !ok {
+		schema["@type"] = "Article"
+	}
+	schema["headline"] = title
+	schema["description"] = description
+	if keyword != "" {
+		schema["keywords"] = keyword
+	}
+
+	schemaJSON, err := json.Marshal(schema)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal schema: %w", err)
+	}
+
+	return json.RawMessage(schemaJSON), nil
+}
+
 // STEP 1: REMOVED extractKeywords - Keyword TIDAK BOLEH dihasilkan AI
 // Keywords hanya dari input user (riset SEO manual)
 
@@ -1,6 +1,7 @@
 package content
 
 import (
+	"database/sql"
 	"fmt"
 	"log"
 	"time"
@@ -10,7 +11,8 @@ import (
 )
 
 // WriteResult writes the process result to ContentResult and BlogPost
-// Sets job status to DONE or FAILED
+// Sets job status to DONE or FAILED - only while the job is still RUNNING under this instance's lease,
+// otherwise nothing is written and errLeaseLost is returned
 // Returns the blog post ID if successful, empty string if failed
 func WriteResult(job *ContentJob, result *ProcessResult) (string, error) {
 	db := GetDB()
@@ -55,17 +57,23 @@ func WriteResult(job *ContentJob, result *ProcessResult) (string, error) {
 			return "", fmt.Errorf("failed to insert ContentResult: %w", err)
 		}
 
-		// Update job status to FAILED and store error
+		// Update job status to FAILED and store error (+ history)
 		updateJobQuery := `
 			UPDATE "ContentJob"
-			SET status = $1, "finishedAt" = $2, error = $4
-			WHERE id = $3
+			SET status = $1, "finishedAt" = $2, error = $4, "errorHistory" = $5,
+			    "leaseOwner" = NULL, "leaseExpiresAt" = NULL
+			WHERE id = $3 AND status = $6 AND "leaseOwner" = $7
 		`
 		
-		_, err = tx.Exec(updateJobQuery, string(JobStatusFailed), now, job.ID, errorSummary)
+		history := appendErrorHistory(job, result.Error, now)
+		res, err := tx.Exec(updateJobQuery, string(JobStatusFailed), now, job.ID, errorSummary, string(history),
+			string(JobStatusRunning), instanceID)
 		if err != nil {
 			return "", fmt.Errorf("failed to update job status to FAILED: %w", err)
 		}
+		if err := requireLease(res, job); err != nil {
+			return "", err
+		}
 
 		// Commit transaction
 		if err := tx.Commit(); err != nil {
@@ -75,6 +83,11 @@ func WriteResult(job *ContentJob, result *ProcessResult) (string, error) {
 		return "", nil
 	}
 
+	// REFRESH/OPTIMIZE: update existing post in place (slug & publishedAt are kept)
+	if result.PostID != nil {
+		return writeRevision(tx, job, result, now)
+	}
+
 	// FASE D - D4: IDEMPOTENCY & ANTI DUPLIKASI
 	// Check if slug already exists (prevent duplicates)
 	var existingPostID string
@@ -235,16 +248,9 @@ func WriteResult(job *ContentJob, result *ProcessResult) (string, error) {
 		return "", fmt.Errorf("failed to insert ContentResult: %w", err)
 	}
 
-	// Update job status to DONE
-	updateJobQuery := `
-		UPDATE "ContentJob"
-		SET status = $1, "finishedAt" = $2
-		WHERE id = $3
-	`
-
-	_, err = tx.Exec(updateJobQuery, string(JobStatusDone), now, job.ID)
-	if err != nil {
-		return "", fmt.Errorf("failed to update job status to DONE: %w", err)
+	// Update job status to DONE (only while this instance still holds the lease)
+	if err := finishJob(tx, job, now); err != nil {
+		return "", err
 	}
 
 	// Commit transaction
@@ -255,6 +261,152 @@ func WriteResult(job *ContentJob, result *ProcessResult) (string, error) {
 	return blogPostIDVal, nil
 }
 
+// writeRevision updates an existing BlogPost in place as a new revision
+// The stored version is first copied to "EngineHubPostRevision" (same transaction) so it can be recovered;
+// slug, status and publishedAt are never touched; earlier ContentResult rows stay linked to the post
+func writeRevision(tx *sql.Tx, job *ContentJob, result *ProcessResult, now time.Time) (string, error) {
+	postID := *result.PostID
+
+	// FASE D - D3: FEATURE_FREEZE check - if enabled, don't modify existing posts
+	if isFeatureFreezeEnabled() {
+		log.Printf("[FEATURE-FREEZE] Feature freeze enabled - skipping post update for job %s", job.ID)
+		tx.Rollback()
+		return "", fmt.Errorf("feature freeze enabled - post update blocked")
+	}
+
+	var seoSchemaVal []byte
+	if len(result.SeoSchema) > 0 {
+		seoSchemaVal = result.SeoSchema
+	}
+
+	// Before-snapshot of the stored post (title, body, SEO fields, counts)
+	revisionID := uuid.New().String()
+	snap, err := tx.Exec(`
+		INSERT INTO "EngineHubPostRevision" (
+			id, "postId", "jobId", "jobType", title, content, excerpt,
+			"seoTitle", "seoDescription", "seoSchema", "wordCount", "readingTime", "createdAt"
+		)
+		SELECT $1, id, $2, $3, title, content, excerpt,
+			"seoTitle", "seoDescription", "seoSchema", "wordCount", "readingTime", $4
+		FROM "BlogPost" WHERE id = $5
+	`, revisionID, job.ID, string(job.Type), now, postID)
+	if err != nil {
+		return "", fmt.Errorf("failed to save post revision: %w", err)
+	}
+	if rows, _ := snap.RowsAffected(); rows == 0 {
+		return "", fmt.Errorf("blog post not found: %s", postID)
+	}
+
+	var res sql.Result
+	var summary string
+	if job.Type == JobTypeOptimize {
+		// OPTIMIZE: only SEO fields and heading structure - title, excerpt & counts untouched
+		updatePostQuery := `
+			UPDATE "BlogPost"
+			SET content = $1, "seoTitle" = $2, "seoDescription" = $3, "seoSchema" = $4, "updatedAt" = $5
+			WHERE id = $6
+		`
+		res, err = tx.Exec(
+			updatePostQuery,
+			result.Content,
+			result.SeoTitle,
+			result.SeoDescription,
+			seoSchemaVal,
+			now,
+			postID,
+		)
+		summary = fmt.Sprintf("SEO optimized successfully. Meta Title: %s", stringValue(result.SeoTitle))
+	} else {
+		updatePostQuery := `
+			UPDATE "BlogPost"
+			SET title = $1, content = $2, excerpt = $3,
+			    "seoTitle" = $4, "seoDescription" = $5, "seoSchema" = $6,
+			    "wordCount" = $7, "readingTime" = $8, "updatedAt" = $9
+			WHERE id = $10
+		`
+		res, err = tx.Exec(
+			updatePostQuery,
+			result.Title,
+			result.Content,
+			result.Excerpt,
+			result.SeoTitle,
+			result.SeoDescription,
+			seoSchemaVal,
+			result.WordCount,
+			result.ReadingTime,
+			now,
+			postID,
+		)
+		summary = fmt.Sprintf("Content refreshed successfully. Title: %s, Word Count: %d, Reading Time: %d min",
+			result.Title,
+			getIntValue(result.WordCount),
+			getIntValue(result.ReadingTime),
+		)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to update BlogPost: %w", err)
+	}
+	if rows, _ := res.RowsAffected(); rows == 0 {
+		return "", fmt.Errorf("blog post not found: %s", postID)
+	}
+
+	var outlineVal []byte
+	if len(result.Outline) > 0 {
+		outlineVal = result.Outline
+	}
+
+	var metricsVal []byte
+	if len(result.Metrics) > 0 {
+		metricsVal = result.Metrics
+	}
+
+	insertResultQuery := `
+		INSERT INTO "ContentResult" (
+			id, "jobId", "postId", summary, outline, metrics,
+			"engineVersion", "createdAt"
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`
+	_, err = tx.Exec(
+		insertResultQuery,
+		uuid.New().String(),
+		job.ID,
+		postID,
+		summary,
+		outlineVal,
+		metricsVal,
+		"1.0.0", // Engine version
+		now,
+	)
+	if err != nil {
+		return "", fmt.Errorf("failed to insert ContentResult: %w", err)
+	}
+
+	// Update job status to DONE (only while this instance still holds the lease)
+	if err := finishJob(tx, job, now); err != nil {
+		return "", err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return "", fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	log.Printf("[CONTENT-ENGINE] Post %s updated in place by %s job %s (previous version saved as revision %s)", postID, job.Type, job.ID, revisionID)
+	return postID, nil
+}
+
+// finishJob marks a RUNNING job leased by this instance as DONE inside tx
+// Returns errLeaseLost when the job was reaped or taken over (caller's deferred Rollback discards the writes)
+func finishJob(tx *sql.Tx, job *ContentJob, now time.Time) error {
+	res, err := tx.Exec(`
+		UPDATE "ContentJob"
+		SET status = $1, "finishedAt" = $2, "leaseOwner" = NULL, "leaseExpiresAt" = NULL
+		WHERE id = $3 AND status = $4 AND "leaseOwner" = $5
+	`, string(JobStatusDone), now, job.ID, string(JobStatusRunning), instanceID)
+	if err != nil {
+		return fmt.Errorf("failed to update job status to DONE: %w", err)
+	}
+	return requireLease(res, job)
+}
 
 // getIntValue safely gets int value from pointer
 func getIntValue(ptr *int) int {
@@ -0,0 +1,43 @@
+package engine
+
+import (
+	"sort"
+	"sync"
+)
+
+// Flushers are called once on shutdown, after engines stopped, to persist buffered entries (e.g. the engine log sink)
+
+var (
+	flushMu  sync.Mutex
+	flushers = make(map[string]func() error)
+)
+
+// RegisterFlusher registers a named flush func run by FlushAll
+func RegisterFlusher(name string, fn func() error) {
+	flushMu.Lock()
+	defer flushMu.Unlock()
+	flushers[name] = fn
+}
+
+// FlushAll runs every registered flusher (in name order) and returns failures per name
+func FlushAll() map[string]error {
+	flushMu.Lock()
+	names := make([]string, 0, len(flushers))
+	for name := range flushers {
+		names = append(names, name)
+	}
+	fns := make(map[string]func() error, len(flushers))
+	for name, fn := range flushers {
+		fns[name] = fn
+	}
+	flushMu.Unlock()
+
+	sort.Strings(names)
+	failures := make(map[string]error)
+	for _, name := range names {
+		if err := fns[name](); err != nil {
+			failures[name] = err
+		}
+	}
+	return failures
+}
@@ -1,5 +1,10 @@
 package engine
 
+import (
+	"context"
+	"time"
+)
+
 // EngineStatus represents the status of an engine
 type EngineStatus string
 
@@ -10,12 +15,37 @@ const (
 	StatusError EngineStatus = "ERROR"
 )
 
+// Resource names engines can declare in Descriptor.Requires
+const (
+	ResourceDatabase = "database"
+)
+
 // Engine defines the interface that all engines must implement
+// Start receives a context so boot (and /api/engines/control) can bound startup time
 type Engine interface {
 	Name() string
-	Start() error
+	Start(ctx context.Context) error
 	Stop() error
 	Status() EngineStatus
+	HealthCheck() HealthReport
+	Describe() Descriptor
 }
 
+// Descriptor is the static metadata of an engine
+type Descriptor struct {
+	Name        string            `json:"name"`
+	Description string            `json:"description"`
+	DependsOn   []string          `json:"dependsOn,omitempty"` // engines that must be running first
+	Requires    []string          `json:"requires,omitempty"`  // resources, e.g. ResourceDatabase
+	Config      map[string]string `json:"config,omitempty"`
+	Manual      bool              `json:"manual,omitempty"` // skipped by StartAll, start via /api/engines/control
+}
 
+// HealthReport is the result of Engine.HealthCheck
+type HealthReport struct {
+	Status    EngineStatus           `json:"status"`
+	Healthy   bool                   `json:"healthy"`
+	Detail    string                 `json:"detail,omitempty"`
+	Metrics   map[string]interface{} `json:"metrics,omitempty"`
+	CheckedAt time.Time              `json:"checkedAt"`
+}
@@ -2,9 +2,21 @@ package engine
 
 import "time"
 
+// Log levels (EngineLog.Level)
+const (
+	LevelDebug = "DEBUG"
+	LevelInfo  = "INFO"
+	LevelWarn  = "WARN"
+	LevelError = "ERROR"
+)
+
 type EngineLog struct {
-	Level     string    `json:"level"` // INFO, WARN, ERROR
-	Message   string    `json:"message"`
-	JobID     string    `json:"jobId,omitempty"`
-	Timestamp time.Time `json:"timestamp"`
+	Seq       uint64                 `json:"seq,omitempty"` // process-local sequence assigned by AddLog (SSE event id, resume from the ring buffer); 0 when read from Postgres
+	Level     string                 `json:"level"`         // DEBUG, INFO, WARN, ERROR
+	Subsystem string                 `json:"subsystem,omitempty"`
+	Message   string                 `json:"message"`
+	JobID     string                 `json:"jobId,omitempty"`
+	PageID    string                 `json:"pageId,omitempty"`
+	Fields    map[string]interface{} `json:"fields,omitempty"`
+	Timestamp time.Time              `json:"timestamp"`
 }
@@ -0,0 +1,115 @@
+package engine
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// STD LOG BRIDGE
+// Most subsystems log with log.Printf("[TAG] ...") and never call AddLog
+// StdLogWriter is installed with log.SetOutput(io.MultiWriter(os.Stderr, engine.StdLogWriter{}))
+// and turns each line into a structured EngineLog:
+//   [CONTENT-ENGINE] ...        → subsystem "content-engine"
+//   level only from the leading tag or an explicit prefix (never from words inside the message):
+//   [JOB-FAIL] / [ERROR]        → ERROR (tag word ERROR / FAIL / FAILED / FATAL / PANIC), [...-WARN] → WARN
+//   WARNING: / WARN: / ERROR: / FATAL: / PANIC: / DEBUG: / INFO: right after the tag → that level
+//   jobId=... pageId=... k=v    → JobID, PageID, Fields
+
+var (
+	stdLogPrefix = regexp.MustCompile(`^\d{4}/\d{2}/\d{2} \d{2}:\d{2}:\d{2}(\.\d+)? `)
+	stdLogTag    = regexp.MustCompile(`^\[([^\]]+)\]\s*`)
+	stdLogPair   = regexp.MustCompile(`\b([A-Za-z][A-Za-z0-9_]*)=("[^"]*"|[^\s,]+)`)
+	stdLogLevel  = regexp.MustCompile(`^(?i)(WARNING|WARN|ERROR|FATAL|PANIC|DEBUG|INFO):`)
+)
+
+// stdLogLevels maps tag words and LEVEL: prefixes to EngineLog levels
+var stdLogLevels = map[string]string{
+	"WARNING": LevelWarn,
+	"WARN":    LevelWarn,
+	"ERROR":   LevelError,
+	"FAIL":    LevelError,
+	"FAILED":  LevelError,
+	"FATAL":   LevelError,
+	"PANIC":   LevelError,
+	"DEBUG":   LevelDebug,
+	"INFO":    LevelInfo,
+}
+
+// maxStdLogMessage caps bridged messages; raw LLM responses and prompt dumps stay complete on stderr only
+const maxStdLogMessage = 2000
+
+// StdLogWriter is an io.Writer for the standard log package
+type StdLogWriter struct{}
+
+// Write parses one log line into AddLog; never fails so the std logger keeps writing to stderr
+// Messages longer than maxStdLogMessage bytes are truncated before reaching the ring buffer and sink
+func (StdLogWriter) Write(p []byte) (int, error) {
+	line := strings.TrimRight(string(p), "\n")
+	if line != "" {
+		entry := ParseStdLogLine(line, time.Now())
+		entry.Message = truncateLogMessage(entry.Message, maxStdLogMessage)
+		AddLog(entry)
+	}
+	return len(p), nil
+}
+
+// truncateLogMessage cuts msg to at most max bytes (on a rune boundary) and notes the original size
+func truncateLogMessage(msg string, max int) string {
+	if len(msg) <= max {
+		return msg
+	}
+	cut := max
+	for cut > 0 && !utf8.RuneStart(msg[cut]) {
+		cut--
+	}
+	return fmt.Sprintf("%s… [truncated, %d bytes]", msg[:cut], len(msg))
+}
+
+// ParseStdLogLine converts a "[TAG] message k=v" line into an EngineLog
+func ParseStdLogLine(line string, at time.Time) EngineLog {
+	line = stdLogPrefix.ReplaceAllString(line, "")
+
+	entry := EngineLog{
+		Level:     LevelInfo,
+		Message:   line,
+		Timestamp: at,
+	}
+
+	rest := line
+	if m := stdLogTag.FindStringSubmatch(line); m != nil {
+		entry.Subsystem = strings.ToLower(strings.ReplaceAll(strings.TrimSpace(m[1]), " ", "-"))
+		rest = line[len(m[0]):]
+		for _, word := range strings.FieldsFunc(strings.ToUpper(m[1]), func(r rune) bool {
+			return r == '-' || r == '_' || r == ' '
+		}) {
+			if level, ok := stdLogLevels[word]; ok && level != LevelInfo && level != LevelDebug {
+				entry.Level = level
+			}
+		}
+	}
+
+	// Explicit prefix ("WARNING: ...") wins over the tag
+	if m := stdLogLevel.FindStringSubmatch(rest); m != nil {
+		entry.Level = stdLogLevels[strings.ToUpper(m[1])]
+	}
+
+	for _, m := range stdLogPair.FindAllStringSubmatch(line, -1) {
+		key, value := m[1], strings.Trim(m[2], `"`)
+		switch strings.ToLower(key) {
+		case "jobid":
+			entry.JobID = value
+		case "pageid":
+			entry.PageID = value
+		default:
+			if entry.Fields == nil {
+				entry.Fields = make(map[string]interface{})
+			}
+			entry.Fields[key] = value
+		}
+	}
+
+	return entry
+}
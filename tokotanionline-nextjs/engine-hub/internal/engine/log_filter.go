@@ -0,0 +1,50 @@
+package engine
+
+import (
+	"strings"
+	"time"
+)
+
+// LogFilter selects engine logs (GET /api/engines/logs, SSE stream)
+// Zero values mean "no filter"; Levels match any of the given levels
+type LogFilter struct {
+	Levels    []string
+	Subsystem string
+	JobID     string
+	PageID    string
+	Since     time.Time
+	Until     time.Time // exclusive
+	Limit     int
+}
+
+// Matches reports whether an entry passes the filter (Limit is ignored)
+func (f LogFilter) Matches(entry EngineLog) bool {
+	if len(f.Levels) > 0 {
+		found := false
+		for _, level := range f.Levels {
+			if strings.EqualFold(level, entry.Level) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if f.Subsystem != "" && !strings.EqualFold(f.Subsystem, entry.Subsystem) {
+		return false
+	}
+	if f.JobID != "" && f.JobID != entry.JobID {
+		return false
+	}
+	if f.PageID != "" && f.PageID != entry.PageID {
+		return false
+	}
+	if !f.Since.IsZero() && entry.Timestamp.Before(f.Since) {
+		return false
+	}
+	if !f.Until.IsZero() && !entry.Timestamp.Before(f.Until) {
+		return false
+	}
+	return true
+}
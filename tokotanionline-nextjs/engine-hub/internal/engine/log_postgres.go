@@ -0,0 +1,161 @@
+package engine
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// PostgresLogSink persists engine logs in "EngineHubLog" ("EngineLog" belongs to the Next.js engine logger)
+type PostgresLogSink struct {
+	db *sql.DB
+}
+
+// NewPostgresLogSink creates a log sink backed by the given database
+func NewPostgresLogSink(db *sql.DB) *PostgresLogSink {
+	return &PostgresLogSink{db: db}
+}
+
+// Write inserts a batch of entries with one multi-row INSERT
+func (p *PostgresLogSink) Write(entries []EngineLog) error {
+	if len(entries) == 0 {
+		return nil
+	}
+
+	const columns = 7
+	placeholders := make([]string, 0, len(entries))
+	args := make([]interface{}, 0, len(entries)*columns)
+	for i, e := range entries {
+		var fields interface{}
+		if len(e.Fields) > 0 {
+			data, err := json.Marshal(e.Fields)
+			if err != nil {
+				data, _ = json.Marshal(map[string]string{"marshalError": err.Error()})
+			}
+			fields = string(data)
+		}
+
+		base := i * columns
+		placeholders = append(placeholders, fmt.Sprintf("($%d, $%d, $%d, $%d, $%d, $%d, $%d)",
+			base+1, base+2, base+3, base+4, base+5, base+6, base+7))
+		args = append(args,
+			e.Level,
+			nullLogString(e.Subsystem),
+			e.Message,
+			nullLogString(e.JobID),
+			nullLogString(e.PageID),
+			fields,
+			e.Timestamp,
+		)
+	}
+
+	query := `INSERT INTO "EngineHubLog" (level, subsystem, message, "jobId", "pageId", fields, timestamp) VALUES ` +
+		strings.Join(placeholders, ", ")
+	if _, err := p.db.Exec(query, args...); err != nil {
+		return fmt.Errorf("failed to insert engine logs: %w", err)
+	}
+	return nil
+}
+
+// Query returns the last filter.Limit matching entries in chronological order
+func (p *PostgresLogSink) Query(filter LogFilter) ([]EngineLog, error) {
+	where, args := logWhereClause(filter)
+	query := `
+		SELECT level, subsystem, message, "jobId", "pageId", fields, timestamp
+		FROM "EngineHubLog"` + where + ` ORDER BY timestamp DESC, id DESC`
+	if filter.Limit > 0 {
+		query += fmt.Sprintf(" LIMIT %d", filter.Limit)
+	}
+
+	rows, err := p.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query engine logs: %w", err)
+	}
+	defer rows.Close()
+
+	logs := []EngineLog{}
+	for rows.Next() {
+		// Seq stays 0: it is assigned once by AddLog and only meaningful for SSE resume (ring buffer)
+		var e EngineLog
+		var subsystem, jobID, pageID, fields sql.NullString
+		if err := rows.Scan(&e.Level, &subsystem, &e.Message, &jobID, &pageID, &fields, &e.Timestamp); err != nil {
+			return nil, fmt.Errorf("failed to scan engine log: %w", err)
+		}
+		e.Subsystem = subsystem.String
+		e.JobID = jobID.String
+		e.PageID = pageID.String
+		if fields.Valid {
+			json.Unmarshal([]byte(fields.String), &e.Fields)
+		}
+		logs = append(logs, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate engine logs: %w", err)
+	}
+
+	// Newest first from SQL → chronological order
+	for i, j := 0, len(logs)-1; i < j; i, j = i+1, j-1 {
+		logs[i], logs[j] = logs[j], logs[i]
+	}
+	return logs, nil
+}
+
+// Prune deletes entries older than before
+func (p *PostgresLogSink) Prune(before time.Time) (int, error) {
+	res, err := p.db.Exec(`DELETE FROM "EngineHubLog" WHERE timestamp < $1`, before)
+	if err != nil {
+		return 0, fmt.Errorf("failed to prune engine logs: %w", err)
+	}
+	removed, _ := res.RowsAffected()
+	return int(removed), nil
+}
+
+// logWhereClause builds the WHERE clause and args for a filter
+func logWhereClause(f LogFilter) (string, []interface{}) {
+	var conditions []string
+	var args []interface{}
+
+	if len(f.Levels) > 0 {
+		placeholders := make([]string, 0, len(f.Levels))
+		for _, level := range f.Levels {
+			args = append(args, strings.ToUpper(level))
+			placeholders = append(placeholders, fmt.Sprintf("$%d", len(args)))
+		}
+		conditions = append(conditions, "level IN ("+strings.Join(placeholders, ", ")+")")
+	}
+	if f.Subsystem != "" {
+		args = append(args, strings.ToLower(f.Subsystem))
+		conditions = append(conditions, fmt.Sprintf("LOWER(subsystem) = $%d", len(args)))
+	}
+	if f.JobID != "" {
+		args = append(args, f.JobID)
+		conditions = append(conditions, fmt.Sprintf(`"jobId" = $%d`, len(args)))
+	}
+	if f.PageID != "" {
+		args = append(args, f.PageID)
+		conditions = append(conditions, fmt.Sprintf(`"pageId" = $%d`, len(args)))
+	}
+	if !f.Since.IsZero() {
+		args = append(args, f.Since)
+		conditions = append(conditions, fmt.Sprintf("timestamp >= $%d", len(args)))
+	}
+	if !f.Until.IsZero() {
+		args = append(args, f.Until)
+		conditions = append(conditions, fmt.Sprintf("timestamp < $%d", len(args)))
+	}
+
+	if len(conditions) == 0 {
+		return "", args
+	}
+	return " WHERE " + strings.Join(conditions, " AND "), args
+}
+
+// nullLogString maps an empty string to NULL
+func nullLogString(s string) interface{} {
+	if s == "" {
+		return nil
+	}
+	return s
+}
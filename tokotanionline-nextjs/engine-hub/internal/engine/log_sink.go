@@ -0,0 +1,216 @@
+package engine
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// LogSink persists engine logs beyond the 500-entry ring buffer
+type LogSink interface {
+	Write(entries []EngineLog) error
+	Query(filter LogFilter) ([]EngineLog, error)
+	Prune(before time.Time) (int, error)
+}
+
+const (
+	sinkQueueSize     = 5000
+	sinkBatchSize     = 100
+	sinkFlushInterval = 2 * time.Second
+)
+
+// asyncSink batches AddLog entries so logging never blocks on the database
+type asyncSink struct {
+	sink     LogSink
+	queue    chan EngineLog
+	flushReq chan chan error
+	dropped  uint64
+
+	writeMu sync.RWMutex // held by batch writes; readers see the sink and written consistently
+	written uint64       // highest EngineLog.Seq handed to the sink (entries above it are still queued)
+}
+
+var (
+	sinkMu      sync.RWMutex
+	currentSink *asyncSink
+)
+
+// SetLogSink sets the persistent log sink (e.g. NewPostgresLogSink) and starts its writer
+func SetLogSink(sink LogSink) {
+	s := &asyncSink{
+		sink:     sink,
+		queue:    make(chan EngineLog, sinkQueueSize),
+		flushReq: make(chan chan error),
+	}
+	go s.run()
+
+	sinkMu.Lock()
+	currentSink = s
+	sinkMu.Unlock()
+}
+
+// getSink returns the configured sink or nil
+func getSink() LogSink {
+	sinkMu.RLock()
+	defer sinkMu.RUnlock()
+	if currentSink == nil {
+		return nil
+	}
+	return currentSink.sink
+}
+
+// querySink queries the configured sink and returns the highest Seq it covers
+// Returns ok=false when no sink is set
+func querySink(filter LogFilter) (entries []EngineLog, written uint64, ok bool, err error) {
+	sinkMu.RLock()
+	s := currentSink
+	sinkMu.RUnlock()
+	if s == nil {
+		return nil, 0, false, nil
+	}
+
+	// No batch write in between: every entry up to written is in the result (if it matches)
+	s.writeMu.RLock()
+	defer s.writeMu.RUnlock()
+	entries, err = s.sink.Query(filter)
+	return entries, s.written, true, err
+}
+
+// enqueueSink queues an entry for the sink; drops it when the queue is full
+func enqueueSink(entry EngineLog) {
+	sinkMu.RLock()
+	s := currentSink
+	sinkMu.RUnlock()
+	if s == nil {
+		return
+	}
+
+	select {
+	case s.queue <- entry:
+	default:
+		atomic.AddUint64(&s.dropped, 1)
+	}
+}
+
+// FlushLogSink writes every queued entry to the sink (registered as shutdown flusher)
+func FlushLogSink() error {
+	sinkMu.RLock()
+	s := currentSink
+	sinkMu.RUnlock()
+	if s == nil {
+		return nil
+	}
+
+	reply := make(chan error, 1)
+	s.flushReq <- reply
+	return <-reply
+}
+
+// run collects entries and writes them in batches
+func (s *asyncSink) run() {
+	ticker := time.NewTicker(sinkFlushInterval)
+	defer ticker.Stop()
+
+	batch := make([]EngineLog, 0, sinkBatchSize)
+	write := func() error {
+		if dropped := atomic.SwapUint64(&s.dropped, 0); dropped > 0 {
+			batch = append(batch, EngineLog{
+				Level:     LevelWarn,
+				Subsystem: "engine-log",
+				Message:   fmt.Sprintf("log sink queue full: dropped %d entries", dropped),
+				Timestamp: time.Now(),
+			})
+		}
+		if len(batch) == 0 {
+			return nil
+		}
+		s.writeMu.Lock()
+		err := s.sink.Write(batch)
+		// Advanced on failure too: a lost batch must not be served from the ring buffer forever
+		for _, entry := range batch {
+			if entry.Seq > s.written {
+				s.written = entry.Seq
+			}
+		}
+		s.writeMu.Unlock()
+		if err != nil {
+			// Not log.Printf: the std log bridge would feed the error back into this sink
+			fmt.Fprintf(os.Stderr, "[ENGINE-LOG] Failed to write %d log entries: %v\n", len(batch), err)
+		}
+		batch = batch[:0]
+		return err
+	}
+
+	for {
+		select {
+		case entry := <-s.queue:
+			batch = append(batch, entry)
+			if len(batch) >= sinkBatchSize {
+				write()
+			}
+		case <-ticker.C:
+			write()
+		case reply := <-s.flushReq:
+			var err error
+			for drained := false; !drained; {
+				select {
+				case entry := <-s.queue:
+					batch = append(batch, entry)
+					if len(batch) >= sinkBatchSize {
+						if werr := write(); werr != nil {
+							err = werr
+						}
+					}
+				default:
+					drained = true
+				}
+			}
+			if werr := write(); werr != nil {
+				err = werr
+			}
+			reply <- err
+		}
+	}
+}
+
+// LogRetentionPeriod reads ENGINE_LOG_RETENTION_DAYS from env (default 14 days)
+func LogRetentionPeriod() time.Duration {
+	days := 14
+	if v := os.Getenv("ENGINE_LOG_RETENTION_DAYS"); v != "" {
+		if val, err := strconv.Atoi(v); err == nil && val > 0 {
+			days = val
+		}
+	}
+	return time.Duration(days) * 24 * time.Hour
+}
+
+// StartLogPruning deletes persisted logs older than retention every interval
+// Stops when stopCh is closed
+func StartLogPruning(retention, interval time.Duration, stopCh <-chan struct{}) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			if sink := getSink(); sink != nil {
+				if removed, err := sink.Prune(time.Now().Add(-retention)); err != nil {
+					fmt.Fprintf(os.Stderr, "[ENGINE-LOG] Failed to prune logs: %v\n", err)
+				} else if removed > 0 {
+					AddLog(EngineLog{
+						Level:     LevelInfo,
+						Subsystem: "engine-log",
+						Message:   fmt.Sprintf("pruned %d log entries older than %v", removed, retention),
+					})
+				}
+			}
+			select {
+			case <-stopCh:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
@@ -1,6 +1,7 @@
 package engine
 
 import (
+	"strings"
 	"sync"
 	"time"
 )
@@ -10,32 +11,41 @@ var (
 	logBuf   = make([]EngineLog, 500) // ring buffer
 	logStart = 0                      // index of oldest entry
 	logCount = 0                      // number of valid entries (<= len(logBuf))
+	logSeq   uint64                   // last assigned EngineLog.Seq
 )
 
+// AddLog stores an entry in the ring buffer, forwards it to the persistent sink (if set)
+// and to live SSE subscribers
 func AddLog(entry EngineLog) {
 	if entry.Timestamp.IsZero() {
 		entry.Timestamp = time.Now()
 	}
+	entry.Level = strings.ToUpper(entry.Level)
+	if entry.Level == "" {
+		entry.Level = LevelInfo
+	}
 
 	logMu.Lock()
-	defer logMu.Unlock()
+	logSeq++
+	entry.Seq = logSeq
 
 	capacity := len(logBuf)
-	if capacity == 0 {
-		return
-	}
-
-	// If buffer not full, write at end.
-	if logCount < capacity {
-		idx := (logStart + logCount) % capacity
-		logBuf[idx] = entry
-		logCount++
-		return
+	if capacity > 0 {
+		if logCount < capacity {
+			// If buffer not full, write at end.
+			idx := (logStart + logCount) % capacity
+			logBuf[idx] = entry
+			logCount++
+		} else {
+			// Buffer full: overwrite oldest and advance start.
+			logBuf[logStart] = entry
+			logStart = (logStart + 1) % capacity
+		}
 	}
+	logMu.Unlock()
 
-	// Buffer full: overwrite oldest and advance start.
-	logBuf[logStart] = entry
-	logStart = (logStart + 1) % capacity
+	enqueueSink(entry)
+	publish(entry)
 }
 
 func GetLogs(limit int) []EngineLog {
@@ -63,3 +73,59 @@ func GetLogs(limit int) []EngineLog {
 	}
 	return out
 }
+
+// QueryLogs returns the last filter.Limit matching entries in chronological order
+// Uses the persistent sink when configured, merged with the ring buffer entries still queued for it
+// (the sink writes in batches every few seconds); otherwise the in-memory ring buffer only
+func QueryLogs(filter LogFilter) ([]EngineLog, error) {
+	if filter.Limit <= 0 {
+		filter.Limit = 20
+	}
+
+	persisted, written, ok, err := querySink(filter)
+	if !ok {
+		return queryBuffer(filter, 0), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	pending := queryBuffer(filter, written)
+	merged := append(persisted, pending...)
+	if len(merged) > filter.Limit {
+		merged = merged[len(merged)-filter.Limit:]
+	}
+	return merged, nil
+}
+
+// queryBuffer filters the ring buffer; afterSeq > 0 only returns entries with a higher Seq
+func queryBuffer(filter LogFilter, afterSeq uint64) []EngineLog {
+	logMu.RLock()
+	defer logMu.RUnlock()
+
+	capacity := len(logBuf)
+	var matched []EngineLog
+	// Walk newest → oldest until Limit entries matched
+	for i := logCount - 1; i >= 0; i-- {
+		entry := logBuf[(logStart+i)%capacity]
+		if entry.Seq <= afterSeq {
+			break
+		}
+		if !filter.Matches(entry) {
+			continue
+		}
+		matched = append(matched, entry)
+		if filter.Limit > 0 && len(matched) >= filter.Limit {
+			break
+		}
+	}
+
+	// Back to chronological order
+	for i, j := 0, len(matched)-1; i < j; i, j = i+1, j-1 {
+		matched[i], matched[j] = matched[j], matched[i]
+	}
+	if matched == nil {
+		matched = []EngineLog{}
+	}
+	return matched
+}
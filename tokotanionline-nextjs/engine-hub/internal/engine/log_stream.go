@@ -0,0 +1,70 @@
+package engine
+
+import "sync"
+
+// Live log subscribers (SSE /api/engines/logs/stream)
+// Slow subscribers drop entries instead of blocking AddLog
+
+const subscriberBuffer = 256
+
+var (
+	subMu       sync.Mutex
+	subscribers = make(map[chan EngineLog]struct{})
+	subClosed   bool
+)
+
+// SubscribeLogs returns a channel receiving every new entry and an unsubscribe func
+// The channel is closed by unsubscribe or by CloseLogStreams
+func SubscribeLogs() (<-chan EngineLog, func()) {
+	ch := make(chan EngineLog, subscriberBuffer)
+
+	subMu.Lock()
+	if subClosed {
+		subMu.Unlock()
+		close(ch)
+		return ch, func() {}
+	}
+	subscribers[ch] = struct{}{}
+	subMu.Unlock()
+
+	var once sync.Once
+	unsubscribe := func() {
+		once.Do(func() {
+			subMu.Lock()
+			defer subMu.Unlock()
+			if _, ok := subscribers[ch]; ok {
+				delete(subscribers, ch)
+				close(ch)
+			}
+		})
+	}
+	return ch, unsubscribe
+}
+
+// CloseLogStreams closes every subscriber channel so SSE handlers return (server shutdown)
+func CloseLogStreams() {
+	subMu.Lock()
+	defer subMu.Unlock()
+	subClosed = true
+	for ch := range subscribers {
+		delete(subscribers, ch)
+		close(ch)
+	}
+}
+
+// publish sends an entry to every subscriber without blocking
+func publish(entry EngineLog) {
+	subMu.Lock()
+	defer subMu.Unlock()
+	for ch := range subscribers {
+		select {
+		case ch <- entry:
+		default:
+		}
+	}
+}
+
+// LogsAfter returns buffered entries matching filter with Seq > afterSeq (SSE Last-Event-ID resume)
+func LogsAfter(filter LogFilter, afterSeq uint64) []EngineLog {
+	return queryBuffer(filter, afterSeq)
+}
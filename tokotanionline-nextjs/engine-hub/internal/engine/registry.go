@@ -1,12 +1,19 @@
 package engine
 
 import (
+	"context"
+	"fmt"
+	"sort"
 	"sync"
+	"time"
 )
 
 var (
 	engines = make(map[string]Engine)
 	mu      sync.RWMutex
+
+	resources   = make(map[string]func() error)
+	resourcesMu sync.RWMutex
 )
 
 // Register registers an engine in the registry
@@ -46,4 +53,251 @@ func GetStatuses() map[string]EngineStatus {
 	return result
 }
 
+// RegisterResource registers a checker for a resource engines can declare in Descriptor.Requires
+// The checker returns nil when the resource is available
+func RegisterResource(name string, check func() error) {
+	resourcesMu.Lock()
+	defer resourcesMu.Unlock()
+	resources[name] = check
+}
+
+// checkResources verifies every resource required by an engine
+func checkResources(d Descriptor) error {
+	resourcesMu.RLock()
+	defer resourcesMu.RUnlock()
+	for _, name := range d.Requires {
+		check, ok := resources[name]
+		if !ok {
+			return fmt.Errorf("required resource %q is not registered", name)
+		}
+		if err := check(); err != nil {
+			return fmt.Errorf("required resource %q unavailable: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// StartOrder returns all engine names sorted so that dependencies come first
+// Returns an error on unknown dependencies or dependency cycles
+func StartOrder() ([]string, error) {
+	all := GetAll()
+
+	names := make([]string, 0, len(all))
+	for name := range all {
+		names = append(names, name)
+	}
+	sort.Strings(names) // deterministic order between independent engines
+
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := make(map[string]int)
+	order := make([]string, 0, len(names))
 
+	var visit func(name string, path []string) error
+	visit = func(name string, path []string) error {
+		e, ok := all[name]
+		if !ok {
+			return fmt.Errorf("engine %q depends on unknown engine %q", path[len(path)-1], name)
+		}
+		switch state[name] {
+		case visited:
+			return nil
+		case visiting:
+			return fmt.Errorf("dependency cycle: %v -> %s", path, name)
+		}
+
+		state[name] = visiting
+		for _, dep := range e.Describe().DependsOn {
+			if err := visit(dep, append(path, name)); err != nil {
+				return err
+			}
+		}
+		state[name] = visited
+		order = append(order, name)
+		return nil
+	}
+
+	for _, name := range names {
+		if err := visit(name, nil); err != nil {
+			return nil, err
+		}
+	}
+	return order, nil
+}
+
+// StartEngine starts an engine after checking its dependencies are running and its resources are available
+// Engines that are already ON are left alone
+func StartEngine(ctx context.Context, name string) error {
+	e, ok := Get(name)
+	if !ok {
+		return fmt.Errorf("engine %q not found", name)
+	}
+	if e.Status() == StatusOn || e.Status() == StatusBusy {
+		return nil
+	}
+
+	d := e.Describe()
+	for _, dep := range d.DependsOn {
+		depEngine, ok := Get(dep)
+		if !ok {
+			return fmt.Errorf("engine %q depends on unknown engine %q", name, dep)
+		}
+		if status := depEngine.Status(); status != StatusOn && status != StatusBusy {
+			return fmt.Errorf("engine %q depends on %q which is %s", name, dep, status)
+		}
+	}
+	if err := checkResources(d); err != nil {
+		return fmt.Errorf("engine %q: %w", name, err)
+	}
+
+	if err := e.Start(ctx); err != nil {
+		AddLog(EngineLog{
+			Level:     "ERROR",
+			Message:   fmt.Sprintf("%s: failed to start: %v", name, err),
+			Timestamp: time.Now(),
+		})
+		return fmt.Errorf("failed to start engine %q: %w", name, err)
+	}
+	return nil
+}
+
+// StartWithDependencies starts an engine and, first, every engine it (transitively) depends on
+// Returns the names of engines that were started, in order
+func StartWithDependencies(ctx context.Context, name string) ([]string, error) {
+	order, err := StartOrder()
+	if err != nil {
+		return nil, err
+	}
+
+	needed := map[string]bool{name: true}
+	for i := len(order) - 1; i >= 0; i-- {
+		if !needed[order[i]] {
+			continue
+		}
+		e, _ := Get(order[i])
+		for _, dep := range e.Describe().DependsOn {
+			needed[dep] = true
+		}
+	}
+
+	var started []string
+	for _, n := range order {
+		if !needed[n] {
+			continue
+		}
+		e, _ := Get(n)
+		if e.Status() == StatusOn || e.Status() == StatusBusy {
+			continue
+		}
+		if err := StartEngine(ctx, n); err != nil {
+			return started, err
+		}
+		started = append(started, n)
+	}
+	return started, nil
+}
+
+// StartAll starts every registered engine in dependency order (Manual engines excluded)
+// Failures are collected per engine; dependents of a failed engine fail their dependency check
+func StartAll(ctx context.Context) map[string]error {
+	failures := make(map[string]error)
+
+	order, err := StartOrder()
+	if err != nil {
+		failures["*"] = err
+		return failures
+	}
+
+	for _, name := range order {
+		if e, _ := Get(name); e.Describe().Manual {
+			continue
+		}
+		if err := ctx.Err(); err != nil {
+			failures[name] = err
+			continue
+		}
+		if err := StartEngine(ctx, name); err != nil {
+			failures[name] = err
+		}
+	}
+	return failures
+}
+
+// StopEngine stops an engine after stopping every running engine that depends on it
+// Returns the names of engines that were stopped, in order
+func StopEngine(name string) ([]string, error) {
+	if _, ok := Get(name); !ok {
+		return nil, fmt.Errorf("engine %q not found", name)
+	}
+
+	order, err := StartOrder()
+	if err != nil {
+		return nil, err
+	}
+
+	// Collect dependents (reverse start order visits dependents before their dependencies)
+	affected := map[string]bool{name: true}
+	for _, n := range order {
+		e, _ := Get(n)
+		for _, dep := range e.Describe().DependsOn {
+			if affected[dep] {
+				affected[n] = true
+			}
+		}
+	}
+
+	var stopped []string
+	for i := len(order) - 1; i >= 0; i-- {
+		n := order[i]
+		if !affected[n] {
+			continue
+		}
+		e, _ := Get(n)
+		if e.Status() == StatusOff {
+			continue
+		}
+		if err := e.Stop(); err != nil {
+			return stopped, fmt.Errorf("failed to stop engine %q: %w", n, err)
+		}
+		stopped = append(stopped, n)
+	}
+	return stopped, nil
+}
+
+// StopAll stops every running engine in reverse dependency order
+// Each Stop is bounded by ctx; an engine that does not stop in time is reported and skipped
+func StopAll(ctx context.Context) map[string]error {
+	failures := make(map[string]error)
+
+	order, err := StartOrder()
+	if err != nil {
+		// Cycle/unknown dependency: still stop everything, order undefined
+		order = nil
+		for name := range GetAll() {
+			order = append(order, name)
+		}
+	}
+
+	for i := len(order) - 1; i >= 0; i-- {
+		e, ok := Get(order[i])
+		if !ok || e.Status() == StatusOff {
+			continue
+		}
+
+		done := make(chan error, 1)
+		go func() { done <- e.Stop() }()
+
+		select {
+		case err := <-done:
+			if err != nil {
+				failures[order[i]] = err
+			}
+		case <-ctx.Done():
+			failures[order[i]] = fmt.Errorf("stop did not finish before deadline: %w", ctx.Err())
+		}
+	}
+	return failures
+}
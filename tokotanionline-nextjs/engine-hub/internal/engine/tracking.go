@@ -1,6 +1,10 @@
 package engine
 
 import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
 	"sync"
 	"time"
 )
@@ -20,7 +24,11 @@ func (t *TrackingEngine) Name() string {
 	return "tracking"
 }
 
-func (t *TrackingEngine) Start() error {
+func (t *TrackingEngine) Start(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
 	t.mu.Lock()
 	defer t.mu.Unlock()
 	t.status = StatusOn
@@ -53,3 +61,47 @@ func (t *TrackingEngine) Status() EngineStatus {
 	defer t.mu.RUnlock()
 	return t.status
 }
+
+func (t *TrackingEngine) HealthCheck() HealthReport {
+	status := t.Status()
+	return HealthReport{
+		Status:    status,
+		Healthy:   status == StatusOn,
+		CheckedAt: time.Now(),
+	}
+}
+
+func (t *TrackingEngine) Describe() Descriptor {
+	return Descriptor{
+		Name:        t.Name(),
+		Description: "Tracking & metrics scan engine (runs jobs of engine \"tracking\")",
+	}
+}
+
+// RunJob runs a tracking job (registered as jobs handler for engine "tracking")
+// Returns a summary of the engine registry at the time of the scan
+func (t *TrackingEngine) RunJob(ctx context.Context, name string) (string, error) {
+	if t.Status() != StatusOn {
+		return "", fmt.Errorf("%s engine is not running", t.Name())
+	}
+
+	select {
+	case <-ctx.Done():
+		return "", context.Cause(ctx)
+	default:
+	}
+
+	statuses := GetStatuses()
+	names := make([]string, 0, len(statuses))
+	for engineName := range statuses {
+		names = append(names, engineName)
+	}
+	sort.Strings(names)
+
+	parts := make([]string, 0, len(names))
+	for _, engineName := range names {
+		parts = append(parts, engineName+"="+string(statuses[engineName]))
+	}
+
+	return fmt.Sprintf("%s: scanned %d engine(s) [%s]", name, len(names), strings.Join(parts, ", ")), nil
+}
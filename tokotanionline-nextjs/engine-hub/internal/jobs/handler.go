@@ -0,0 +1,34 @@
+package jobs
+
+import (
+	"context"
+	"sync"
+)
+
+// Handler executes the real work of a job and returns its output
+// ctx is cancelled on timeout or via /api/jobs/{id}/cancel
+type Handler func(ctx context.Context, job Job) (string, error)
+
+var (
+	handlerMu sync.RWMutex
+	handlers  = make(map[string]Handler)
+)
+
+// RegisterHandler registers a handler for a job Type or Engine name
+// Engines register at boot, e.g. jobs.RegisterHandler("tracking", ...)
+func RegisterHandler(key string, h Handler) {
+	handlerMu.Lock()
+	defer handlerMu.Unlock()
+	handlers[key] = h
+}
+
+// lookupHandler finds the handler for a job (by Type first, then Engine)
+func lookupHandler(job Job) (Handler, bool) {
+	handlerMu.RLock()
+	defer handlerMu.RUnlock()
+	if h, ok := handlers[job.Type]; ok {
+		return h, true
+	}
+	h, ok := handlers[job.Engine]
+	return h, ok
+}
@@ -5,11 +5,12 @@ import "time"
 type JobStatus string
 
 const (
-	JobReady   JobStatus = "READY"
-	JobQueued  JobStatus = "QUEUED"
-	JobRunning JobStatus = "RUNNING"
-	JobDone    JobStatus = "DONE"
-	JobFailed  JobStatus = "FAILED"
+	JobReady     JobStatus = "READY"
+	JobQueued    JobStatus = "QUEUED"
+	JobRunning   JobStatus = "RUNNING"
+	JobDone      JobStatus = "DONE"
+	JobFailed    JobStatus = "FAILED"
+	JobCancelled JobStatus = "CANCELLED"
 )
 
 // EngineJob matches STEP 18B specification
@@ -0,0 +1,180 @@
+package jobs
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// defaultMemoryLimit caps jobs/results kept by the in-memory store
+const defaultMemoryLimit = 5000
+
+// MemoryStore keeps jobs and results in memory (lost on restart)
+// Oldest entries are dropped once maxEntries is reached
+type MemoryStore struct {
+	mu         sync.Mutex
+	jobs       []Job
+	results    []JobResult
+	maxEntries int
+}
+
+// NewMemoryStore creates an in-memory store bounded to maxEntries jobs and results
+func NewMemoryStore(maxEntries int) *MemoryStore {
+	if maxEntries <= 0 {
+		maxEntries = defaultMemoryLimit
+	}
+	return &MemoryStore{maxEntries: maxEntries}
+}
+
+func (m *MemoryStore) AddJob(job Job) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.jobs = append(m.jobs, job)
+	if len(m.jobs) > m.maxEntries {
+		m.jobs = m.jobs[len(m.jobs)-m.maxEntries:]
+	}
+	return nil
+}
+
+func (m *MemoryStore) UpdateStatus(id string, status JobStatus, at time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for i := range m.jobs {
+		if m.jobs[i].ID == id {
+			applyStatus(&m.jobs[i], status, at)
+			return nil
+		}
+	}
+	return nil
+}
+
+func (m *MemoryStore) FindJob(id string) (*Job, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for i := range m.jobs {
+		if m.jobs[i].ID == id {
+			job := m.jobs[i]
+			return &job, nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *MemoryStore) ListJobs(filter Filter) ([]Job, int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	matched := []Job{}
+	for _, job := range m.jobs {
+		if filter.matches(job.Engine, job.Status, job.CreatedAt) {
+			matched = append(matched, job)
+		}
+	}
+	sort.SliceStable(matched, func(i, j int) bool {
+		return matched[i].CreatedAt.After(matched[j].CreatedAt)
+	})
+
+	start, end := filter.window(len(matched))
+	return matched[start:end], len(matched), nil
+}
+
+func (m *MemoryStore) AddResult(r JobResult) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.results = append(m.results, r)
+	if len(m.results) > m.maxEntries {
+		m.results = m.results[len(m.results)-m.maxEntries:]
+	}
+	return nil
+}
+
+func (m *MemoryStore) ListResults(filter Filter) ([]JobResult, int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	matched := []JobResult{}
+	for _, r := range m.results {
+		if filter.matches(r.Engine, r.Status, r.CreatedAt) {
+			matched = append(matched, r)
+		}
+	}
+	sort.SliceStable(matched, func(i, j int) bool {
+		return matched[i].CreatedAt.After(matched[j].CreatedAt)
+	})
+
+	start, end := filter.window(len(matched))
+	return matched[start:end], len(matched), nil
+}
+
+func (m *MemoryStore) Prune(before time.Time) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	removed := 0
+	keptJobs := m.jobs[:0]
+	for _, job := range m.jobs {
+		// Never prune a job that is still running
+		if job.CreatedAt.Before(before) && job.Status != JobRunning {
+			removed++
+			continue
+		}
+		keptJobs = append(keptJobs, job)
+	}
+	m.jobs = keptJobs
+
+	keptResults := m.results[:0]
+	for _, r := range m.results {
+		if r.CreatedAt.Before(before) {
+			removed++
+			continue
+		}
+		keptResults = append(keptResults, r)
+	}
+	m.results = keptResults
+
+	return removed, nil
+}
+
+// applyStatus sets status and the matching start/end timestamp
+func applyStatus(job *Job, status JobStatus, at time.Time) {
+	job.Status = status
+	switch status {
+	case JobRunning:
+		job.StartedAt = at
+	case JobDone, JobFailed, JobCancelled:
+		job.EndedAt = at
+	}
+}
+
+// matches reports whether an entry passes the engine/status/date filter
+func (f Filter) matches(engineName string, status JobStatus, createdAt time.Time) bool {
+	if f.Engine != "" && engineName != f.Engine {
+		return false
+	}
+	if f.Status != "" && status != f.Status {
+		return false
+	}
+	if !f.Since.IsZero() && createdAt.Before(f.Since) {
+		return false
+	}
+	if !f.Until.IsZero() && !createdAt.Before(f.Until) {
+		return false
+	}
+	return true
+}
+
+// window returns the slice bounds for limit/offset pagination
+func (f Filter) window(total int) (int, int) {
+	start := f.Offset
+	if start < 0 {
+		start = 0
+	}
+	if start > total {
+		start = total
+	}
+	end := total
+	if f.Limit > 0 && start+f.Limit < total {
+		end = start + f.Limit
+	}
+	return start, end
+}
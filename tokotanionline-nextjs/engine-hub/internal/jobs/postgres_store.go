@@ -0,0 +1,250 @@
+package jobs
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// PostgresStore persists jobs in "EngineHubJob" and results in "EngineHubJobResult"
+type PostgresStore struct {
+	db *sql.DB
+}
+
+// NewPostgresStore creates a store backed by the given database
+func NewPostgresStore(db *sql.DB) *PostgresStore {
+	return &PostgresStore{db: db}
+}
+
+func (p *PostgresStore) AddJob(job Job) error {
+	query := `
+		INSERT INTO "EngineHubJob" (id, engine, name, type, status, "createdAt", "startedAt", "endedAt")
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`
+	_, err := p.db.Exec(query,
+		job.ID,
+		job.Engine,
+		job.Name,
+		job.Type,
+		string(job.Status),
+		job.CreatedAt,
+		nullTime(job.StartedAt),
+		nullTime(job.EndedAt),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to insert job: %w", err)
+	}
+	return nil
+}
+
+func (p *PostgresStore) UpdateStatus(id string, status JobStatus, at time.Time) error {
+	var err error
+	switch status {
+	case JobRunning:
+		_, err = p.db.Exec(`UPDATE "EngineHubJob" SET status = $1, "startedAt" = $2 WHERE id = $3`, string(status), at, id)
+	case JobDone, JobFailed, JobCancelled:
+		_, err = p.db.Exec(`UPDATE "EngineHubJob" SET status = $1, "endedAt" = $2 WHERE id = $3`, string(status), at, id)
+	default:
+		_, err = p.db.Exec(`UPDATE "EngineHubJob" SET status = $1 WHERE id = $2`, string(status), id)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to update job status: %w", err)
+	}
+	return nil
+}
+
+func (p *PostgresStore) FindJob(id string) (*Job, error) {
+	query := `
+		SELECT id, engine, name, type, status, "createdAt", "startedAt", "endedAt"
+		FROM "EngineHubJob"
+		WHERE id = $1
+	`
+	job, err := scanJob(p.db.QueryRow(query, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query job: %w", err)
+	}
+	return job, nil
+}
+
+func (p *PostgresStore) ListJobs(filter Filter) ([]Job, int, error) {
+	where, args := filter.whereClause()
+
+	var total int
+	if err := p.db.QueryRow(`SELECT COUNT(*) FROM "EngineHubJob"`+where, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count jobs: %w", err)
+	}
+
+	query := `
+		SELECT id, engine, name, type, status, "createdAt", "startedAt", "endedAt"
+		FROM "EngineHubJob"` + where + filter.pageClause()
+	rows, err := p.db.Query(query, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to query jobs: %w", err)
+	}
+	defer rows.Close()
+
+	jobList := []Job{}
+	for rows.Next() {
+		job, err := scanJob(rows)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to scan job: %w", err)
+		}
+		jobList = append(jobList, *job)
+	}
+	return jobList, total, rows.Err()
+}
+
+func (p *PostgresStore) AddResult(r JobResult) error {
+	query := `
+		INSERT INTO "EngineHubJobResult" (id, "jobId", engine, name, status, output, error, "createdAt")
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`
+	_, err := p.db.Exec(query,
+		uuid.New().String(),
+		r.JobID,
+		r.Engine,
+		r.Name,
+		string(r.Status),
+		r.Output,
+		nullString(r.Error),
+		r.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to insert job result: %w", err)
+	}
+	return nil
+}
+
+func (p *PostgresStore) ListResults(filter Filter) ([]JobResult, int, error) {
+	where, args := filter.whereClause()
+
+	var total int
+	if err := p.db.QueryRow(`SELECT COUNT(*) FROM "EngineHubJobResult"`+where, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count job results: %w", err)
+	}
+
+	query := `
+		SELECT "jobId", engine, name, status, output, error, "createdAt"
+		FROM "EngineHubJobResult"` + where + filter.pageClause()
+	rows, err := p.db.Query(query, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to query job results: %w", err)
+	}
+	defer rows.Close()
+
+	results := []JobResult{}
+	for rows.Next() {
+		var r JobResult
+		var status string
+		var jobErr sql.NullString
+		if err := rows.Scan(&r.JobID, &r.Engine, &r.Name, &status, &r.Output, &jobErr, &r.CreatedAt); err != nil {
+			return nil, 0, fmt.Errorf("failed to scan job result: %w", err)
+		}
+		r.Status = JobStatus(status)
+		r.Error = jobErr.String
+		results = append(results, r)
+	}
+	return results, total, rows.Err()
+}
+
+func (p *PostgresStore) Prune(before time.Time) (int, error) {
+	res, err := p.db.Exec(`DELETE FROM "EngineHubJobResult" WHERE "createdAt" < $1`, before)
+	if err != nil {
+		return 0, fmt.Errorf("failed to prune job results: %w", err)
+	}
+	resultRows, _ := res.RowsAffected()
+
+	// Never prune a job that is still running
+	res, err = p.db.Exec(`DELETE FROM "EngineHubJob" WHERE "createdAt" < $1 AND status <> $2`, before, string(JobRunning))
+	if err != nil {
+		return 0, fmt.Errorf("failed to prune jobs: %w", err)
+	}
+	jobRows, _ := res.RowsAffected()
+
+	return int(resultRows + jobRows), nil
+}
+
+// rowScanner is implemented by *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanJob scans one "EngineHubJob" row
+func scanJob(row rowScanner) (*Job, error) {
+	var job Job
+	var status string
+	var startedAt, endedAt sql.NullTime
+	if err := row.Scan(&job.ID, &job.Engine, &job.Name, &job.Type, &status, &job.CreatedAt, &startedAt, &endedAt); err != nil {
+		return nil, err
+	}
+	job.Status = JobStatus(status)
+	if startedAt.Valid {
+		job.StartedAt = startedAt.Time
+	}
+	if endedAt.Valid {
+		job.EndedAt = endedAt.Time
+	}
+	return &job, nil
+}
+
+// whereClause builds the WHERE clause and args for the filter
+func (f Filter) whereClause() (string, []interface{}) {
+	var conditions []string
+	var args []interface{}
+
+	if f.Engine != "" {
+		args = append(args, f.Engine)
+		conditions = append(conditions, fmt.Sprintf("engine = $%d", len(args)))
+	}
+	if f.Status != "" {
+		args = append(args, string(f.Status))
+		conditions = append(conditions, fmt.Sprintf("status = $%d", len(args)))
+	}
+	if !f.Since.IsZero() {
+		args = append(args, f.Since)
+		conditions = append(conditions, fmt.Sprintf(`"createdAt" >= $%d`, len(args)))
+	}
+	if !f.Until.IsZero() {
+		args = append(args, f.Until)
+		conditions = append(conditions, fmt.Sprintf(`"createdAt" < $%d`, len(args)))
+	}
+
+	if len(conditions) == 0 {
+		return "", args
+	}
+	return " WHERE " + strings.Join(conditions, " AND "), args
+}
+
+// pageClause builds ORDER BY / LIMIT / OFFSET (limit and offset are ints, safe to inline)
+func (f Filter) pageClause() string {
+	clause := ` ORDER BY "createdAt" DESC`
+	if f.Limit > 0 {
+		clause += fmt.Sprintf(" LIMIT %d", f.Limit)
+	}
+	if f.Offset > 0 {
+		clause += fmt.Sprintf(" OFFSET %d", f.Offset)
+	}
+	return clause
+}
+
+// nullString maps an empty string to NULL
+func nullString(s string) interface{} {
+	if s == "" {
+		return nil
+	}
+	return s
+}
+
+// nullTime maps a zero time to NULL
+func nullTime(t time.Time) interface{} {
+	if t.IsZero() {
+		return nil
+	}
+	return t
+}
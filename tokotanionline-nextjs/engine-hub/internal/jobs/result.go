@@ -6,7 +6,9 @@ type JobResult struct {
 	JobID     string    `json:"jobId"`
 	Engine    string    `json:"engine"`
 	Name      string    `json:"name"`
+	Status    JobStatus `json:"status"` // DONE | FAILED | CANCELLED
 	Output    string    `json:"output"`
+	Error     string    `json:"error,omitempty"`
 	CreatedAt time.Time `json:"createdAt"`
 }
 
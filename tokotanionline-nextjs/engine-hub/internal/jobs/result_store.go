@@ -1,32 +1,30 @@
 package jobs
 
-import "sync"
-
-var (
-	resultMu sync.Mutex
-	results  []JobResult
-)
+import "log"
 
 func AddResult(r JobResult) {
-	resultMu.Lock()
-	defer resultMu.Unlock()
-	results = append(results, r)
+	if err := getStore().AddResult(r); err != nil {
+		log.Printf("[JOBS] Failed to add result for job %s: %v", r.JobID, err)
+	}
 }
 
 func GetResults(limit int) []JobResult {
-	resultMu.Lock()
-	defer resultMu.Unlock()
+	results, _ := QueryResults(Filter{Limit: limit})
+	return results
+}
 
-	if limit > len(results) {
-		limit = len(results)
+// QueryResults lists job results matching the filter and returns the total count before pagination
+func QueryResults(filter Filter) ([]JobResult, int) {
+	filter = filter.normalized()
+	results, total, err := getStore().ListResults(filter)
+	if err != nil {
+		log.Printf("[JOBS] Failed to list job results: %v", err)
+		return []JobResult{}, 0
 	}
-
-	start := len(results) - limit
-	if start < 0 {
-		start = 0
+	if !filter.NewestFirst {
+		for i, j := 0, len(results)-1; i < j; i, j = i+1, j-1 {
+			results[i], results[j] = results[j], results[i]
+		}
 	}
-
-	return results[start:]
+	return results, total
 }
-
-
@@ -0,0 +1,49 @@
+package jobs
+
+import (
+	"log"
+	"os"
+	"strconv"
+	"time"
+)
+
+// RetentionPeriod reads JOBS_RETENTION_DAYS from env (default 30 days)
+func RetentionPeriod() time.Duration {
+	days := 30
+	if v := os.Getenv("JOBS_RETENTION_DAYS"); v != "" {
+		if val, err := strconv.Atoi(v); err == nil && val > 0 {
+			days = val
+		}
+	}
+	return time.Duration(days) * 24 * time.Hour
+}
+
+// StartPruning deletes jobs and results older than retention every interval
+// Stops when stopCh is closed
+func StartPruning(retention, interval time.Duration, stopCh <-chan struct{}) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			prune(retention)
+			select {
+			case <-stopCh:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// prune removes entries older than retention from the current store
+func prune(retention time.Duration) {
+	removed, err := getStore().Prune(time.Now().Add(-retention))
+	if err != nil {
+		log.Printf("[JOBS] Failed to prune job history: %v", err)
+		return
+	}
+	if removed > 0 {
+		log.Printf("[JOBS] Pruned %d job entries older than %v", removed, retention)
+	}
+}
@@ -1,14 +1,47 @@
 package jobs
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"sync"
 	"time"
 
 	"github.com/google/uuid"
 
+	"engine-hub/internal/ai/usage"
 	"engine-hub/internal/engine"
 )
 
+// ErrJobCancelled is the cancel cause set by Cancel
+var ErrJobCancelled = errors.New("job cancelled")
+
+// ErrJobNotRunning is returned by Cancel when the job is not running in this process
+var ErrJobNotRunning = errors.New("job is not running")
+
+// ErrShuttingDown is the cancel cause set by Drain when its deadline passes
+var ErrShuttingDown = errors.New("server shutting down")
+
+var (
+	runningMu sync.Mutex
+	running   = make(map[string]context.CancelCauseFunc)
+	inflight  sync.WaitGroup // execute goroutines, waited by Drain
+)
+
+// handlerTimeout reads JOBS_HANDLER_TIMEOUT_SEC from env (default 300 detik)
+func handlerTimeout() time.Duration {
+	seconds := 300
+	if v := os.Getenv("JOBS_HANDLER_TIMEOUT_SEC"); v != "" {
+		if val, err := strconv.Atoi(v); err == nil && val > 0 {
+			seconds = val
+		}
+	}
+	return time.Duration(seconds) * time.Second
+}
+
 // Run creates and runs a new job (old API, kept for backward compatibility)
 func Run(engineName string, jobName string) {
 	id := uuid.New().String()
@@ -25,45 +58,8 @@ func Run(engineName string, jobName string) {
 	Add(job)
 
 	// Auto-run for backward compatibility (old API)
-	go func() {
-		defer func() {
-			if r := recover(); r != nil {
-				engine.AddLog(engine.EngineLog{
-					Level:     "ERROR",
-					Message:   fmt.Sprintf("Job failed: %v", r),
-					JobID:     id,
-					Timestamp: time.Now(),
-				})
-				Update(id, JobFailed)
-			}
-		}()
-
-		engine.AddLog(engine.EngineLog{
-			Level:     "INFO",
-			Message:   "Job started",
-			JobID:     id,
-			Timestamp: time.Now(),
-		})
-
-		Update(id, JobRunning)
-		time.Sleep(3 * time.Second) // simulasi kerja
-		Update(id, JobDone)
-
-		engine.AddLog(engine.EngineLog{
-			Level:     "INFO",
-			Message:   "Job finished",
-			JobID:     id,
-			Timestamp: time.Now(),
-		})
-
-		AddResult(JobResult{
-			JobID:     id,
-			Engine:    engineName,
-			Name:      jobName,
-			Output:    "Job completed successfully",
-			CreatedAt: time.Now(),
-		})
-	}()
+	ctx := start(job)
+	go execute(ctx, job)
 }
 
 // RunJobByID runs an existing job by ID (STEP 18B-1: Manual run only)
@@ -73,49 +69,184 @@ func RunJobByID(jobID string) {
 		return
 	}
 
+	ctx := start(*job)
+	go execute(ctx, *job)
+}
+
+// Cancel cancels a running job; the job ends CANCELLED right away and its handler sees ctx.Done()
+// (a handler that ignores ctx is abandoned, see execute)
+func Cancel(jobID string) error {
+	runningMu.Lock()
+	cancel, ok := running[jobID]
+	runningMu.Unlock()
+	if !ok {
+		return ErrJobNotRunning
+	}
+
+	cancel(ErrJobCancelled)
+	return nil
+}
+
+// Drain waits for in-flight jobs to finish
+// When ctx expires first, remaining jobs are cancelled (ErrShuttingDown) and given a short grace period to record their result
+func Drain(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		inflight.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+	}
+
+	runningMu.Lock()
+	count := len(running)
+	for _, cancel := range running {
+		cancel(ErrShuttingDown)
+	}
+	runningMu.Unlock()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+	}
+	return fmt.Errorf("cancelled %d job(s) still running at shutdown deadline: %w", count, ctx.Err())
+}
+
+// start marks the job RUNNING and registers its cancel func
+// Caller must run execute for the returned context
+func start(job Job) context.Context {
+	ctx, cancel := context.WithCancelCause(context.Background())
+
+	runningMu.Lock()
+	running[job.ID] = cancel
+	runningMu.Unlock()
+	inflight.Add(1)
+
 	engine.AddLog(engine.EngineLog{
 		Level:     "INFO",
 		Message:   "Job started",
-		JobID:     jobID,
+		JobID:     job.ID,
 		Timestamp: time.Now(),
 	})
 
-	// Update status to RUNNING
-	Update(jobID, JobRunning)
+	Update(job.ID, JobRunning)
+	return ctx
+}
 
-	// Simulate job execution
+// execute runs the registered handler with a timeout and records the result
+// On timeout/cancel the result is recorded immediately, without waiting for the handler
+func execute(parent context.Context, job Job) {
+	timeout := handlerTimeout()
+	ctx, cancel := context.WithTimeoutCause(parent, timeout, fmt.Errorf("job timed out after %v", timeout))
+	ctx = usage.WithAttribution(ctx, usage.Attribution{JobID: job.ID}) // AI calls made by the handler are billed to the job
+	defer inflight.Done()
+	defer cancel()
+	defer func() {
+		runningMu.Lock()
+		if cancelParent, ok := running[job.ID]; ok {
+			cancelParent(nil)
+			delete(running, job.ID)
+		}
+		runningMu.Unlock()
+	}()
+
+	// The handler runs in its own goroutine so cancel/timeout hold even when it ignores ctx:
+	// the job is recorded right away and the handler is abandoned (its worker and Drain are released)
+	done := make(chan handlerOutcome, 1) // buffered: an abandoned handler can still finish and exit
 	go func() {
-		defer func() {
-			if r := recover(); r != nil {
-				engine.AddLog(engine.EngineLog{
-					Level:     "ERROR",
-					Message:   fmt.Sprintf("Job failed: %v", r),
-					JobID:     jobID,
-					Timestamp: time.Now(),
-				})
-				Update(jobID, JobFailed)
-			}
-		}()
-
-		time.Sleep(3 * time.Second) // simulasi kerja
-
-		// Mark as DONE
-		Update(jobID, JobDone)
-
-		engine.AddLog(engine.EngineLog{
-			Level:     "INFO",
-			Message:   "Job finished",
-			JobID:     jobID,
-			Timestamp: time.Now(),
-		})
-
-		// Add result
-		AddResult(JobResult{
-			JobID:     jobID,
-			Engine:    job.Engine,
-			Name:      job.Name,
-			Output:    "Job completed successfully",
-			CreatedAt: time.Now(),
-		})
+		output, err := invoke(ctx, job)
+		done <- handlerOutcome{output: output, err: err}
 	}()
+
+	var output string
+	var err error
+	select {
+	case res := <-done:
+		output, err = res.output, res.err
+		// A handler that ignored ctx may still return nil after cancel/timeout
+		if err == nil && ctx.Err() != nil {
+			err = context.Cause(ctx)
+		}
+	case <-ctx.Done():
+		select {
+		case res := <-done:
+			// Handler stopped together with ctx - keep its output
+			output = res.output
+		case <-time.After(abandonGrace):
+			log.Printf("[JOBS] Job %s (%s): handler did not return after %v, abandoned", job.ID, job.Type, context.Cause(ctx))
+			engine.AddLog(engine.EngineLog{
+				Level:     "WARN",
+				Message:   fmt.Sprintf("Handler abandoned: %v", context.Cause(ctx)),
+				JobID:     job.ID,
+				Timestamp: time.Now(),
+			})
+			go func() {
+				<-done
+				log.Printf("[JOBS] Job %s (%s): abandoned handler returned", job.ID, job.Type)
+			}()
+		}
+		err = context.Cause(ctx)
+	}
+
+	status := JobDone
+	level := "INFO"
+	message := "Job finished"
+	errMsg := ""
+	if err != nil {
+		status = JobFailed
+		if errors.Is(context.Cause(ctx), ErrJobCancelled) {
+			status = JobCancelled
+		}
+		level = "ERROR"
+		errMsg = err.Error()
+		message = fmt.Sprintf("Job %s: %s", status, errMsg)
+	}
+
+	Update(job.ID, status)
+
+	engine.AddLog(engine.EngineLog{
+		Level:     level,
+		Message:   message,
+		JobID:     job.ID,
+		Timestamp: time.Now(),
+	})
+
+	AddResult(JobResult{
+		JobID:     job.ID,
+		Engine:    job.Engine,
+		Name:      job.Name,
+		Status:    status,
+		Output:    output,
+		Error:     errMsg,
+		CreatedAt: time.Now(),
+	})
+}
+
+// abandonGrace is how long execute waits for a handler to react to cancel/timeout before abandoning it
+const abandonGrace = 200 * time.Millisecond
+
+// handlerOutcome is what a handler goroutine hands back to execute
+type handlerOutcome struct {
+	output string
+	err    error
+}
+
+// invoke calls the job handler, converting panics into errors
+func invoke(ctx context.Context, job Job) (output string, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("job panicked: %v", r)
+		}
+	}()
+
+	handler, ok := lookupHandler(job)
+	if !ok {
+		return "", fmt.Errorf("no handler registered for job type %q (engine %q)", job.Type, job.Engine)
+	}
+
+	return handler(ctx, job)
 }
@@ -1,53 +1,114 @@
 package jobs
 
-import "sync"
+import (
+	"log"
+	"sync"
+	"time"
+)
+
+// Listing page size: Limit <= 0 uses DefaultListLimit, larger values are capped at MaxListLimit
+const (
+	DefaultListLimit = 50
+	MaxListLimit     = 500
+)
+
+// Filter narrows job and result listings (/api/jobs, /api/jobs/results)
+// Zero values mean "no filter"; the page holds the newest matches (Offset counts back from the newest),
+// listed oldest first like before the store existed, or newest first with NewestFirst
+type Filter struct {
+	Engine      string
+	Status      JobStatus
+	Since       time.Time
+	Until       time.Time
+	Limit       int
+	Offset      int
+	NewestFirst bool
+}
+
+// normalized clamps Limit to DefaultListLimit / MaxListLimit (stores never return an unbounded page)
+func (f Filter) normalized() Filter {
+	if f.Limit <= 0 {
+		f.Limit = DefaultListLimit
+	}
+	if f.Limit > MaxListLimit {
+		f.Limit = MaxListLimit
+	}
+	if f.Offset < 0 {
+		f.Offset = 0
+	}
+	return f
+}
+
+// Store persists jobs and job results
+// MemoryStore is the default; PostgresStore is used when a database is available
+type Store interface {
+	AddJob(job Job) error
+	UpdateStatus(id string, status JobStatus, at time.Time) error
+	FindJob(id string) (*Job, error)
+	ListJobs(filter Filter) ([]Job, int, error)
+	AddResult(r JobResult) error
+	ListResults(filter Filter) ([]JobResult, int, error)
+	Prune(before time.Time) (int, error)
+}
 
 var (
-	jobMu sync.Mutex
-	jobs  []Job
+	storeMu sync.RWMutex
+	store   Store = NewMemoryStore(defaultMemoryLimit)
 )
 
+// SetStore replaces the job store (call once at boot, before jobs are created)
+func SetStore(s Store) {
+	storeMu.Lock()
+	defer storeMu.Unlock()
+	store = s
+}
+
+// getStore returns the current job store
+func getStore() Store {
+	storeMu.RLock()
+	defer storeMu.RUnlock()
+	return store
+}
+
 func Add(job Job) {
-	jobMu.Lock()
-	defer jobMu.Unlock()
-	jobs = append(jobs, job)
+	if err := getStore().AddJob(job); err != nil {
+		log.Printf("[JOBS] Failed to add job %s: %v", job.ID, err)
+	}
 }
 
 func Update(id string, status JobStatus) {
-	jobMu.Lock()
-	defer jobMu.Unlock()
-	for i := range jobs {
-		if jobs[i].ID == id {
-			jobs[i].Status = status
-			return
-		}
+	if err := getStore().UpdateStatus(id, status, time.Now()); err != nil {
+		log.Printf("[JOBS] Failed to update job %s to %s: %v", id, status, err)
 	}
 }
 
 // FindByID finds a job by ID (STEP 18B-1: for manual run)
 func FindByID(id string) *Job {
-	jobMu.Lock()
-	defer jobMu.Unlock()
-	for i := range jobs {
-		if jobs[i].ID == id {
-			return &jobs[i]
-		}
+	job, err := getStore().FindJob(id)
+	if err != nil {
+		log.Printf("[JOBS] Failed to find job %s: %v", id, err)
+		return nil
 	}
-	return nil
+	return job
 }
 
 func List(limit int) []Job {
-	jobMu.Lock()
-	defer jobMu.Unlock()
+	jobList, _ := Query(Filter{Limit: limit})
+	return jobList
+}
 
-	if limit > len(jobs) {
-		limit = len(jobs)
+// Query lists jobs matching the filter and returns the total count before pagination
+func Query(filter Filter) ([]Job, int) {
+	filter = filter.normalized()
+	jobList, total, err := getStore().ListJobs(filter)
+	if err != nil {
+		log.Printf("[JOBS] Failed to list jobs: %v", err)
+		return []Job{}, 0
 	}
-	start := len(jobs) - limit
-	if start < 0 {
-		start = 0
+	if !filter.NewestFirst {
+		for i, j := 0, len(jobList)-1; i < j; i, j = i+1, j-1 {
+			jobList[i], jobList[j] = jobList[j], jobList[i]
+		}
 	}
-	return jobs[start:]
+	return jobList, total
 }
-
-
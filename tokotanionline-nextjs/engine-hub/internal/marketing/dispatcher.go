@@ -331,7 +331,13 @@ func (d *Dispatcher) callAdapter(
 
 	// Send event to adapter (non-blocking, error handling in adapter)
 	// Use goroutine to avoid blocking dispatcher
+	// Tracked so shutdown can wait for pending adapter calls (see Drain)
+	if !track() {
+		log.Printf("[DISPATCHER] Shutting down - adapter %s not called for event %s", integration.Type, event.ID)
+		return
+	}
 	go func() {
+		defer inflight.Done()
 		adapterResult := adapter.Send(adapterEvent)
 		
 		// Log result
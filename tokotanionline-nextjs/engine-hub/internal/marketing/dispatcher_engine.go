@@ -0,0 +1,96 @@
+package marketing
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"engine-hub/internal/engine"
+)
+
+// DispatcherEngine exposes the global Dispatcher to the engine registry
+// The database is resolved at Start time because it is opened by the content engine
+type DispatcherEngine struct {
+	dbProvider func() *sql.DB
+}
+
+// NewDispatcherEngine creates a registry adapter for the marketing dispatcher
+func NewDispatcherEngine(dbProvider func() *sql.DB) *DispatcherEngine {
+	return &DispatcherEngine{dbProvider: dbProvider}
+}
+
+// Name returns the engine registry name
+func (e *DispatcherEngine) Name() string {
+	return "marketing-dispatcher"
+}
+
+// Start initializes (once) and starts the dispatch loop
+func (e *DispatcherEngine) Start(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	db := e.dbProvider()
+	if db == nil {
+		return fmt.Errorf("database connection not initialized")
+	}
+
+	if err := InitDispatcher(db); err != nil {
+		return fmt.Errorf("failed to initialize dispatcher: %w", err)
+	}
+	return GetDispatcher().Start()
+}
+
+// Stop stops the dispatch loop
+func (e *DispatcherEngine) Stop() error {
+	if globalDispatcher == nil {
+		return fmt.Errorf("dispatcher not initialized")
+	}
+	GetDispatcher().Stop()
+	return nil
+}
+
+// Status returns the dispatcher status for the engine registry
+func (e *DispatcherEngine) Status() engine.EngineStatus {
+	if globalDispatcher != nil && globalDispatcher.IsRunning() {
+		return engine.StatusOn
+	}
+	return engine.StatusOff
+}
+
+// HealthCheck reports dispatcher stats (lastPollTime, batchSize, audit log count)
+func (e *DispatcherEngine) HealthCheck() engine.HealthReport {
+	report := engine.HealthReport{
+		Status:    e.Status(),
+		CheckedAt: time.Now(),
+	}
+	if globalDispatcher == nil {
+		report.Detail = "not initialized"
+		return report
+	}
+
+	stats := globalDispatcher.GetStats()
+	report.Metrics = stats
+	report.Healthy = report.Status == engine.StatusOn
+	if !report.Healthy {
+		report.Detail = "stopped"
+	}
+
+	return report
+}
+
+// Describe returns the dispatcher metadata
+func (e *DispatcherEngine) Describe() engine.Descriptor {
+	return engine.Descriptor{
+		Name:        e.Name(),
+		Description: "Marketing event dispatcher (MarketingEventLog → Facebook / Google / TikTok adapters)",
+		DependsOn:   []string{"content"},
+		Requires:    []string{engine.ResourceDatabase},
+		Config: map[string]string{
+			"pollInterval": "5s",
+			"batchSize":    "50",
+		},
+		Manual: true, // STEP 22B-3: no auto-fire at boot
+	}
+}
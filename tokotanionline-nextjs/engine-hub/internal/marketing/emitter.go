@@ -117,7 +117,13 @@ func (e *EventEmitter) Emit(event EventPayload) {
 	}
 
 	// Fire-and-forget: run in goroutine (async, non-blocking)
+	// Tracked so shutdown can wait for pending emits (see Drain)
+	if !track() {
+		log.Printf("[EVENT EMITTER] WARNING: Shutting down, skipping emit for event: %s", event.EventKey)
+		return
+	}
 	go func() {
+		defer inflight.Done()
 		if err := e.emitSync(event); err != nil {
 			// Error handling: log only, no panic, no retry
 			log.Printf("[EVENT EMITTER] WARNING: Failed to emit event %s: %v", event.EventKey, err)
@@ -0,0 +1,47 @@
+package marketing
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+var (
+	// inflight tracks fire-and-forget goroutines (emitter POSTs, adapter sends)
+	inflight sync.WaitGroup
+	// inflightMu guards draining so no inflight.Add can race with Drain's inflight.Wait
+	inflightMu sync.Mutex
+	draining   bool
+)
+
+// track registers one fire-and-forget send with inflight
+// Returns false once Drain has started - the caller must drop the send
+func track() bool {
+	inflightMu.Lock()
+	defer inflightMu.Unlock()
+	if draining {
+		return false
+	}
+	inflight.Add(1)
+	return true
+}
+
+// Drain stops accepting new sends, then waits for pending emits and adapter sends to finish, bounded by ctx
+func Drain(ctx context.Context) error {
+	inflightMu.Lock()
+	draining = true
+	inflightMu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		inflight.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("marketing sends still running at shutdown deadline: %w", ctx.Err())
+	}
+}
@@ -118,6 +118,7 @@ function removeMarkdownForWordCount(text: string): string {
   text = text.replace(/`[^`]+`/g, '');
   // Remove images
   text = text.replace(/!\[([^\]]*)\]\([^\)]+\)/g, '');
+  text = text.replace(/<img[^>]*>/g, '');
   return text;
 }
 
@@ -0,0 +1,6 @@
+-- AlterTable: lease / visibility timeout for RUNNING ContentJob rows (idempotent)
+ALTER TABLE "ContentJob" ADD COLUMN IF NOT EXISTS "leaseOwner" TEXT;
+ALTER TABLE "ContentJob" ADD COLUMN IF NOT EXISTS "leaseExpiresAt" TIMESTAMP(3);
+
+-- CreateIndex (idempotent)
+CREATE INDEX IF NOT EXISTS "ContentJob_status_leaseExpiresAt_idx" ON "ContentJob"("status", "leaseExpiresAt");
@@ -0,0 +1,3 @@
+-- AlterTable: persistent retry state for ContentJob (idempotent)
+ALTER TABLE "ContentJob" ADD COLUMN IF NOT EXISTS "attempts" INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE "ContentJob" ADD COLUMN IF NOT EXISTS "errorHistory" JSONB;
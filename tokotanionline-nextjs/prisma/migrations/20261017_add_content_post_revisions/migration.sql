@@ -0,0 +1,27 @@
+-- DropIndex: ContentResult.postId is no longer unique (REFRESH / OPTIMIZE results stay linked to the post)
+DROP INDEX IF EXISTS "ContentResult_postId_key";
+
+-- CreateIndex (idempotent)
+CREATE INDEX IF NOT EXISTS "ContentResult_postId_idx" ON "ContentResult"("postId");
+
+-- CreateTable: version of a BlogPost before a REFRESH / OPTIMIZE job updated it in place
+CREATE TABLE IF NOT EXISTS "EngineHubPostRevision" (
+    "id" TEXT NOT NULL,
+    "postId" TEXT NOT NULL,
+    "jobId" TEXT NOT NULL,
+    "jobType" TEXT NOT NULL,
+    "title" TEXT NOT NULL,
+    "content" TEXT NOT NULL,
+    "excerpt" TEXT,
+    "seoTitle" TEXT,
+    "seoDescription" TEXT,
+    "seoSchema" JSONB,
+    "wordCount" INTEGER,
+    "readingTime" INTEGER,
+    "createdAt" TIMESTAMP(3) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT "EngineHubPostRevision_pkey" PRIMARY KEY ("id")
+);
+
+-- CreateIndex
+CREATE INDEX IF NOT EXISTS "EngineHubPostRevision_postId_createdAt_idx" ON "EngineHubPostRevision"("postId", "createdAt");
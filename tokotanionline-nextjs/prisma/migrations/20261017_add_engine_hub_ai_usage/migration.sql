@@ -0,0 +1,28 @@
+-- CreateTable
+CREATE TABLE IF NOT EXISTS "EngineHubAIUsage" (
+    "id" TEXT NOT NULL,
+    "createdAt" TIMESTAMP(3) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    "kind" TEXT NOT NULL,
+    "caller" TEXT NOT NULL,
+    "endpoint" TEXT,
+    "provider" TEXT NOT NULL,
+    "model" TEXT NOT NULL,
+    "inputTokens" INTEGER NOT NULL DEFAULT 0,
+    "outputTokens" INTEGER NOT NULL DEFAULT 0,
+    "images" INTEGER NOT NULL DEFAULT 0,
+    "costUsd" DOUBLE PRECISION NOT NULL DEFAULT 0,
+    "jobId" TEXT,
+    "pageId" TEXT,
+    "brandId" TEXT,
+    "success" BOOLEAN NOT NULL DEFAULT true,
+    "error" TEXT,
+    "latencyMs" INTEGER NOT NULL DEFAULT 0,
+
+    CONSTRAINT "EngineHubAIUsage_pkey" PRIMARY KEY ("id")
+);
+
+-- CreateIndex (idempotent)
+CREATE INDEX IF NOT EXISTS "EngineHubAIUsage_createdAt_idx" ON "EngineHubAIUsage"("createdAt");
+CREATE INDEX IF NOT EXISTS "EngineHubAIUsage_brandId_createdAt_idx" ON "EngineHubAIUsage"("brandId", "createdAt");
+CREATE INDEX IF NOT EXISTS "EngineHubAIUsage_endpoint_createdAt_idx" ON "EngineHubAIUsage"("endpoint", "createdAt");
+CREATE INDEX IF NOT EXISTS "EngineHubAIUsage_jobId_idx" ON "EngineHubAIUsage"("jobId");
@@ -0,0 +1,40 @@
+-- CreateTable
+CREATE TABLE IF NOT EXISTS "EngineHubBatch" (
+    "id" TEXT NOT NULL,
+    "status" TEXT NOT NULL,
+    "config" JSONB NOT NULL,
+    "pauseReason" TEXT,
+    "createdAt" TIMESTAMP(3) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    "startedAt" TIMESTAMP(3),
+    "finishedAt" TIMESTAMP(3),
+    "updatedAt" TIMESTAMP(3) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT "EngineHubBatch_pkey" PRIMARY KEY ("id")
+);
+
+-- CreateTable
+CREATE TABLE IF NOT EXISTS "EngineHubBatchItem" (
+    "batchId" TEXT NOT NULL,
+    "position" INTEGER NOT NULL,
+    "keyword" TEXT NOT NULL,
+    "status" TEXT NOT NULL,
+    "attempts" INTEGER NOT NULL DEFAULT 0,
+    "failureClass" TEXT,
+    "error" TEXT,
+    "blacklisted" BOOLEAN NOT NULL DEFAULT false,
+    "title" TEXT,
+    "wordCount" INTEGER NOT NULL DEFAULT 0,
+    "imagesCount" INTEGER NOT NULL DEFAULT 0,
+    "draftRef" TEXT,
+    "experimentArm" TEXT,
+    "exposureId" TEXT,
+    "startedAt" TIMESTAMP(3),
+    "finishedAt" TIMESTAMP(3),
+
+    CONSTRAINT "EngineHubBatchItem_pkey" PRIMARY KEY ("batchId", "position"),
+    CONSTRAINT "EngineHubBatchItem_batchId_fkey" FOREIGN KEY ("batchId") REFERENCES "EngineHubBatch"("id") ON DELETE CASCADE ON UPDATE CASCADE
+);
+
+-- CreateIndex (idempotent)
+CREATE INDEX IF NOT EXISTS "EngineHubBatch_status_idx" ON "EngineHubBatch"("status");
+CREATE INDEX IF NOT EXISTS "EngineHubBatch_createdAt_idx" ON "EngineHubBatch"("createdAt");
@@ -0,0 +1,12 @@
+-- CreateTable
+CREATE TABLE IF NOT EXISTS "EngineHubCompliancePack" (
+    "name" TEXT NOT NULL,
+    "brand" TEXT,
+    "locale" TEXT,
+    "description" TEXT,
+    "rules" JSONB NOT NULL,
+    "updatedBy" TEXT,
+    "updatedAt" TIMESTAMP(3) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT "EngineHubCompliancePack_pkey" PRIMARY KEY ("name")
+);
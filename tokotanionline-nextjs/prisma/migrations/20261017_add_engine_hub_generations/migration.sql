@@ -0,0 +1,27 @@
+-- CreateTable
+CREATE TABLE IF NOT EXISTS "EngineHubGeneration" (
+    "id" TEXT NOT NULL,
+    "status" TEXT NOT NULL,
+    "state" TEXT NOT NULL,
+    "statusCode" TEXT,
+    "progress" INTEGER NOT NULL DEFAULT 0,
+    "steps" JSONB NOT NULL,
+    "request" JSONB NOT NULL,
+    "result" JSONB,
+    "error" TEXT,
+    "experiment" JSONB,
+    "exposureId" TEXT,
+    "webhookUrl" TEXT,
+    "webhookStatus" TEXT,
+    "webhookError" TEXT,
+    "createdAt" TIMESTAMP(3) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    "startedAt" TIMESTAMP(3),
+    "finishedAt" TIMESTAMP(3),
+    "updatedAt" TIMESTAMP(3) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT "EngineHubGeneration_pkey" PRIMARY KEY ("id")
+);
+
+-- CreateIndex (idempotent)
+CREATE INDEX IF NOT EXISTS "EngineHubGeneration_status_idx" ON "EngineHubGeneration"("status");
+CREATE INDEX IF NOT EXISTS "EngineHubGeneration_createdAt_idx" ON "EngineHubGeneration"("createdAt");
@@ -0,0 +1,13 @@
+-- CreateTable
+CREATE TABLE IF NOT EXISTS "EngineHubImageHash" (
+    "path" TEXT NOT NULL,
+    "hash" BIGINT NOT NULL,
+    "source" TEXT NOT NULL,
+    "role" TEXT NOT NULL,
+    "createdAt" TIMESTAMP(3) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT "EngineHubImageHash_pkey" PRIMARY KEY ("path")
+);
+
+-- CreateIndex
+CREATE INDEX IF NOT EXISTS "EngineHubImageHash_source_idx" ON "EngineHubImageHash"("source");
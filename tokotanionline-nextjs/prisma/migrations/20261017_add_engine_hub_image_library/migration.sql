@@ -0,0 +1,25 @@
+-- CreateTable
+CREATE TABLE IF NOT EXISTS "EngineHubImageLibrary" (
+    "id" TEXT NOT NULL,
+    "path" TEXT NOT NULL,
+    "url" TEXT NOT NULL,
+    "width" INTEGER NOT NULL DEFAULT 0,
+    "height" INTEGER NOT NULL DEFAULT 0,
+    "variants" JSONB,
+    "placeholder" TEXT,
+    "altText" TEXT,
+    "tags" TEXT[],
+    "keywords" TEXT[],
+    "source" TEXT,
+    "disabled" BOOLEAN NOT NULL DEFAULT false,
+    "usageCount" INTEGER NOT NULL DEFAULT 0,
+    "lastUsedAt" TIMESTAMP(3),
+    "lastUsedIn" TEXT,
+    "createdAt" TIMESTAMP(3) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    "updatedAt" TIMESTAMP(3) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT "EngineHubImageLibrary_pkey" PRIMARY KEY ("id")
+);
+
+-- CreateIndex
+CREATE UNIQUE INDEX IF NOT EXISTS "EngineHubImageLibrary_path_key" ON "EngineHubImageLibrary"("path");
@@ -0,0 +1,24 @@
+-- CreateTable
+CREATE TABLE IF NOT EXISTS "EngineHubImageRevision" (
+    "id" TEXT NOT NULL,
+    "kind" TEXT NOT NULL,
+    "slug" TEXT NOT NULL,
+    "targetId" TEXT,
+    "section" TEXT,
+    "role" TEXT NOT NULL,
+    "oldSrc" TEXT,
+    "oldRef" TEXT,
+    "newSrc" TEXT NOT NULL,
+    "newRef" TEXT NOT NULL,
+    "prompt" TEXT,
+    "altText" TEXT,
+    "draft" BOOLEAN NOT NULL DEFAULT false,
+    "restoredFrom" TEXT,
+    "requestedBy" TEXT,
+    "createdAt" TIMESTAMP(3) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT "EngineHubImageRevision_pkey" PRIMARY KEY ("id")
+);
+
+-- CreateIndex
+CREATE INDEX IF NOT EXISTS "EngineHubImageRevision_kind_slug_createdAt_idx" ON "EngineHubImageRevision"("kind", "slug", "createdAt");
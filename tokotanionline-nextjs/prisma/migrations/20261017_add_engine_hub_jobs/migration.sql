@@ -0,0 +1,35 @@
+-- CreateTable
+CREATE TABLE IF NOT EXISTS "EngineHubJob" (
+    "id" TEXT NOT NULL,
+    "engine" TEXT NOT NULL,
+    "name" TEXT NOT NULL,
+    "type" TEXT NOT NULL,
+    "status" TEXT NOT NULL,
+    "createdAt" TIMESTAMP(3) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    "startedAt" TIMESTAMP(3),
+    "endedAt" TIMESTAMP(3),
+
+    CONSTRAINT "EngineHubJob_pkey" PRIMARY KEY ("id")
+);
+
+-- CreateTable
+CREATE TABLE IF NOT EXISTS "EngineHubJobResult" (
+    "id" TEXT NOT NULL,
+    "jobId" TEXT NOT NULL,
+    "engine" TEXT NOT NULL,
+    "name" TEXT NOT NULL,
+    "status" TEXT NOT NULL,
+    "output" TEXT NOT NULL,
+    "createdAt" TIMESTAMP(3) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT "EngineHubJobResult_pkey" PRIMARY KEY ("id")
+);
+
+-- CreateIndex (idempotent)
+CREATE INDEX IF NOT EXISTS "EngineHubJob_engine_createdAt_idx" ON "EngineHubJob"("engine", "createdAt");
+CREATE INDEX IF NOT EXISTS "EngineHubJob_status_createdAt_idx" ON "EngineHubJob"("status", "createdAt");
+CREATE INDEX IF NOT EXISTS "EngineHubJob_createdAt_idx" ON "EngineHubJob"("createdAt");
+CREATE INDEX IF NOT EXISTS "EngineHubJobResult_jobId_idx" ON "EngineHubJobResult"("jobId");
+CREATE INDEX IF NOT EXISTS "EngineHubJobResult_engine_createdAt_idx" ON "EngineHubJobResult"("engine", "createdAt");
+CREATE INDEX IF NOT EXISTS "EngineHubJobResult_status_createdAt_idx" ON "EngineHubJobResult"("status", "createdAt");
+CREATE INDEX IF NOT EXISTS "EngineHubJobResult_createdAt_idx" ON "EngineHubJobResult"("createdAt");
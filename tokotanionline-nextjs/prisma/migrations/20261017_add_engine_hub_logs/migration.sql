@@ -0,0 +1,20 @@
+-- CreateTable
+CREATE TABLE IF NOT EXISTS "EngineHubLog" (
+    "id" BIGSERIAL NOT NULL,
+    "level" TEXT NOT NULL,
+    "subsystem" TEXT,
+    "message" TEXT NOT NULL,
+    "jobId" TEXT,
+    "pageId" TEXT,
+    "fields" JSONB,
+    "timestamp" TIMESTAMP(3) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT "EngineHubLog_pkey" PRIMARY KEY ("id")
+);
+
+-- CreateIndex (idempotent)
+CREATE INDEX IF NOT EXISTS "EngineHubLog_timestamp_idx" ON "EngineHubLog"("timestamp");
+CREATE INDEX IF NOT EXISTS "EngineHubLog_level_timestamp_idx" ON "EngineHubLog"("level", "timestamp");
+CREATE INDEX IF NOT EXISTS "EngineHubLog_subsystem_timestamp_idx" ON "EngineHubLog"("subsystem", "timestamp");
+CREATE INDEX IF NOT EXISTS "EngineHubLog_jobId_idx" ON "EngineHubLog"("jobId");
+CREATE INDEX IF NOT EXISTS "EngineHubLog_pageId_idx" ON "EngineHubLog"("pageId");
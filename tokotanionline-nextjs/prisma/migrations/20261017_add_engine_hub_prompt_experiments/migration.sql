@@ -0,0 +1,44 @@
+-- CreateTable
+CREATE TABLE IF NOT EXISTS "EngineHubPromptExperiment" (
+    "id" TEXT NOT NULL,
+    "name" TEXT NOT NULL,
+    "templateName" TEXT NOT NULL,
+    "arms" JSONB NOT NULL,
+    "metric" TEXT NOT NULL,
+    "minSamples" INTEGER NOT NULL DEFAULT 30,
+    "status" TEXT NOT NULL,
+    "winner" TEXT,
+    "createdBy" TEXT,
+    "createdAt" TIMESTAMP(3) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    "stoppedAt" TIMESTAMP(3),
+
+    CONSTRAINT "EngineHubPromptExperiment_pkey" PRIMARY KEY ("id")
+);
+
+-- CreateTable
+CREATE TABLE IF NOT EXISTS "EngineHubPromptExposure" (
+    "id" TEXT NOT NULL,
+    "experimentId" TEXT NOT NULL,
+    "arm" TEXT NOT NULL,
+    "promptRef" TEXT,
+    "channel" TEXT NOT NULL,
+    "unitKey" TEXT,
+    "contentKey" TEXT,
+    "failed" BOOLEAN NOT NULL DEFAULT false,
+    "error" TEXT,
+    "metrics" JSONB,
+    "pass" BOOLEAN NOT NULL DEFAULT false,
+    "seoScore" DOUBLE PRECISION,
+    "impressions" BIGINT NOT NULL DEFAULT 0,
+    "clicks" BIGINT NOT NULL DEFAULT 0,
+    "dwellSeconds" DOUBLE PRECISION,
+    "createdAt" TIMESTAMP(3) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    "updatedAt" TIMESTAMP(3) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT "EngineHubPromptExposure_pkey" PRIMARY KEY ("id")
+);
+
+-- CreateIndex (idempotent)
+CREATE INDEX IF NOT EXISTS "EngineHubPromptExperiment_templateName_status_idx" ON "EngineHubPromptExperiment"("templateName", "status");
+CREATE INDEX IF NOT EXISTS "EngineHubPromptExposure_experimentId_arm_idx" ON "EngineHubPromptExposure"("experimentId", "arm");
+CREATE INDEX IF NOT EXISTS "EngineHubPromptExposure_contentKey_idx" ON "EngineHubPromptExposure"("contentKey");
@@ -0,0 +1,37 @@
+-- CreateTable
+CREATE TABLE IF NOT EXISTS "EngineHubGenerationSample" (
+    "id" TEXT NOT NULL,
+    "createdAt" TIMESTAMP(3) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    "category" TEXT NOT NULL,
+    "contentType" TEXT NOT NULL,
+    "promptVersion" TEXT NOT NULL,
+    "pass" BOOLEAN NOT NULL,
+    "inputOutline" TEXT NOT NULL,
+    "outputText" TEXT NOT NULL,
+    "metrics" JSONB NOT NULL,
+
+    CONSTRAINT "EngineHubGenerationSample_pkey" PRIMARY KEY ("id")
+);
+
+-- CreateTable
+CREATE TABLE IF NOT EXISTS "EngineHubPromptProposal" (
+    "id" TEXT NOT NULL,
+    "templateName" TEXT NOT NULL,
+    "baseRef" TEXT NOT NULL,
+    "body" TEXT NOT NULL,
+    "status" TEXT NOT NULL,
+    "sampleCount" INTEGER NOT NULL DEFAULT 0,
+    "passingCount" INTEGER NOT NULL DEFAULT 0,
+    "note" TEXT,
+    "createdAt" TIMESTAMP(3) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    "decidedAt" TIMESTAMP(3),
+    "decidedBy" TEXT,
+    "approvedRef" TEXT,
+
+    CONSTRAINT "EngineHubPromptProposal_pkey" PRIMARY KEY ("id")
+);
+
+-- CreateIndex (idempotent)
+CREATE INDEX IF NOT EXISTS "EngineHubGenerationSample_promptVersion_createdAt_idx" ON "EngineHubGenerationSample"("promptVersion", "createdAt");
+CREATE INDEX IF NOT EXISTS "EngineHubGenerationSample_category_contentType_createdAt_idx" ON "EngineHubGenerationSample"("category", "contentType", "createdAt");
+CREATE INDEX IF NOT EXISTS "EngineHubPromptProposal_templateName_status_idx" ON "EngineHubPromptProposal"("templateName", "status");
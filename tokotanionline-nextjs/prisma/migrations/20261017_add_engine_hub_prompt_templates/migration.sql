@@ -0,0 +1,20 @@
+-- CreateTable
+CREATE TABLE IF NOT EXISTS "EngineHubPromptTemplate" (
+    "name" TEXT NOT NULL,
+    "version" INTEGER NOT NULL,
+    "body" TEXT NOT NULL,
+    "description" TEXT,
+    "createdBy" TEXT,
+    "createdAt" TIMESTAMP(3) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT "EngineHubPromptTemplate_pkey" PRIMARY KEY ("name", "version")
+);
+
+-- CreateTable
+CREATE TABLE IF NOT EXISTS "EngineHubPromptActive" (
+    "name" TEXT NOT NULL,
+    "version" INTEGER NOT NULL,
+    "updatedAt" TIMESTAMP(3) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT "EngineHubPromptActive_pkey" PRIMARY KEY ("name")
+);
@@ -0,0 +1,2 @@
+-- AlterTable: handler error for engine-hub job results (idempotent)
+ALTER TABLE "EngineHubJobResult" ADD COLUMN IF NOT EXISTS "error" TEXT;